@@ -0,0 +1,7 @@
+package v1
+
+// Hub marks NginxIngressController as the version other versions convert through
+func (*NginxIngressController) Hub() {}
+
+// Hub marks ExternalDNS as the version other versions convert through
+func (*ExternalDNS) Hub() {}
@@ -0,0 +1,151 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&ExternalDNS{}, &ExternalDNSList{})
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+//+kubebuilder:resource:shortName=edns
+
+// ExternalDNS allows users to specify desired the state of a namespace-scoped ExternalDNS deployment and includes information about the state of their resources in the form of Kubernetes events.
+type ExternalDNS struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec   ExternalDNSSpec   `json:"spec,omitempty"`
+	Status ExternalDNSStatus `json:"status,omitempty"`
+}
+
+// ExternalDNSSpec allows users to specify desired the state of a namespace-scoped ExternalDNS deployment.
+type ExternalDNSSpec struct {
+	// ResourceName is the name that will be used for the ExternalDNS deployment and related resources. Will default to the name of the ExternalDNS resource if not specified.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][-a-z0-9\.]*[a-z0-9]$`
+	// +kubebuilder:validation:Required
+	ResourceName string `json:"resourceName"`
+
+	// TenantID is the ID of the Azure tenant where the DNS zones are located.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format:=uuid
+	// +kubebuilder:validation:Pattern=`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+	TenantID *string `json:"tenantId,omitempty"`
+
+	// DNSZoneResourceIDs is a list of Azure Resource IDs of the DNS zones that the ExternalDNS controller should manage. These must be in the same resource group and be of the same type (public or private). The number of zones is currently capped at 7 but may be expanded in the future.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=7
+	// +kubebuilder:validation:XValidation:rule="self.all(item, item.split('/')[2] == self[0].split('/')[2])",message="all items must have the same subscription ID"
+	// +kubebuilder:validation:XValidation:rule="self.all(item, item.split('/')[4] == self[0].split('/')[4])",message="all items must have the same resource group"
+	// +kubebuilder:validation:XValidation:rule="self.all(item, item.split('/')[7] == self[0].split('/')[7])",message="all items must be of the same resource type"
+	// +listType:=set
+	DNSZoneResourceIDs []string `json:"dnsZoneResourceIDs"`
+
+	// ResourceTypes is a list of Kubernetes resource types that the ExternalDNS controller should manage. The supported resource types are 'ingress' and 'gateway'.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=2
+	// +kubebuilder:validation:XValidation:rule="self.all(item, item.matches('(?i)(gateway|ingress)'))",message="all items must be either 'gateway' or 'ingress'"
+	// +listType:=set
+	ResourceTypes []string `json:"resourceTypes"`
+
+	// Identity contains information about the identity that ExternalDNS will use to interface with Azure resources.
+	// +kubebuilder:validation:Required
+	Identity ExternalDNSIdentity `json:"identity"`
+
+	// Filters contains optional filters that the ExternalDNS controller should use to determine which resources to manage.
+	// +optional
+	Filters *ExternalDNSFilters `json:"filters,omitempty"`
+}
+
+// ExternalDNSIdentityType is the type of identity that ExternalDNS will use to interface with Azure resources.
+// +kubebuilder:validation:Enum=workloadIdentity;managedIdentity
+type ExternalDNSIdentityType string
+
+const (
+	// IdentityTypeWorkloadIdentity uses Workload Identity (federated OIDC) for authentication.
+	// Requires a ServiceAccount with the azure.workload.identity/client-id annotation.
+	IdentityTypeWorkloadIdentity ExternalDNSIdentityType = "workloadIdentity"
+
+	// IdentityTypeManagedIdentity uses Azure Managed Service Identity (MSI) for authentication.
+	// Requires ClientID to be specified.
+	IdentityTypeManagedIdentity ExternalDNSIdentityType = "managedIdentity"
+)
+
+// ExternalDNSIdentity contains information about the identity that ExternalDNS will use to interface with Azure resources.
+// +kubebuilder:validation:XValidation:rule="self.type == 'workloadIdentity' || self.type == '' ? has(self.serviceAccount) && self.serviceAccount != '' : true",message="serviceAccount is required when type is workloadIdentity"
+// +kubebuilder:validation:XValidation:rule="self.type == 'managedIdentity' ? has(self.clientID) && self.clientID != '' : true",message="clientID is required when type is managedIdentity"
+type ExternalDNSIdentity struct {
+	// Type is the type of identity that ExternalDNS will use to interface with Azure resources.
+	// Supported values are "workloadIdentity" and "managedIdentity".
+	// +kubebuilder:default=workloadIdentity
+	Type ExternalDNSIdentityType `json:"type,omitempty"`
+
+	// ServiceAccount is the name of the Kubernetes ServiceAccount that ExternalDNS will use to interface with Azure resources.
+	// Required when type is "workloadIdentity". The ServiceAccount must exist in the namespace where the ExternalDNS resources
+	// will be deployed (for ClusterExternalDNS, this is the ResourceNamespace) and must have the azure.workload.identity/client-id annotation.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][-a-z0-9\.]*[a-z0-9]$`
+	// +kubebuilder:validation:Optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// ClientID is the client ID of the Azure Managed Identity that ExternalDNS will use to interface with Azure resources.
+	// Required when type is "managedIdentity".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format:=uuid
+	// +kubebuilder:validation:Pattern=`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+	ClientID string `json:"clientID,omitempty"`
+}
+
+type ExternalDNSFilters struct {
+	// GatewayLabelSelector is the label selector that the ExternalDNS controller will use to filter the Gateways that it manages.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^=]+=[^=]+$`
+	GatewayLabelSelector *string `json:"gatewayLabels,omitempty"`
+
+	// RouteAndIngressLabelSelector is the label selector that the ExternalDNS controller will use to filter the HTTPRoutes and Ingresses that it manages.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^=]+=[^=]+$`
+	RouteAndIngressLabelSelector *string `json:"routeAndIngressLabels,omitempty"`
+}
+
+// ExternalDNSStatus defines the observed state of ExternalDNS.
+type ExternalDNSStatus struct {
+	// Conditions is an array of current observed conditions for the ExternalDNS
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions"`
+
+	ExternalDNSReadyReplicas       int32 `json:"externalDNSReadyReplicas"`
+	ExternalDNSUnavailableReplicas int32 `json:"externalDNSUnavailableReplicas"`
+
+	// Count of hash collisions for the managed resources. The App Routing Operator uses this field
+	// as a collision avoidance mechanism when it needs to create the name for the managed resources.
+	// +optional
+	// +kubebuilder:validation:Maximum=5
+	CollisionCount int32 `json:"collisionCount"`
+
+	// ManagedResourceRefs is a list of references to the managed resources
+	// +optional
+	ManagedResourceRefs []ManagedObjectReference `json:"managedResourceRefs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExternalDNSList contains a list of ExternalDNS.
+type ExternalDNSList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExternalDNS `json:"items"`
+}
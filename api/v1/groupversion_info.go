@@ -0,0 +1,21 @@
+// Package v1 contains API Schema definitions for the approuting v1 API group. NginxIngressController and ExternalDNS graduated to v1
+// with the same schema they had in v1alpha1. v1 is their storage version and the hub v1alpha1 converts to and from.
+// +kubebuilder:object:generate=true
+// +groupName=approuting.kubernetes.azure.com
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "approuting.kubernetes.azure.com", Version: "v1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"github.com/Azure/aks-app-routing-operator/api"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&AppRoutingConfiguration{}, &AppRoutingConfigurationList{})
+}
+
+// AppRoutingConfigurationName is the name of the only AppRoutingConfiguration the operator reads
+const AppRoutingConfigurationName = "default"
+
+// AppRoutingConfigurationSpec overlays the operator's startup flags. Omitted fields keep the value of the corresponding flag.
+type AppRoutingConfigurationSpec struct {
+	// DefaultController is the kind of default NginxIngressController to run. Overlays --default-controller.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=standard;public;private;off
+	DefaultController *string `json:"defaultController,omitempty"`
+
+	// DNSZoneResourceIDs are the Azure DNS zones App Routing manages records in. Overlays --dns-zone-ids, an empty list
+	// removes every zone.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=set
+	DNSZoneResourceIDs []string `json:"dnsZoneResourceIDs,omitempty"`
+
+	// DNSSyncInterval is how often DNS records are synced. Overlays --dns-sync-interval.
+	// +kubebuilder:validation:Optional
+	DNSSyncInterval *metav1.Duration `json:"dnsSyncInterval,omitempty"`
+
+	// ConcurrencyWatchdog configures when the concurrency watchdog sheds load from nginx pods.
+	// +kubebuilder:validation:Optional
+	ConcurrencyWatchdog *ConcurrencyWatchdogConfiguration `json:"concurrencyWatchdog,omitempty"`
+
+	// DefaultDomain configures the default domain. The default domain must be enabled with --enable-default-domain.
+	// +kubebuilder:validation:Optional
+	DefaultDomain *DefaultDomainConfiguration `json:"defaultDomain,omitempty"`
+}
+
+// ConcurrencyWatchdogConfiguration configures the concurrency watchdog
+type ConcurrencyWatchdogConfiguration struct {
+	// Threshold is the percentage of concurrent connections above the mean required to vote for load shedding. Overlays --concurrency-watchdog-threshold.
+	// +kubebuilder:validation:Optional
+	Threshold *int32 `json:"threshold,omitempty"`
+
+	// Votes is the number of votes required for a pod to be considered for load shedding. Overlays --concurrency-watchdog-votes.
+	// +kubebuilder:validation:Optional
+	Votes *int32 `json:"votes,omitempty"`
+}
+
+// DefaultDomainConfiguration configures the default domain
+type DefaultDomainConfiguration struct {
+	// ClientID is the client ID of the managed identity with federated permissions to the default domain DNS zone. Overlays --default-domain-client-id.
+	// +kubebuilder:validation:Optional
+	ClientID *string `json:"clientID,omitempty"`
+
+	// ZoneID is the resource ID of the default domain DNS zone. Overlays --default-domain-zone-id.
+	// +kubebuilder:validation:Optional
+	ZoneID *string `json:"zoneID,omitempty"`
+
+	// EnableGateway manages DNS records for Gateway API resources on the default domain. Overlays --enable-default-domain-gateway.
+	// +kubebuilder:validation:Optional
+	EnableGateway *bool `json:"enableGateway,omitempty"`
+}
+
+// AppRoutingConfigurationStatus defines the observed state of AppRoutingConfiguration
+type AppRoutingConfigurationStatus struct {
+	// Effective is the configuration in effect, the flags with the last valid spec overlaid
+	// +optional
+	Effective AppRoutingConfigurationSpec `json:"effective,omitempty"`
+
+	// Conditions is an array of current observed conditions for the AppRoutingConfiguration.
+	// Conditions can include:
+	// - "Applied": Indicates if the current spec is valid and in effect.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// AppRoutingConfigurationConditionTypeApplied indicates whether the spec is valid and in effect. An invalid spec leaves the previous configuration in effect.
+	AppRoutingConfigurationConditionTypeApplied = "Applied"
+)
+
+// AppRoutingConfiguration overlays the App Routing Operator's startup flags at runtime. Only the AppRoutingConfiguration named default is read.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=arc
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the AppRoutingConfiguration must be named default"
+type AppRoutingConfiguration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AppRoutingConfigurationSpec   `json:"spec,omitempty"`
+	Status AppRoutingConfigurationStatus `json:"status,omitempty"`
+}
+
+func (a *AppRoutingConfiguration) GetCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(a.Status.Conditions, conditionType)
+}
+
+func (a *AppRoutingConfiguration) GetConditions() *[]metav1.Condition {
+	return &a.Status.Conditions
+}
+
+func (a *AppRoutingConfiguration) GetGeneration() int64 {
+	return a.Generation
+}
+
+func (a *AppRoutingConfiguration) SetCondition(condition metav1.Condition) {
+	api.VerifyAndSetCondition(a, condition)
+}
+
+// +kubebuilder:object:root=true
+
+// AppRoutingConfigurationList contains a list of AppRoutingConfiguration
+type AppRoutingConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppRoutingConfiguration `json:"items"`
+}
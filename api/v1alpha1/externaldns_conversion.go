@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	v1 "github.com/Azure/aks-app-routing-operator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this ExternalDNS to the hub version
+func (e *ExternalDNS) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1.ExternalDNS)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+
+	src := e.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1.ExternalDNSSpec{
+		ResourceName:       src.Spec.ResourceName,
+		TenantID:           src.Spec.TenantID,
+		DNSZoneResourceIDs: src.Spec.DNSZoneResourceIDs,
+		ResourceTypes:      src.Spec.ResourceTypes,
+		Identity: v1.ExternalDNSIdentity{
+			Type:           v1.ExternalDNSIdentityType(src.Spec.Identity.Type),
+			ServiceAccount: src.Spec.Identity.ServiceAccount,
+			ClientID:       src.Spec.Identity.ClientID,
+		},
+		Filters: (*v1.ExternalDNSFilters)(src.Spec.Filters),
+	}
+	dst.Status = v1.ExternalDNSStatus{
+		Conditions:                     src.Status.Conditions,
+		ExternalDNSReadyReplicas:       src.Status.ExternalDNSReadyReplicas,
+		ExternalDNSUnavailableReplicas: src.Status.ExternalDNSUnavailableReplicas,
+		CollisionCount:                 src.Status.CollisionCount,
+		ManagedResourceRefs:            convertSlice(src.Status.ManagedResourceRefs, func(r ManagedObjectReference) v1.ManagedObjectReference { return v1.ManagedObjectReference(r) }),
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the hub version to this ExternalDNS
+func (e *ExternalDNS) ConvertFrom(hub conversion.Hub) error {
+	in, ok := hub.(*v1.ExternalDNS)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+
+	src := in.DeepCopy()
+	e.ObjectMeta = src.ObjectMeta
+	e.Spec = ExternalDNSSpec{
+		ResourceName:       src.Spec.ResourceName,
+		TenantID:           src.Spec.TenantID,
+		DNSZoneResourceIDs: src.Spec.DNSZoneResourceIDs,
+		ResourceTypes:      src.Spec.ResourceTypes,
+		Identity: ExternalDNSIdentity{
+			Type:           ExternalDNSIdentityType(src.Spec.Identity.Type),
+			ServiceAccount: src.Spec.Identity.ServiceAccount,
+			ClientID:       src.Spec.Identity.ClientID,
+		},
+		Filters: (*ExternalDNSFilters)(src.Spec.Filters),
+	}
+	e.Status = ExternalDNSStatus{
+		Conditions:                     src.Status.Conditions,
+		ExternalDNSReadyReplicas:       src.Status.ExternalDNSReadyReplicas,
+		ExternalDNSUnavailableReplicas: src.Status.ExternalDNSUnavailableReplicas,
+		CollisionCount:                 src.Status.CollisionCount,
+		ManagedResourceRefs:            convertSlice(src.Status.ManagedResourceRefs, func(r v1.ManagedObjectReference) ManagedObjectReference { return ManagedObjectReference(r) }),
+	}
+
+	return nil
+}
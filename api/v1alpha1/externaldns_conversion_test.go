@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/Azure/aks-app-routing-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func fullExternalDNS() *ExternalDNS {
+	return &ExternalDNS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "edns",
+			Namespace:  "default",
+			Generation: 2,
+			Labels:     map[string]string{"label": "value"},
+		},
+		Spec: ExternalDNSSpec{
+			ResourceName:       "edns",
+			TenantID:           ptr.To("12345678-1234-1234-1234-012345678912"),
+			DNSZoneResourceIDs: []string{"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.network/dnszones/example.com"},
+			ResourceTypes:      []string{"ingress", "gateway"},
+			Identity: ExternalDNSIdentity{
+				Type:           IdentityTypeManagedIdentity,
+				ServiceAccount: "sa",
+				ClientID:       "12345678-1234-1234-1234-012345678912",
+			},
+			Filters: &ExternalDNSFilters{
+				GatewayLabelSelector:         ptr.To("app=gateway"),
+				RouteAndIngressLabelSelector: ptr.To("app=route"),
+			},
+		},
+		Status: ExternalDNSStatus{
+			Conditions:                     []metav1.Condition{{Type: ConditionTypeExternalDNSDeploymentReady, Status: metav1.ConditionTrue, Reason: "Ready"}},
+			ExternalDNSReadyReplicas:       1,
+			ExternalDNSUnavailableReplicas: 1,
+			CollisionCount:                 1,
+			ManagedResourceRefs:            []ManagedObjectReference{{Name: "edns", Namespace: "default", Kind: "Deployment", APIGroup: "apps"}},
+		},
+	}
+}
+
+func TestExternalDNSConversion(t *testing.T) {
+	t.Run("fixture sets every field", func(t *testing.T) {
+		requireAllFieldsSet(t, "ExternalDNS", reflect.ValueOf(fullExternalDNS()))
+	})
+
+	t.Run("spoke to hub to spoke", func(t *testing.T) {
+		for _, original := range []*ExternalDNS{fullExternalDNS(), {ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"}}} {
+			hub := &v1.ExternalDNS{}
+			require.NoError(t, original.DeepCopy().ConvertTo(hub))
+			require.Equal(t, original.Spec.ResourceName, hub.Spec.ResourceName)
+
+			roundTripped := &ExternalDNS{}
+			require.NoError(t, roundTripped.ConvertFrom(hub))
+			require.Equal(t, original, roundTripped)
+		}
+	})
+
+	t.Run("hub to spoke to hub", func(t *testing.T) {
+		hub := &v1.ExternalDNS{}
+		require.NoError(t, fullExternalDNS().ConvertTo(hub))
+
+		spoke := &ExternalDNS{}
+		require.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+
+		roundTripped := &v1.ExternalDNS{}
+		require.NoError(t, spoke.ConvertTo(roundTripped))
+		require.Equal(t, hub, roundTripped)
+	})
+}
@@ -0,0 +1,271 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	v1 "github.com/Azure/aks-app-routing-operator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this NginxIngressController to the hub version
+func (n *NginxIngressController) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1.NginxIngressController)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+
+	src := n.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1.NginxIngressControllerSpec{
+		IngressClassName:         src.Spec.IngressClassName,
+		ControllerNamePrefix:     src.Spec.ControllerNamePrefix,
+		LoadBalancerAnnotations:  src.Spec.LoadBalancerAnnotations,
+		LoadBalancerSourceRanges: src.Spec.LoadBalancerSourceRanges,
+		DefaultBackendService:    (*v1.NICNamespacedName)(src.Spec.DefaultBackendService),
+		CustomHTTPErrors:         src.Spec.CustomHTTPErrors,
+		HTTPDisabled:             src.Spec.HTTPDisabled,
+		LogFormat:                src.Spec.LogFormat,
+		EnableSSLPassthrough:     src.Spec.EnableSSLPassthrough,
+		Config:                   src.Spec.Config,
+		PodTemplate:              (*v1.PodTemplate)(src.Spec.PodTemplate),
+		UpgradeChannel:           convertStringPtr[v1.UpgradeChannel](src.Spec.UpgradeChannel),
+		Version:                  src.Spec.Version,
+		ImageFlavor:              convertStringPtr[v1.ImageFlavor](src.Spec.ImageFlavor),
+		Suspend:                  src.Spec.Suspend,
+	}
+
+	if lb := src.Spec.LoadBalancer; lb != nil {
+		dst.Spec.LoadBalancer = &v1.LoadBalancer{
+			Type:                   convertStringPtr[v1.LoadBalancerType](lb.Type),
+			PublicIP:               (*v1.PublicIP)(lb.PublicIP),
+			DNSLabelName:           lb.DNSLabelName,
+			Subnet:                 lb.Subnet,
+			PrivateLinkService:     (*v1.PrivateLinkService)(lb.PrivateLinkService),
+			HealthProbeRequestPath: lb.HealthProbeRequestPath,
+			ExternalTrafficPolicy:  lb.ExternalTrafficPolicy,
+		}
+		if secondary := lb.Secondary; secondary != nil {
+			dst.Spec.LoadBalancer.Secondary = &v1.SecondaryLoadBalancer{
+				Annotations:  secondary.Annotations,
+				PublicIP:     (*v1.PublicIP)(secondary.PublicIP),
+				DNSLabelName: secondary.DNSLabelName,
+				Subnet:       secondary.Subnet,
+			}
+		}
+	}
+
+	if cert := src.Spec.DefaultSSLCertificate; cert != nil {
+		dst.Spec.DefaultSSLCertificate = &v1.DefaultSSLCertificate{
+			Secret:           (*v1.Secret)(cert.Secret),
+			KeyVaultURI:      cert.KeyVaultURI,
+			ForceSSLRedirect: cert.ForceSSLRedirect,
+		}
+	}
+
+	if scaling := src.Spec.Scaling; scaling != nil {
+		dst.Spec.Scaling = &v1.Scaling{
+			MinReplicas:             scaling.MinReplicas,
+			MaxReplicas:             scaling.MaxReplicas,
+			Threshold:               convertStringPtr[v1.Threshold](scaling.Threshold),
+			TargetMemoryUtilization: scaling.TargetMemoryUtilization,
+			Behavior:                scaling.Behavior,
+		}
+		if metric := scaling.Metric; metric != nil {
+			dst.Spec.Scaling.Metric = &v1.ScalingMetric{
+				Type:               v1.ScalingMetricType(metric.Type),
+				Source:             v1.ScalingMetricSource(metric.Source),
+				Name:               metric.Name,
+				Selector:           metric.Selector,
+				TargetAverageValue: metric.TargetAverageValue,
+			}
+		}
+	}
+
+	if protection := src.Spec.DeletionProtection; protection != nil {
+		dst.Spec.DeletionProtection = &v1.DeletionProtection{
+			Mode:                     convertStringPtr[v1.DeletionProtectionMode](protection.Mode),
+			FallbackIngressClassName: protection.FallbackIngressClassName,
+			DrainPeriodSeconds:       protection.DrainPeriodSeconds,
+		}
+	}
+
+	if shedding := src.Spec.LoadShedding; shedding != nil {
+		dst.Spec.LoadShedding = &v1.LoadShedding{
+			Mode:                convertStringPtr[v1.LoadSheddingMode](shedding.Mode),
+			Threshold:           shedding.Threshold,
+			Votes:               shedding.Votes,
+			MinPodAgeSeconds:    shedding.MinPodAgeSeconds,
+			MaxEvictionsPerHour: shedding.MaxEvictionsPerHour,
+		}
+		if signal := shedding.Signal; signal != nil {
+			dst.Spec.LoadShedding.Signal = &v1.LoadSignal{
+				Type:    v1.LoadSignalType(signal.Type),
+				Weights: (*v1.LoadSignalWeights)(signal.Weights),
+			}
+		}
+	}
+
+	dst.Status = v1.NginxIngressControllerStatus{
+		Conditions:                    src.Status.Conditions,
+		ControllerReplicas:            src.Status.ControllerReplicas,
+		ControllerReadyReplicas:       src.Status.ControllerReadyReplicas,
+		ControllerAvailableReplicas:   src.Status.ControllerAvailableReplicas,
+		ControllerUnavailableReplicas: src.Status.ControllerUnavailableReplicas,
+		CollisionCount:                src.Status.CollisionCount,
+		ManagedResourceRefs:           convertSlice(src.Status.ManagedResourceRefs, func(r ManagedObjectReference) v1.ManagedObjectReference { return v1.ManagedObjectReference(r) }),
+		CurrentVersion:                src.Status.CurrentVersion,
+		AvailableVersion:              src.Status.AvailableVersion,
+		LoadBalancerIngress:           src.Status.LoadBalancerIngress,
+		IngressCount:                  src.Status.IngressCount,
+		Ingresses:                     convertSlice(src.Status.Ingresses, func(r IngressReference) v1.IngressReference { return v1.IngressReference(r) }),
+		LoadBalancers: convertSlice(src.Status.LoadBalancers, func(lb LoadBalancerStatus) v1.LoadBalancerStatus {
+			return v1.LoadBalancerStatus{Type: v1.LoadBalancerType(lb.Type), ServiceName: lb.ServiceName, Addresses: lb.Addresses}
+		}),
+		DrainStartTime: src.Status.DrainStartTime,
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the hub version to this NginxIngressController
+func (n *NginxIngressController) ConvertFrom(hub conversion.Hub) error {
+	in, ok := hub.(*v1.NginxIngressController)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+
+	src := in.DeepCopy()
+	n.ObjectMeta = src.ObjectMeta
+	n.Spec = NginxIngressControllerSpec{
+		IngressClassName:         src.Spec.IngressClassName,
+		ControllerNamePrefix:     src.Spec.ControllerNamePrefix,
+		LoadBalancerAnnotations:  src.Spec.LoadBalancerAnnotations,
+		LoadBalancerSourceRanges: src.Spec.LoadBalancerSourceRanges,
+		DefaultBackendService:    (*NICNamespacedName)(src.Spec.DefaultBackendService),
+		CustomHTTPErrors:         src.Spec.CustomHTTPErrors,
+		HTTPDisabled:             src.Spec.HTTPDisabled,
+		LogFormat:                src.Spec.LogFormat,
+		EnableSSLPassthrough:     src.Spec.EnableSSLPassthrough,
+		Config:                   src.Spec.Config,
+		PodTemplate:              (*PodTemplate)(src.Spec.PodTemplate),
+		UpgradeChannel:           convertStringPtr[UpgradeChannel](src.Spec.UpgradeChannel),
+		Version:                  src.Spec.Version,
+		ImageFlavor:              convertStringPtr[ImageFlavor](src.Spec.ImageFlavor),
+		Suspend:                  src.Spec.Suspend,
+	}
+
+	if lb := src.Spec.LoadBalancer; lb != nil {
+		n.Spec.LoadBalancer = &LoadBalancer{
+			Type:                   convertStringPtr[LoadBalancerType](lb.Type),
+			PublicIP:               (*PublicIP)(lb.PublicIP),
+			DNSLabelName:           lb.DNSLabelName,
+			Subnet:                 lb.Subnet,
+			PrivateLinkService:     (*PrivateLinkService)(lb.PrivateLinkService),
+			HealthProbeRequestPath: lb.HealthProbeRequestPath,
+			ExternalTrafficPolicy:  lb.ExternalTrafficPolicy,
+		}
+		if secondary := lb.Secondary; secondary != nil {
+			n.Spec.LoadBalancer.Secondary = &SecondaryLoadBalancer{
+				Annotations:  secondary.Annotations,
+				PublicIP:     (*PublicIP)(secondary.PublicIP),
+				DNSLabelName: secondary.DNSLabelName,
+				Subnet:       secondary.Subnet,
+			}
+		}
+	}
+
+	if cert := src.Spec.DefaultSSLCertificate; cert != nil {
+		n.Spec.DefaultSSLCertificate = &DefaultSSLCertificate{
+			Secret:           (*Secret)(cert.Secret),
+			KeyVaultURI:      cert.KeyVaultURI,
+			ForceSSLRedirect: cert.ForceSSLRedirect,
+		}
+	}
+
+	if scaling := src.Spec.Scaling; scaling != nil {
+		n.Spec.Scaling = &Scaling{
+			MinReplicas:             scaling.MinReplicas,
+			MaxReplicas:             scaling.MaxReplicas,
+			Threshold:               convertStringPtr[Threshold](scaling.Threshold),
+			TargetMemoryUtilization: scaling.TargetMemoryUtilization,
+			Behavior:                scaling.Behavior,
+		}
+		if metric := scaling.Metric; metric != nil {
+			n.Spec.Scaling.Metric = &ScalingMetric{
+				Type:               ScalingMetricType(metric.Type),
+				Source:             ScalingMetricSource(metric.Source),
+				Name:               metric.Name,
+				Selector:           metric.Selector,
+				TargetAverageValue: metric.TargetAverageValue,
+			}
+		}
+	}
+
+	if protection := src.Spec.DeletionProtection; protection != nil {
+		n.Spec.DeletionProtection = &DeletionProtection{
+			Mode:                     convertStringPtr[DeletionProtectionMode](protection.Mode),
+			FallbackIngressClassName: protection.FallbackIngressClassName,
+			DrainPeriodSeconds:       protection.DrainPeriodSeconds,
+		}
+	}
+
+	if shedding := src.Spec.LoadShedding; shedding != nil {
+		n.Spec.LoadShedding = &LoadShedding{
+			Mode:                convertStringPtr[LoadSheddingMode](shedding.Mode),
+			Threshold:           shedding.Threshold,
+			Votes:               shedding.Votes,
+			MinPodAgeSeconds:    shedding.MinPodAgeSeconds,
+			MaxEvictionsPerHour: shedding.MaxEvictionsPerHour,
+		}
+		if signal := shedding.Signal; signal != nil {
+			n.Spec.LoadShedding.Signal = &LoadSignal{
+				Type:    LoadSignalType(signal.Type),
+				Weights: (*LoadSignalWeights)(signal.Weights),
+			}
+		}
+	}
+
+	n.Status = NginxIngressControllerStatus{
+		Conditions:                    src.Status.Conditions,
+		ControllerReplicas:            src.Status.ControllerReplicas,
+		ControllerReadyReplicas:       src.Status.ControllerReadyReplicas,
+		ControllerAvailableReplicas:   src.Status.ControllerAvailableReplicas,
+		ControllerUnavailableReplicas: src.Status.ControllerUnavailableReplicas,
+		CollisionCount:                src.Status.CollisionCount,
+		ManagedResourceRefs:           convertSlice(src.Status.ManagedResourceRefs, func(r v1.ManagedObjectReference) ManagedObjectReference { return ManagedObjectReference(r) }),
+		CurrentVersion:                src.Status.CurrentVersion,
+		AvailableVersion:              src.Status.AvailableVersion,
+		LoadBalancerIngress:           src.Status.LoadBalancerIngress,
+		IngressCount:                  src.Status.IngressCount,
+		Ingresses:                     convertSlice(src.Status.Ingresses, func(r v1.IngressReference) IngressReference { return IngressReference(r) }),
+		LoadBalancers: convertSlice(src.Status.LoadBalancers, func(lb v1.LoadBalancerStatus) LoadBalancerStatus {
+			return LoadBalancerStatus{Type: LoadBalancerType(lb.Type), ServiceName: lb.ServiceName, Addresses: lb.Addresses}
+		}),
+		DrainStartTime: src.Status.DrainStartTime,
+	}
+
+	return nil
+}
+
+// convertStringPtr converts a pointer to a string enum of one version to the same enum of another version
+func convertStringPtr[To, From ~string](from *From) *To {
+	if from == nil {
+		return nil
+	}
+
+	to := To(*from)
+	return &to
+}
+
+// convertSlice converts each item of a slice keeping nil slices nil so conversions round-trip
+func convertSlice[From, To any](from []From, convert func(From) To) []To {
+	if from == nil {
+		return nil
+	}
+
+	to := make([]To, 0, len(from))
+	for _, item := range from {
+		to = append(to, convert(item))
+	}
+	return to
+}
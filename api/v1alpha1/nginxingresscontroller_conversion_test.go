@@ -0,0 +1,195 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/Azure/aks-app-routing-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func fullNginxIngressController() *NginxIngressController {
+	return &NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nic",
+			Generation:  3,
+			Labels:      map[string]string{"label": "value"},
+			Annotations: map[string]string{"annotation": "value"},
+			Finalizers:  []string{"finalizer"},
+		},
+		Spec: NginxIngressControllerSpec{
+			IngressClassName:         "nginx.example.com",
+			ControllerNamePrefix:     "nginx-example",
+			LoadBalancerAnnotations:  map[string]string{"service.beta.kubernetes.io/azure-pip-tags": "tag=value"},
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			LoadBalancer: &LoadBalancer{
+				Type:                   ptr.To(InternalLoadBalancerType),
+				PublicIP:               &PublicIP{Name: "pip", ResourceGroup: ptr.To("rg")},
+				DNSLabelName:           ptr.To("label"),
+				Subnet:                 ptr.To("subnet"),
+				PrivateLinkService:     &PrivateLinkService{Name: ptr.To("pls"), IPConfigurationSubnet: ptr.To("pls-subnet"), VisibilitySubscriptions: []string{"*"}, AutoApprovalSubscriptions: []string{"sub"}},
+				HealthProbeRequestPath: ptr.To("/healthz"),
+				ExternalTrafficPolicy:  ptr.To(corev1.ServiceExternalTrafficPolicyCluster),
+				Secondary: &SecondaryLoadBalancer{
+					Annotations:  map[string]string{"annotation": "value"},
+					PublicIP:     &PublicIP{Name: "secondary-pip", ResourceGroup: ptr.To("rg")},
+					DNSLabelName: ptr.To("secondary-label"),
+					Subnet:       ptr.To("secondary-subnet"),
+				},
+			},
+			DefaultSSLCertificate: &DefaultSSLCertificate{
+				Secret:           &Secret{Name: "secret", Namespace: "default"},
+				KeyVaultURI:      ptr.To("https://vault.vault.azure.net/certificates/cert"),
+				ForceSSLRedirect: true,
+			},
+			DefaultBackendService: &NICNamespacedName{Name: "backend", Namespace: "default"},
+			CustomHTTPErrors:      []int32{404, 503},
+			Scaling: &Scaling{
+				MinReplicas:             ptr.To(int32(3)),
+				MaxReplicas:             ptr.To(int32(10)),
+				Threshold:               ptr.To(RapidThreshold),
+				TargetMemoryUtilization: ptr.To(int32(80)),
+				Metric: &ScalingMetric{
+					Type:               RequestsPerSecondScalingMetric,
+					Source:             ExternalScalingMetricSource,
+					Name:               "requests",
+					Selector:           &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+					TargetAverageValue: resource.MustParse("100"),
+				},
+				Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+					ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: ptr.To(int32(300))},
+				},
+			},
+			HTTPDisabled:         true,
+			LogFormat:            ptr.To("$remote_addr"),
+			EnableSSLPassthrough: true,
+			Config:               map[string]string{"proxy-body-size": "8m"},
+			PodTemplate: &PodTemplate{
+				Labels:                    map[string]string{"label": "value"},
+				Annotations:               map[string]string{"annotation": "value"},
+				Resources:                 &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				NodeSelector:              map[string]string{"pool": "ingress"},
+				Tolerations:               []corev1.Toleration{{Key: "ingress", Operator: corev1.TolerationOpExists}},
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "zone"}},
+				PriorityClassName:         "high",
+			},
+			UpgradeChannel: ptr.To(PinnedUpgradeChannel),
+			Version:        ptr.To("v1.13.7"),
+			ImageFlavor:    ptr.To(DalecImageFlavor),
+			Suspend:        ptr.To(true),
+			DeletionProtection: &DeletionProtection{
+				Mode:                     ptr.To(WarnDeletionProtectionMode),
+				FallbackIngressClassName: ptr.To("fallback"),
+				DrainPeriodSeconds:       ptr.To(int32(60)),
+			},
+			LoadShedding: &LoadShedding{
+				Mode:                ptr.To(DryRunLoadSheddingMode),
+				Threshold:           ptr.To(int32(150)),
+				Votes:               ptr.To(int32(3)),
+				MinPodAgeSeconds:    ptr.To(int32(600)),
+				MaxEvictionsPerHour: ptr.To(int32(2)),
+				Signal: &LoadSignal{
+					Type: WeightedLoadSignalType,
+					Weights: &LoadSignalWeights{
+						Connections:       ptr.To(int32(1)),
+						RequestsPerSecond: ptr.To(int32(2)),
+						UpstreamLatency:   ptr.To(int32(3)),
+						Memory:            ptr.To(int32(4)),
+					},
+				},
+			},
+		},
+		Status: NginxIngressControllerStatus{
+			Conditions:                    []metav1.Condition{{Type: ConditionTypeAvailable, Status: metav1.ConditionTrue, Reason: "Available"}},
+			ControllerReplicas:            3,
+			ControllerReadyReplicas:       2,
+			ControllerAvailableReplicas:   2,
+			ControllerUnavailableReplicas: 1,
+			CollisionCount:                1,
+			ManagedResourceRefs:           []ManagedObjectReference{{Name: "nginx", Namespace: "app-routing-system", Kind: "Deployment", APIGroup: "apps"}},
+			CurrentVersion:                "v1.13.7",
+			AvailableVersion:              "v1.14.1",
+			LoadBalancers:                 []LoadBalancerStatus{{Type: InternalLoadBalancerType, ServiceName: "nginx", Addresses: []string{"10.0.0.4"}}},
+			LoadBalancerIngress:           []corev1.LoadBalancerIngress{{IP: "10.0.0.4"}},
+			IngressCount:                  1,
+			Ingresses:                     []IngressReference{{Name: "ingress", Namespace: "default"}},
+			DrainStartTime:                &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestNginxIngressControllerConversion(t *testing.T) {
+	t.Run("fixture sets every field", func(t *testing.T) {
+		// a field missing here would be missed by the round trip below, add it when adding fields to the API
+		requireAllFieldsSet(t, "NginxIngressController", reflect.ValueOf(fullNginxIngressController()))
+	})
+
+	t.Run("spoke to hub to spoke", func(t *testing.T) {
+		for _, original := range []*NginxIngressController{fullNginxIngressController(), {ObjectMeta: metav1.ObjectMeta{Name: "empty"}}} {
+			hub := &v1.NginxIngressController{}
+			require.NoError(t, original.DeepCopy().ConvertTo(hub))
+			require.Equal(t, original.Spec.IngressClassName, hub.Spec.IngressClassName)
+
+			roundTripped := &NginxIngressController{}
+			require.NoError(t, roundTripped.ConvertFrom(hub))
+			require.Equal(t, original, roundTripped)
+		}
+	})
+
+	t.Run("hub to spoke to hub", func(t *testing.T) {
+		hub := &v1.NginxIngressController{}
+		require.NoError(t, fullNginxIngressController().ConvertTo(hub))
+
+		spoke := &NginxIngressController{}
+		require.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+
+		roundTripped := &v1.NginxIngressController{}
+		require.NoError(t, spoke.ConvertTo(roundTripped))
+		require.Equal(t, hub, roundTripped)
+	})
+
+	t.Run("conversion doesn't share memory", func(t *testing.T) {
+		original := fullNginxIngressController()
+		hub := &v1.NginxIngressController{}
+		require.NoError(t, original.ConvertTo(hub))
+
+		hub.Spec.Config["proxy-body-size"] = "1m"
+		*hub.Spec.LoadBalancer.Subnet = "other"
+		require.Equal(t, fullNginxIngressController(), original)
+	})
+}
+
+// requireAllFieldsSet fails if a field of one of this package's structs reachable from v is its zero value
+func requireAllFieldsSet(t *testing.T, path string, v reflect.Value) {
+	t.Helper()
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		require.False(t, v.IsNil(), "%s is not set", path)
+		requireAllFieldsSet(t, path, v.Elem())
+	case reflect.Slice:
+		require.NotZero(t, v.Len(), "%s is empty", path)
+		requireAllFieldsSet(t, path+"[0]", v.Index(0))
+	case reflect.Struct:
+		if v.Type().PkgPath() != reflect.TypeOf(NginxIngressController{}).PkgPath() {
+			require.False(t, v.IsZero(), "%s is not set", path)
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.Anonymous {
+				continue // TypeMeta and ObjectMeta
+			}
+			requireAllFieldsSet(t, path+"."+field.Name, v.Field(i))
+		}
+	default:
+		require.False(t, v.IsZero(), "%s is not set", path)
+	}
+}
@@ -6,9 +6,12 @@ import (
 
 	"github.com/Azure/aks-app-routing-operator/api"
 	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -21,12 +24,18 @@ func init() {
 const (
 	// MaxCollisions is the maximum number of collisions allowed when generating a name for a managed resource. This corresponds to the status.CollisionCount
 	MaxCollisions = 5
+
+	// MaxIngressReferences is the maximum number of Ingresses listed in status.ingresses. This corresponds to the status.Ingresses
+	MaxIngressReferences = 50
 )
 
 // Important: Run "make crd" to regenerate code after modifying this file
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // NginxIngressControllerSpec defines the desired state of NginxIngressController
+// +kubebuilder:validation:XValidation:rule="!has(self.upgradeChannel) || self.upgradeChannel != 'pinned' || has(self.version)",message="spec.version is required when spec.upgradeChannel is pinned"
+// +kubebuilder:validation:XValidation:rule="!has(self.version) || (has(self.upgradeChannel) && self.upgradeChannel == 'pinned')",message="spec.version can only be set when spec.upgradeChannel is pinned"
+// +kubebuilder:validation:XValidation:rule="!has(self.deletionProtection) || !has(self.deletionProtection.fallbackIngressClassName) || self.deletionProtection.fallbackIngressClassName != self.ingressClassName",message="spec.deletionProtection.fallbackIngressClassName must be different from spec.ingressClassName"
 type NginxIngressControllerSpec struct {
 	// IngressClassName is the name of the IngressClass that will be used for the NGINX Ingress Controller. Defaults to metadata.name if
 	// not specified.
@@ -56,6 +65,12 @@ type NginxIngressControllerSpec struct {
 	// deny-all annotations to restrict access  https://cloud-provider-azure.sigs.k8s.io/topics/loadbalancer/#loadbalancer-annotations
 	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
 
+	// LoadBalancer configures the Azure Load Balancer that exposes the NGINX Ingress Controller without needing to know the Azure
+	// LoadBalancer annotations. Settings here are translated into annotations on the NGINX Ingress Controller's Service and can't
+	// disagree with loadBalancerAnnotations.
+	// +optional
+	LoadBalancer *LoadBalancer `json:"loadBalancer,omitempty"`
+
 	// DefaultSSLCertificate defines whether the NginxIngressController should use a certain SSL certificate by default.
 	// If this field is omitted, no default certificate will be used.
 	// +optional
@@ -85,6 +100,387 @@ type NginxIngressControllerSpec struct {
 	// EnableSSLPassthrough is a flag that enables SSL passthrough for the NginxIngressController. This allows the controller to pass through SSL traffic without terminating it.
 	// +optional
 	EnableSSLPassthrough bool `json:"enableSSLPassthrough,omitempty"`
+
+	// Config is a map of ingress-nginx ConfigMap keys and values that are merged into the NGINX Ingress Controller's ConfigMap. See
+	// https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/ for the supported keys. Keys that App Routing
+	// manages for security reasons or that are configured through other fields of this spec are rejected and reported through the
+	// ConfigAccepted condition.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=100
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[a-z0-9][-a-z0-9]*[a-z0-9]$'))",message="keys must consist of lowercase alphanumeric characters or '-'"
+	Config map[string]string `json:"config,omitempty"`
+
+	// PodTemplate defines scheduling and resource options for the NGINX Ingress Controller pods. Fields that are omitted keep the
+	// App Routing defaults.
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+
+	// UpgradeChannel determines which NGINX Ingress Controller version is deployed. Latest upgrades to the newest version as soon as the
+	// App Routing Operator supports it. Stable uses the newest patch of the minor version before the newest one. Pinned uses spec.version
+	// and never upgrades. Defaults to latest.
+	// +kubebuilder:validation:Enum=pinned;stable;latest
+	// +optional
+	UpgradeChannel *UpgradeChannel `json:"upgradeChannel,omitempty"`
+
+	// Version is the NGINX Ingress Controller version to deploy when spec.upgradeChannel is pinned, for example v1.13.7. Available versions
+	// are reported in status.availableVersion and depend on spec.imageFlavor.
+	// +kubebuilder:validation:Pattern=`^v[0-9]+\.[0-9]+\.[0-9]+$`
+	// +optional
+	Version *string `json:"version,omitempty"`
+
+	// ImageFlavor is the build of the NGINX Ingress Controller image. Upstream is built from the ingress-nginx project. Dalec is built with
+	// Dalec and uses a different set of available versions. Defaults to the App Routing Operator's configured flavor.
+	// +kubebuilder:validation:Enum=upstream;dalec
+	// +optional
+	ImageFlavor *ImageFlavor `json:"imageFlavor,omitempty"`
+
+	// Suspend stops the App Routing Operator from writing to the NGINX Ingress Controller's managed resources so they can be edited by
+	// hand, for example during incident response. Changes made while suspended are reported and overwritten when resumed. The default
+	// NginxIngressController's spec is managed by the App Routing Operator, suspend it with the kubernetes.azure.com/nginx-suspend: "true"
+	// annotation instead.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// DeletionProtection configures how the NginxIngressController is deleted while Ingresses still use its IngressClass and how long
+	// connections drain before its load balancer is released. Without it the NginxIngressController is deleted right away.
+	// +optional
+	DeletionProtection *DeletionProtection `json:"deletionProtection,omitempty"`
+
+	// LoadShedding configures how the App Routing Operator sheds load from NGINX Ingress Controller replicas that hold much more of it
+	// than the others, like long-lived connections that stay on the replicas that existed before a scale up.
+	// +optional
+	LoadShedding *LoadShedding `json:"loadShedding,omitempty"`
+}
+
+// LoadSheddingMode is whether load is shed from NGINX Ingress Controller replicas
+type LoadSheddingMode string
+
+const (
+	// EnabledLoadSheddingMode evicts replicas
+	EnabledLoadSheddingMode LoadSheddingMode = "Enabled"
+	// DryRunLoadSheddingMode reports the evictions that would happen through events and metrics without evicting replicas
+	DryRunLoadSheddingMode LoadSheddingMode = "DryRun"
+	// OffLoadSheddingMode doesn't measure or evict replicas
+	OffLoadSheddingMode LoadSheddingMode = "Off"
+)
+
+// LoadShedding defines how load is shed from NGINX Ingress Controller replicas. Replicas whose load stays well above the average of
+// the ready replicas are evicted so their clients reconnect to the others.
+type LoadShedding struct {
+	// Mode is Enabled to evict replicas, DryRun to report the evictions that would happen through events and metrics without
+	// evicting, or Off. Defaults to Enabled.
+	// +kubebuilder:validation:Enum=Enabled;DryRun;Off
+	// +optional
+	Mode *LoadSheddingMode `json:"mode,omitempty"`
+
+	// Signal is how the load of each replica is measured. Defaults to active connections.
+	// +optional
+	Signal *LoadSignal `json:"signal,omitempty"`
+
+	// Threshold is the load a replica must have, as a percentage of the average load of the ready replicas, to get a vote for
+	// eviction. Defaults to the App Routing Operator's configured threshold.
+	// +kubebuilder:validation:Minimum=101
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	Threshold *int32 `json:"threshold,omitempty"`
+
+	// Votes is how many votes a replica needs within ten minutes before it's evicted. Replicas are voted on about once a minute.
+	// Defaults to the App Routing Operator's configured votes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Votes *int32 `json:"votes,omitempty"`
+
+	// MinPodAgeSeconds is how long a replica must have existed before it gets votes so new replicas have time to take on load.
+	// Defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	MinPodAgeSeconds *int32 `json:"minPodAgeSeconds,omitempty"`
+
+	// MaxEvictionsPerHour limits how many replicas are evicted within an hour. Defaults to no limit.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +optional
+	MaxEvictionsPerHour *int32 `json:"maxEvictionsPerHour,omitempty"`
+}
+
+// LoadSignalType is a measure of the load of an NGINX Ingress Controller replica
+type LoadSignalType string
+
+const (
+	// ConnectionsLoadSignalType is the number of active client connections
+	ConnectionsLoadSignalType LoadSignalType = "Connections"
+	// RequestsPerSecondLoadSignalType is the rate of requests handled
+	RequestsPerSecondLoadSignalType LoadSignalType = "RequestsPerSecond"
+	// UpstreamLatencyLoadSignalType is the average time upstream services take to respond
+	UpstreamLatencyLoadSignalType LoadSignalType = "UpstreamLatency"
+	// MemoryLoadSignalType is the resident memory of the NGINX processes
+	MemoryLoadSignalType LoadSignalType = "Memory"
+	// WeightedLoadSignalType combines the other signals using weights
+	WeightedLoadSignalType LoadSignalType = "Weighted"
+)
+
+// LoadSignal defines how the load of an NGINX Ingress Controller replica is measured
+// +kubebuilder:validation:XValidation:rule="(self.type == 'Weighted') == has(self.weights)",message="weights must be set if and only if type is Weighted"
+type LoadSignal struct {
+	// Type is the signal compared across replicas. Weighted combines several signals so replicas holding heavy streams, like gRPC,
+	// are shed even when their connection count looks balanced.
+	// +kubebuilder:validation:Enum=Connections;RequestsPerSecond;UpstreamLatency;Memory;Weighted
+	// +kubebuilder:validation:Required
+	Type LoadSignalType `json:"type"`
+
+	// Weights are the relative weights of each signal when type is Weighted.
+	// +optional
+	Weights *LoadSignalWeights `json:"weights,omitempty"`
+}
+
+// LoadSignalWeights are the relative weights of the signals of a Weighted load signal. Each signal is compared to its average across
+// replicas before it's weighted so signals with different units can be combined. Signals without a weight aren't used.
+// +kubebuilder:validation:XValidation:rule="has(self.connections) || has(self.requestsPerSecond) || has(self.upstreamLatency) || has(self.memory)",message="at least one weight must be set"
+type LoadSignalWeights struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Connections *int32 `json:"connections,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	RequestsPerSecond *int32 `json:"requestsPerSecond,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	UpstreamLatency *int32 `json:"upstreamLatency,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Memory *int32 `json:"memory,omitempty"`
+}
+
+// DeletionProtectionMode is what happens when a NginxIngressController is deleted while Ingresses still use its IngressClass
+type DeletionProtectionMode string
+
+const (
+	// BlockDeletionProtectionMode keeps the NginxIngressController until no Ingresses use its IngressClass
+	BlockDeletionProtectionMode DeletionProtectionMode = "Block"
+	// WarnDeletionProtectionMode deletes the NginxIngressController anyway and emits a Warning event listing the Ingresses left behind
+	WarnDeletionProtectionMode DeletionProtectionMode = "Warn"
+)
+
+// DeletionProtection defines how a NginxIngressController is deleted
+type DeletionProtection struct {
+	// Mode is Block to keep the NginxIngressController until no Ingresses use its IngressClass or Warn to delete it anyway. Ingresses
+	// are moved to fallbackIngressClassName first when it's set. Defaults to Block.
+	// +kubebuilder:validation:Enum=Block;Warn
+	// +optional
+	Mode *DeletionProtectionMode `json:"mode,omitempty"`
+
+	// FallbackIngressClassName is the IngressClass that Ingresses using this NginxIngressController's IngressClass are moved to when
+	// the NginxIngressController is deleted. The IngressClass must exist.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][-a-z0-9\.]*[a-z0-9]$`
+	// +optional
+	FallbackIngressClassName *string `json:"fallbackIngressClassName,omitempty"`
+
+	// DrainPeriodSeconds is how long the NGINX Ingress Controller keeps serving after its Ingresses are gone so clients and DNS move
+	// away before the controller pods shut down. The load balancer is released once the pods have finished their in-flight requests.
+	// Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	DrainPeriodSeconds *int32 `json:"drainPeriodSeconds,omitempty"`
+}
+
+// LoadBalancerType is whether the Azure Load Balancer is reachable from the internet or only from the virtual network
+type LoadBalancerType string
+
+const (
+	PublicLoadBalancerType   LoadBalancerType = "Public"
+	InternalLoadBalancerType LoadBalancerType = "Internal"
+)
+
+// LoadBalancer defines the Azure Load Balancer that exposes the NGINX Ingress Controller
+// +kubebuilder:validation:XValidation:rule="!has(self.publicIP) || !has(self.type) || self.type == 'Public'",message="publicIP can only be set for a Public load balancer"
+// +kubebuilder:validation:XValidation:rule="!has(self.dnsLabelName) || !has(self.type) || self.type == 'Public'",message="dnsLabelName can only be set for a Public load balancer"
+// +kubebuilder:validation:XValidation:rule="!has(self.subnet) || (has(self.type) && self.type == 'Internal')",message="subnet can only be set for an Internal load balancer"
+// +kubebuilder:validation:XValidation:rule="!has(self.privateLinkService) || (has(self.type) && self.type == 'Internal')",message="privateLinkService can only be set for an Internal load balancer"
+// +kubebuilder:validation:XValidation:rule="!has(self.secondary) || has(self.type)",message="type is required when secondary is set"
+// +kubebuilder:validation:XValidation:rule="!has(self.secondary) || !has(self.secondary.subnet) || self.type == 'Public'",message="secondary.subnet can only be set when the secondary load balancer is Internal"
+// +kubebuilder:validation:XValidation:rule="!has(self.secondary) || (!has(self.secondary.publicIP) && !has(self.secondary.dnsLabelName)) || self.type == 'Internal'",message="secondary.publicIP and secondary.dnsLabelName can only be set when the secondary load balancer is Public"
+type LoadBalancer struct {
+	// Type is whether the load balancer is Public and reachable from the internet or Internal and only reachable from the virtual
+	// network. Defaults to the Azure default which is Public.
+	// +kubebuilder:validation:Enum=Public;Internal
+	// +optional
+	Type *LoadBalancerType `json:"type,omitempty"`
+
+	// PublicIP is an existing static public IP to use for a Public load balancer
+	// +optional
+	PublicIP *PublicIP `json:"publicIP,omitempty"`
+
+	// DNSLabelName is the DNS label of the public IP of a Public load balancer. The IP is reachable at
+	// <dnsLabelName>.<location>.cloudapp.azure.com.
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9-]{1,61}[a-z0-9]$`
+	// +optional
+	DNSLabelName *string `json:"dnsLabelName,omitempty"`
+
+	// Subnet is the name of the subnet the frontend IP of an Internal load balancer is allocated from
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Subnet *string `json:"subnet,omitempty"`
+
+	// PrivateLinkService creates an Azure Private Link Service in front of an Internal load balancer
+	// +optional
+	PrivateLinkService *PrivateLinkService `json:"privateLinkService,omitempty"`
+
+	// HealthProbeRequestPath is the HTTP path Azure probes to determine the health of the NGINX Ingress Controller
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	// +optional
+	HealthProbeRequestPath *string `json:"healthProbeRequestPath,omitempty"`
+
+	// ExternalTrafficPolicy is the externalTrafficPolicy of the NGINX Ingress Controller's Service. Local preserves the client source IP.
+	// Defaults to Local.
+	// +kubebuilder:validation:Enum=Local;Cluster
+	// +optional
+	ExternalTrafficPolicy *corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// Secondary exposes the NGINX Ingress Controller through a second load balancer of the opposite type so the same Ingresses are
+	// reachable both from the internet and from the virtual network. Ingresses of this NginxIngressController report the address of the
+	// first load balancer and both addresses are reported in status.loadBalancers. When App Routing's external-dns publishes the second
+	// load balancer, the Ingresses are annotated with kubernetes.azure.com/external-dns-zone-type so the Public address is only
+	// published into public DNS zones and the Internal address into private DNS zones.
+	// +optional
+	Secondary *SecondaryLoadBalancer `json:"secondary,omitempty"`
+}
+
+// SecondaryLoadBalancer defines the second Azure Load Balancer of a NginxIngressController. Its type is the opposite of spec.loadBalancer.type
+// and it shares spec.loadBalancerSourceRanges, healthProbeRequestPath and externalTrafficPolicy with the first one.
+type SecondaryLoadBalancer struct {
+	// Annotations are additional Azure LoadBalancer annotations to apply to the second load balancer's Service
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// PublicIP is an existing static public IP to use when the second load balancer is Public
+	// +optional
+	PublicIP *PublicIP `json:"publicIP,omitempty"`
+
+	// DNSLabelName is the DNS label of the public IP when the second load balancer is Public
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9-]{1,61}[a-z0-9]$`
+	// +optional
+	DNSLabelName *string `json:"dnsLabelName,omitempty"`
+
+	// Subnet is the name of the subnet the frontend IP is allocated from when the second load balancer is Internal
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Subnet *string `json:"subnet,omitempty"`
+}
+
+// LoadBalancerStatus is the observed state of one of the NGINX Ingress Controller's load balancers
+type LoadBalancerStatus struct {
+	// Type is whether the load balancer is Public or Internal
+	Type LoadBalancerType `json:"type"`
+
+	// ServiceName is the name of the load balancer's Service
+	ServiceName string `json:"serviceName"`
+
+	// Addresses are the IPs or hostnames of the load balancer. Empty until Azure provisions the load balancer.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// PublicIP is an existing Azure static public IP
+type PublicIP struct {
+	// Name is the name of the public IP
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// ResourceGroup is the resource group of the public IP. Defaults to the node resource group of the cluster.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ResourceGroup *string `json:"resourceGroup,omitempty"`
+}
+
+// PrivateLinkService defines an Azure Private Link Service for an Internal load balancer
+type PrivateLinkService struct {
+	// Name is the name of the Private Link Service. Defaults to a name generated by Azure.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// IPConfigurationSubnet is the name of the subnet the Private Link Service's NAT IPs are allocated from. Defaults to the subnet of
+	// the load balancer.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	IPConfigurationSubnet *string `json:"ipConfigurationSubnet,omitempty"`
+
+	// VisibilitySubscriptions are the Azure subscription IDs that can find the Private Link Service. Use "*" to make it visible to
+	// all subscriptions. Defaults to only the cluster's subscription.
+	// +optional
+	VisibilitySubscriptions []string `json:"visibilitySubscriptions,omitempty"`
+
+	// AutoApprovalSubscriptions are the Azure subscription IDs whose private endpoint connections are approved automatically
+	// +optional
+	AutoApprovalSubscriptions []string `json:"autoApprovalSubscriptions,omitempty"`
+}
+
+type UpgradeChannel string
+
+const (
+	PinnedUpgradeChannel UpgradeChannel = "pinned"
+	StableUpgradeChannel UpgradeChannel = "stable"
+	LatestUpgradeChannel UpgradeChannel = "latest"
+)
+
+type ImageFlavor string
+
+const (
+	UpstreamImageFlavor ImageFlavor = "upstream"
+	DalecImageFlavor    ImageFlavor = "dalec"
+)
+
+// PodTemplate holds scheduling and resource options applied to the NGINX Ingress Controller pods
+type PodTemplate struct {
+	// Labels are extra labels added to the NGINX Ingress Controller pods. Labels App Routing uses to select the pods can't be overridden.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=20
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are extra annotations added to the NGINX Ingress Controller pods. Annotations App Routing sets can't be overridden.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=20
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Resources are the compute resource requests and limits of the NGINX Ingress Controller container. They're set per resource on
+	// top of the default requests of 500m CPU and 127Mi memory. A default request is lowered to a limit that's set below it without a request.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector is a selector which must match a node's labels for the NGINX Ingress Controller pods to be scheduled on that node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the NGINX Ingress Controller pods in addition to the CriticalAddonsOnly toleration App Routing sets.
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TopologySpreadConstraints are added to the NGINX Ingress Controller pods in addition to the default constraint that spreads the
+	// pods across nodes. Constraints without a labelSelector select the NGINX Ingress Controller pods.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of the NGINX Ingress Controller pods. Defaults to system-cluster-critical.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][-a-z0-9\.]*[a-z0-9]$`
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
 
 // DefaultSSLCertificate holds a secret in the form of a secret struct with name and namespace properties or a key vault uri
@@ -153,6 +549,23 @@ type Scaling struct {
 	// +kubebuilder:validation:Enum=rapid;balanced;steady;
 	// +optional
 	Threshold *Threshold `json:"threshold,omitempty"`
+
+	// TargetMemoryUtilization is the target average memory utilization, as a percentage of the requested memory, of the Ingress
+	// Controller pods. Memory isn't used for scaling if unspecified.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	TargetMemoryUtilization *int32 `json:"targetMemoryUtilization,omitempty"`
+
+	// Metric is an NGINX traffic metric the Ingress Controller scales on in addition to CPU and memory. This requires a metrics adapter,
+	// such as prometheus-adapter or KEDA, serving the metric from the NGINX metrics Service through the custom or external metrics API.
+	// +optional
+	Metric *ScalingMetric `json:"metric,omitempty"`
+
+	// Behavior configures the scale up and scale down behavior of the Ingress Controller, such as stabilization windows and rate
+	// limiting policies. The Kubernetes HorizontalPodAutoscaler defaults are used if unspecified.
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
 }
 
 type Threshold string
@@ -163,6 +576,53 @@ const (
 	SteadyThreshold   Threshold = "steady"
 )
 
+// ScalingMetric is an NGINX traffic metric used to scale the Ingress Controller
+// +kubebuilder:validation:XValidation:rule="self.source == 'External' || !has(self.selector)",message="selector can only be set for External metrics"
+type ScalingMetric struct {
+	// Type is the NGINX traffic metric to scale on. RequestsPerSecond is the rate of requests handled by each pod. ActiveConnections
+	// is the number of active client connections to each pod.
+	// +kubebuilder:validation:Enum=RequestsPerSecond;ActiveConnections
+	// +kubebuilder:validation:Required
+	Type ScalingMetricType `json:"type"`
+
+	// Source is the metrics API serving the metric. Pods reads the per-pod metric from the custom metrics API. External reads the metric
+	// from the external metrics API. Defaults to Pods.
+	// +kubebuilder:validation:Enum=Pods;External
+	// +kubebuilder:default:=Pods
+	// +optional
+	Source ScalingMetricSource `json:"source,omitempty"`
+
+	// Name overrides the name of the metric served by the metrics adapter. Defaults to nginx_ingress_controller_requests_per_second for
+	// RequestsPerSecond and nginx_ingress_controller_nginx_process_connections for ActiveConnections.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector selects the External metric series that belong to this Ingress Controller. Defaults to series labelled with the
+	// Ingress Controller pods' app label.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// TargetAverageValue is the target value of the metric averaged across the Ingress Controller pods
+	// +kubebuilder:validation:Required
+	TargetAverageValue resource.Quantity `json:"targetAverageValue"`
+}
+
+type ScalingMetricType string
+
+const (
+	RequestsPerSecondScalingMetric ScalingMetricType = "RequestsPerSecond"
+	ActiveConnectionsScalingMetric ScalingMetricType = "ActiveConnections"
+)
+
+type ScalingMetricSource string
+
+const (
+	PodsScalingMetricSource     ScalingMetricSource = "Pods"
+	ExternalScalingMetricSource ScalingMetricSource = "External"
+)
+
 // NginxIngressControllerStatus defines the observed state of NginxIngressController
 type NginxIngressControllerStatus struct {
 	// Conditions is an array of current observed conditions for the NGINX Ingress Controller
@@ -198,6 +658,44 @@ type NginxIngressControllerStatus struct {
 	// ManagedResourceRefs is a list of references to the managed resources
 	// +optional
 	ManagedResourceRefs []ManagedObjectReference `json:"managedResourceRefs,omitempty"`
+
+	// CurrentVersion is the NGINX Ingress Controller version that is fully rolled out
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// AvailableVersion is the newest NGINX Ingress Controller version available for the spec.imageFlavor. An upgrade is available
+	// when it differs from status.currentVersion.
+	// +optional
+	AvailableVersion string `json:"availableVersion,omitempty"`
+
+	// LoadBalancers are the load balancers exposing the NGINX Ingress Controller
+	// +optional
+	// +listType=map
+	// +listMapKey=serviceName
+	LoadBalancers []LoadBalancerStatus `json:"loadBalancers,omitempty"`
+
+	// LoadBalancerIngress is the list of ingress points of the NGINX Ingress Controller's load balancer Service. Empty until Azure
+	// provisions the load balancer.
+	// +optional
+	// +listType=atomic
+	LoadBalancerIngress []corev1.LoadBalancerIngress `json:"loadBalancerIngress,omitempty"`
+
+	// IngressCount is the number of Ingresses using the NGINX Ingress Controller's IngressClass through spec.ingressClassName or,
+	// when that isn't set, the kubernetes.io/ingress.class annotation
+	// +optional
+	IngressCount int32 `json:"ingressCount,omitempty"`
+
+	// Ingresses are the Ingresses using the NGINX Ingress Controller's IngressClass sorted by namespace and name. Only the first 50
+	// are listed, status.ingressCount is the total.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=50
+	Ingresses []IngressReference `json:"ingresses,omitempty"`
+
+	// DrainStartTime is when the deleted NGINX Ingress Controller started draining connections. The drain period of
+	// spec.deletionProtection is measured from it.
+	// +optional
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
 }
 
 const (
@@ -225,8 +723,42 @@ const (
 	// - "False" when the NGINX Ingress Controller availability is not progressing
 	// - "Unknown" when the NGINX Ingress Controller availability's progress cannot be determined
 	ConditionTypeProgressing = "Progressing"
+
+	// ConditionTypeConfigAccepted indicates whether every key in spec.config was merged into the NGINX ConfigMap. Its condition status is one of
+	// - "True" when all keys in spec.config were accepted
+	// - "False" when one or more keys were rejected. The message lists the rejected keys and the reason each was rejected
+	ConditionTypeConfigAccepted = "ConfigAccepted"
+
+	// ConditionTypeVersionRollout indicates the progress of a staged rollout of a new NGINX Ingress Controller version. Its condition status is one of
+	// - "True" when the NGINX Ingress Controller runs the version of its upgrade channel
+	// - "False" when the new version is waiting for its turn, baking, was rolled back or the rollout is halted. The reason says which
+	ConditionTypeVersionRollout = "VersionRollout"
+
+	// ConditionTypeLoadBalancerReady indicates whether the load balancer Services of the NGINX Ingress Controller have an address. Its condition status is one of
+	// - "True" when every load balancer Service has an address
+	// - "False" when a load balancer Service still has no address after the provisioning timeout
+	// - "Unknown" when a load balancer Service is waiting for Azure to provision an address
+	ConditionTypeLoadBalancerReady = "LoadBalancerReady"
+
+	// ConditionTypeSuspended indicates whether the App Routing Operator has stopped writing to the NGINX Ingress Controller's managed resources. Its condition status is one of
+	// - "True" when spec.suspend or the kubernetes.azure.com/nginx-suspend annotation is set and managed resources are left as they are
+	// - "False" when managed resources are reconciled. After a resume the message lists the resources that were changed while suspended
+	ConditionTypeSuspended = "Suspended"
+
+	// ConditionTypeDeleting indicates the progress of deleting the NGINX Ingress Controller. It's only set once the NginxIngressController is deleted. Its condition status is
+	// - "True" with a reason saying whether deletion is blocked by Ingresses, moving Ingresses to the fallback IngressClass, draining connections or waiting for pods to shut down
+	ConditionTypeDeleting = "Deleting"
 )
 
+// IngressReference is a reference to an Ingress
+type IngressReference struct {
+	// Name is the name of the Ingress
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Ingress
+	Namespace string `json:"namespace"`
+}
+
 // ManagedObjectReference is a reference to an object
 type ManagedObjectReference struct {
 	// Name is the name of the managed object
@@ -250,6 +782,7 @@ type ManagedObjectReference struct {
 //+kubebuilder:printcolumn:name="IngressClass",type="string",JSONPath=`.spec.ingressClassName`
 //+kubebuilder:printcolumn:name="ControllerNamePrefix",type="string",JSONPath=`.spec.controllerNamePrefix`
 //+kubebuilder:printcolumn:name="Available",type="string",JSONPath=`.status.conditions[?(@.type=="Available")].status`
+//+kubebuilder:printcolumn:name="Version",type="string",JSONPath=`.status.currentVersion`,priority=1
 
 // NginxIngressController is the Schema for the nginxingresscontrollers API
 type NginxIngressController struct {
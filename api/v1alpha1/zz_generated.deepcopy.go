@@ -5,10 +5,134 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRoutingConfiguration) DeepCopyInto(out *AppRoutingConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRoutingConfiguration.
+func (in *AppRoutingConfiguration) DeepCopy() *AppRoutingConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRoutingConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AppRoutingConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRoutingConfigurationList) DeepCopyInto(out *AppRoutingConfigurationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AppRoutingConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRoutingConfigurationList.
+func (in *AppRoutingConfigurationList) DeepCopy() *AppRoutingConfigurationList {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRoutingConfigurationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AppRoutingConfigurationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRoutingConfigurationSpec) DeepCopyInto(out *AppRoutingConfigurationSpec) {
+	*out = *in
+	if in.DefaultController != nil {
+		in, out := &in.DefaultController, &out.DefaultController
+		*out = new(string)
+		**out = **in
+	}
+	if in.DNSZoneResourceIDs != nil {
+		in, out := &in.DNSZoneResourceIDs, &out.DNSZoneResourceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSSyncInterval != nil {
+		in, out := &in.DNSSyncInterval, &out.DNSSyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ConcurrencyWatchdog != nil {
+		in, out := &in.ConcurrencyWatchdog, &out.ConcurrencyWatchdog
+		*out = new(ConcurrencyWatchdogConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultDomain != nil {
+		in, out := &in.DefaultDomain, &out.DefaultDomain
+		*out = new(DefaultDomainConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRoutingConfigurationSpec.
+func (in *AppRoutingConfigurationSpec) DeepCopy() *AppRoutingConfigurationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRoutingConfigurationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRoutingConfigurationStatus) DeepCopyInto(out *AppRoutingConfigurationStatus) {
+	*out = *in
+	in.Effective.DeepCopyInto(&out.Effective)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRoutingConfigurationStatus.
+func (in *AppRoutingConfigurationStatus) DeepCopy() *AppRoutingConfigurationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRoutingConfigurationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterExternalDNS) DeepCopyInto(out *ClusterExternalDNS) {
 	*out = *in
@@ -120,6 +244,31 @@ func (in *ClusterExternalDNSStatus) DeepCopy() *ClusterExternalDNSStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConcurrencyWatchdogConfiguration) DeepCopyInto(out *ConcurrencyWatchdogConfiguration) {
+	*out = *in
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Votes != nil {
+		in, out := &in.Votes, &out.Votes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConcurrencyWatchdogConfiguration.
+func (in *ConcurrencyWatchdogConfiguration) DeepCopy() *ConcurrencyWatchdogConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ConcurrencyWatchdogConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultDomainCertificate) DeepCopyInto(out *DefaultDomainCertificate) {
 	*out = *in
@@ -241,6 +390,36 @@ func (in *DefaultDomainCertificateTarget) DeepCopy() *DefaultDomainCertificateTa
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultDomainConfiguration) DeepCopyInto(out *DefaultDomainConfiguration) {
+	*out = *in
+	if in.ClientID != nil {
+		in, out := &in.ClientID, &out.ClientID
+		*out = new(string)
+		**out = **in
+	}
+	if in.ZoneID != nil {
+		in, out := &in.ZoneID, &out.ZoneID
+		*out = new(string)
+		**out = **in
+	}
+	if in.EnableGateway != nil {
+		in, out := &in.EnableGateway, &out.EnableGateway
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultDomainConfiguration.
+func (in *DefaultDomainConfiguration) DeepCopy() *DefaultDomainConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultDomainConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultSSLCertificate) DeepCopyInto(out *DefaultSSLCertificate) {
 	*out = *in
@@ -266,6 +445,36 @@ func (in *DefaultSSLCertificate) DeepCopy() *DefaultSSLCertificate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionProtection) DeepCopyInto(out *DeletionProtection) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(DeletionProtectionMode)
+		**out = **in
+	}
+	if in.FallbackIngressClassName != nil {
+		in, out := &in.FallbackIngressClassName, &out.FallbackIngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.DrainPeriodSeconds != nil {
+		in, out := &in.DrainPeriodSeconds, &out.DrainPeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionProtection.
+func (in *DeletionProtection) DeepCopy() *DeletionProtection {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionProtection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalDNS) DeepCopyInto(out *ExternalDNS) {
 	*out = *in
@@ -428,6 +637,196 @@ func (in *ExternalDNSStatus) DeepCopy() *ExternalDNSStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressReference) DeepCopyInto(out *IngressReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressReference.
+func (in *IngressReference) DeepCopy() *IngressReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(LoadBalancerType)
+		**out = **in
+	}
+	if in.PublicIP != nil {
+		in, out := &in.PublicIP, &out.PublicIP
+		*out = new(PublicIP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSLabelName != nil {
+		in, out := &in.DNSLabelName, &out.DNSLabelName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Subnet != nil {
+		in, out := &in.Subnet, &out.Subnet
+		*out = new(string)
+		**out = **in
+	}
+	if in.PrivateLinkService != nil {
+		in, out := &in.PrivateLinkService, &out.PrivateLinkService
+		*out = new(PrivateLinkService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthProbeRequestPath != nil {
+		in, out := &in.HealthProbeRequestPath, &out.HealthProbeRequestPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExternalTrafficPolicy != nil {
+		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
+		*out = new(corev1.ServiceExternalTrafficPolicy)
+		**out = **in
+	}
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(SecondaryLoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
+func (in *LoadBalancer) DeepCopy() *LoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStatus) DeepCopyInto(out *LoadBalancerStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatus.
+func (in *LoadBalancerStatus) DeepCopy() *LoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadShedding) DeepCopyInto(out *LoadShedding) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(LoadSheddingMode)
+		**out = **in
+	}
+	if in.Signal != nil {
+		in, out := &in.Signal, &out.Signal
+		*out = new(LoadSignal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Votes != nil {
+		in, out := &in.Votes, &out.Votes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPodAgeSeconds != nil {
+		in, out := &in.MinPodAgeSeconds, &out.MinPodAgeSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxEvictionsPerHour != nil {
+		in, out := &in.MaxEvictionsPerHour, &out.MaxEvictionsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadShedding.
+func (in *LoadShedding) DeepCopy() *LoadShedding {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadShedding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadSignal) DeepCopyInto(out *LoadSignal) {
+	*out = *in
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = new(LoadSignalWeights)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadSignal.
+func (in *LoadSignal) DeepCopy() *LoadSignal {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadSignal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadSignalWeights) DeepCopyInto(out *LoadSignalWeights) {
+	*out = *in
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequestsPerSecond != nil {
+		in, out := &in.RequestsPerSecond, &out.RequestsPerSecond
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpstreamLatency != nil {
+		in, out := &in.UpstreamLatency, &out.UpstreamLatency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadSignalWeights.
+func (in *LoadSignalWeights) DeepCopy() *LoadSignalWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadSignalWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedObjectReference) DeepCopyInto(out *ManagedObjectReference) {
 	*out = *in
@@ -532,6 +931,11 @@ func (in *NginxIngressControllerSpec) DeepCopyInto(out *NginxIngressControllerSp
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(LoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DefaultSSLCertificate != nil {
 		in, out := &in.DefaultSSLCertificate, &out.DefaultSSLCertificate
 		*out = new(DefaultSSLCertificate)
@@ -557,6 +961,48 @@ func (in *NginxIngressControllerSpec) DeepCopyInto(out *NginxIngressControllerSp
 		*out = new(string)
 		**out = **in
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeChannel != nil {
+		in, out := &in.UpgradeChannel, &out.UpgradeChannel
+		*out = new(UpgradeChannel)
+		**out = **in
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImageFlavor != nil {
+		in, out := &in.ImageFlavor, &out.ImageFlavor
+		*out = new(ImageFlavor)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeletionProtection != nil {
+		in, out := &in.DeletionProtection, &out.DeletionProtection
+		*out = new(DeletionProtection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadShedding != nil {
+		in, out := &in.LoadShedding, &out.LoadShedding
+		*out = new(LoadShedding)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxIngressControllerSpec.
@@ -584,6 +1030,29 @@ func (in *NginxIngressControllerStatus) DeepCopyInto(out *NginxIngressController
 		*out = make([]ManagedObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.LoadBalancers != nil {
+		in, out := &in.LoadBalancers, &out.LoadBalancers
+		*out = make([]LoadBalancerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerIngress != nil {
+		in, out := &in.LoadBalancerIngress, &out.LoadBalancerIngress
+		*out = make([]corev1.LoadBalancerIngress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ingresses != nil {
+		in, out := &in.Ingresses, &out.Ingresses
+		*out = make([]IngressReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DrainStartTime != nil {
+		in, out := &in.DrainStartTime, &out.DrainStartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxIngressControllerStatus.
@@ -596,6 +1065,116 @@ func (in *NginxIngressControllerStatus) DeepCopy() *NginxIngressControllerStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodTemplate) DeepCopyInto(out *PodTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTemplate.
+func (in *PodTemplate) DeepCopy() *PodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateLinkService) DeepCopyInto(out *PrivateLinkService) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.IPConfigurationSubnet != nil {
+		in, out := &in.IPConfigurationSubnet, &out.IPConfigurationSubnet
+		*out = new(string)
+		**out = **in
+	}
+	if in.VisibilitySubscriptions != nil {
+		in, out := &in.VisibilitySubscriptions, &out.VisibilitySubscriptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoApprovalSubscriptions != nil {
+		in, out := &in.AutoApprovalSubscriptions, &out.AutoApprovalSubscriptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateLinkService.
+func (in *PrivateLinkService) DeepCopy() *PrivateLinkService {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateLinkService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIP) DeepCopyInto(out *PublicIP) {
+	*out = *in
+	if in.ResourceGroup != nil {
+		in, out := &in.ResourceGroup, &out.ResourceGroup
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIP.
+func (in *PublicIP) DeepCopy() *PublicIP {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Scaling) DeepCopyInto(out *Scaling) {
 	*out = *in
@@ -614,6 +1193,21 @@ func (in *Scaling) DeepCopyInto(out *Scaling) {
 		*out = new(Threshold)
 		**out = **in
 	}
+	if in.TargetMemoryUtilization != nil {
+		in, out := &in.TargetMemoryUtilization, &out.TargetMemoryUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metric != nil {
+		in, out := &in.Metric, &out.Metric
+		*out = new(ScalingMetric)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scaling.
@@ -626,6 +1220,64 @@ func (in *Scaling) DeepCopy() *Scaling {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingMetric) DeepCopyInto(out *ScalingMetric) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.TargetAverageValue = in.TargetAverageValue.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingMetric.
+func (in *ScalingMetric) DeepCopy() *ScalingMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecondaryLoadBalancer) DeepCopyInto(out *SecondaryLoadBalancer) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PublicIP != nil {
+		in, out := &in.PublicIP, &out.PublicIP
+		*out = new(PublicIP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSLabelName != nil {
+		in, out := &in.DNSLabelName, &out.DNSLabelName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Subnet != nil {
+		in, out := &in.Subnet, &out.Subnet
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondaryLoadBalancer.
+func (in *SecondaryLoadBalancer) DeepCopy() *SecondaryLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(SecondaryLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Secret) DeepCopyInto(out *Secret) {
 	*out = *in
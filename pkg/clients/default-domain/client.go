@@ -1,11 +1,15 @@
 package defaultdomain
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
@@ -16,6 +20,9 @@ import (
 // Opts contains configuration options for the client
 type Opts struct {
 	ServerAddress string
+	// CABundle optionally returns PEM-encoded CAs trusted in addition to the system roots. It's called on every request so the
+	// bundle can change at runtime.
+	CABundle func() []byte
 }
 
 // Client is a client for the default domain service
@@ -23,6 +30,9 @@ type Client struct {
 	opts       Opts
 	httpClient *http.Client
 	logger     logr.Logger
+
+	mu       sync.Mutex
+	caBundle []byte
 }
 
 // NewClient creates a new default domain client
@@ -36,6 +46,37 @@ func NewClient(opts Opts, logger logr.Logger) *Client {
 	}
 }
 
+// client returns the http client trusting the current CA bundle, the client is rebuilt when the bundle changes
+func (c *Client) client() *http.Client {
+	if c.opts.CABundle == nil {
+		return c.httpClient
+	}
+
+	bundle := c.opts.CABundle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if bytes.Equal(bundle, c.caBundle) {
+		return c.httpClient
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(bundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			c.logger.Error(err, "failed to load system cert pool, only trusting the CA bundle")
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM(bundle)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	c.logger.Info("CA bundle changed, rebuilding http client", "caBundleBytes", len(bundle))
+	c.httpClient = &http.Client{Transport: transport}
+	c.caBundle = bundle
+	return c.httpClient
+}
+
 // GetTLSCertificate retrieves a TLS certificate from the configured server address
 func (c *Client) GetTLSCertificate(ctx context.Context) (*TLSCertificate, error) {
 	c.logger.Info("requesting TLS certificate from default domain service")
@@ -48,7 +89,7 @@ func (c *Client) GetTLSCertificate(ctx context.Context) (*TLSCertificate, error)
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client().Do(req)
 	if err != nil {
 		metrics.DefaultDomainClientCallsTotal.WithLabelValues(metrics.LabelError).Inc()
 		metrics.DefaultDomainClientErrors.Inc()
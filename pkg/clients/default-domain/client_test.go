@@ -3,6 +3,7 @@ package defaultdomain
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -29,6 +30,33 @@ func TestNewClient(t *testing.T) {
 	assert.NotNil(t, client.httpClient)
 }
 
+func TestClient_GetTLSCertificate_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&TLSCertificate{Key: []byte("test-key"), Cert: []byte("test-cert")})
+	}))
+	defer server.Close()
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	var bundle []byte
+	client := NewClient(Opts{ServerAddress: server.URL, CABundle: func() []byte { return bundle }}, logr.Discard())
+
+	// the test server's CA isn't a system root
+	_, err := client.GetTLSCertificate(context.Background())
+	require.Error(t, err)
+
+	// the client trusts the bundle once it changes
+	bundle = serverCA
+	cert, err := client.GetTLSCertificate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test-cert"), cert.Cert)
+
+	// and stops trusting it once it's removed
+	bundle = nil
+	_, err = client.GetTLSCertificate(context.Background())
+	require.Error(t, err)
+}
+
 func TestClient_GetTLSCertificate_Success(t *testing.T) {
 	expiresOn := time.Now().Add(24 * time.Hour)
 	expectedCert := &TLSCertificate{
@@ -4,10 +4,12 @@
 package config
 
 import (
+	"cmp"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,11 +23,32 @@ const (
 	PublicZoneType         = "dnszones"
 	PrivateZoneType        = "privatednszones"
 	defaultDnsSyncInterval = 3 * time.Minute
+
+	// StagedNginxRolloutDefaultNicFirst and StagedNginxRolloutDefaultNicLast control whether the default NginxIngressController
+	// is upgraded before or after every other NginxIngressController during a staged rollout
+	StagedNginxRolloutDefaultNicFirst = "first"
+	StagedNginxRolloutDefaultNicLast  = "last"
+	defaultStagedNginxRolloutBake     = 10 * time.Minute
+
+	// ConcurrencyWatchdogScrapeModeProxy scrapes ingress controller pods through the API server's pod proxy.
+	// ConcurrencyWatchdogScrapeModeDirect scrapes them directly by their IP and only uses the pod proxy when that fails.
+	ConcurrencyWatchdogScrapeModeProxy      = "proxy"
+	ConcurrencyWatchdogScrapeModeDirect     = "direct"
+	defaultConcurrencyWatchdogScrapeWorkers = 10
+	defaultConcurrencyWatchdogScrapeTimeout = 45 * time.Second
+
+	defaultCertExpiryCheckInterval = 10 * time.Minute
+	// defaultCertExpiryWarningThresholds warn 30, 7 and 1 days before a certificate expires
+	defaultCertExpiryWarningThresholds = "720h,168h,24h"
+
+	defaultWebhookPort = 9443
 )
 
 var (
-	Flags          = &Config{}
-	dnsZonesString string
+	Flags                             = &Config{live: &live{}}
+	dnsZonesString                    string
+	caBundleFilesString               string
+	certExpiryWarningThresholdsString string
 )
 
 func init() {
@@ -40,6 +63,11 @@ func init() {
 	flag.BoolVar(&Flags.DisableKeyvault, "disable-keyvault", false, "disable the keyvault integration")
 	flag.Float64Var(&Flags.ConcurrencyWatchdogThres, "concurrency-watchdog-threshold", 200, "percentage of concurrent connections above mean required to vote for load shedding")
 	flag.IntVar(&Flags.ConcurrencyWatchdogVotes, "concurrency-watchdog-votes", 4, "number of votes required for a pod to be considered for load shedding")
+	flag.StringVar(&Flags.ConcurrencyWatchdogScrapeMode, "concurrency-watchdog-scrape-mode", ConcurrencyWatchdogScrapeModeProxy, "how the concurrency watchdog and the staged nginx rollout scrape ingress controller pods. should be one of 'proxy' to go through the API server or 'direct' to go to pod IPs, falling back to the API server")
+	flag.IntVar(&Flags.ConcurrencyWatchdogScrapeWorkers, "concurrency-watchdog-scrape-workers", defaultConcurrencyWatchdogScrapeWorkers, "number of ingress controller pods the concurrency watchdog scrapes at once")
+	flag.DurationVar(&Flags.ConcurrencyWatchdogScrapeTimeout, "concurrency-watchdog-scrape-timeout", defaultConcurrencyWatchdogScrapeTimeout, "how long the concurrency watchdog has to scrape the pods of every target on each check")
+	flag.DurationVar(&Flags.CertExpiryCheckInterval, "cert-expiry-check-interval", defaultCertExpiryCheckInterval, "interval at which the certificates of App Routing managed TLS secrets are checked for expiry")
+	flag.StringVar(&certExpiryWarningThresholdsString, "cert-expiry-warning-thresholds", defaultCertExpiryWarningThresholds, "comma-separated durations before a certificate expires at which a warning event is recorded on the resource that owns it, empty disables the events")
 	flag.BoolVar(&Flags.DisableOSM, "disable-osm", false, "enable Open Service Mesh integration")
 	flag.BoolVar(&Flags.DisableIngressNginx, "disable-ingress-nginx", false, "disable the ingress-nginx integration")
 	flag.BoolVar(&Flags.EnableDalecNginx, "enable-dalec-nginx", false, "use dalec-built nginx ingress controller image")
@@ -54,6 +82,10 @@ func init() {
 	flag.BoolVar(&Flags.DisableExpensiveCache, "disable-expensive-cache", false, "disable the cache for expensive resources that aren't core to App Routing like Pods and Events")
 	flag.BoolVar(&Flags.EnableInternalLogging, "enable-internal-logging", false, "enable internal logging for internal customers, includes things like json format and additional fields in logs")
 	flag.BoolVar(&Flags.EnabledWorkloadIdentity, "enable-workload-identity", false, "enable workload identity allows users to use workload identity to authenticate to Azure resources instead of using the addon managed identity")
+	flag.BoolVar(&Flags.EnableStagedNginxRollout, "enable-staged-nginx-rollout", false, "upgrade NginxIngressControllers to new nginx versions one at a time with a bake period and automatic rollback")
+	flag.StringVar(&Flags.StagedNginxRolloutDefaultNic, "staged-nginx-rollout-default-nic", StagedNginxRolloutDefaultNicLast, "whether the default NginxIngressController is upgraded 'first' or 'last' during a staged nginx rollout")
+	flag.DurationVar(&Flags.StagedNginxRolloutBakePeriod, "staged-nginx-rollout-bake-period", defaultStagedNginxRolloutBake, "how long an upgraded NginxIngressController must stay healthy before the staged nginx rollout moves on")
+	flag.BoolVar(&Flags.EnableNginxSecondaryLoadBalancerDNS, "enable-nginx-secondary-load-balancer-dns", false, "publish the secondary load balancer of NginxIngressControllers into the DNS zones of its type through external-dns")
 
 	// Default domain flags
 	flag.BoolVar(&Flags.EnableDefaultDomain, "enable-default-domain", false, "enable default domain feature including the Default Domain Certificate Controller and CRD")
@@ -62,6 +94,18 @@ func init() {
 	flag.StringVar(&Flags.DefaultDomainZoneID, "default-domain-zone-id", "", "resource ID of the DNS zone for the default domain")
 	flag.DurationVar(&Flags.DefaultDomainCertCacheTTL, "default-domain-cert-cache-ttl", defaultdomain.DefaultCacheTTL, "time-to-live for cached default domain certificates")
 	flag.BoolVar(&Flags.EnableDefaultDomainGateway, "enable-default-domain-gateway", false, "enable Gateway API resource type for default domain external DNS (defaults to off)")
+
+	// Webhook flags
+	flag.BoolVar(&Flags.EnableWebhooks, "enable-webhooks", false, "serve validating admission webhooks for App Routing resources and Key Vault annotations")
+	flag.IntVar(&Flags.WebhookPort, "webhook-port", defaultWebhookPort, "port to serve the admission webhooks on")
+	flag.StringVar(&Flags.WebhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "directory the admission webhook serving certificate is written to")
+	flag.StringVar(&Flags.WebhookServiceName, "webhook-service-name", "app-routing-operator-webhook", "name of the Service that routes admission requests to the operator")
+	flag.StringVar(&Flags.WebhookServiceNamespace, "webhook-service-namespace", "kube-system", "namespace of the webhook Service, the serving certificate is stored in a Secret there")
+
+	// Config file flags
+	flag.StringVar(&Flags.OperatorNamespace, "operator-namespace", "kube-system", "namespace the operator's k8s deployment runs in, config reload events are recorded on the deployment")
+	flag.StringVar(&Flags.ConfigFile, "config-file", "", "optional path to a mounted YAML file overlaying these flags in the format of an AppRoutingConfiguration spec, reloaded when it changes")
+	flag.StringVar(&caBundleFilesString, "ca-bundle-files", "", "optional comma-separated paths to mounted PEM CA bundles trusted when calling the default domain service, reloaded when they change")
 }
 
 func (c *Config) Validate() error {
@@ -89,6 +133,37 @@ func (c *Config) Validate() error {
 	if c.ConcurrencyWatchdogVotes < 1 {
 		return errors.New("--concurrency-watchdog-votes must be a positive number")
 	}
+	if c.ConcurrencyWatchdogScrapeMode == "" {
+		c.ConcurrencyWatchdogScrapeMode = ConcurrencyWatchdogScrapeModeProxy
+	}
+	if c.ConcurrencyWatchdogScrapeMode != ConcurrencyWatchdogScrapeModeProxy && c.ConcurrencyWatchdogScrapeMode != ConcurrencyWatchdogScrapeModeDirect {
+		return errors.New("--concurrency-watchdog-scrape-mode must be one of 'proxy' or 'direct'")
+	}
+	if c.ConcurrencyWatchdogScrapeWorkers == 0 {
+		c.ConcurrencyWatchdogScrapeWorkers = defaultConcurrencyWatchdogScrapeWorkers
+	}
+	if c.ConcurrencyWatchdogScrapeWorkers < 0 {
+		return errors.New("--concurrency-watchdog-scrape-workers must be a positive number")
+	}
+	if c.ConcurrencyWatchdogScrapeTimeout == 0 {
+		c.ConcurrencyWatchdogScrapeTimeout = defaultConcurrencyWatchdogScrapeTimeout
+	}
+	if c.ConcurrencyWatchdogScrapeTimeout < 0 {
+		return errors.New("--concurrency-watchdog-scrape-timeout must be a positive duration")
+	}
+	if c.CertExpiryCheckInterval == 0 {
+		c.CertExpiryCheckInterval = defaultCertExpiryCheckInterval
+	}
+	if c.CertExpiryCheckInterval < 0 {
+		return errors.New("--cert-expiry-check-interval must be a positive duration")
+	}
+	if certExpiryWarningThresholdsString != "" {
+		thresholds, err := parseCertExpiryWarningThresholds(certExpiryWarningThresholdsString)
+		if err != nil {
+			return err
+		}
+		c.CertExpiryWarningThresholds = thresholds
+	}
 	if c.OperatorDeployment == "" {
 		return errors.New("--operator-deployment is required")
 	}
@@ -133,6 +208,47 @@ func (c *Config) Validate() error {
 		return errors.New("--default-domain-cert-cache-ttl must be a positive duration")
 	}
 
+	if c.StagedNginxRolloutDefaultNic == "" {
+		c.StagedNginxRolloutDefaultNic = StagedNginxRolloutDefaultNicLast
+	}
+	if c.StagedNginxRolloutDefaultNic != StagedNginxRolloutDefaultNicFirst && c.StagedNginxRolloutDefaultNic != StagedNginxRolloutDefaultNicLast {
+		return errors.New("--staged-nginx-rollout-default-nic must be one of 'first' or 'last'")
+	}
+
+	if c.StagedNginxRolloutBakePeriod == 0 {
+		c.StagedNginxRolloutBakePeriod = defaultStagedNginxRolloutBake
+	}
+	if c.StagedNginxRolloutBakePeriod < 0 {
+		return errors.New("--staged-nginx-rollout-bake-period must be a positive duration")
+	}
+
+	if caBundleFilesString != "" {
+		c.CABundleFiles = nil
+		for _, path := range strings.Split(caBundleFilesString, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				c.CABundleFiles = append(c.CABundleFiles, path)
+			}
+		}
+	}
+	if (c.ConfigFile != "" || len(c.CABundleFiles) > 0) && c.OperatorNamespace == "" {
+		return errors.New("--operator-namespace is required when --config-file or --ca-bundle-files is set")
+	}
+
+	if c.EnableWebhooks {
+		if c.WebhookPort == 0 {
+			c.WebhookPort = defaultWebhookPort
+		}
+		if c.WebhookPort < 0 || c.WebhookPort > 65535 {
+			return errors.New("--webhook-port must be a valid port")
+		}
+		if c.WebhookCertDir == "" {
+			return errors.New("--webhook-cert-dir is required when --enable-webhooks is set")
+		}
+		if c.WebhookServiceName == "" || c.WebhookServiceNamespace == "" {
+			return errors.New("--webhook-service-name and --webhook-service-namespace are required when --enable-webhooks is set")
+		}
+	}
+
 	return nil
 }
 
@@ -196,3 +312,27 @@ func ValidateProviderSubAndRg(parsedZone azure.Resource, subscription, resourceG
 
 	return nil
 }
+
+// parseCertExpiryWarningThresholds parses comma-separated durations, they're returned longest first without duplicates
+func parseCertExpiryWarningThresholds(value string) ([]time.Duration, error) {
+	var thresholds []time.Duration
+	for _, raw := range strings.Split(value, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+
+		threshold, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("--cert-expiry-warning-thresholds: invalid duration %q: %w", raw, err)
+		}
+		if threshold <= 0 {
+			return nil, fmt.Errorf("--cert-expiry-warning-thresholds: %s must be a positive duration", raw)
+		}
+		if !slices.Contains(thresholds, threshold) {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+
+	slices.SortFunc(thresholds, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+	return thresholds, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/stretchr/testify/require"
@@ -492,6 +493,294 @@ var validateTestCases = []struct {
 			EnableDefaultDomainGateway:  false,
 		},
 	},
+	{
+		Name: "valid-staged-nginx-rollout",
+		Conf: &Config{
+			DefaultController:            Standard,
+			NS:                           "test-namespace",
+			Registry:                     "test-registry",
+			MSIClientID:                  "test-msi-client-id",
+			TenantID:                     "test-tenant-id",
+			Cloud:                        "test-cloud",
+			Location:                     "test-location",
+			ConcurrencyWatchdogThres:     101,
+			ConcurrencyWatchdogVotes:     2,
+			ClusterUid:                   "cluster-uid",
+			OperatorDeployment:           "app-routing-operator",
+			CrdPath:                      validCrdPath,
+			EnableStagedNginxRollout:     true,
+			StagedNginxRolloutDefaultNic: StagedNginxRolloutDefaultNicFirst,
+			StagedNginxRolloutBakePeriod: time.Minute,
+		},
+	},
+	{
+		Name: "invalid-staged-nginx-rollout-default-nic",
+		Conf: &Config{
+			DefaultController:            Standard,
+			NS:                           "test-namespace",
+			Registry:                     "test-registry",
+			MSIClientID:                  "test-msi-client-id",
+			TenantID:                     "test-tenant-id",
+			Cloud:                        "test-cloud",
+			Location:                     "test-location",
+			ConcurrencyWatchdogThres:     101,
+			ConcurrencyWatchdogVotes:     2,
+			ClusterUid:                   "cluster-uid",
+			OperatorDeployment:           "app-routing-operator",
+			CrdPath:                      validCrdPath,
+			EnableStagedNginxRollout:     true,
+			StagedNginxRolloutDefaultNic: "middle",
+		},
+		Error: "--staged-nginx-rollout-default-nic must be one of 'first' or 'last'",
+	},
+	{
+		Name: "invalid-staged-nginx-rollout-bake-period",
+		Conf: &Config{
+			DefaultController:            Standard,
+			NS:                           "test-namespace",
+			Registry:                     "test-registry",
+			MSIClientID:                  "test-msi-client-id",
+			TenantID:                     "test-tenant-id",
+			Cloud:                        "test-cloud",
+			Location:                     "test-location",
+			ConcurrencyWatchdogThres:     101,
+			ConcurrencyWatchdogVotes:     2,
+			ClusterUid:                   "cluster-uid",
+			OperatorDeployment:           "app-routing-operator",
+			CrdPath:                      validCrdPath,
+			EnableStagedNginxRollout:     true,
+			StagedNginxRolloutBakePeriod: -time.Minute,
+		},
+		Error: "--staged-nginx-rollout-bake-period must be a positive duration",
+	},
+	{
+		Name: "valid-concurrency-watchdog-direct-scrape",
+		Conf: &Config{
+			DefaultController:                Standard,
+			NS:                               "test-namespace",
+			Registry:                         "test-registry",
+			MSIClientID:                      "test-msi-client-id",
+			TenantID:                         "test-tenant-id",
+			Cloud:                            "test-cloud",
+			Location:                         "test-location",
+			ConcurrencyWatchdogThres:         101,
+			ConcurrencyWatchdogVotes:         2,
+			ClusterUid:                       "cluster-uid",
+			OperatorDeployment:               "app-routing-operator",
+			CrdPath:                          validCrdPath,
+			ConcurrencyWatchdogScrapeMode:    ConcurrencyWatchdogScrapeModeDirect,
+			ConcurrencyWatchdogScrapeWorkers: 20,
+			ConcurrencyWatchdogScrapeTimeout: time.Minute,
+		},
+	},
+	{
+		Name: "invalid-concurrency-watchdog-scrape-mode",
+		Conf: &Config{
+			DefaultController:             Standard,
+			NS:                            "test-namespace",
+			Registry:                      "test-registry",
+			MSIClientID:                   "test-msi-client-id",
+			TenantID:                      "test-tenant-id",
+			Cloud:                         "test-cloud",
+			Location:                      "test-location",
+			ConcurrencyWatchdogThres:      101,
+			ConcurrencyWatchdogVotes:      2,
+			ClusterUid:                    "cluster-uid",
+			OperatorDeployment:            "app-routing-operator",
+			CrdPath:                       validCrdPath,
+			ConcurrencyWatchdogScrapeMode: "sideways",
+		},
+		Error: "--concurrency-watchdog-scrape-mode must be one of 'proxy' or 'direct'",
+	},
+	{
+		Name: "invalid-concurrency-watchdog-scrape-workers",
+		Conf: &Config{
+			DefaultController:                Standard,
+			NS:                               "test-namespace",
+			Registry:                         "test-registry",
+			MSIClientID:                      "test-msi-client-id",
+			TenantID:                         "test-tenant-id",
+			Cloud:                            "test-cloud",
+			Location:                         "test-location",
+			ConcurrencyWatchdogThres:         101,
+			ConcurrencyWatchdogVotes:         2,
+			ClusterUid:                       "cluster-uid",
+			OperatorDeployment:               "app-routing-operator",
+			CrdPath:                          validCrdPath,
+			ConcurrencyWatchdogScrapeWorkers: -1,
+		},
+		Error: "--concurrency-watchdog-scrape-workers must be a positive number",
+	},
+	{
+		Name: "invalid-concurrency-watchdog-scrape-timeout",
+		Conf: &Config{
+			DefaultController:                Standard,
+			NS:                               "test-namespace",
+			Registry:                         "test-registry",
+			MSIClientID:                      "test-msi-client-id",
+			TenantID:                         "test-tenant-id",
+			Cloud:                            "test-cloud",
+			Location:                         "test-location",
+			ConcurrencyWatchdogThres:         101,
+			ConcurrencyWatchdogVotes:         2,
+			ClusterUid:                       "cluster-uid",
+			OperatorDeployment:               "app-routing-operator",
+			CrdPath:                          validCrdPath,
+			ConcurrencyWatchdogScrapeTimeout: -time.Second,
+		},
+		Error: "--concurrency-watchdog-scrape-timeout must be a positive duration",
+	},
+	{
+		Name: "invalid-cert-expiry-check-interval",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			CertExpiryCheckInterval:  -time.Minute,
+		},
+		Error: "--cert-expiry-check-interval must be a positive duration",
+	},
+	{
+		Name: "valid-webhooks",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			EnableWebhooks:           true,
+			WebhookCertDir:           "/tmp/certs",
+			WebhookServiceName:       "app-routing-operator-webhook",
+			WebhookServiceNamespace:  "kube-system",
+		},
+	},
+	{
+		Name: "invalid-webhook-port",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			EnableWebhooks:           true,
+			WebhookPort:              70000,
+			WebhookCertDir:           "/tmp/certs",
+			WebhookServiceName:       "app-routing-operator-webhook",
+			WebhookServiceNamespace:  "kube-system",
+		},
+		Error: "--webhook-port must be a valid port",
+	},
+	{
+		Name: "invalid-webhooks-missing-cert-dir",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			EnableWebhooks:           true,
+			WebhookServiceName:       "app-routing-operator-webhook",
+			WebhookServiceNamespace:  "kube-system",
+		},
+		Error: "--webhook-cert-dir is required when --enable-webhooks is set",
+	},
+	{
+		Name: "invalid-webhooks-missing-service-name",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			EnableWebhooks:           true,
+			WebhookCertDir:           "/tmp/certs",
+		},
+		Error: "--webhook-service-name and --webhook-service-namespace are required when --enable-webhooks is set",
+	},
+	{
+		Name: "invalid-config-file-missing-operator-namespace",
+		Conf: &Config{
+			DefaultController:        Standard,
+			NS:                       "test-namespace",
+			Registry:                 "test-registry",
+			MSIClientID:              "test-msi-client-id",
+			TenantID:                 "test-tenant-id",
+			Cloud:                    "test-cloud",
+			Location:                 "test-location",
+			ConcurrencyWatchdogThres: 101,
+			ConcurrencyWatchdogVotes: 2,
+			ClusterUid:               "cluster-uid",
+			OperatorDeployment:       "app-routing-operator",
+			CrdPath:                  validCrdPath,
+			ConfigFile:               "/etc/app-routing/config.yaml",
+		},
+		Error: "--operator-namespace is required when --config-file or --ca-bundle-files is set",
+	},
+}
+
+func TestConfigValidateCABundleFiles(t *testing.T) {
+	caBundleFilesString = " /etc/ca/one.pem,, /etc/ca/two.pem "
+	t.Cleanup(func() { caBundleFilesString = "" })
+
+	conf := *validateTestCases[0].Conf
+	conf.OperatorNamespace = "kube-system"
+	require.NoError(t, conf.Validate())
+	require.Equal(t, []string{"/etc/ca/one.pem", "/etc/ca/two.pem"}, conf.CABundleFiles)
+}
+
+func TestConfigValidateCertExpiryWarningThresholds(t *testing.T) {
+	t.Cleanup(func() { certExpiryWarningThresholdsString = "" })
+
+	certExpiryWarningThresholdsString = " 24h,, 720h,168h,24h "
+	conf := *validateTestCases[0].Conf
+	require.NoError(t, conf.Validate())
+	require.Equal(t, []time.Duration{720 * time.Hour, 168 * time.Hour, 24 * time.Hour}, conf.CertExpiryWarningThresholds)
+	require.Equal(t, defaultCertExpiryCheckInterval, conf.CertExpiryCheckInterval)
+
+	certExpiryWarningThresholdsString = "720h,a week"
+	conf = *validateTestCases[0].Conf
+	require.ErrorContains(t, conf.Validate(), `--cert-expiry-warning-thresholds: invalid duration "a week"`)
+
+	certExpiryWarningThresholdsString = "720h,-24h"
+	conf = *validateTestCases[0].Conf
+	require.EqualError(t, conf.Validate(), "--cert-expiry-warning-thresholds: -24h must be a positive duration")
 }
 
 func TestConfigValidate(t *testing.T) {
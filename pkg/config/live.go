@@ -0,0 +1,69 @@
+package config
+
+import "sync"
+
+// live tracks the Config currently in effect, which differs from the flags once an AppRoutingConfiguration overlays them
+type live struct {
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []chan struct{}
+}
+
+// liveInit guards lazily creating live state for Configs that weren't built from Flags, like those in tests
+var liveInit sync.Mutex
+
+func (c *Config) state() *live {
+	liveInit.Lock()
+	defer liveInit.Unlock()
+
+	if c.live == nil {
+		c.live = &live{}
+	}
+	return c.live
+}
+
+// Current returns the Config currently in effect. It's c itself until SetCurrent is called on c or any copy of it.
+// The returned Config must not be modified.
+func (c *Config) Current() *Config {
+	s := c.state()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.current == nil {
+		return c
+	}
+	return s.current
+}
+
+// SetCurrent replaces the Config in effect with next, or reverts to the original Config when next is nil, and notifies subscribers
+func (c *Config) SetCurrent(next *Config) {
+	s := c.state()
+	if next != nil {
+		copied := *next
+		copied.live = s
+		next = &copied
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current = next
+	for _, ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default: // a notification is already pending
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a value whenever the Config in effect changes. Notifications are coalesced
+// so readers should call Current after receiving instead of counting notifications.
+func (c *Config) Subscribe() <-chan struct{} {
+	s := c.state()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrent(t *testing.T) {
+	base := &Config{DnsSyncInterval: time.Minute}
+	require.Same(t, base, base.Current())
+	copied := *base
+
+	updates := copied.Subscribe()
+	base.SetCurrent(&Config{DnsSyncInterval: time.Hour})
+	<-updates
+	require.Equal(t, time.Hour, copied.Current().DnsSyncInterval, "copies share the config in effect")
+	require.Equal(t, time.Minute, copied.DnsSyncInterval, "the original config isn't modified")
+	require.Equal(t, time.Hour, copied.Current().Current().DnsSyncInterval)
+
+	// notifications are coalesced
+	base.SetCurrent(&Config{DnsSyncInterval: 2 * time.Hour})
+	base.SetCurrent(&Config{DnsSyncInterval: 3 * time.Hour})
+	<-updates
+	require.Len(t, updates, 0)
+	require.Equal(t, 3*time.Hour, base.Current().DnsSyncInterval)
+
+	base.SetCurrent(nil)
+	<-updates
+	require.Same(t, base, base.Current())
+}
@@ -71,6 +71,11 @@ type Config struct {
 	PrivateZoneConfig, PublicZoneConfig DnsZoneConfig
 	ConcurrencyWatchdogThres            float64
 	ConcurrencyWatchdogVotes            int
+	ConcurrencyWatchdogScrapeMode       string
+	ConcurrencyWatchdogScrapeWorkers    int
+	ConcurrencyWatchdogScrapeTimeout    time.Duration
+	CertExpiryCheckInterval             time.Duration
+	CertExpiryWarningThresholds         []time.Duration
 	DisableOSM                          bool
 	DisableIngressNginx                 bool
 	EnableDalecNginx                    bool
@@ -82,6 +87,10 @@ type Config struct {
 	DisableExpensiveCache               bool
 	EnableInternalLogging               bool
 	EnabledWorkloadIdentity             bool
+	EnableStagedNginxRollout            bool
+	StagedNginxRolloutDefaultNic        string
+	StagedNginxRolloutBakePeriod        time.Duration
+	EnableNginxSecondaryLoadBalancerDNS bool
 
 	EnableDefaultDomain        bool
 	DefaultDomainServerAddress string
@@ -89,4 +98,19 @@ type Config struct {
 	DefaultDomainZoneID        string
 	DefaultDomainCertCacheTTL  time.Duration
 	EnableDefaultDomainGateway bool
+
+	EnableWebhooks          bool
+	WebhookPort             int
+	WebhookCertDir          string
+	WebhookServiceName      string
+	WebhookServiceNamespace string
+
+	OperatorNamespace string
+	ConfigFile        string
+	CABundleFiles     []string
+	// CABundle is the concatenated content of CABundleFiles, it's only set on the config in effect
+	CABundle []byte
+
+	// live is shared by every copy of a Config so runtime overlays reach controllers that copied it at startup
+	live *live
 }
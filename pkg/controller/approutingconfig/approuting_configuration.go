@@ -0,0 +1,159 @@
+package approutingconfig
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/store"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var name = controllername.New("app", "routing", "configuration", "reconciler")
+
+// reconciler overlays the AppRoutingConfiguration onto the flags and the config file and makes the result the config in effect.
+// Controllers that read the config in effect pick up changes without a restart.
+type reconciler struct {
+	client client.Client
+	events record.EventRecorder
+	layers *layers
+}
+
+// Setup sets up the AppRoutingConfiguration reconciler and, when a config file or CA bundles are mounted, the reloader that watches
+// them through s. conf must be the config built from the flags.
+func Setup(mgr ctrl.Manager, conf *config.Config, s store.Store) error {
+	l := newLayers(conf)
+
+	if conf.ConfigFile != "" || len(conf.CABundleFiles) > 0 {
+		if err := addFileReloader(mgr, l, s); err != nil {
+			return fmt.Errorf("adding config file reloader: %w", err)
+		}
+	}
+
+	if err := addReconciler(mgr, l); err != nil {
+		return fmt.Errorf("adding app routing configuration reconciler: %w", err)
+	}
+
+	return nil
+}
+
+func addReconciler(mgr ctrl.Manager, l *layers) error {
+	metrics.InitControllerMetrics(name)
+
+	r := &reconciler{
+		client: mgr.GetClient(),
+		events: mgr.GetEventRecorderFor("aks-app-routing-operator"),
+		layers: l,
+	}
+
+	if err := name.AddToController(
+		ctrl.NewControllerManagedBy(mgr).
+			For(&approutingv1alpha1.AppRoutingConfiguration{}),
+		mgr.GetLogger(),
+	).Complete(r); err != nil {
+		return fmt.Errorf("building the controller: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, err error) {
+	start := time.Now()
+	lgr := log.FromContext(ctx, "name", req.Name)
+	ctx = log.IntoContext(ctx, lgr)
+
+	lgr.Info("reconciling AppRoutingConfiguration")
+	defer func() {
+		lgr.Info("reconcile finished", "latency", time.Since(start))
+	}()
+
+	if req.Name != approutingv1alpha1.AppRoutingConfigurationName {
+		lgr.Info("ignoring AppRoutingConfiguration that isn't named " + approutingv1alpha1.AppRoutingConfigurationName)
+		return ctrl.Result{}, nil
+	}
+
+	arc := &approutingv1alpha1.AppRoutingConfiguration{}
+	if err := r.client.Get(ctx, req.NamespacedName, arc); err != nil {
+		if apierrors.IsNotFound(err) {
+			lgr.Info("AppRoutingConfiguration not found, removing it from the config in effect")
+			if _, err := r.layers.update(func(s *sources) { s.crd = nil }); err != nil {
+				// the config file is only valid together with the AppRoutingConfiguration, keep the previous configuration
+				lgr.Error(err, "unable to remove AppRoutingConfiguration from the config in effect")
+			}
+			return ctrl.Result{}, nil
+		}
+
+		lgr.Error(err, "unable to get AppRoutingConfiguration")
+		return ctrl.Result{}, err
+	}
+
+	changed, err := r.layers.update(func(s *sources) { s.crd = arc.Spec.DeepCopy() })
+	if err != nil {
+		msg := fmt.Sprintf("AppRoutingConfiguration is invalid, the previous configuration stays in effect: %s", err.Error())
+		lgr.Info(msg)
+		r.events.Event(arc, corev1.EventTypeWarning, "InvalidConfiguration", msg)
+		arc.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.AppRoutingConfigurationConditionTypeApplied,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidConfiguration",
+			Message: msg,
+		})
+	} else {
+		if changed {
+			lgr.Info("applied AppRoutingConfiguration")
+			r.events.Event(arc, corev1.EventTypeNormal, "ConfigurationApplied", "AppRoutingConfiguration is in effect")
+		}
+		arc.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.AppRoutingConfigurationConditionTypeApplied,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ConfigurationApplied",
+			Message: "AppRoutingConfiguration is in effect",
+		})
+	}
+
+	arc.Status.Effective = effective(r.layers.flags.Current())
+	if err := r.client.Status().Update(ctx, arc); err != nil {
+		lgr.Error(err, "failed to update status for AppRoutingConfiguration")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// effective returns conf in the shape of an AppRoutingConfigurationSpec for the status
+func effective(conf *config.Config) approutingv1alpha1.AppRoutingConfigurationSpec {
+	zones := slices.Concat(util.Keys(conf.PublicZoneConfig.ZoneIds), util.Keys(conf.PrivateZoneConfig.ZoneIds))
+	slices.Sort(zones)
+
+	ret := approutingv1alpha1.AppRoutingConfigurationSpec{
+		DefaultController:  util.ToPtr(conf.DefaultController.String()),
+		DNSZoneResourceIDs: zones,
+		DNSSyncInterval:    &metav1.Duration{Duration: conf.DnsSyncInterval},
+		ConcurrencyWatchdog: &approutingv1alpha1.ConcurrencyWatchdogConfiguration{
+			Threshold: util.Int32Ptr(int32(conf.ConcurrencyWatchdogThres)),
+			Votes:     util.Int32Ptr(int32(conf.ConcurrencyWatchdogVotes)),
+		},
+	}
+
+	if conf.EnableDefaultDomain {
+		ret.DefaultDomain = &approutingv1alpha1.DefaultDomainConfiguration{
+			ClientID:      util.ToPtr(conf.DefaultDomainClientID),
+			ZoneID:        util.ToPtr(conf.DefaultDomainZoneID),
+			EnableGateway: util.ToPtr(conf.EnableDefaultDomainGateway),
+		}
+	}
+
+	return ret
+}
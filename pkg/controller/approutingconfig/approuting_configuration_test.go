@@ -0,0 +1,156 @@
+package approutingconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	publicZone  = "/subscriptions/sub/resourcegroups/rg/providers/microsoft.network/dnszones/example.com"
+	privateZone = "/subscriptions/sub/resourcegroups/rg/providers/microsoft.network/privatednszones/example.internal"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		NS:                       "app-routing-system",
+		Registry:                 "mcr.microsoft.com",
+		MSIClientID:              "client-id",
+		TenantID:                 "tenant-id",
+		Cloud:                    "AzurePublicCloud",
+		Location:                 "eastus",
+		ConcurrencyWatchdogThres: 200,
+		ConcurrencyWatchdogVotes: 4,
+		OperatorDeployment:       "app-routing-operator",
+		ClusterUid:               "cluster-uid",
+		DnsSyncInterval:          3 * time.Minute,
+		CrdPath:                  t.TempDir(),
+	}
+}
+
+func newTestReconciler(t *testing.T, objs ...client.Object) *reconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+
+	return &reconciler{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(objs...).Build(),
+		events: record.NewFakeRecorder(10),
+		layers: newLayers(testConfig(t)),
+	}
+}
+
+func reconcileDefault(t *testing.T, r *reconciler) *approutingv1alpha1.AppRoutingConfiguration {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: approutingv1alpha1.AppRoutingConfigurationName}}
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+
+	arc := &approutingv1alpha1.AppRoutingConfiguration{}
+	if err := r.client.Get(context.Background(), req.NamespacedName, arc); err != nil {
+		require.True(t, client.IgnoreNotFound(err) == nil, "unexpected error %s", err)
+		return nil
+	}
+	return arc
+}
+
+func TestReconcile(t *testing.T) {
+	arc := &approutingv1alpha1.AppRoutingConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: approutingv1alpha1.AppRoutingConfigurationName, Generation: 1},
+		Spec: approutingv1alpha1.AppRoutingConfigurationSpec{
+			DefaultController:  util.ToPtr("private"),
+			DNSZoneResourceIDs: []string{publicZone, privateZone},
+			DNSSyncInterval:    &metav1.Duration{Duration: time.Minute},
+			ConcurrencyWatchdog: &approutingv1alpha1.ConcurrencyWatchdogConfiguration{
+				Threshold: util.Int32Ptr(150),
+			},
+		},
+	}
+	r := newTestReconciler(t, arc)
+	updates := r.layers.flags.Subscribe()
+
+	t.Run("valid spec is applied", func(t *testing.T) {
+		got := reconcileDefault(t, r)
+		<-updates
+
+		current := r.layers.flags.Current()
+		require.Equal(t, config.Private, current.DefaultController)
+		require.Equal(t, time.Minute, current.DnsSyncInterval)
+		require.Equal(t, float64(150), current.ConcurrencyWatchdogThres)
+		require.Equal(t, 4, current.ConcurrencyWatchdogVotes, "unset fields keep the flag value")
+		require.Contains(t, current.PublicZoneConfig.ZoneIds, publicZone)
+		require.Contains(t, current.PrivateZoneConfig.ZoneIds, privateZone)
+		require.Equal(t, config.Standard, r.layers.flags.DefaultController, "flags aren't modified")
+
+		cond := meta.FindStatusCondition(got.Status.Conditions, approutingv1alpha1.AppRoutingConfigurationConditionTypeApplied)
+		require.Equal(t, metav1.ConditionTrue, cond.Status)
+		require.Equal(t, int64(1), cond.ObservedGeneration)
+		require.Equal(t, "private", *got.Status.Effective.DefaultController)
+		require.Equal(t, []string{publicZone, privateZone}, got.Status.Effective.DNSZoneResourceIDs)
+		require.Equal(t, int32(150), *got.Status.Effective.ConcurrencyWatchdog.Threshold)
+		require.Nil(t, got.Status.Effective.DefaultDomain, "default domain isn't enabled")
+	})
+
+	t.Run("unchanged spec doesn't notify", func(t *testing.T) {
+		reconcileDefault(t, r)
+		require.Len(t, updates, 0)
+	})
+
+	t.Run("invalid spec keeps the previous configuration", func(t *testing.T) {
+		got := reconcileDefault(t, r)
+		got.Spec.ConcurrencyWatchdog.Threshold = util.Int32Ptr(50)
+		got.Spec.DefaultController = util.ToPtr("public")
+		got.Generation = 2
+		require.NoError(t, r.client.Update(context.Background(), got))
+
+		got = reconcileDefault(t, r)
+		require.Len(t, updates, 0)
+		require.Equal(t, config.Private, r.layers.flags.Current().DefaultController)
+
+		cond := meta.FindStatusCondition(got.Status.Conditions, approutingv1alpha1.AppRoutingConfigurationConditionTypeApplied)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, "InvalidConfiguration", cond.Reason)
+		require.Contains(t, cond.Message, "must be greater than 100")
+		require.Equal(t, "private", *got.Status.Effective.DefaultController)
+	})
+
+	t.Run("empty zones remove every zone", func(t *testing.T) {
+		got := reconcileDefault(t, r)
+		got.Spec.ConcurrencyWatchdog.Threshold = util.Int32Ptr(150)
+		got.Spec.DNSZoneResourceIDs = []string{}
+		require.NoError(t, r.client.Update(context.Background(), got))
+
+		got = reconcileDefault(t, r)
+		<-updates
+		require.Empty(t, r.layers.flags.Current().PublicZoneConfig.ZoneIds)
+		require.Empty(t, r.layers.flags.Current().PrivateZoneConfig.ZoneIds)
+		require.Equal(t, config.Public, r.layers.flags.Current().DefaultController)
+		require.Empty(t, got.Status.Effective.DNSZoneResourceIDs)
+	})
+
+	t.Run("deleting reverts to the flags", func(t *testing.T) {
+		require.NoError(t, r.client.Delete(context.Background(), arc))
+
+		require.Nil(t, reconcileDefault(t, r))
+		<-updates
+		require.Same(t, r.layers.flags, r.layers.flags.Current())
+	})
+}
+
+func TestReconcileIgnoresOtherNames(t *testing.T) {
+	r := newTestReconciler(t)
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}})
+	require.NoError(t, err)
+	require.Same(t, r.layers.flags, r.layers.flags.Current())
+}
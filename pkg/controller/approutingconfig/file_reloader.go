@@ -0,0 +1,183 @@
+package approutingconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/store"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var reloaderName = controllername.New("config", "file", "reloader")
+
+const (
+	sourceFile     = "file"
+	sourceCABundle = "ca_bundle"
+)
+
+// fileReloader overlays the config file onto the flags and adds the CA bundles to the config in effect. Both are usually mounted
+// from a ConfigMap so they're reloaded whenever the store sees them rotate. Rejected reloads keep the previous configuration.
+type fileReloader struct {
+	layers    *layers
+	store     store.Store
+	rotations <-chan store.RotationEvent
+	events    record.EventRecorder
+	lgr       logr.Logger
+
+	// deployment is the operator's deployment, reload events are recorded on it
+	deployment *corev1.ObjectReference
+}
+
+func addFileReloader(mgr ctrl.Manager, l *layers, s store.Store) error {
+	conf := l.flags
+	f := &fileReloader{
+		layers: l,
+		store:  s,
+		events: mgr.GetEventRecorderFor("aks-app-routing-operator"),
+		lgr:    reloaderName.AddToLogger(mgr.GetLogger()),
+		deployment: &corev1.ObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  conf.OperatorNamespace,
+			Name:       conf.OperatorDeployment,
+		},
+	}
+
+	// files are loaded up front so controllers start with the config in effect, a bad file at startup is an operator error
+	var paths []string
+	for _, path := range slices.Concat([]string{conf.ConfigFile}, conf.CABundleFiles) {
+		if path == "" {
+			continue
+		}
+		if err := s.AddFile(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	f.rotations = s.Subscribe(paths...)
+	if conf.ConfigFile != "" {
+		if _, err := f.loadConfigFile(); err != nil {
+			return err
+		}
+	}
+	if len(conf.CABundleFiles) > 0 {
+		if _, err := f.loadCABundle(); err != nil {
+			return err
+		}
+	}
+
+	return mgr.Add(f)
+}
+
+// NeedLeaderElection is false so every replica, including the webhook servers, runs with the same config
+func (f *fileReloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (f *fileReloader) Start(ctx context.Context) error {
+	f.lgr.Info("starting config file reloader")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-f.rotations:
+			if !ok {
+				f.lgr.Info("store stopped watching files, stopping config file reloader")
+				return nil
+			}
+			f.reload(event.Path)
+		case err, ok := <-f.store.Errors():
+			if !ok {
+				f.lgr.Info("store stopped watching files, stopping config file reloader")
+				return nil
+			}
+			f.lgr.Error(err, "watching config files")
+		}
+	}
+}
+
+// reload reloads the source path belongs to and records the result
+func (f *fileReloader) reload(path string) {
+	var source string
+	var load func() (bool, error)
+	switch {
+	case path == f.layers.flags.ConfigFile:
+		source, load = sourceFile, f.loadConfigFile
+	case slices.Contains(f.layers.flags.CABundleFiles, path):
+		source, load = sourceCABundle, f.loadCABundle
+	default:
+		return
+	}
+
+	lgr := f.lgr.WithValues("path", path, "source", source)
+	lgr.Info("reloading config")
+
+	changed, err := load()
+	if err != nil {
+		msg := fmt.Sprintf("rejected reload of %s, the previous configuration stays in effect: %s", path, err.Error())
+		lgr.Info(msg)
+		metrics.ConfigReloadsTotal.WithLabelValues(source, metrics.LabelRejected).Inc()
+		f.events.Event(f.deployment, corev1.EventTypeWarning, "ConfigReloadRejected", msg)
+		return
+	}
+
+	metrics.ConfigReloadsTotal.WithLabelValues(source, metrics.LabelSuccess).Inc()
+	if !changed {
+		lgr.Info("reloaded config is unchanged")
+		return
+	}
+
+	lgr.Info("reloaded config")
+	f.events.Event(f.deployment, corev1.EventTypeNormal, "ConfigReloaded", fmt.Sprintf("reloaded %s", path))
+}
+
+// loadConfigFile parses the config file as an AppRoutingConfigurationSpec and overlays it
+func (f *fileReloader) loadConfigFile() (bool, error) {
+	path := f.layers.flags.ConfigFile
+	content, ok := f.store.GetContent(path)
+	if !ok {
+		return false, fmt.Errorf("config file %s isn't in the store", path)
+	}
+
+	spec := &approutingv1alpha1.AppRoutingConfigurationSpec{}
+	if err := yaml.UnmarshalStrict(content, spec); err != nil {
+		return false, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	changed, err := f.layers.update(func(s *sources) { s.file = spec })
+	if err != nil {
+		return false, fmt.Errorf("validating config file %s: %w", path, err)
+	}
+
+	return changed, nil
+}
+
+// loadCABundle concatenates the CA bundles in order, every bundle must be valid
+func (f *fileReloader) loadCABundle() (bool, error) {
+	var bundle bytes.Buffer
+	for _, path := range f.layers.flags.CABundleFiles {
+		content, ok := f.store.GetContent(path)
+		if !ok {
+			return false, fmt.Errorf("ca bundle %s isn't in the store", path)
+		}
+
+		if _, err := tls.ParseCABundle(content); err != nil {
+			return false, fmt.Errorf("parsing ca bundle %s: %w", path, err)
+		}
+
+		bundle.Write(bytes.TrimSpace(content))
+		bundle.WriteByte('\n')
+	}
+
+	return f.layers.update(func(s *sources) { s.caBundle = bundle.Bytes() })
+}
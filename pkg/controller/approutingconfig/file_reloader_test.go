@@ -0,0 +1,122 @@
+package approutingconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/store"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func newTestFileReloader(t *testing.T, conf *config.Config) (*fileReloader, *record.FakeRecorder) {
+	s, err := store.New(logr.Discard(), t.Context())
+	require.NoError(t, err)
+
+	paths := append([]string{conf.ConfigFile}, conf.CABundleFiles...)
+	for _, path := range paths {
+		require.NoError(t, s.AddFile(path))
+	}
+
+	events := record.NewFakeRecorder(10)
+	return &fileReloader{
+		layers:     newLayers(conf),
+		store:      s,
+		rotations:  s.Subscribe(paths...),
+		events:     events,
+		lgr:        logr.Discard(),
+		deployment: &corev1.ObjectReference{Kind: "Deployment", Namespace: conf.OperatorNamespace, Name: conf.OperatorDeployment},
+	}, events
+}
+
+// replaceFile replaces path in a single rename so the store never sees a partially written file
+func replaceFile(t *testing.T, path string, content []byte) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, content, 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestFileReloader(t *testing.T) {
+	dir := t.TempDir()
+	conf := testConfig(t)
+	conf.OperatorNamespace = "kube-system"
+	conf.ConfigFile = filepath.Join(dir, "config.yaml")
+	conf.CABundleFiles = []string{filepath.Join(dir, "ca-1.crt"), filepath.Join(dir, "ca-2.crt")}
+	conf.Current() // initialize the live state before anything copies conf
+
+	ca1, _, _, err := tls.GenerateServingCertificate([]string{"one"}, time.Hour)
+	require.NoError(t, err)
+	ca2, _, _, err := tls.GenerateServingCertificate([]string{"two"}, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(conf.ConfigFile, []byte("defaultController: private\n"), 0o644))
+	require.NoError(t, os.WriteFile(conf.CABundleFiles[0], ca1, 0o644))
+	require.NoError(t, os.WriteFile(conf.CABundleFiles[1], ca2, 0o644))
+
+	f, events := newTestFileReloader(t, conf)
+	_, err = f.loadConfigFile()
+	require.NoError(t, err)
+	_, err = f.loadCABundle()
+	require.NoError(t, err)
+
+	require.Equal(t, config.Private, conf.Current().DefaultController)
+	certs, err := tls.ParseCABundle(conf.Current().CABundle)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Start(ctx)
+
+	updates := conf.Subscribe()
+	reloads := func(source, result string) float64 {
+		return testutil.ToFloat64(metrics.ConfigReloadsTotal.WithLabelValues(source, result))
+	}
+
+	t.Run("valid config file is reloaded", func(t *testing.T) {
+		before := reloads(sourceFile, metrics.LabelSuccess)
+		replaceFile(t, conf.ConfigFile, []byte("defaultController: public\n"))
+
+		require.Contains(t, <-events.Events, "Normal ConfigReloaded")
+		<-updates
+		require.Equal(t, config.Public, conf.Current().DefaultController)
+		require.Equal(t, before+1, reloads(sourceFile, metrics.LabelSuccess))
+	})
+
+	t.Run("invalid config file is rejected", func(t *testing.T) {
+		before := reloads(sourceFile, metrics.LabelRejected)
+		replaceFile(t, conf.ConfigFile, []byte("unknownField: true\n"))
+
+		require.Contains(t, <-events.Events, "Warning ConfigReloadRejected")
+		require.Equal(t, config.Public, conf.Current().DefaultController)
+		require.Equal(t, before+1, reloads(sourceFile, metrics.LabelRejected))
+	})
+
+	t.Run("invalid ca bundle is rejected", func(t *testing.T) {
+		before := reloads(sourceCABundle, metrics.LabelRejected)
+		replaceFile(t, conf.CABundleFiles[1], []byte("garbage"))
+
+		require.Contains(t, <-events.Events, "Warning ConfigReloadRejected")
+		certs, err := tls.ParseCABundle(conf.Current().CABundle)
+		require.NoError(t, err)
+		require.Len(t, certs, 2)
+		require.Equal(t, before+1, reloads(sourceCABundle, metrics.LabelRejected))
+	})
+
+	t.Run("valid ca bundle is reloaded", func(t *testing.T) {
+		replaceFile(t, conf.CABundleFiles[1], ca1)
+
+		require.Contains(t, <-events.Events, "Normal ConfigReloaded")
+		<-updates
+		require.Equal(t, string(ca1)+string(ca1), string(conf.Current().CABundle))
+	})
+}
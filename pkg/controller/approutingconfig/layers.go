@@ -0,0 +1,142 @@
+package approutingconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+)
+
+// sources are the layers overlaid onto the flags. Later layers take precedence: the config file, then the AppRoutingConfiguration.
+type sources struct {
+	file     *approutingv1alpha1.AppRoutingConfigurationSpec
+	crd      *approutingv1alpha1.AppRoutingConfigurationSpec
+	caBundle []byte
+}
+
+// layers builds the config in effect from the flags and every source. The config file reloader and the AppRoutingConfiguration
+// reconciler each own one source and share the layers so neither overwrites the other's changes.
+type layers struct {
+	mu      sync.Mutex
+	flags   *config.Config
+	sources sources
+}
+
+func newLayers(flags *config.Config) *layers {
+	return &layers{flags: flags}
+}
+
+// update changes the sources with fn and makes the result the config in effect. The sources are left unchanged when the result is
+// invalid. It returns whether the config in effect changed.
+func (l *layers) update(fn func(*sources)) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := l.sources
+	fn(&next)
+
+	conf, err := next.build(l.flags)
+	if err != nil {
+		return false, err
+	}
+
+	l.sources = next
+	return setCurrent(l.flags, conf), nil
+}
+
+// build overlays the sources onto flags
+func (s sources) build(flags *config.Config) (*config.Config, error) {
+	if s.file == nil && s.crd == nil && s.caBundle == nil {
+		return flags, nil
+	}
+
+	next, err := overlay(flags, s.file, s.crd)
+	if err != nil {
+		return nil, err
+	}
+
+	next.CABundle = s.caBundle
+	return next, nil
+}
+
+// setCurrent makes next the config in effect and returns whether that changed it. Unchanged configs don't notify controllers.
+func setCurrent(flags, next *config.Config) bool {
+	if reflect.DeepEqual(flags.Current(), next) {
+		return false
+	}
+
+	if next == flags {
+		next = nil // revert to the flags
+	}
+	flags.SetCurrent(next)
+	return true
+}
+
+// overlay returns base with the fields set in each spec replaced, in order. Nil specs are skipped. The result is validated the same
+// way the flags are.
+func overlay(base *config.Config, specs ...*approutingv1alpha1.AppRoutingConfigurationSpec) (*config.Config, error) {
+	next := *base
+
+	var zones []string
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
+		if spec.DefaultController != nil {
+			if err := next.DefaultController.Set(*spec.DefaultController); err != nil {
+				return nil, fmt.Errorf("invalid defaultController %s: %w", *spec.DefaultController, err)
+			}
+		}
+
+		if spec.DNSSyncInterval != nil {
+			next.DnsSyncInterval = spec.DNSSyncInterval.Duration
+		}
+
+		if cw := spec.ConcurrencyWatchdog; cw != nil {
+			if cw.Threshold != nil {
+				next.ConcurrencyWatchdogThres = float64(*cw.Threshold)
+			}
+			if cw.Votes != nil {
+				next.ConcurrencyWatchdogVotes = int(*cw.Votes)
+			}
+		}
+
+		if dd := spec.DefaultDomain; dd != nil {
+			if dd.ClientID != nil {
+				next.DefaultDomainClientID = *dd.ClientID
+			}
+			if dd.ZoneID != nil {
+				next.DefaultDomainZoneID = *dd.ZoneID
+			}
+			if dd.EnableGateway != nil {
+				next.EnableDefaultDomainGateway = *dd.EnableGateway
+			}
+		}
+
+		if spec.DNSZoneResourceIDs != nil {
+			zones = spec.DNSZoneResourceIDs
+		}
+	}
+
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate parses the zones from the flags so they're overlaid afterwards
+	switch {
+	case zones == nil:
+	case len(zones) == 0:
+		next.PublicZoneConfig = config.DnsZoneConfig{}
+		next.PrivateZoneConfig = config.DnsZoneConfig{}
+	default:
+		if err := next.ParseAndValidateZoneIDs(strings.Join(zones, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	return &next, nil
+}
@@ -0,0 +1,112 @@
+package approutingconfig
+
+import (
+	"testing"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayersUpdate(t *testing.T) {
+	l := newLayers(testConfig(t))
+	flags := l.flags
+	flags.Current() // initialize the live state before anything copies flags
+
+	file := &approutingv1alpha1.AppRoutingConfigurationSpec{
+		DefaultController:  util.ToPtr("private"),
+		DNSZoneResourceIDs: []string{publicZone},
+		ConcurrencyWatchdog: &approutingv1alpha1.ConcurrencyWatchdogConfiguration{
+			Threshold: util.Int32Ptr(150),
+		},
+	}
+	changed, err := l.update(func(s *sources) { s.file = file })
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, config.Private, flags.Current().DefaultController)
+	require.Equal(t, float64(150), flags.Current().ConcurrencyWatchdogThres)
+
+	t.Run("the AppRoutingConfiguration takes precedence over the file", func(t *testing.T) {
+		changed, err := l.update(func(s *sources) {
+			s.crd = &approutingv1alpha1.AppRoutingConfigurationSpec{DNSZoneResourceIDs: []string{privateZone}}
+		})
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		current := flags.Current()
+		require.Equal(t, config.Private, current.DefaultController, "unset fields keep the file value")
+		require.Empty(t, current.PublicZoneConfig.ZoneIds)
+		require.Contains(t, current.PrivateZoneConfig.ZoneIds, privateZone)
+	})
+
+	t.Run("unchanged sources don't change the config", func(t *testing.T) {
+		changed, err := l.update(func(s *sources) { s.file = file.DeepCopy() })
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("invalid sources are rejected", func(t *testing.T) {
+		_, err := l.update(func(s *sources) {
+			s.file = &approutingv1alpha1.AppRoutingConfigurationSpec{
+				ConcurrencyWatchdog: &approutingv1alpha1.ConcurrencyWatchdogConfiguration{Threshold: util.Int32Ptr(50)},
+			}
+		})
+		require.ErrorContains(t, err, "must be greater than 100")
+		require.Equal(t, file, l.sources.file)
+		require.Equal(t, float64(150), flags.Current().ConcurrencyWatchdogThres)
+	})
+
+	t.Run("ca bundle is set on the config in effect", func(t *testing.T) {
+		changed, err := l.update(func(s *sources) { s.caBundle = []byte("bundle") })
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, []byte("bundle"), flags.Current().CABundle)
+		require.Nil(t, flags.CABundle, "flags aren't modified")
+	})
+
+	t.Run("removing every source reverts to the flags", func(t *testing.T) {
+		changed, err := l.update(func(s *sources) { *s = sources{} })
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Same(t, flags, flags.Current())
+	})
+}
+
+func TestOverlay(t *testing.T) {
+	base := testConfig(t)
+	base.EnableDefaultDomain = true
+	base.DefaultDomainServerAddress = "http://default-domain"
+	base.DefaultDomainClientID = "client-id"
+	base.DefaultDomainZoneID = "zone-id"
+
+	next, err := overlay(base, &approutingv1alpha1.AppRoutingConfigurationSpec{
+		DefaultDomain: &approutingv1alpha1.DefaultDomainConfiguration{
+			ZoneID:        util.ToPtr("other-zone-id"),
+			EnableGateway: util.ToPtr(true),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "client-id", next.DefaultDomainClientID)
+	require.Equal(t, "other-zone-id", next.DefaultDomainZoneID)
+	require.True(t, next.EnableDefaultDomainGateway)
+
+	_, err = overlay(base, &approutingv1alpha1.AppRoutingConfigurationSpec{
+		DefaultDomain: &approutingv1alpha1.DefaultDomainConfiguration{ClientID: util.ToPtr("")},
+	})
+	require.ErrorContains(t, err, "are all required when --enable-default-domain is set")
+
+	_, err = overlay(base, &approutingv1alpha1.AppRoutingConfigurationSpec{DNSZoneResourceIDs: []string{"invalid"}})
+	require.ErrorContains(t, err, "while parsing dns zone resource ID invalid")
+
+	_, err = overlay(base, &approutingv1alpha1.AppRoutingConfigurationSpec{DefaultController: util.ToPtr("invalid")})
+	require.ErrorContains(t, err, "invalid defaultController invalid")
+
+	next, err = overlay(base,
+		&approutingv1alpha1.AppRoutingConfigurationSpec{DNSZoneResourceIDs: []string{publicZone}},
+		nil,
+		&approutingv1alpha1.AppRoutingConfigurationSpec{DNSZoneResourceIDs: []string{}},
+	)
+	require.NoError(t, err)
+	require.Empty(t, next.PublicZoneConfig.ZoneIds, "the last zones win")
+}
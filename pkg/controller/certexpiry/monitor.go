@@ -0,0 +1,347 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package certexpiry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+)
+
+var name = controllername.New("cert", "expiry", "monitor")
+
+const (
+	eventReasonExpiring = "CertificateExpiring"
+	eventReasonExpired  = "CertificateExpired"
+)
+
+var nicGvk = approutingv1alpha1.GroupVersion.WithKind("NginxIngressController")
+
+// certSecret is a TLS secret App Routing manages and the resource its certificate is for
+type certSecret struct {
+	secret types.NamespacedName
+	owner  *metav1.PartialObjectMetadata
+}
+
+// series are the label values of a TLSCertExpirySeconds series
+type series struct {
+	namespace, secret, ownerKind, ownerName string
+}
+
+// warning is the last warning recorded for the certificate of a secret
+type warning struct {
+	notAfter time.Time
+	// threshold is the smallest threshold warned about, zero once the certificate expired
+	threshold time.Duration
+}
+
+// Monitor exports how long until the certificates of App Routing managed TLS secrets expire and records warning events on the
+// resources they're for as the expiry crosses each threshold. Secrets are read directly from the API server so the operator
+// doesn't cache every secret of the cluster.
+type Monitor struct {
+	client    client.Client
+	apiReader client.Reader
+	logger    logr.Logger
+	events    record.EventRecorder
+	now       func() time.Time
+
+	interval   time.Duration
+	thresholds []time.Duration
+
+	keyvault, nginx, defaultDomain bool
+
+	// warned is kept in memory so a certificate is warned about again for each threshold after the operator restarts
+	warned map[string]warning
+	// exported are the series set on the last check, ones that aren't set again are deleted
+	exported map[series]struct{}
+}
+
+func NewMonitor(manager ctrl.Manager, conf *config.Config) error {
+	metrics.InitControllerMetrics(name)
+	if conf.CertExpiryCheckInterval <= 0 {
+		return fmt.Errorf("certificate expiry check interval must be positive")
+	}
+
+	m := &Monitor{
+		client:    manager.GetClient(),
+		apiReader: manager.GetAPIReader(),
+		logger:    name.AddToLogger(manager.GetLogger()),
+		events:    manager.GetEventRecorderFor("aks-app-routing-operator"),
+		now:       time.Now,
+
+		interval:   conf.CertExpiryCheckInterval,
+		thresholds: conf.CertExpiryWarningThresholds,
+
+		keyvault:      !conf.DisableKeyvault,
+		nginx:         !conf.DisableIngressNginx,
+		defaultDomain: conf.EnableDefaultDomain,
+
+		warned:   map[string]warning{},
+		exported: map[series]struct{}{},
+	}
+
+	return manager.Add(m)
+}
+
+func (m *Monitor) Start(ctx context.Context) error {
+	// check right away so the metric is exported without waiting a whole interval
+	interval := time.Nanosecond
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(util.Jitter(interval, 0.3)):
+		}
+		if err := m.tick(ctx); err != nil {
+			m.logger.Error(err, "error checking certificate expiry")
+		}
+
+		interval = m.interval
+	}
+}
+
+func (m *Monitor) NeedLeaderElection() bool {
+	return true
+}
+
+func (m *Monitor) tick(ctx context.Context) error {
+	lgr := m.logger
+	start := time.Now()
+	var retErr *multierror.Error
+	defer func() {
+		lgr.Info("finished checking certificate expiry", "latencySec", time.Since(start).Seconds())
+		metrics.HandleControllerReconcileMetrics(name, ctrl.Result{}, retErr.ErrorOrNil())
+	}()
+
+	certSecrets, err := m.listCertSecrets(ctx)
+	if err != nil {
+		retErr = multierror.Append(retErr, err)
+		return retErr.ErrorOrNil()
+	}
+
+	now := m.now()
+	seen := map[string]struct{}{}
+	exported := map[series]struct{}{}
+	for _, cs := range certSecrets {
+		lgr := lgr.WithValues("secret", cs.secret.String(), "ownerKind", cs.owner.Kind, "ownerName", cs.owner.Name)
+		s := series{namespace: cs.secret.Namespace, secret: cs.secret.Name, ownerKind: cs.owner.Kind, ownerName: cs.owner.Name}
+
+		secret := &corev1.Secret{}
+		if err := m.apiReader.Get(ctx, cs.secret, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				// Keyvault secrets only exist once they're pulled
+				lgr.Info("secret not found, skipping")
+				continue
+			}
+
+			lgr.Error(err, "getting secret")
+			retErr = multierror.Append(retErr, fmt.Errorf("getting secret %s: %w", cs.secret, err))
+			// the last value is kept through errors that are likely transient
+			if _, ok := m.exported[s]; ok {
+				exported[s] = struct{}{}
+			}
+			continue
+		}
+
+		cert, err := tls.ParseCertificate(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			lgr.Error(err, "parsing certificate")
+			retErr = multierror.Append(retErr, fmt.Errorf("parsing certificate of secret %s: %w", cs.secret, err))
+			continue
+		}
+
+		remaining := cert.NotAfter.Sub(now)
+		metrics.TLSCertExpirySeconds.WithLabelValues(s.namespace, s.secret, s.ownerKind, s.ownerName).Set(remaining.Seconds())
+		exported[s] = struct{}{}
+
+		key := fmt.Sprintf("%s/%s", cs.owner.UID, cs.secret)
+		seen[key] = struct{}{}
+		m.warn(lgr, key, cs, cert.NotAfter, remaining)
+	}
+
+	for key := range m.warned {
+		if _, ok := seen[key]; !ok {
+			delete(m.warned, key)
+		}
+	}
+
+	// secrets that are gone or can't be parsed shouldn't keep their series
+	for s := range m.exported {
+		if _, ok := exported[s]; !ok {
+			metrics.TLSCertExpirySeconds.DeleteLabelValues(s.namespace, s.secret, s.ownerKind, s.ownerName)
+		}
+	}
+	m.exported = exported
+
+	return retErr.ErrorOrNil()
+}
+
+// warn records a warning event on the owner of the secret when the certificate's remaining validity crosses a threshold it
+// hasn't been warned about
+func (m *Monitor) warn(lgr logr.Logger, key string, cs certSecret, notAfter time.Time, remaining time.Duration) {
+	threshold, ok := m.crossedThreshold(remaining)
+	if !ok {
+		// the certificate was renewed or hasn't reached a threshold
+		delete(m.warned, key)
+		return
+	}
+
+	if prev, ok := m.warned[key]; ok && prev.notAfter.Equal(notAfter) && prev.threshold <= threshold {
+		return
+	}
+	m.warned[key] = warning{notAfter: notAfter, threshold: threshold}
+
+	expiry := notAfter.UTC().Format(time.RFC3339)
+	if remaining <= 0 {
+		lgr.Info("certificate expired", "notAfter", expiry)
+		m.events.Eventf(cs.owner, corev1.EventTypeWarning, eventReasonExpired, "certificate in Secret %s expired at %s", cs.secret, expiry)
+		return
+	}
+
+	lgr.Info("certificate expiring", "notAfter", expiry, "threshold", threshold)
+	m.events.Eventf(cs.owner, corev1.EventTypeWarning, eventReasonExpiring, "certificate in Secret %s expires in %s at %s", cs.secret, formatRemaining(remaining), expiry)
+}
+
+// crossedThreshold returns the smallest threshold the remaining validity is within, zero when the certificate expired. Nothing
+// is crossed when no thresholds are configured.
+func (m *Monitor) crossedThreshold(remaining time.Duration) (time.Duration, bool) {
+	if len(m.thresholds) == 0 {
+		return 0, false
+	}
+	if remaining <= 0 {
+		return 0, true
+	}
+
+	var crossed time.Duration
+	var ok bool
+	for _, threshold := range m.thresholds {
+		if remaining <= threshold && (!ok || threshold < crossed) {
+			crossed, ok = threshold, true
+		}
+	}
+
+	return crossed, ok
+}
+
+// listCertSecrets returns the TLS secrets of Keyvault SecretProviderClasses, NginxIngressController default certificates and
+// DefaultDomainCertificates
+func (m *Monitor) listCertSecrets(ctx context.Context) ([]certSecret, error) {
+	var ret []certSecret
+
+	if m.keyvault {
+		spcs := &secv1.SecretProviderClassList{}
+		if err := m.client.List(ctx, spcs); err != nil {
+			return nil, fmt.Errorf("listing SecretProviderClass objects: %w", err)
+		}
+
+		for _, spc := range spcs.Items {
+			if !manifests.HasTopLevelLabels(spc.Labels) {
+				continue
+			}
+
+			ownerRef := metav1.GetControllerOf(&spc)
+			if ownerRef == nil {
+				continue
+			}
+
+			owner := &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind},
+				ObjectMeta: metav1.ObjectMeta{Name: ownerRef.Name, Namespace: spc.Namespace, UID: ownerRef.UID},
+			}
+			if owner.GroupVersionKind() == nicGvk {
+				// NginxIngressControllers are cluster scoped
+				owner.Namespace = ""
+			}
+
+			for _, secretObject := range spc.Spec.SecretObjects {
+				if secretObject == nil || secretObject.Type != string(corev1.SecretTypeTLS) {
+					continue
+				}
+
+				ret = append(ret, certSecret{
+					secret: types.NamespacedName{Namespace: spc.Namespace, Name: secretObject.SecretName},
+					owner:  owner,
+				})
+			}
+		}
+	}
+
+	if m.nginx {
+		nics := &approutingv1alpha1.NginxIngressControllerList{}
+		if err := m.client.List(ctx, nics); err != nil {
+			return nil, fmt.Errorf("listing NginxIngressController objects: %w", err)
+		}
+
+		for _, nic := range nics.Items {
+			// Keyvault default certificates are found through their SecretProviderClass
+			if nic.Spec.DefaultSSLCertificate == nil || nic.Spec.DefaultSSLCertificate.Secret == nil {
+				continue
+			}
+
+			ret = append(ret, certSecret{
+				secret: types.NamespacedName{
+					Namespace: nic.Spec.DefaultSSLCertificate.Secret.Namespace,
+					Name:      nic.Spec.DefaultSSLCertificate.Secret.Name,
+				},
+				owner: ownerOf(&nic, nicGvk.Kind),
+			})
+		}
+	}
+
+	if m.defaultDomain {
+		ddcs := &approutingv1alpha1.DefaultDomainCertificateList{}
+		if err := m.client.List(ctx, ddcs); err != nil {
+			return nil, fmt.Errorf("listing DefaultDomainCertificate objects: %w", err)
+		}
+
+		for _, ddc := range ddcs.Items {
+			if ddc.Spec.Target.Secret == nil {
+				continue
+			}
+
+			ret = append(ret, certSecret{
+				secret: types.NamespacedName{Namespace: ddc.Namespace, Name: *ddc.Spec.Target.Secret},
+				owner:  ownerOf(&ddc, "DefaultDomainCertificate"),
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+// ownerOf returns a reference to an App Routing resource that events can be recorded on. Listed objects don't keep their kind.
+func ownerOf(obj client.Object, kind string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: approutingv1alpha1.GroupVersion.String(), Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace(), UID: obj.GetUID()},
+	}
+}
+
+// formatRemaining returns the remaining validity of a certificate in days, or hours and minutes within the last two days
+func formatRemaining(remaining time.Duration) string {
+	if days := int(remaining / (24 * time.Hour)); days >= 2 {
+		return fmt.Sprintf("%d days", days)
+	}
+
+	return remaining.Truncate(time.Minute).String()
+}
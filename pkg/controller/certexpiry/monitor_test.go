@@ -0,0 +1,324 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package certexpiry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+)
+
+const testNamespace = "test-ns"
+
+var testThresholds = []time.Duration{720 * time.Hour, 168 * time.Hour, 24 * time.Hour}
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, secv1.AddToScheme(scheme))
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func newTestMonitor(cl client.Client) *Monitor {
+	return &Monitor{
+		client:        cl,
+		apiReader:     cl,
+		logger:        logr.Discard(),
+		events:        record.NewFakeRecorder(10),
+		now:           time.Now,
+		interval:      time.Hour,
+		thresholds:    testThresholds,
+		keyvault:      true,
+		nginx:         true,
+		defaultDomain: true,
+		warned:        map[string]warning{},
+		exported:      map[series]struct{}{},
+	}
+}
+
+func newTestSpc(name, secretName, secretType string, owner metav1.OwnerReference) *secv1.SecretProviderClass {
+	return &secv1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       testNamespace,
+			Labels:          manifests.GetTopLevelLabels(),
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: secv1.SecretProviderClassSpec{
+			SecretObjects: []*secv1.SecretObject{{SecretName: secretName, Type: secretType}},
+		},
+	}
+}
+
+func newTestCertSecret(t *testing.T, name string, validity time.Duration) (*corev1.Secret, time.Time) {
+	_, certPEM, keyPEM, err := tls.GenerateServingCertificate([]string{"example.com"}, validity)
+	require.NoError(t, err)
+
+	cert, err := tls.ParseCertificate(certPEM)
+	require.NoError(t, err)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}, cert.NotAfter
+}
+
+func TestListCertSecrets(t *testing.T) {
+	ingressOwner := metav1.OwnerReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "ingress", UID: "ingress-uid", Controller: util.ToPtr(true)}
+	nicOwner := metav1.OwnerReference{APIVersion: approutingv1alpha1.GroupVersion.String(), Kind: "NginxIngressController", Name: "nic", UID: "nic-uid", Controller: util.ToPtr(true)}
+
+	unmanaged := newTestSpc("unmanaged", "unmanaged", "kubernetes.io/tls", ingressOwner)
+	unmanaged.Labels = nil
+
+	cl := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newTestSpc("keyvault-ingress", "keyvault-ingress", "kubernetes.io/tls", ingressOwner),
+		newTestSpc("kv-secrets-ingress", "kv-secrets-ingress", string(corev1.SecretTypeOpaque), ingressOwner),
+		newTestSpc("keyvault-nginx-nic", "keyvault-nginx-nic", "kubernetes.io/tls", nicOwner),
+		unmanaged,
+		&approutingv1alpha1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-nic", UID: "secret-nic-uid"},
+			Spec: approutingv1alpha1.NginxIngressControllerSpec{
+				DefaultSSLCertificate: &approutingv1alpha1.DefaultSSLCertificate{
+					Secret: &approutingv1alpha1.Secret{Name: "default-cert", Namespace: "nic-ns"},
+				},
+			},
+		},
+		&approutingv1alpha1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "keyvault-nic", UID: "keyvault-nic-uid"},
+			Spec: approutingv1alpha1.NginxIngressControllerSpec{
+				DefaultSSLCertificate: &approutingv1alpha1.DefaultSSLCertificate{KeyVaultURI: util.ToPtr("https://vault.vault.azure.net/secrets/cert")},
+			},
+		},
+		&approutingv1alpha1.DefaultDomainCertificate{
+			ObjectMeta: metav1.ObjectMeta{Name: "ddc", Namespace: testNamespace, UID: "ddc-uid"},
+			Spec:       approutingv1alpha1.DefaultDomainCertificateSpec{Target: approutingv1alpha1.DefaultDomainCertificateTarget{Secret: util.ToPtr("ddc-secret")}},
+		},
+	).Build()
+
+	owner := func(apiVersion, kind, name, namespace string, uid types.UID) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: uid},
+		}
+	}
+	keyvaultSecrets := []certSecret{
+		{
+			secret: types.NamespacedName{Namespace: testNamespace, Name: "keyvault-ingress"},
+			owner:  owner("networking.k8s.io/v1", "Ingress", "ingress", testNamespace, "ingress-uid"),
+		},
+		{
+			secret: types.NamespacedName{Namespace: testNamespace, Name: "keyvault-nginx-nic"},
+			owner:  owner(approutingv1alpha1.GroupVersion.String(), "NginxIngressController", "nic", "", "nic-uid"),
+		},
+	}
+	nginxSecrets := []certSecret{{
+		secret: types.NamespacedName{Namespace: "nic-ns", Name: "default-cert"},
+		owner:  owner(approutingv1alpha1.GroupVersion.String(), "NginxIngressController", "secret-nic", "", "secret-nic-uid"),
+	}}
+	defaultDomainSecrets := []certSecret{{
+		secret: types.NamespacedName{Namespace: testNamespace, Name: "ddc-secret"},
+		owner:  owner(approutingv1alpha1.GroupVersion.String(), "DefaultDomainCertificate", "ddc", testNamespace, "ddc-uid"),
+	}}
+
+	m := newTestMonitor(cl)
+	got, err := m.listCertSecrets(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, append(append(keyvaultSecrets, nginxSecrets...), defaultDomainSecrets...), got)
+
+	m.keyvault = false
+	got, err = m.listCertSecrets(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, append(nginxSecrets, defaultDomainSecrets...), got)
+
+	m.nginx = false
+	got, err = m.listCertSecrets(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, defaultDomainSecrets, got)
+
+	m.defaultDomain = false
+	got, err = m.listCertSecrets(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestTick(t *testing.T) {
+	secret, notAfter := newTestCertSecret(t, "keyvault-ingress", 90*24*time.Hour)
+	ingressOwner := metav1.OwnerReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "ingress", UID: "ingress-uid", Controller: util.ToPtr(true)}
+
+	cl := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newTestSpc("keyvault-ingress", "keyvault-ingress", "kubernetes.io/tls", ingressOwner),
+		newTestSpc("keyvault-missing", "keyvault-missing", "kubernetes.io/tls", ingressOwner),
+		secret,
+	).Build()
+
+	m := newTestMonitor(cl)
+	m.nginx = false
+	m.defaultDomain = false
+	events := m.events.(*record.FakeRecorder).Events
+
+	gauge := func() float64 {
+		return promtestutil.ToFloat64(metrics.TLSCertExpirySeconds.WithLabelValues(testNamespace, "keyvault-ingress", "Ingress", "ingress"))
+	}
+	tickAt := func(remaining time.Duration) {
+		m.now = func() time.Time { return notAfter.Add(-remaining) }
+		require.NoError(t, m.tick(context.Background()))
+	}
+	requireEvent := func(expected string) {
+		select {
+		case event := <-events:
+			assert.Equal(t, expected, event)
+		default:
+			t.Fatalf("expected event %q", expected)
+		}
+	}
+	requireNoEvent := func() {
+		select {
+		case event := <-events:
+			t.Fatalf("unexpected event %q", event)
+		default:
+		}
+	}
+
+	tickAt(60 * 24 * time.Hour)
+	assert.Equal(t, (60 * 24 * time.Hour).Seconds(), gauge())
+	assert.Equal(t, 1, promtestutil.CollectAndCount(metrics.TLSCertExpirySeconds), "missing secrets have no series")
+	requireNoEvent()
+
+	expiry := notAfter.UTC().Format(time.RFC3339)
+	tickAt(20 * 24 * time.Hour)
+	requireEvent("Warning CertificateExpiring certificate in Secret test-ns/keyvault-ingress expires in 20 days at " + expiry)
+
+	tickAt(10 * 24 * time.Hour)
+	requireNoEvent()
+
+	// skipping a threshold only warns about the smallest
+	tickAt(12 * time.Hour)
+	requireEvent("Warning CertificateExpiring certificate in Secret test-ns/keyvault-ingress expires in 12h0m0s at " + expiry)
+
+	tickAt(-time.Hour)
+	assert.Equal(t, (-time.Hour).Seconds(), gauge())
+	requireEvent("Warning CertificateExpired certificate in Secret test-ns/keyvault-ingress expired at " + expiry)
+
+	tickAt(-2 * time.Hour)
+	requireNoEvent()
+
+	// a renewed certificate is warned about again
+	renewed, renewedNotAfter := newTestCertSecret(t, "keyvault-ingress", 91*24*time.Hour)
+	require.NoError(t, cl.Update(context.Background(), renewed))
+	notAfter = renewedNotAfter
+	tickAt(5 * 24 * time.Hour)
+	requireEvent("Warning CertificateExpiring certificate in Secret test-ns/keyvault-ingress expires in 5 days at " + renewedNotAfter.UTC().Format(time.RFC3339))
+
+	// secrets that aren't managed anymore are forgotten
+	require.NoError(t, cl.Delete(context.Background(), &secv1.SecretProviderClass{ObjectMeta: metav1.ObjectMeta{Name: "keyvault-ingress", Namespace: testNamespace}}))
+	tickAt(5 * 24 * time.Hour)
+	assert.Empty(t, m.warned)
+	assert.Equal(t, 0, promtestutil.CollectAndCount(metrics.TLSCertExpirySeconds))
+}
+
+func TestTickStaleSeries(t *testing.T) {
+	secret, _ := newTestCertSecret(t, "keyvault-ingress", 90*24*time.Hour)
+	ingressOwner := metav1.OwnerReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "ingress", UID: "ingress-uid", Controller: util.ToPtr(true)}
+
+	getErr := false
+	cl := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newTestSpc("keyvault-ingress", "keyvault-ingress", "kubernetes.io/tls", ingressOwner),
+		secret,
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*corev1.Secret); ok && getErr {
+				return errors.New("transient")
+			}
+			return cl.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+
+	// series the monitor didn't set are left alone
+	other := metrics.TLSCertExpirySeconds.WithLabelValues("other-ns", "other", "Ingress", "other")
+	other.Set(1)
+	defer metrics.TLSCertExpirySeconds.DeleteLabelValues("other-ns", "other", "Ingress", "other")
+
+	m := newTestMonitor(cl)
+	require.NoError(t, m.tick(context.Background()))
+	assert.Equal(t, 2, promtestutil.CollectAndCount(metrics.TLSCertExpirySeconds))
+
+	// the last value is kept while the secret can't be read
+	getErr = true
+	require.Error(t, m.tick(context.Background()))
+	assert.Equal(t, 2, promtestutil.CollectAndCount(metrics.TLSCertExpirySeconds))
+
+	getErr = false
+	require.NoError(t, cl.Delete(context.Background(), &secv1.SecretProviderClass{ObjectMeta: metav1.ObjectMeta{Name: "keyvault-ingress", Namespace: testNamespace}}))
+	require.NoError(t, m.tick(context.Background()))
+	assert.Equal(t, 1, promtestutil.CollectAndCount(metrics.TLSCertExpirySeconds))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(other))
+}
+
+func TestTickInvalidCertificate(t *testing.T) {
+	ingressOwner := metav1.OwnerReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "ingress", UID: "ingress-uid", Controller: util.ToPtr(true)}
+	cl := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newTestSpc("keyvault-ingress", "keyvault-ingress", "kubernetes.io/tls", ingressOwner),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "keyvault-ingress", Namespace: testNamespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("not a certificate")},
+		},
+	).Build()
+
+	err := newTestMonitor(cl).tick(context.Background())
+	require.ErrorContains(t, err, "parsing certificate of secret test-ns/keyvault-ingress")
+}
+
+func TestCrossedThreshold(t *testing.T) {
+	m := &Monitor{thresholds: testThresholds}
+
+	cases := []struct {
+		remaining time.Duration
+		expected  time.Duration
+		crossed   bool
+	}{
+		{remaining: 60 * 24 * time.Hour},
+		{remaining: 720 * time.Hour, expected: 720 * time.Hour, crossed: true},
+		{remaining: 100 * time.Hour, expected: 168 * time.Hour, crossed: true},
+		{remaining: time.Minute, expected: 24 * time.Hour, crossed: true},
+		{remaining: 0, expected: 0, crossed: true},
+		{remaining: -time.Hour, expected: 0, crossed: true},
+	}
+	for _, tc := range cases {
+		threshold, crossed := m.crossedThreshold(tc.remaining)
+		assert.Equal(t, tc.crossed, crossed, tc.remaining.String())
+		assert.Equal(t, tc.expected, threshold, tc.remaining.String())
+	}
+
+	_, crossed := (&Monitor{}).crossedThreshold(-time.Hour)
+	assert.False(t, crossed, "no thresholds disables warnings")
+}
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "30 days", formatRemaining(30*24*time.Hour+time.Hour))
+	assert.Equal(t, "2 days", formatRemaining(48*time.Hour))
+	assert.Equal(t, "47h59m0s", formatRemaining(48*time.Hour-time.Second))
+}
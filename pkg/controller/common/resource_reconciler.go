@@ -19,6 +19,10 @@ type resourceReconciler struct {
 	logger                  logr.Logger
 	interval, retryInterval time.Duration
 	resources               []client.Object
+
+	// dynamic replaces resources when they're derived from configuration that changes at runtime, changed triggers a reconcile when it does
+	dynamic func() ([]client.Object, error)
+	changed <-chan struct{}
 }
 
 // NewResourceReconciler creates a reconciler that continuously ensures that the provided resources are provisioned
@@ -35,6 +39,22 @@ func NewResourceReconciler(manager ctrl.Manager, name controllername.ControllerN
 	return manager.Add(rr)
 }
 
+// NewDynamicResourceReconciler creates a reconciler that continuously ensures that the resources returned by resources are provisioned.
+// resources is called on every reconcile and receiving from changed reconciles immediately.
+func NewDynamicResourceReconciler(manager ctrl.Manager, name controllername.ControllerNamer, resources func() ([]client.Object, error), changed <-chan struct{}, reconcileInterval time.Duration) error {
+	metrics.InitControllerMetrics(name)
+	rr := &resourceReconciler{
+		name:          name,
+		client:        manager.GetClient(),
+		logger:        name.AddToLogger(manager.GetLogger()),
+		interval:      reconcileInterval,
+		retryInterval: time.Second,
+		dynamic:       resources,
+		changed:       changed,
+	}
+	return manager.Add(rr)
+}
+
 func (r *resourceReconciler) Start(ctx context.Context) error {
 	r.logger.Info("starting resource reconciler")
 	defer r.logger.Info("stopping resource reconciler")
@@ -44,6 +64,7 @@ func (r *resourceReconciler) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-r.changed: // nil for static resources
 		case <-time.After(util.Jitter(interval, 0.3)):
 		}
 
@@ -67,7 +88,15 @@ func (r *resourceReconciler) tick(ctx context.Context) error {
 		metrics.HandleControllerReconcileMetrics(r.name, ctrl.Result{}, err)
 	}()
 
-	for _, res := range r.resources {
+	resources := r.resources
+	if r.dynamic != nil {
+		if resources, err = r.dynamic(); err != nil {
+			r.logger.Error(err, "getting resources")
+			return err
+		}
+	}
+
+	for _, res := range resources {
 		lgr := r.logger.WithValues("name", res.GetName(), "namespace", res.GetNamespace(), "kind", res.GetObjectKind().GroupVersionKind())
 		copy := res.DeepCopyObject().(client.Object)
 		if copy.GetDeletionTimestamp() != nil {
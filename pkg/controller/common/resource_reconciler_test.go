@@ -91,6 +91,38 @@ func TestResourceReconcilerIntegration(t *testing.T) {
 		"expected resource to exist")
 }
 
+func TestResourceReconcilerDynamic(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+
+	name := "first"
+	var resourcesErr error
+	rr := &resourceReconciler{
+		name:   controllername.New("test", "dynamic"),
+		client: c,
+		logger: logr.Discard(),
+		dynamic: func() ([]client.Object, error) {
+			return []client.Object{&corev1.Namespace{
+				TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			}}, resourcesErr
+		},
+	}
+
+	// prove the resources are read on every tick
+	for _, name = range []string{"first", "second"} {
+		require.NoError(t, rr.tick(context.Background()))
+		require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: name}, &corev1.Namespace{}))
+	}
+
+	// prove failing to get resources fails the tick
+	name = "third"
+	resourcesErr = errors.NewBadRequest("invalid")
+	beforeErrCount := testutils.GetErrMetricCount(t, rr.name)
+	require.Equal(t, resourcesErr, rr.tick(context.Background()))
+	require.Greater(t, testutils.GetErrMetricCount(t, rr.name), beforeErrCount)
+	require.True(t, errors.IsNotFound(c.Get(context.Background(), client.ObjectKey{Name: name}, &corev1.Namespace{})))
+}
+
 func TestResourceReconcilerLeaderElection(t *testing.T) {
 	var ler manager.LeaderElectionRunnable = &resourceReconciler{}
 	require.True(t, ler.NeedLeaderElection(), "should need leader election")
@@ -101,6 +133,8 @@ func TestNewResourceReconciler(t *testing.T) {
 	require.NoError(t, err)
 	err = NewResourceReconciler(m, controllername.New("test"), nil, 1*time.Nanosecond)
 	require.NoError(t, err)
+	err = NewDynamicResourceReconciler(m, controllername.New("test", "dynamic"), func() ([]client.Object, error) { return nil, nil }, nil, 1*time.Nanosecond)
+	require.NoError(t, err)
 }
 
 func TestResourceReconciler_DeletionTimestamp(t *testing.T) {
@@ -8,8 +8,11 @@ import (
 	"fmt"
 	"net/http"
 
+	approutingv1 "github.com/Azure/aks-app-routing-operator/api/v1"
 	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
 	defaultdomain "github.com/Azure/aks-app-routing-operator/pkg/clients/default-domain"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/approutingconfig"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/certexpiry"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/defaultdomaincert"
 	placeholderpod "github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/placeholderpod"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/spc"
@@ -17,6 +20,7 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/service"
 	"github.com/Azure/aks-app-routing-operator/pkg/store"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/Azure/aks-app-routing-operator/pkg/webhook"
 	"github.com/go-logr/logr"
 	cfgv1alpha2 "github.com/openservicemesh/osm/pkg/apis/config/v1alpha2"
 	policyv1alpha1 "github.com/openservicemesh/osm/pkg/apis/policy/v1alpha1"
@@ -38,6 +42,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 
@@ -77,6 +82,7 @@ func registerSchemes(s *runtime.Scheme) {
 	utilruntime.Must(cfgv1alpha2.AddToScheme(s))
 	utilruntime.Must(policyv1alpha1.AddToScheme(s))
 	utilruntime.Must(approutingv1alpha1.AddToScheme(s))
+	utilruntime.Must(approutingv1.AddToScheme(s))
 	utilruntime.Must(apiextensionsv1.AddToScheme(s))
 	utilruntime.Must(gatewayv1.Install(s))
 }
@@ -119,6 +125,9 @@ func NewManagerForRestConfig(conf *config.Config, rc *rest.Config) (ctrl.Manager
 
 		Client: client.Options{Cache: clientOpts},
 		Cache:  cacheOpts,
+
+		// the webhook server is only started when webhooks are registered with it
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{Port: conf.WebhookPort, CertDir: conf.WebhookCertDir}),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating manager: %w", err)
@@ -156,6 +165,11 @@ func NewManagerForRestConfig(conf *config.Config, rc *rest.Config) (ctrl.Manager
 		return nil, fmt.Errorf("loading CRDs: %w", err)
 	}
 
+	if err := m.Add(&storageVersionMigrator{client: cl, cfg: conf, log: m.GetLogger().WithName("storage-version-migrator")}); err != nil {
+		setupLog.Error(err, "failed to add storage version migrator")
+		return nil, fmt.Errorf("adding storage version migrator: %w", err)
+	}
+
 	if !conf.EnableDefaultDomain {
 		if err := dns.CleanDefaultDomainDNS(context.Background(), cl, conf, setupLog); err != nil {
 			setupLog.Error(err, "failed to clean up disabled default domain dns resources")
@@ -201,12 +215,18 @@ func setupIndexers(mgr ctrl.Manager, lgr logr.Logger, conf *config.Config) error
 func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl client.Client, store store.Store, healthCheckers *healthCheckers) error {
 	lgr.Info("setting up controllers")
 
+	lgr.Info("setting up AppRoutingConfiguration reconciler")
+	if err := approutingconfig.Setup(mgr, conf, store); err != nil {
+		return fmt.Errorf("setting up app routing configuration reconciler: %w", err)
+	}
+
 	lgr.Info("setting up ExternalDNS controller")
 	if err := dns.NewExternalDns(mgr, conf); err != nil {
 		return fmt.Errorf("setting up external dns controller: %w", err)
 	}
 
 	var ingressManager util.IngressManager
+	var watchdogTargets []ingress.ListWatchdogTargets
 	if !conf.DisableIngressNginx {
 		lgr.Info("determining default IngressClass controller class")
 		defaultCc, err := nginxingress.GetDefaultIngressClassControllerClass(cl)
@@ -215,7 +235,7 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 		}
 
 		lgr.Info("setting up Nginx Ingress Controller reconciler")
-		if err := nginxingress.NewReconciler(conf, mgr, defaultCc); err != nil {
+		if err := nginxingress.NewReconciler(conf, mgr, defaultCc, nicIngressClassIndex); err != nil {
 			return fmt.Errorf("setting up nginx ingress controller reconciler: %w", err)
 		}
 
@@ -234,11 +254,13 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 			return fmt.Errorf("setting up nginx ingress default controller reconciler: %w", err)
 		}
 
-		lgr.Info("setting up ingress concurrency watchdog")
-		if err := ingress.NewConcurrencyWatchdog(mgr, conf, ingress.GetListNginxWatchdogTargets(mgr.GetClient(), defaultCc)); err != nil {
-			return fmt.Errorf("setting up ingress concurrency watchdog: %w", err)
+		lgr.Info("setting up staged nginx rollout")
+		if err := nginxingress.NewStagedRollout(mgr, conf, defaultCc); err != nil {
+			return fmt.Errorf("setting up staged nginx rollout: %w", err)
 		}
 
+		watchdogTargets = append(watchdogTargets, ingress.GetListNginxWatchdogTargets(mgr.GetClient(), defaultCc))
+
 		ingressManager = util.NewIngressManagerFromFn(func(ing *netv1.Ingress) (bool, error) {
 			return nginxingress.IsIngressManaged(context.Background(), mgr.GetClient(), ing, nicIngressClassIndex)
 		})
@@ -246,6 +268,10 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 		if err := spc.NewIngressSecretProviderClassReconciler(mgr, conf, ingressManager); err != nil {
 			return fmt.Errorf("setting up ingress secret provider class reconciler: %w", err)
 		}
+		lgr.Info("setting up keyvault secrets provider class reconciler")
+		if err := spc.NewIngressSecretsProviderClassReconciler(mgr, conf, ingressManager); err != nil {
+			return fmt.Errorf("setting up ingress secrets provider class reconciler: %w", err)
+		}
 		lgr.Info("setting up nginx keyvault secret provider class reconciler")
 		if err := spc.NewNginxSecretProviderClassReconciler(mgr, conf); err != nil {
 			return fmt.Errorf("setting up nginx secret provider class reconciler: %w", err)
@@ -276,6 +302,19 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 		}
 	}
 
+	// managed Gateways are watched whether or not Gateway TLS is enabled
+	watchdogTargets = append(watchdogTargets, ingress.GetListGatewayWatchdogTargets(mgr.GetClient()))
+
+	lgr.Info("setting up ingress concurrency watchdog")
+	if err := ingress.NewConcurrencyWatchdog(mgr, conf, ingress.CombineListWatchdogTargets(watchdogTargets...)); err != nil {
+		return fmt.Errorf("setting up ingress concurrency watchdog: %w", err)
+	}
+
+	lgr.Info("setting up webhooks")
+	if err := webhook.Setup(mgr, conf, cl, ingressManager, lgr); err != nil {
+		return fmt.Errorf("setting up webhooks: %w", err)
+	}
+
 	if conf.EnableDefaultDomain {
 		lgr.Info("setting up default domain reconcilers")
 
@@ -285,6 +324,7 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 			defaultdomain.CachedClientOpts{
 				Opts: defaultdomain.Opts{
 					ServerAddress: conf.DefaultDomainServerAddress,
+					CABundle:      func() []byte { return conf.Current().CABundle },
 				},
 				CacheTTL: conf.DefaultDomainCertCacheTTL,
 			},
@@ -302,6 +342,11 @@ func setupControllers(mgr ctrl.Manager, conf *config.Config, lgr logr.Logger, cl
 		}
 	}
 
+	lgr.Info("setting up certificate expiry monitor")
+	if err := certexpiry.NewMonitor(mgr, conf); err != nil {
+		return fmt.Errorf("setting up certificate expiry monitor: %w", err)
+	}
+
 	lgr.Info("finished setting up controllers")
 	return nil
 }
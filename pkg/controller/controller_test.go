@@ -10,6 +10,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/testutils"
@@ -94,6 +95,7 @@ func minimalTestConfig() *config.Config {
 		ClusterUid:               "test-cluster-uid",
 		OperatorDeployment:       "app-routing-operator",
 		CrdPath:                  validCrdPath,
+		CertExpiryCheckInterval:  time.Hour,
 	}
 }
 
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/go-logr/logr"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
@@ -22,6 +26,7 @@ const (
 	clusterExternalDnsCrdFilename       = "approuting.kubernetes.azure.com_clusterexternaldnses.yaml"
 	nginxIngresscontrollerCrdFilename   = "approuting.kubernetes.azure.com_nginxingresscontrollers.yaml"
 	defaultDomainCertificateCrdFilename = "approuting.kubernetes.azure.com_defaultdomaincertificates.yaml"
+	appRoutingConfigurationCrdFilename  = "approuting.kubernetes.azure.com_approutingconfigurations.yaml"
 )
 
 // readAllCRDs reads and parses all CRD files from the configured directory, returning them keyed by filename.
@@ -142,7 +147,114 @@ func shouldLoadCRD(cfg *config.Config, filename string) bool {
 	case defaultDomainCertificateCrdFilename:
 		return cfg.EnableDefaultDomain
 
+	// the AppRoutingConfiguration overlays flags shared by every feature
+	case appRoutingConfigurationCrdFilename:
+		return true
+
 	default:
 		return false
 	}
 }
+
+// storageVersionMigrationInterval is how often storage version migration is retried until it succeeds
+var storageVersionMigrationInterval = 30 * time.Second
+
+// storageVersionMigrator moves the custom resources of the loaded CRDs to the storage version of their CRD once the manager
+// starts. It runs after the webhook server has started so custom resources stored in another version can be converted.
+type storageVersionMigrator struct {
+	client client.Client
+	cfg    *config.Config
+	log    logr.Logger
+}
+
+func (s *storageVersionMigrator) Start(ctx context.Context) error {
+	for {
+		err := migrateStoredVersions(ctx, s.client, s.cfg, s.log)
+		if err == nil {
+			return nil
+		}
+		s.log.Error(err, "migrating stored versions, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(util.Jitter(storageVersionMigrationInterval, 0.3)):
+		}
+	}
+}
+
+// migrateStoredVersions migrates every loaded CRD that still has custom resources stored in versions other than its storage version
+func migrateStoredVersions(ctx context.Context, c client.Client, cfg *config.Config, log logr.Logger) error {
+	crds, err := readAllCRDs(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	for filename, crd := range crds {
+		if !shouldLoadCRD(cfg, filename) {
+			continue
+		}
+		if err := migrateStoredVersion(ctx, c, crd, log); err != nil {
+			return fmt.Errorf("migrating stored versions of crd %s: %w", crd.Name, err)
+		}
+	}
+	return nil
+}
+
+// storageVersionMigratedAnnotation records the storage version and CRD generation the custom resources of a CRD were last rewritten
+// in. status.storedVersions keeps the other versions so it can't tell whether that happened.
+const storageVersionMigratedAnnotation = "kubernetes.azure.com/storage-version-migrated"
+
+// migrateStoredVersion rewrites the custom resources of a CRD so they're stored in its storage version. The other versions are kept in
+// status.storedVersions so the operator can still be rolled back, they're pruned in a later release. The custom resources are only
+// rewritten again once the CRD changes, which includes a rollback that moved the storage version back.
+func migrateStoredVersion(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition, log logr.Logger) error {
+	storageVersion := ""
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			storageVersion = version.Name
+		}
+	}
+	if storageVersion == "" {
+		return fmt.Errorf("crd %s has no storage version", crd.Name)
+	}
+
+	live := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(crd), live); err != nil {
+		return fmt.Errorf("getting crd %s: %w", crd.Name, err)
+	}
+	migrated := fmt.Sprintf("%s/%d", storageVersion, live.Generation)
+	if slices.Equal(live.Status.StoredVersions, []string{storageVersion}) || live.Annotations[storageVersionMigratedAnnotation] == migrated {
+		return nil
+	}
+
+	log = log.WithValues("name", crd.Name, "storedVersions", live.Status.StoredVersions, "storageVersion", storageVersion)
+	log.Info("migrating stored versions")
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion, Kind: crd.Spec.Names.ListKind})
+	if err := c.List(ctx, list); err != nil {
+		return fmt.Errorf("listing %s: %w", crd.Spec.Names.Plural, err)
+	}
+
+	// an update without changes is enough, the API server writes the object in the storage version
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if err := c.Update(ctx, obj); err != nil {
+			// a concurrent write or delete also leaves nothing stored in the old version
+			if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("rewriting %s %s: %w", crd.Spec.Names.Singular, client.ObjectKeyFromObject(obj), err)
+		}
+	}
+
+	patch := client.MergeFrom(live.DeepCopy())
+	live.SetAnnotations(util.MergeMaps(live.GetAnnotations(), map[string]string{storageVersionMigratedAnnotation: migrated}))
+	if err := c.Patch(ctx, live, patch); err != nil {
+		return fmt.Errorf("recording migration of crd %s: %w", crd.Name, err)
+	}
+
+	log.Info("migrated stored versions", "count", len(list.Items))
+	return nil
+}
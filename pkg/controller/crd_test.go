@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
+	approutingv1 "github.com/Azure/aks-app-routing-operator/api/v1"
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -22,6 +25,7 @@ const (
 	externalDnsCrdName        = "externaldnses.approuting.kubernetes.azure.com"
 	managedCertificateCrdName = "managedcertificates.approuting.kubernetes.azure.com"
 	defaultDomainCertCrdName  = "defaultdomaincertificates.approuting.kubernetes.azure.com"
+	appRoutingConfigCrdName   = "approutingconfigurations.approuting.kubernetes.azure.com"
 
 	validCrdPath        = "../../config/crd/bases/"
 	validCrdName        = nginxCrdName
@@ -36,6 +40,7 @@ var (
 	externalDnsCrds           = []string{externalDnsCrdName, clusterExternalDnsCrdName}
 	managedCertificateCrds    = []string{managedCertificateCrdName}
 	defaultDomainCertificates = []string{defaultDomainCertCrdName}
+	appRoutingConfigCrds      = []string{appRoutingConfigCrdName}
 )
 
 var (
@@ -61,6 +66,7 @@ func TestReadAllCRDs(t *testing.T) {
 			clusterExternalDnsCrdFilename,
 			nginxIngresscontrollerCrdFilename,
 			defaultDomainCertificateCrdFilename,
+			appRoutingConfigurationCrdFilename,
 		}
 		require.Len(t, crds, len(expectedFiles))
 		for _, f := range expectedFiles {
@@ -139,16 +145,16 @@ func TestLoadCRDs(t *testing.T) {
 		cfg              *config.Config
 		expectedCRDNames []string
 	}{
-		{name: "workload identity enabled", cfg: workloadIdentityEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName})},
-		{name: "workload identity and gateway enabled", cfg: workloadIdentityAndGatewayEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName})},
-		{name: "workload identity disabled", cfg: workloadIdentityDisabled, expectedCRDNames: nginxCrds},
-		{name: "workload identity disabled with gateway enabled", cfg: workloadIdentityDisabledGatewayEnabled, expectedCRDNames: nginxCrds},
-		{name: "default domain enabled", cfg: defaultDomainEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName}, defaultDomainCertificates)},
-		{name: "default domain disabled", cfg: defaultDomainDisabled, expectedCRDNames: nginxCrds},
-		{name: "all features enabled", cfg: allFeaturesEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName}, defaultDomainCertificates)},
-		{name: "all features disabled", cfg: allFeaturesDisabled, expectedCRDNames: nginxCrds},
-		{name: "ingress nginx disabled", cfg: ingressNginxDisabled, expectedCRDNames: nil},
-		{name: "ingress nginx disabled with workload identity", cfg: ingressNginxDisabledWorkloadIdentity, expectedCRDNames: []string{clusterExternalDnsCrdName, externalDnsCrdName}},
+		{name: "workload identity enabled", cfg: workloadIdentityEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName}, appRoutingConfigCrds)},
+		{name: "workload identity and gateway enabled", cfg: workloadIdentityAndGatewayEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName}, appRoutingConfigCrds)},
+		{name: "workload identity disabled", cfg: workloadIdentityDisabled, expectedCRDNames: slices.Concat(nginxCrds, appRoutingConfigCrds)},
+		{name: "workload identity disabled with gateway enabled", cfg: workloadIdentityDisabledGatewayEnabled, expectedCRDNames: slices.Concat(nginxCrds, appRoutingConfigCrds)},
+		{name: "default domain enabled", cfg: defaultDomainEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName}, defaultDomainCertificates, appRoutingConfigCrds)},
+		{name: "default domain disabled", cfg: defaultDomainDisabled, expectedCRDNames: slices.Concat(nginxCrds, appRoutingConfigCrds)},
+		{name: "all features enabled", cfg: allFeaturesEnabled, expectedCRDNames: slices.Concat(nginxCrds, []string{clusterExternalDnsCrdName, externalDnsCrdName}, defaultDomainCertificates, appRoutingConfigCrds)},
+		{name: "all features disabled", cfg: allFeaturesDisabled, expectedCRDNames: slices.Concat(nginxCrds, appRoutingConfigCrds)},
+		{name: "ingress nginx disabled", cfg: ingressNginxDisabled, expectedCRDNames: appRoutingConfigCrds},
+		{name: "ingress nginx disabled with workload identity", cfg: ingressNginxDisabledWorkloadIdentity, expectedCRDNames: slices.Concat([]string{clusterExternalDnsCrdName, externalDnsCrdName}, appRoutingConfigCrds)},
 	}
 
 	for _, tc := range cases {
@@ -181,6 +187,7 @@ func TestShouldLoadCRD(t *testing.T) {
 		externalDnsCrdFilename:              false,
 		clusterExternalDnsCrdFilename:       false,
 		defaultDomainCertificateCrdFilename: false,
+		appRoutingConfigurationCrdFilename:  false,
 	}
 	for _, file := range crdFiles {
 		seen[file.Name()] = true
@@ -210,6 +217,8 @@ func TestShouldLoadCRD(t *testing.T) {
 		{name: "default domain certificate crd with default domain disabled", cfg: defaultDomainDisabled, filename: defaultDomainCertificateCrdFilename, expected: false},
 		{name: "default domain certificate crd with all features enabled", cfg: allFeaturesEnabled, filename: defaultDomainCertificateCrdFilename, expected: true},
 		{name: "default domain certificate crd with all features disabled", cfg: allFeaturesDisabled, filename: defaultDomainCertificateCrdFilename, expected: false},
+		{name: "app routing configuration crd with all features enabled", cfg: allFeaturesEnabled, filename: appRoutingConfigurationCrdFilename, expected: true},
+		{name: "app routing configuration crd with ingress nginx disabled", cfg: ingressNginxDisabled, filename: appRoutingConfigurationCrdFilename, expected: true},
 		{name: "other crd with workload identity enabled", cfg: workloadIdentityEnabled, filename: "other.crd.yaml", expected: false},
 		{name: "other crd with workload identity disabled", cfg: workloadIdentityDisabled, filename: "other.crd.yaml", expected: false},
 		{name: "other crd with default domain enabled", cfg: defaultDomainEnabled, filename: "other.crd.yaml", expected: false},
@@ -373,3 +382,61 @@ func TestLoadCRDsAndRemoveDisabledCRDs(t *testing.T) {
 		require.True(t, hasClusterExtDns, "expected cluster external dns crd to be installed")
 	})
 }
+
+func TestMigrateStoredVersions(t *testing.T) {
+	ctx := context.Background()
+	cfg := workloadIdentityDisabled
+	crds, err := readAllCRDs(cfg, logr.Discard())
+	require.NoError(t, err)
+
+	nicCrd := crds[nginxIngresscontrollerCrdFilename]
+	nicCrd.Status.StoredVersions = []string{"v1alpha1", "v1"}
+	arcCrd := crds[appRoutingConfigurationCrdFilename]
+	arcCrd.Status.StoredVersions = []string{"v1alpha1"}
+	// the fake client doesn't convert between versions so the custom resource is served in the storage version like the API server would
+	nic := &approutingv1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "nic", ResourceVersion: "1"},
+		Spec:       approutingv1.NginxIngressControllerSpec{IngressClassName: "nic.example.com", ControllerNamePrefix: "nic"},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nicCrd, arcCrd, nic).WithStatusSubresource(nicCrd, arcCrd).Build()
+
+	require.NoError(t, migrateStoredVersions(ctx, cl, cfg, logr.Discard()))
+
+	got := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nicCrd), got))
+	// the old version is only pruned in a later release
+	require.Equal(t, []string{"v1alpha1", "v1"}, got.Status.StoredVersions)
+
+	// the custom resource was rewritten
+	gotNic := &approutingv1.NginxIngressController{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nic), gotNic))
+	require.NotEqual(t, "1", gotNic.ResourceVersion)
+	require.Equal(t, nic.Spec, gotNic.Spec)
+
+	require.Equal(t, "v1/"+strconv.FormatInt(got.Generation, 10), got.Annotations[storageVersionMigratedAnnotation])
+
+	// migrating again doesn't rewrite the custom resources
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nic), gotNic))
+	migratedVersion := gotNic.ResourceVersion
+	require.NoError(t, migrateStoredVersions(ctx, cl, cfg, logr.Discard()))
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nic), gotNic))
+	require.Equal(t, migratedVersion, gotNic.ResourceVersion)
+
+	// a changed crd, like after a rollback and upgrade, rewrites them again
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nicCrd), got))
+	got.Generation++
+	require.NoError(t, cl.Update(ctx, got))
+	require.NoError(t, migrateStoredVersions(ctx, cl, cfg, logr.Discard()))
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nic), gotNic))
+	require.NotEqual(t, migratedVersion, gotNic.ResourceVersion)
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nicCrd), got))
+	require.Equal(t, "v1/"+strconv.FormatInt(got.Generation, 10), got.Annotations[storageVersionMigratedAnnotation])
+
+	t.Run("crd without storage version", func(t *testing.T) {
+		crd := nicCrd.DeepCopy()
+		for i := range crd.Spec.Versions {
+			crd.Spec.Versions[i].Storage = false
+		}
+		require.Error(t, migrateStoredVersion(ctx, cl, crd, logr.Discard()))
+	})
+}
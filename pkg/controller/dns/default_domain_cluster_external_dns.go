@@ -64,10 +64,11 @@ func NewDefaultDomainDNSReconciler(
 	manager ctrl.Manager,
 	conf *config.Config,
 ) error {
-	return common.NewResourceReconciler(
+	return common.NewDynamicResourceReconciler(
 		manager,
 		name,
-		defaultDomainObjects(conf),
+		func() ([]client.Object, error) { return defaultDomainObjects(conf.Current()), nil },
+		conf.Subscribe(),
 		reconcileInterval,
 	)
 }
@@ -10,7 +10,9 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,9 +22,41 @@ const (
 	reconcileInterval = time.Minute * 3
 )
 
-// addExternalDnsReconciler creates a reconciler that manages external dns resources
-func addExternalDnsReconciler(manager ctrl.Manager, resources []client.Object) error {
-	return common.NewResourceReconciler(manager, controllername.New("external", "dns", "reconciler"), resources, reconcileInterval)
+// addExternalDnsReconciler creates a reconciler that manages external dns resources for the config in effect
+func addExternalDnsReconciler(manager ctrl.Manager, conf *config.Config) error {
+	return common.NewDynamicResourceReconciler(manager, controllername.New("external", "dns", "reconciler"), externalDnsResources(conf), conf.Subscribe(), reconcileInterval)
+}
+
+// externalDnsResources returns the resources of the external dns instances to deploy for the config in effect. The Deployments of
+// instances whose zones were all removed at runtime are returned for deletion so they stop managing records in those zones.
+func externalDnsResources(conf *config.Config) func() ([]client.Object, error) {
+	deployed := map[string]bool{}
+	return func() ([]client.Object, error) {
+		instances, err := instances(conf.Current())
+		if err != nil {
+			return nil, fmt.Errorf("creating instances: %w", err)
+		}
+
+		var deleted []client.Object
+		for _, i := range instances {
+			for _, res := range i.resources {
+				deployment, ok := res.(*appsv1.Deployment)
+				if !ok {
+					continue
+				}
+
+				switch {
+				case i.action == deploy:
+					deployed[deployment.Name] = true
+				case deployed[deployment.Name]:
+					deployment.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+					deleted = append(deleted, deployment)
+				}
+			}
+		}
+
+		return append(deleted, getResources(filterAction(instances, deploy))...), nil
+	}
 }
 
 func addExternalDnsCleaner(manager ctrl.Manager, instances []instance) error {
@@ -58,9 +92,7 @@ func NewExternalDns(manager ctrl.Manager, conf *config.Config) error {
 		return fmt.Errorf("failed to create instances: %w", err)
 	}
 
-	deployInstances := filterAction(instances, deploy)
-	deployRes := getResources(deployInstances)
-	if err := addExternalDnsReconciler(manager, deployRes); err != nil {
+	if err := addExternalDnsReconciler(manager, conf); err != nil {
 		return err
 	}
 
@@ -133,6 +165,8 @@ func publicConfigForIngress(conf *config.Config) (*manifests.ExternalDnsConfig,
 			ResourceTypes:      map[manifests.ResourceType]struct{}{manifests.ResourceTypeIngress: {}},
 			DnsZoneresourceIDs: util.Keys(conf.PublicZoneConfig.ZoneIds),
 			Provider:           to.Ptr(manifests.PublicProvider),
+
+			LoadBalancerServices: conf.EnableNginxSecondaryLoadBalancerDNS,
 		})
 	if err != nil {
 		return nil, err
@@ -151,6 +185,8 @@ func privateConfigForIngress(conf *config.Config) (*manifests.ExternalDnsConfig,
 			ResourceTypes:      map[manifests.ResourceType]struct{}{manifests.ResourceTypeIngress: {}},
 			DnsZoneresourceIDs: util.Keys(conf.PrivateZoneConfig.ZoneIds),
 			Provider:           to.Ptr(manifests.PrivateProvider),
+
+			LoadBalancerServices: conf.EnableNginxSecondaryLoadBalancerDNS,
 		},
 	)
 	if err != nil {
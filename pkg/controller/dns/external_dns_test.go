@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
@@ -497,10 +498,42 @@ func TestActionFromConfig(t *testing.T) {
 func TestAddExternalDnsReconciler(t *testing.T) {
 	m, err := manager.New(restConfig, manager.Options{Metrics: metricsserver.Options{BindAddress: ":0"}})
 	require.NoError(t, err)
-	err = addExternalDnsReconciler(m, []client.Object{obj(gvk1, nil)})
+	err = addExternalDnsReconciler(m, &noZones)
 	require.NoError(t, err)
 }
 
+func TestExternalDnsResources(t *testing.T) {
+	deployments := func(objs []client.Object) map[string]bool {
+		ret := map[string]bool{} // name to whether it's being deleted
+		for _, obj := range objs {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				ret[deployment.Name] = deployment.DeletionTimestamp != nil
+			}
+		}
+		return ret
+	}
+
+	conf := noZones
+	conf.Current()
+	resources := externalDnsResources(&conf)
+
+	// prove nothing is deleted that wasn't deployed
+	objs, err := resources()
+	require.NoError(t, err)
+	require.Empty(t, objs)
+
+	conf.SetCurrent(&allZones)
+	objs, err = resources()
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"external-dns": false, "external-dns-private": false}, deployments(objs))
+
+	// prove the deployment of an instance whose zones were removed is deleted
+	conf.SetCurrent(&onlyPrivZones)
+	objs, err = resources()
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"external-dns": true, "external-dns-private": false}, deployments(objs))
+}
+
 func TestAddExternalDnsCleaner(t *testing.T) {
 	m, err := manager.New(restConfig, manager.Options{Metrics: metricsserver.Options{BindAddress: ":0"}})
 	require.NoError(t, err)
@@ -120,3 +120,17 @@ func verifyIdentity(ctx context.Context, k8sclient client.Client, obj ExternalDN
 
 	return nil
 }
+
+// ValidateExternalDNSConfiguration returns an error if an ExternalDNS or ClusterExternalDNS can't be deployed. Problems the user has to
+// fix are returned as a util.UserError. These are the same checks the reconcilers make.
+func ValidateExternalDNSConfiguration(ctx context.Context, k8sclient client.Client, config *config.Config, obj ExternalDNSCRDConfiguration) error {
+	if err := verifyIdentity(ctx, k8sclient, obj); err != nil {
+		return err
+	}
+
+	if _, err := generateManifestsConf(config, obj); err != nil {
+		return err
+	}
+
+	return nil
+}
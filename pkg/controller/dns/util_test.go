@@ -348,3 +348,34 @@ func Test_verifyIdentity(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateExternalDNSConfiguration(t *testing.T) {
+	validSa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mockConfigWithTenantId.identity.ServiceAccount,
+			Namespace: mockConfigWithTenantId.resourceNamespace,
+			Annotations: map[string]string{
+				util.WiSaClientIdAnnotation: "test-client-id",
+			},
+		},
+	}
+
+	// valid
+	k8sClient := generateDefaultClientBuilder(t, []client.Object{validSa}).Build()
+	require.NoError(t, ValidateExternalDNSConfiguration(context.Background(), k8sClient, conf, mockConfigWithTenantId))
+
+	// missing service account
+	k8sClient = generateDefaultClientBuilder(t, nil).Build()
+	err := ValidateExternalDNSConfiguration(context.Background(), k8sClient, conf, mockConfigWithTenantId)
+	var userErr util.UserError
+	require.ErrorAs(t, err, &userErr)
+	require.Contains(t, userErr.UserError(), "does not exist")
+
+	// invalid zones
+	invalidZones := mockConfigWithTenantId
+	invalidZones.dnsZoneresourceIDs = []string{"/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/test-rg/providers/Microsoft.Network/dnsZones/test.com", "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/test-rg/providers/Microsoft.Network/privateDnsZones/test2.com"}
+	k8sClient = generateDefaultClientBuilder(t, []client.Object{validSa}).Build()
+	err = ValidateExternalDNSConfiguration(context.Background(), k8sClient, conf, invalidZones)
+	require.ErrorAs(t, err, &userErr)
+	require.Contains(t, userErr.UserError(), "failed to generate ExternalDNS resources")
+}
@@ -4,12 +4,11 @@
 package ingress
 
 import (
-	"bytes"
 	"container/ring"
 	"context"
-	"errors"
 	"fmt"
-	"io"
+	"net/http"
+	"sync"
 	"time"
 
 	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
@@ -17,79 +16,129 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/hashicorp/go-multierror"
 	prommodel "github.com/prometheus/client_model/go"
-	"github.com/prometheus/common/expfmt"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/podmetrics"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 )
 
 var concurrencyWatchdogControllerName = controllername.New("concurrency", "watchdog")
 
+// voteRingSize is how many of the latest votes are kept for each target, twice the most votes a NginxIngressController can require.
+// Targets whose policy requires more votes keep as many votes as they require.
+const voteRingSize = 20
+
 // ScrapeFn returns the connection count for the given pod
 type ScrapeFn func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (float64, error)
 
-// NginxScrapeFn is the scrape function for Nginx
+// NginxScrapeFn is the scrape function for Nginx, it returns the active connections of the pod
 func NginxScrapeFn(ctx context.Context, client rest.Interface, pod *corev1.Pod) (float64, error) {
-	lgr := logr.FromContextOrDiscard(ctx)
-
-	lgr.Info("scraping pod", "pod", pod.Name)
-	resp, err := client.Get().
-		AbsPath("/api/v1/namespaces", pod.Namespace, "pods", pod.Name+":10254", "proxy/metrics").
-		Timeout(time.Second * 30).
-		MaxRetries(4).
-		DoRaw(ctx)
+	families, err := podmetrics.Nginx(ctx, client, pod)
 	if err != nil {
 		return 0, err
 	}
 
-	family := &prommodel.MetricFamily{}
-	format, err := expfmt.NewOpenMetricsFormat(expfmt.OpenMetricsVersion_0_0_1)
-	if err != nil {
-		return 0, fmt.Errorf("creating open metrics format: %w", err)
-	}
-
-	dec := expfmt.NewDecoder(bytes.NewReader(resp), format)
-	for {
-		err = dec.Decode(family)
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return 0, err
-		}
-		if family.GetName() != "nginx_ingress_controller_nginx_process_connections" {
-			continue
-		}
-		for _, metric := range family.Metric {
-			if metric.Gauge == nil || !metricHasLabel(metric, "state", "active") {
-				continue
-			}
-			return metric.Gauge.GetValue(), nil
-		}
-	}
-
-	return 0, fmt.Errorf("active connections metric not found")
+	return activeConnections(families)
 }
 
 // WatchdogTarget refers to a target the concurrency watchdog should track
 type WatchdogTarget struct {
 	ScrapeFn  ScrapeFn
 	PodLabels map[string]string
+
+	// Signals replace ScrapeFn with a weighted combination of load signals when set. Each signal is compared to its average across
+	// the ready pods before it's weighted.
+	Signals []WeightedScrapeFn
+
+	// Name identifies the target in metrics and eviction limits, the pod labels are used when it's empty
+	Name string
+	// Namespace is the namespace of the target's pods, the namespace for managed resources is used when it's empty
+	Namespace string
+	// Object is the resource that owns the target's pods, eviction events are recorded on it when it's set
+	Object client.Object
+	// Policy overrides how the watchdog votes on and evicts the target's pods, nil uses the watchdog's defaults
+	Policy *WatchdogPolicy
+}
+
+// WatchdogPolicy overrides the watchdog's defaults for a target. Zero values keep the defaults.
+type WatchdogPolicy struct {
+	// DryRun records the evictions the watchdog would perform through events and metrics without evicting pods
+	DryRun bool
+	// Threshold is the percent of the average load a pod needs to get a vote
+	Threshold float64
+	// Votes is how many votes a pod needs before it's evicted
+	Votes int
+	// MinPodAge is how old a pod must be before it gets votes
+	MinPodAge *time.Duration
+	// MaxEvictionsPerHour limits how many pods are evicted, including dry run evictions, within an hour
+	MaxEvictionsPerHour int
+}
+
+// votingPolicy is the thresholds votes are processed with
+type votingPolicy struct {
+	threshold float64
+	votes     int
+	minPodAge time.Duration
+}
+
+// key returns the name of the target
+func (w WatchdogTarget) key() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return labels.Set(w.PodLabels).String()
+}
+
+// namespace returns the namespace of the target's pods
+func (w WatchdogTarget) namespace(conf *config.Config) string {
+	if w.Namespace != "" {
+		return w.Namespace
+	}
+	return conf.NS
+}
+
+// signals returns the target's load signals
+func (w WatchdogTarget) signals() []WeightedScrapeFn {
+	if len(w.Signals) > 0 {
+		return w.Signals
+	}
+	return []WeightedScrapeFn{{ScrapeFn: w.ScrapeFn, Weight: 1}}
 }
 
 type ListWatchdogTargets func() ([]WatchdogTarget, error)
 
+// CombineListWatchdogTargets returns the targets of every source
+func CombineListWatchdogTargets(sources ...ListWatchdogTargets) ListWatchdogTargets {
+	return func() ([]WatchdogTarget, error) {
+		var targets []WatchdogTarget
+		for _, source := range sources {
+			cur, err := source()
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, cur...)
+		}
+
+		return targets, nil
+	}
+}
+
 func GetListNginxWatchdogTargets(cl client.Client, defaultNicControllerClass string) ListWatchdogTargets {
+	// the scraper outlives each listing so rates can be computed across ticks
+	scraper := newNginxScraper()
+
 	return func() ([]WatchdogTarget, error) {
 		nics := &approutingv1alpha1.NginxIngressControllerList{}
 		if err := cl.List(nil, nics); err != nil {
@@ -103,23 +152,63 @@ func GetListNginxWatchdogTargets(cl client.Client, defaultNicControllerClass str
 				continue
 			}
 
-			targets = append(targets, WatchdogTarget{
+			shedding := nic.Spec.LoadShedding
+			if shedding != nil && shedding.Mode != nil && *shedding.Mode == approutingv1alpha1.OffLoadSheddingMode {
+				continue
+			}
+
+			target := WatchdogTarget{
 				ScrapeFn:  NginxScrapeFn,
 				PodLabels: ingCfg.PodLabels(),
-			})
+				Name:      nic.Name,
+				Object:    &nic,
+			}
+			if shedding != nil {
+				target.Policy = nginxWatchdogPolicy(shedding)
+				if shedding.Signal != nil && shedding.Signal.Type != approutingv1alpha1.ConnectionsLoadSignalType {
+					target.ScrapeFn = nil
+					target.Signals = scraper.Signals(shedding.Signal)
+				}
+			}
+
+			targets = append(targets, target)
 		}
 
 		return targets, nil
 	}
 }
 
+// nginxWatchdogPolicy returns the watchdog policy of a NginxIngressController's load shedding
+func nginxWatchdogPolicy(shedding *approutingv1alpha1.LoadShedding) *WatchdogPolicy {
+	policy := &WatchdogPolicy{
+		DryRun: shedding.Mode != nil && *shedding.Mode == approutingv1alpha1.DryRunLoadSheddingMode,
+	}
+	if shedding.Threshold != nil {
+		policy.Threshold = float64(*shedding.Threshold)
+	}
+	if shedding.Votes != nil {
+		policy.Votes = int(*shedding.Votes)
+	}
+	if shedding.MinPodAgeSeconds != nil {
+		minPodAge := time.Duration(*shedding.MinPodAgeSeconds) * time.Second
+		policy.MinPodAge = &minPodAge
+	}
+	if shedding.MaxEvictionsPerHour != nil {
+		policy.MaxEvictionsPerHour = int(*shedding.MaxEvictionsPerHour)
+	}
+
+	return policy
+}
+
 // ConcurrencyWatchdog evicts ingress controller pods that have too many active connections relative to others.
 // This helps redistribute long-running connections when the ingress controller scales up.
 type ConcurrencyWatchdog struct {
 	client              client.Client
+	apiReader           client.Reader
 	clientset           kubernetes.Interface
 	restClient          rest.Interface
 	logger              logr.Logger
+	events              record.EventRecorder
 	config              *config.Config
 	listWatchdogTargets ListWatchdogTargets
 
@@ -127,7 +216,17 @@ type ConcurrencyWatchdog struct {
 	minVotesBeforeEviction       int
 	minPercentOverAvgBeforeVote  float64
 
-	votes *ring.Ring
+	scrapeTimeout time.Duration
+	scrapeWorkers int
+	// directClient scrapes pods by their IP when the watchdog scrapes directly, nil scrapes through the API server
+	directClient *http.Client
+
+	// votes are the latest votes of each target's pods, each ring points at the newest vote
+	votes map[string]*ring.Ring
+	// evictions are the times of each target's evictions within the last hour
+	evictions map[string][]time.Time
+	// persisted is the state last persisted so unchanged state isn't written every tick
+	persisted []byte
 }
 
 func NewConcurrencyWatchdog(manager ctrl.Manager, conf *config.Config, target ListWatchdogTargets) error {
@@ -139,9 +238,11 @@ func NewConcurrencyWatchdog(manager ctrl.Manager, conf *config.Config, target Li
 
 	c := &ConcurrencyWatchdog{
 		client:              manager.GetClient(),
+		apiReader:           manager.GetAPIReader(),
 		clientset:           clientset,
 		restClient:          clientset.CoreV1().RESTClient(),
 		logger:              concurrencyWatchdogControllerName.AddToLogger(manager.GetLogger()),
+		events:              manager.GetEventRecorderFor("aks-app-routing-operator"),
 		config:              conf,
 		listWatchdogTargets: target,
 
@@ -150,18 +251,32 @@ func NewConcurrencyWatchdog(manager ctrl.Manager, conf *config.Config, target Li
 		minVotesBeforeEviction:      conf.ConcurrencyWatchdogVotes,
 		minPercentOverAvgBeforeVote: conf.ConcurrencyWatchdogThres,
 		voteTTL:                     time.Minute * 10,
+		scrapeTimeout:               conf.ConcurrencyWatchdogScrapeTimeout,
+		scrapeWorkers:               conf.ConcurrencyWatchdogScrapeWorkers,
 
-		votes: ring.New(20),
+		votes:     map[string]*ring.Ring{},
+		evictions: map[string][]time.Time{},
+	}
+	if conf.ConcurrencyWatchdogScrapeMode == config.ConcurrencyWatchdogScrapeModeDirect {
+		c.directClient = &http.Client{Timeout: podmetrics.DirectTimeout}
 	}
 
 	return manager.Add(c)
 }
 
 func (c *ConcurrencyWatchdog) Start(ctx context.Context) error {
+	updates := c.config.Subscribe()
+	c.applyConfig(c.config.Current())
+	if err := c.loadState(ctx); err != nil {
+		c.logger.Error(err, "loading persisted watchdog state, starting without votes")
+	}
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-updates:
+			c.applyConfig(c.config.Current())
+			continue
 		case <-time.After(util.Jitter(c.interval, 0.3)):
 		}
 		if err := c.tick(ctx); err != nil {
@@ -171,6 +286,16 @@ func (c *ConcurrencyWatchdog) Start(ctx context.Context) error {
 	}
 }
 
+// applyConfig updates the eviction thresholds to those of the config in effect. It's only called from Start so ticks never see a partial update.
+func (c *ConcurrencyWatchdog) applyConfig(conf *config.Config) {
+	if c.minVotesBeforeEviction != conf.ConcurrencyWatchdogVotes || c.minPercentOverAvgBeforeVote != conf.ConcurrencyWatchdogThres {
+		c.logger.Info("updating eviction thresholds", "votes", conf.ConcurrencyWatchdogVotes, "threshold", conf.ConcurrencyWatchdogThres)
+	}
+
+	c.minVotesBeforeEviction = conf.ConcurrencyWatchdogVotes
+	c.minPercentOverAvgBeforeVote = conf.ConcurrencyWatchdogThres
+}
+
 func (c *ConcurrencyWatchdog) tick(ctx context.Context) error {
 	lgr := c.logger
 	start := time.Now()
@@ -193,13 +318,32 @@ func (c *ConcurrencyWatchdog) tick(ctx context.Context) error {
 		return retErr.ErrorOrNil()
 	}
 
+	// targets that are gone shouldn't keep their votes
+	listed := map[string]struct{}{}
+	for _, target := range targets {
+		listed[target.key()] = struct{}{}
+	}
+	for key := range c.votes {
+		if _, ok := listed[key]; !ok {
+			delete(c.votes, key)
+		}
+	}
+
+	// pods and targets that are gone shouldn't keep their series
+	metrics.ConcurrencyWatchdogPodLoad.Reset()
+	metrics.ConcurrencyWatchdogPodVotes.Reset()
+
+	// the scrape deadline covers every target so a tick can't take longer than it however many targets there are
+	scrapeCtx, cancel := context.WithTimeout(ctx, c.scrapeTimeout)
+	defer cancel()
+
 	for _, target := range targets {
 		lgr := c.logger.WithValues("target", target.PodLabels)
 		lgr.Info("starting checking on ingress controller pods")
 
 		lgr.Info("listing pods")
 		list := &corev1.PodList{}
-		err := c.client.List(ctx, list, client.InNamespace(c.config.NS), client.MatchingLabels(target.PodLabels))
+		err := c.client.List(ctx, list, client.InNamespace(target.namespace(c.config)), client.MatchingLabels(target.PodLabels))
 		if err != nil {
 			lgr.Error(err, "listing pods")
 			retErr = multierror.Append(retErr, fmt.Errorf("listing pods: %w", err))
@@ -207,36 +351,32 @@ func (c *ConcurrencyWatchdog) tick(ctx context.Context) error {
 		}
 
 		lgr.Info("gathering metrics for each pod")
-		connectionCountByPod := make([]float64, len(list.Items))
+		signals := target.signals()
+		valuesBySignal := make([][]float64, len(signals))
+		for s := range signals {
+			valuesBySignal[s] = make([]float64, len(list.Items))
+		}
+		ready := make([]bool, len(list.Items))
 		nReadyPods := 0
-		var totalConnectionCount float64
-		for i, pod := range list.Items {
-			lgr := lgr.WithValues("pod", pod.Name, "namespace", pod.Namespace)
-			if !podIsReady(&pod) {
-				lgr.Info("pod is not ready", "name", pod.Name)
+		for i, scrape := range c.scrapePods(scrapeCtx, lgr, list, signals) {
+			if !scrape.ready {
+				continue
+			}
+			// pods without a load aren't counted as ready, their load of zero would lower the average and add votes
+			if scrape.err != nil {
+				retErr = multierror.Append(retErr, fmt.Errorf("scraping pod %q: %w", list.Items[i].Name, scrape.err))
+				continue
+			}
+			if scrape.values == nil {
 				continue
 			}
 			nReadyPods++
-			ctx := logr.NewContext(ctx, lgr)
-			count, err := target.ScrapeFn(ctx, c.restClient, &pod)
-			if err != nil {
+			ready[i] = true
 
-				// check if pod is still ready. Pod might have become unready after checking it (this solves a race condition).
-				// we ignore an error on the podIsActive call, we want the retErr to be the error from scrapping not from checking if
-				// the pod is active.
-				if active, err := podIsActive(ctx, lgr, c.client, client.ObjectKeyFromObject(&pod)); err == nil && !active {
-					lgr.Info("pod isn't active anymore")
-					continue
-				}
-
-				lgr.Error(err, "scraping pod")
-				retErr = multierror.Append(retErr, fmt.Errorf("scraping pod %q: %w", pod.Name, err))
-				continue
+			for s, value := range scrape.values {
+				valuesBySignal[s][i] = value
 			}
-			connectionCountByPod[i] = count
-			totalConnectionCount += count
 		}
-		avgConnectionCount := totalConnectionCount / float64(nReadyPods)
 
 		// Only rebalance connections when three or more replicas are ready.
 		// Otherwise we will just push the connections to the other replica.
@@ -246,24 +386,27 @@ func (c *ConcurrencyWatchdog) tick(ctx context.Context) error {
 		}
 
 		lgr.Info("processing votes")
-		pod := c.processVotes(list, connectionCountByPod, avgConnectionCount)
+		loadByPod, avgLoad := combineSignals(signals, valuesBySignal, ready, nReadyPods)
+		pod := c.processVotes(target.key(), list, loadByPod, avgLoad, c.votingPolicy(target.Policy))
+		for i := range list.Items {
+			if ready[i] {
+				metrics.ConcurrencyWatchdogPodLoad.WithLabelValues(target.key(), list.Items[i].Name).Set(loadByPod[i])
+			}
+		}
+		for name, votes := range c.votesPerPod(target.key(), list) {
+			metrics.ConcurrencyWatchdogPodVotes.WithLabelValues(target.key(), name).Set(float64(votes))
+		}
 		if pod == "" {
 			lgr.Info("no pod to evict")
 			continue
 		}
 
-		lgr.Info("evicting pod due to high relative connection concurrency", "name", pod)
-		eviction := &policyv1beta1.Eviction{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pod,
-				Namespace: c.config.NS,
-			},
-		}
+		c.evict(ctx, lgr, target, pod, percentOfAvg(list, pod, loadByPod, avgLoad))
+	}
 
-		if err := c.clientset.CoreV1().Pods(eviction.Namespace).EvictV1beta1(ctx, eviction); err != nil {
-			lgr.Error(err, "unable to evict pod", "name", pod)
-			// don't add the error to return since we shouldn't retry right away
-		}
+	if err := c.saveState(ctx); err != nil {
+		c.logger.Error(err, "persisting watchdog state")
+		retErr = multierror.Append(retErr, err)
 	}
 	if err := retErr.ErrorOrNil(); err != nil {
 		c.logger.Error(err, "reconciling ingress controller resources")
@@ -272,34 +415,245 @@ func (c *ConcurrencyWatchdog) tick(ctx context.Context) error {
 	return nil
 }
 
-func (c *ConcurrencyWatchdog) processVotes(list *corev1.PodList, connectionCountByPod []float64, avgConnectionCount float64) string {
+// podScrape is the result of scraping the load signals of a pod
+type podScrape struct {
+	ready  bool
+	values []float64
+	err    error
+}
+
+// scrapePods scrapes the load signals of the ready pods in list with a bounded number of workers. Scrapes still running when ctx
+// is done are cancelled and fail so a tick can't take longer than the scrape deadline however many pods there are.
+func (c *ConcurrencyWatchdog) scrapePods(ctx context.Context, lgr logr.Logger, list *corev1.PodList, signals []WeightedScrapeFn) []podScrape {
+	ctx = podmetrics.WithObserver(ctx, observeScrape)
+	if c.directClient != nil {
+		ctx = podmetrics.WithDirectClient(ctx, c.directClient)
+	}
+
+	scrapes := make([]podScrape, len(list.Items))
+	workers := make(chan struct{}, max(c.scrapeWorkers, 1))
+	var wg sync.WaitGroup
+	for i := range list.Items {
+		pod := &list.Items[i]
+		lgr := lgr.WithValues("pod", pod.Name, "namespace", pod.Namespace)
+		if !podIsReady(pod) {
+			lgr.Info("pod is not ready", "name", pod.Name)
+			continue
+		}
+		scrapes[i].ready = true
+
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			scrapes[i].values, scrapes[i].err = c.scrapePod(logr.NewContext(ctx, lgr), lgr, pod, signals)
+		}()
+	}
+	wg.Wait()
+
+	return scrapes
+}
+
+// scrapePod returns the value of each load signal of pod. Pods that stopped being active while they were scraped have no values.
+func (c *ConcurrencyWatchdog) scrapePod(ctx context.Context, lgr logr.Logger, pod *corev1.Pod, signals []WeightedScrapeFn) ([]float64, error) {
+	values := make([]float64, len(signals))
+	for s, signal := range signals {
+		value, err := signal.ScrapeFn(ctx, c.restClient, pod)
+		if err != nil {
+
+			// check if pod is still ready. Pod might have become unready after checking it (this solves a race condition).
+			// we ignore an error on the podIsActive call, we want the retErr to be the error from scrapping not from checking if
+			// the pod is active.
+			if active, err := podIsActive(ctx, lgr, c.client, client.ObjectKeyFromObject(pod)); err == nil && !active {
+				lgr.Info("pod isn't active anymore")
+				return nil, nil
+			}
+
+			lgr.Error(err, "scraping pod")
+			return nil, err
+		}
+		values[s] = value
+	}
+
+	return values, nil
+}
+
+// evict evicts the pod of target the votes chose, or only records the eviction when the target's policy is a dry run. Evictions past
+// the target's hourly limit are skipped and the pod keeps its votes so it's evicted once the limit allows it.
+func (c *ConcurrencyWatchdog) evict(ctx context.Context, lgr logr.Logger, target WatchdogTarget, pod string, percentOfAvg float64) {
+	lgr = lgr.WithValues("name", pod, "percentOfAvg", percentOfAvg)
+	key := target.key()
+	policy := target.Policy
+	if policy == nil {
+		policy = &WatchdogPolicy{}
+	}
+
+	recent := c.evictions[key][:0]
+	for _, evicted := range c.evictions[key] {
+		if time.Since(evicted) < time.Hour {
+			recent = append(recent, evicted)
+		}
+	}
+	c.evictions[key] = recent
+
+	if policy.MaxEvictionsPerHour > 0 && len(recent) >= policy.MaxEvictionsPerHour {
+		lgr.Info("not evicting pod, the target reached its eviction limit", "maxEvictionsPerHour", policy.MaxEvictionsPerHour)
+		metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues(key, metrics.LabelRateLimited).Inc()
+		c.event(target, corev1.EventTypeWarning, "LoadSheddingRateLimited", fmt.Sprintf(
+			"not evicting pod %s at %.0f%% of the average load, %d pods were evicted within the last hour", pod, percentOfAvg, len(recent)))
+		return
+	}
+
+	if policy.DryRun {
+		lgr.Info("dry run, not evicting pod due to high relative load")
+		c.evictions[key] = append(c.evictions[key], time.Now())
+		c.clearVotes(key, pod)
+		metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues(key, metrics.LabelDryRun).Inc()
+		c.event(target, corev1.EventTypeNormal, "LoadSheddingDryRun", fmt.Sprintf(
+			"would have evicted pod %s at %.0f%% of the average load", pod, percentOfAvg))
+		return
+	}
+
+	lgr.Info("evicting pod due to high relative load")
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod,
+			Namespace: target.namespace(c.config),
+		},
+	}
+
+	if err := c.clientset.CoreV1().Pods(eviction.Namespace).EvictV1beta1(ctx, eviction); err != nil {
+		lgr.Error(err, "unable to evict pod")
+		metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues(key, metrics.LabelError).Inc()
+		// don't return the error since we shouldn't retry right away
+		return
+	}
+
+	c.evictions[key] = append(c.evictions[key], time.Now())
+	metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues(key, metrics.LabelSuccess).Inc()
+	c.event(target, corev1.EventTypeNormal, "LoadSheddingEviction", fmt.Sprintf(
+		"evicted pod %s at %.0f%% of the average load", pod, percentOfAvg))
+}
+
+// event records an event on the target's object when it has one
+func (c *ConcurrencyWatchdog) event(target WatchdogTarget, eventType, reason, message string) {
+	if target.Object == nil || c.events == nil {
+		return
+	}
+	c.events.Event(target.Object, eventType, reason, message)
+}
+
+// clearVotes forgets the votes of target for pod so it has to be voted on again
+func (c *ConcurrencyWatchdog) clearVotes(key, pod string) {
+	votes, ok := c.votes[key]
+	if !ok {
+		return
+	}
+
+	for i := 0; i < votes.Len(); i++ {
+		if vote, ok := votes.Value.(*evictionVote); ok && vote.PodName == pod {
+			votes.Value = nil
+		}
+		votes = votes.Next()
+	}
+}
+
+// targetVotes returns the votes of target, with room for at least n votes
+func (c *ConcurrencyWatchdog) targetVotes(key string, n int) *ring.Ring {
+	votes, ok := c.votes[key]
+	if !ok {
+		votes = ring.New(voteRingSize)
+		c.votes[key] = votes
+	}
+
+	// the new slots are linked after the newest vote so they're filled before older votes are overwritten
+	if missing := n - votes.Len(); missing > 0 {
+		votes.Link(ring.New(missing))
+	}
+
+	return votes
+}
+
+// votingPolicy returns the thresholds of policy, falling back to the watchdog's for those it doesn't set
+func (c *ConcurrencyWatchdog) votingPolicy(policy *WatchdogPolicy) votingPolicy {
+	ret := votingPolicy{
+		threshold: c.minPercentOverAvgBeforeVote,
+		votes:     c.minVotesBeforeEviction,
+		minPodAge: c.minPodAge,
+	}
+	if policy == nil {
+		return ret
+	}
+
+	if policy.Threshold > 0 {
+		ret.threshold = policy.Threshold
+	}
+	if policy.Votes > 0 {
+		ret.votes = policy.Votes
+	}
+	if policy.MinPodAge != nil {
+		ret.minPodAge = *policy.MinPodAge
+	}
+	return ret
+}
+
+// percentOfAvg returns the load of pod as a percent of the average load
+func percentOfAvg(list *corev1.PodList, pod string, loadByPod []float64, avgLoad float64) float64 {
+	for i := range list.Items {
+		if list.Items[i].Name == pod {
+			return loadByPod[i] / avgLoad * 100
+		}
+	}
+	return 0
+}
+
+func (c *ConcurrencyWatchdog) processVotes(key string, list *corev1.PodList, connectionCountByPod []float64, avgConnectionCount float64, policy votingPolicy) string {
 	// Vote on outlier(s)
-	podsByName := map[string]struct{}{}
+	votes := c.targetVotes(key, policy.votes)
 	for i, pod := range list.Items {
-		podsByName[pod.Name] = struct{}{}
-
 		rank := (connectionCountByPod[i] / avgConnectionCount) * 100
-		if rank < c.minPercentOverAvgBeforeVote || time.Since(pod.CreationTimestamp.Time) < c.minPodAge {
+		if rank < policy.threshold || time.Since(pod.CreationTimestamp.Time) < policy.minPodAge {
 			continue
 		}
 		c.logger.Info("voting to evict pod due to high connection concurrency", "name", pod.Name, "percentOfAvg", rank)
 
-		c.votes = c.votes.Next()
+		votes = votes.Next()
 		var vote *evictionVote
-		if c.votes.Value == nil {
+		if votes.Value == nil {
 			vote = &evictionVote{}
-			c.votes.Value = vote
+			votes.Value = vote
 		} else {
-			vote = c.votes.Value.(*evictionVote)
+			vote = votes.Value.(*evictionVote)
 		}
 
 		vote.PodName = pod.Name
 		vote.Time = time.Now()
 	}
 
-	// Aggregate votes
+	c.votes[key] = votes
+
+	// Apply votes
+	for pod, votes := range c.votesPerPod(key, list) {
+		if votes < policy.votes {
+			continue
+		}
+		return pod
+	}
+	return ""
+}
+
+// votesPerPod returns the unexpired votes of target for each pod in list
+func (c *ConcurrencyWatchdog) votesPerPod(key string, list *corev1.PodList) map[string]int {
+	podsByName := map[string]struct{}{}
+	for _, pod := range list.Items {
+		podsByName[pod.Name] = struct{}{}
+	}
+
 	votesPerPod := map[string]int{}
-	c.votes.Do(func(cur interface{}) {
+	c.votes[key].Do(func(cur interface{}) {
 		vote, ok := cur.(*evictionVote)
 		if !ok {
 			return
@@ -310,14 +664,7 @@ func (c *ConcurrencyWatchdog) processVotes(list *corev1.PodList, connectionCount
 		votesPerPod[vote.PodName]++
 	})
 
-	// Apply votes
-	for pod, votes := range votesPerPod {
-		if votes < c.minVotesBeforeEviction {
-			continue
-		}
-		return pod
-	}
-	return ""
+	return votesPerPod
 }
 
 func (c *ConcurrencyWatchdog) NeedLeaderElection() bool {
@@ -325,8 +672,10 @@ func (c *ConcurrencyWatchdog) NeedLeaderElection() bool {
 }
 
 type evictionVote struct {
-	Time    time.Time
-	PodName string
+	Time    time.Time `json:"time"`
+	PodName string    `json:"pod"`
+	// Target is the key of the target the vote is for. It's only set in the persisted state.
+	Target string `json:"target,omitempty"`
 }
 
 func metricHasLabel(metric *prommodel.Metric, key, value string) bool {
@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+)
+
+const (
+	// watchdogStateName is the name of the ConfigMap the watchdog's votes and evictions are persisted in
+	watchdogStateName = "app-routing-concurrency-watchdog"
+	watchdogStateKey  = "state.json"
+)
+
+// watchdogState is the state of the watchdog that's persisted so a new leader picks up the votes and eviction limits where the
+// previous one left off
+type watchdogState struct {
+	// Votes are ordered by target, then from oldest to newest
+	Votes     []evictionVote         `json:"votes,omitempty"`
+	Evictions map[string][]time.Time `json:"evictions,omitempty"`
+}
+
+// state returns the watchdog's state
+func (c *ConcurrencyWatchdog) state() watchdogState {
+	state := watchdogState{Evictions: map[string][]time.Time{}}
+
+	// targets are sorted so unchanged state marshals the same
+	keys := util.Keys(c.votes)
+	sort.Strings(keys)
+	for _, key := range keys {
+		// the ring points at the newest vote so the oldest is next
+		c.votes[key].Next().Do(func(cur interface{}) {
+			if vote, ok := cur.(*evictionVote); ok {
+				state.Votes = append(state.Votes, evictionVote{PodName: vote.PodName, Time: vote.Time, Target: key})
+			}
+		})
+	}
+	for key, evictions := range c.evictions {
+		for _, evicted := range evictions {
+			if time.Since(evicted) < time.Hour {
+				state.Evictions[key] = append(state.Evictions[key], evicted)
+			}
+		}
+	}
+
+	return state
+}
+
+// loadState restores the state persisted by the previous leader. Votes past the size of their target's ring are dropped oldest first.
+func (c *ConcurrencyWatchdog) loadState(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	err := c.apiReader.Get(ctx, client.ObjectKey{Namespace: c.config.NS, Name: watchdogStateName}, cm)
+	if apierrors.IsNotFound(err) {
+		c.logger.Info("no persisted watchdog state")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting watchdog state: %w", err)
+	}
+
+	state := watchdogState{}
+	if err := json.Unmarshal([]byte(cm.Data[watchdogStateKey]), &state); err != nil {
+		return fmt.Errorf("parsing watchdog state: %w", err)
+	}
+
+	for _, vote := range state.Votes {
+		votes := c.targetVotes(vote.Target, 0).Next()
+		votes.Value = &evictionVote{PodName: vote.PodName, Time: vote.Time}
+		c.votes[vote.Target] = votes
+	}
+	for key, evictions := range state.Evictions {
+		c.evictions[key] = evictions
+	}
+
+	c.persisted = []byte(cm.Data[watchdogStateKey])
+	c.logger.Info("loaded persisted watchdog state", "votes", len(state.Votes))
+	return nil
+}
+
+// saveState persists the watchdog's state when it changed since it was last persisted
+func (c *ConcurrencyWatchdog) saveState(ctx context.Context) error {
+	data, err := json.Marshal(c.state())
+	if err != nil {
+		return fmt.Errorf("marshalling watchdog state: %w", err)
+	}
+	if bytes.Equal(data, c.persisted) {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      watchdogStateName,
+			Namespace: c.config.NS,
+			Labels:    manifests.GetTopLevelLabels(),
+		},
+		Data: map[string]string{watchdogStateKey: string(data)},
+	}
+
+	if err := util.Upsert(ctx, c.client, cm); err != nil {
+		return fmt.Errorf("persisting watchdog state: %w", err)
+	}
+
+	c.persisted = data
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	fakecgo "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+)
+
+func TestConcurrencyWatchdogStateSurvivesFailover(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+	targets := func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+				if pod.Name == "pod-1" {
+					return 2000, nil
+				}
+				return 1, nil
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+			Name:      "failover",
+		}}, nil
+	}
+
+	leader := newTestConcurrencyWatchdog()
+	leader.voteTTL = time.Minute
+	leader.clientset = fakecgo.NewSimpleClientset()
+	leader.client = cli
+	leader.apiReader = cli
+	leader.listWatchdogTargets = targets
+
+	require.NoError(t, leader.tick(ctx))
+	assert.Equal(t, 1, countVotes(leader, "pod-1"))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Name: watchdogStateName}, cm))
+	require.Equal(t, manifests.GetTopLevelLabels(), cm.Labels)
+	resourceVersion := cm.ResourceVersion
+
+	// an unchanged state isn't written again
+	require.NoError(t, leader.saveState(ctx))
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Name: watchdogStateName}, cm))
+	require.Equal(t, resourceVersion, cm.ResourceVersion)
+
+	// the new leader picks up the vote and evicts on its first tick
+	cs := fakecgo.NewSimpleClientset()
+	next := newTestConcurrencyWatchdog()
+	next.voteTTL = time.Minute
+	next.clientset = cs
+	next.client = cli
+	next.apiReader = cli
+	next.listWatchdogTargets = targets
+
+	require.NoError(t, next.loadState(ctx))
+	assert.Equal(t, 1, countVotes(next, "pod-1"))
+	require.NoError(t, next.tick(ctx))
+	assert.Len(t, cs.Fake.Actions(), 1)
+}
+
+func TestConcurrencyWatchdogStateOrder(t *testing.T) {
+	ctx := context.Background()
+	cli := fake.NewClientBuilder().Build()
+
+	c := newTestConcurrencyWatchdog()
+	c.client = cli
+	c.apiReader = cli
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 25; i++ {
+		c.votes["target"] = c.targetVotes("target", 0).Next()
+		c.votes["target"].Value = &evictionVote{PodName: "pod", Time: now.Add(time.Duration(i) * time.Second)}
+	}
+	c.evictions["target"] = []time.Time{now.Add(-2 * time.Hour), now}
+
+	state := c.state()
+	require.Len(t, state.Votes, 20)
+	assert.Equal(t, now.Add(5*time.Second), state.Votes[0].Time, "oldest votes were overwritten")
+	assert.Equal(t, now.Add(24*time.Second), state.Votes[19].Time)
+	assert.Equal(t, "target", state.Votes[0].Target)
+	assert.Equal(t, map[string][]time.Time{"target": {now}}, state.Evictions, "evictions older than an hour are dropped")
+
+	require.NoError(t, c.saveState(ctx))
+
+	loaded := newTestConcurrencyWatchdog()
+	loaded.apiReader = cli
+	require.NoError(t, loaded.loadState(ctx))
+	loadedState := loaded.state()
+	require.Len(t, loadedState.Votes, 20)
+	for i := range state.Votes {
+		assert.True(t, state.Votes[i].Time.Equal(loadedState.Votes[i].Time))
+	}
+	assert.True(t, loaded.votes["target"].Value.(*evictionVote).Time.Equal(now.Add(24*time.Second)), "the ring points at the newest vote")
+	require.Len(t, loaded.evictions["target"], 1)
+}
+
+func TestConcurrencyWatchdogLoadStateMissing(t *testing.T) {
+	c := newTestConcurrencyWatchdog()
+	c.apiReader = fake.NewClientBuilder().Build()
+	require.NoError(t, c.loadState(context.Background()))
+	assert.Empty(t, c.state().Votes)
+}
+
+func TestConcurrencyWatchdogPodMetrics(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+
+	c := newTestConcurrencyWatchdog()
+	c.clientset = fakecgo.NewSimpleClientset()
+	c.client = cli
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+				if pod.Name == "pod-1" {
+					return 2000, nil
+				}
+				return 1, nil
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+			Name:      "metrics",
+		}}, nil
+	}
+
+	require.NoError(t, c.tick(ctx))
+	assert.Equal(t, float64(2000), promtestutil.ToFloat64(metrics.ConcurrencyWatchdogPodLoad.WithLabelValues("metrics", "pod-1")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(metrics.ConcurrencyWatchdogPodLoad.WithLabelValues("metrics", "pod-2")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(metrics.ConcurrencyWatchdogPodVotes.WithLabelValues("metrics", "pod-1")))
+
+	// pods that are gone lose their series
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) { return nil, nil }
+	require.NoError(t, c.tick(ctx))
+	assert.Equal(t, 0, promtestutil.CollectAndCount(metrics.ConcurrencyWatchdogPodLoad))
+	assert.Equal(t, 0, promtestutil.CollectAndCount(metrics.ConcurrencyWatchdogPodVotes))
+}
@@ -19,7 +19,9 @@ import (
 
 	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/nginxingress"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/go-logr/logr"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	fakecgo "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -39,6 +42,7 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/testutils"
 	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
 )
 
 var (
@@ -111,6 +115,85 @@ func TestGetListNginxWatchdogTargets(t *testing.T) {
 	require.Len(t, targets, 2)
 	require.Equal(t, reflect.ValueOf(targets[0].ScrapeFn).Pointer(), reflect.ValueOf(NginxScrapeFn).Pointer()) // need to reflect to compare functions
 	require.Equal(t, reflect.ValueOf(targets[1].ScrapeFn).Pointer(), reflect.ValueOf(NginxScrapeFn).Pointer()) // need to reflect to compare functions
+
+	// nic with a load signal
+
+	nic3 := &approutingv1alpha1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nic-3",
+		},
+		Spec: approutingv1alpha1.NginxIngressControllerSpec{
+			IngressClassName:     "ingressClass3",
+			ControllerNamePrefix: "controllerNamePrefix3",
+			LoadShedding: &approutingv1alpha1.LoadShedding{
+				Signal: &approutingv1alpha1.LoadSignal{
+					Type: approutingv1alpha1.WeightedLoadSignalType,
+					Weights: &approutingv1alpha1.LoadSignalWeights{
+						Connections:       util.Int32Ptr(1),
+						RequestsPerSecond: util.Int32Ptr(3),
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(context.Background(), nic3))
+
+	targets, err = listTargetFn()
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+	require.Nil(t, targets[2].ScrapeFn)
+	require.Len(t, targets[2].Signals, 2)
+	require.Equal(t, float64(1), targets[2].Signals[0].Weight)
+	require.Equal(t, float64(3), targets[2].Signals[1].Weight)
+	require.NotNil(t, targets[2].Policy)
+	require.Equal(t, WatchdogPolicy{}, *targets[2].Policy)
+
+	// nics with load shedding policies
+
+	nic4 := &approutingv1alpha1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nic-4",
+		},
+		Spec: approutingv1alpha1.NginxIngressControllerSpec{
+			IngressClassName:     "ingressClass4",
+			ControllerNamePrefix: "controllerNamePrefix4",
+			LoadShedding: &approutingv1alpha1.LoadShedding{
+				Mode:                to.Ptr(approutingv1alpha1.DryRunLoadSheddingMode),
+				Threshold:           util.Int32Ptr(150),
+				Votes:               util.Int32Ptr(3),
+				MinPodAgeSeconds:    util.Int32Ptr(0),
+				MaxEvictionsPerHour: util.Int32Ptr(2),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(context.Background(), nic4))
+	nic5 := &approutingv1alpha1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nic-5",
+		},
+		Spec: approutingv1alpha1.NginxIngressControllerSpec{
+			IngressClassName:     "ingressClass5",
+			ControllerNamePrefix: "controllerNamePrefix5",
+			LoadShedding: &approutingv1alpha1.LoadShedding{
+				Mode: to.Ptr(approutingv1alpha1.OffLoadSheddingMode),
+			},
+		},
+	}
+	require.NoError(t, cl.Create(context.Background(), nic5))
+
+	targets, err = listTargetFn()
+	require.NoError(t, err)
+	require.Len(t, targets, 4, "nics with load shedding off aren't targets")
+	require.Equal(t, "nic-4", targets[3].Name)
+	require.Equal(t, "nic-4", targets[3].Object.GetName())
+	require.Equal(t, reflect.ValueOf(targets[3].ScrapeFn).Pointer(), reflect.ValueOf(NginxScrapeFn).Pointer())
+	require.Equal(t, WatchdogPolicy{
+		DryRun:              true,
+		Threshold:           150,
+		Votes:               3,
+		MinPodAge:           to.Ptr(time.Duration(0)),
+		MaxEvictionsPerHour: 2,
+	}, *targets[3].Policy)
 }
 
 func TestMain(m *testing.M) {
@@ -178,6 +261,107 @@ func TestConcurrencyWatchdogPositive(t *testing.T) {
 	require.Greater(t, testutils.GetReconcileMetricCount(t, concurrencyWatchdogControllerName, metrics.LabelSuccess), beforeReconcileCount)
 }
 
+func TestConcurrencyWatchdogDryRun(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+	cs := fakecgo.NewSimpleClientset()
+	nic := &approutingv1alpha1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Name: "dry-run-nic"}}
+
+	c := newTestConcurrencyWatchdog()
+	c.clientset = cs
+	c.client = cli
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+				if pod.Name == "pod-1" {
+					return 2000, nil
+				}
+				return 1, nil
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+			Name:      nic.Name,
+			Object:    nic,
+			Policy:    &WatchdogPolicy{DryRun: true},
+		}}, nil
+	}
+
+	dryRuns := func() float64 {
+		return promtestutil.ToFloat64(metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues(nic.Name, metrics.LabelDryRun))
+	}
+	before := dryRuns()
+
+	require.NoError(t, c.tick(ctx))
+	require.NoError(t, c.tick(ctx))
+	assert.Len(t, cs.Fake.Actions(), 0, "dry run doesn't evict")
+	assert.Equal(t, before+1, dryRuns())
+	assert.Equal(t, "Normal LoadSheddingDryRun would have evicted pod pod-1 at 499% of the average load", <-c.events.(*record.FakeRecorder).Events)
+	assert.Equal(t, 0, countVotes(c, "pod-1"), "votes are cleared so the pod is voted on again")
+
+	require.NoError(t, c.tick(ctx))
+	assert.Equal(t, before+1, dryRuns())
+}
+
+func TestConcurrencyWatchdogMaxEvictionsPerHour(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+	cs := fakecgo.NewSimpleClientset(list) // failed evictions don't count towards the limit
+
+	c := newTestConcurrencyWatchdog()
+	c.minVotesBeforeEviction = 1
+	c.clientset = cs
+	c.client = cli
+	target := WatchdogTarget{
+		ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+			if pod.Name == "pod-1" {
+				return 2000, nil
+			}
+			return 1, nil
+		},
+		PodLabels: testLabelGetter{}.PodLabels(),
+		Name:      "rate-limited",
+		Policy:    &WatchdogPolicy{MaxEvictionsPerHour: 1},
+	}
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{target}, nil
+	}
+
+	rateLimited := func() float64 {
+		return promtestutil.ToFloat64(metrics.ConcurrencyWatchdogEvictionsTotal.WithLabelValues("rate-limited", metrics.LabelRateLimited))
+	}
+	before := rateLimited()
+
+	require.NoError(t, c.tick(ctx))
+	require.Len(t, cs.Fake.Actions(), 1)
+
+	require.NoError(t, c.tick(ctx))
+	assert.Len(t, cs.Fake.Actions(), 1, "limit reached")
+	assert.Equal(t, before+1, rateLimited())
+
+	// the eviction falls out of the hour
+	c.evictions["rate-limited"][0] = time.Now().Add(-time.Hour)
+	require.NoError(t, c.tick(ctx))
+	assert.Len(t, cs.Fake.Actions(), 2)
+}
+
+func TestConcurrencyWatchdogVotingPolicy(t *testing.T) {
+	c := newTestConcurrencyWatchdog()
+	require.Equal(t, votingPolicy{threshold: 200, votes: 2, minPodAge: time.Minute}, c.votingPolicy(nil))
+	require.Equal(t, votingPolicy{threshold: 200, votes: 2, minPodAge: time.Minute}, c.votingPolicy(&WatchdogPolicy{DryRun: true}))
+	require.Equal(t, votingPolicy{threshold: 150, votes: 1, minPodAge: 0}, c.votingPolicy(&WatchdogPolicy{
+		Threshold: 150,
+		Votes:     1,
+		MinPodAge: to.Ptr(time.Duration(0)),
+	}))
+
+	// a lower threshold votes for pods the default wouldn't
+	list := buildTestPods(3)
+	loads := []float64{1.5, 1, 0.5}
+	require.Equal(t, "", c.processVotes("target", list, loads, 1, votingPolicy{threshold: 200, votes: 1}))
+	require.Equal(t, "pod-0", c.processVotes("target", list, loads, 1, votingPolicy{threshold: 140, votes: 1}))
+}
+
 func TestConcurrencyWatchdogPodNotReady(t *testing.T) {
 	ctx := context.Background()
 	list := buildTestPods(2)
@@ -220,6 +404,35 @@ func TestConcurrencyWatchdogPodNotReady(t *testing.T) {
 	require.Greater(t, testutils.GetReconcileMetricCount(t, concurrencyWatchdogControllerName, metrics.LabelSuccess), beforeReconcileCount)
 }
 
+func TestConcurrencyWatchdogScrapeError(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(3)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+
+	c := newTestConcurrencyWatchdog()
+	c.client = cli
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{
+			{
+				ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+					switch pod.Name {
+					case "pod-0":
+						return 0, fmt.Errorf("test error")
+					case "pod-1":
+						return 2000, nil
+					}
+					return 1, nil
+				},
+				PodLabels: testLabelGetter{}.PodLabels(),
+			},
+		}, nil
+	}
+
+	// the pod that failed to scrape isn't counted so only two pods are ready
+	require.Error(t, c.tick(ctx))
+	assert.Equal(t, 0, countVotes(c, "pod-1"))
+}
+
 func TestConcurrencyWatchdogProcessVotesNegative(t *testing.T) {
 	c := newTestConcurrencyWatchdog()
 	c.minVotesBeforeEviction = 1
@@ -228,7 +441,7 @@ func TestConcurrencyWatchdogProcessVotesNegative(t *testing.T) {
 	connectionCountByPod := []float64{10, 10, 10, 10, 10}
 	avgConnectionCount := float64(10)
 
-	pod := c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod := c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 }
 
@@ -240,17 +453,17 @@ func TestConcurrencyWatchdogProcessVotesPositive(t *testing.T) {
 	connectionCountByPod := []float64{10, 20, 10, 10, 10}
 	avgConnectionCount := float64(10)
 
-	pod := c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod := c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 
 	// Second vote (under threshold)
 	connectionCountByPod = []float64{10, 10, 10, 10, 10}
-	pod = c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod = c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 
 	// Third vote (over threshold)
 	connectionCountByPod = []float64{10, 20, 10, 10, 10}
-	pod = c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod = c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Equal(t, "pod-1", pod, "the pod was evicted")
 }
 
@@ -319,7 +532,7 @@ func TestConcurrencyWatchdogProcessVotesWrapAroundBuffer(t *testing.T) {
 			n = 20
 		}
 		assert.Equal(t, n, countVotes(c, "pod-1"))
-		c.processVotes(list, connectionCountByPod, avgConnectionCount)
+		c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	}
 
 	// Replace buffer with votes for pod 2
@@ -330,8 +543,31 @@ func TestConcurrencyWatchdogProcessVotesWrapAroundBuffer(t *testing.T) {
 			n = 20
 		}
 		assert.Equal(t, n, countVotes(c, "pod-2"))
-		c.processVotes(list, connectionCountByPod, avgConnectionCount)
+		c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
+	}
+}
+
+func TestConcurrencyWatchdogProcessVotesPerTarget(t *testing.T) {
+	c := newTestConcurrencyWatchdog()
+	list := buildTestPods(5)
+	connectionCountByPod := []float64{10, 20, 10, 10, 10}
+	avgConnectionCount := float64(10)
+	policy := votingPolicy{threshold: 200, votes: 10}
+
+	c.processVotes("a", list, connectionCountByPod, avgConnectionCount, policy)
+	// another target voting more often than the ring holds doesn't overwrite the votes of the first one
+	for i := 0; i < 2*voteRingSize; i++ {
+		c.processVotes("b", list, connectionCountByPod, avgConnectionCount, policy)
+	}
+	assert.Equal(t, 1, c.votesPerPod("a", list)["pod-1"])
+	assert.Equal(t, voteRingSize, c.votesPerPod("b", list)["pod-1"])
+
+	// a policy that requires more votes than the ring holds keeps them all
+	policy.votes = voteRingSize + 5
+	for i := 0; i < policy.votes-1; i++ {
+		assert.Empty(t, c.processVotes("c", list, connectionCountByPod, avgConnectionCount, policy))
 	}
+	assert.Equal(t, "pod-1", c.processVotes("c", list, connectionCountByPod, avgConnectionCount, policy))
 }
 
 func TestConcurrencyWatchdogProcessVotesNewPods(t *testing.T) {
@@ -340,7 +576,7 @@ func TestConcurrencyWatchdogProcessVotesNewPods(t *testing.T) {
 	list.Items[1].CreationTimestamp.Time = time.Now()     // pod 1 is new
 	connectionCountByPod := []float64{10, 20, 10, 10, 10} // pod 1 should get a vote
 	avgConnectionCount := float64(10)
-	c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Equal(t, 0, countVotes(c, "pod-1"))
 }
 
@@ -351,14 +587,14 @@ func TestConcurrencyWatchdogProcessVotesOldVotes(t *testing.T) {
 	avgConnectionCount := float64(10)
 
 	// Register the first vote
-	pod := c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod := c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 
 	// Mutate the vote into the past so that it won't be considered
-	c.votes.Value.(*evictionVote).Time = time.Now().Add(-time.Hour)
+	c.votes["target"].Value.(*evictionVote).Time = time.Now().Add(-time.Hour)
 
 	// The pod would have been evicted if both votes were considered
-	pod = c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod = c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 }
 
@@ -369,14 +605,14 @@ func TestConcurrencyWatchdogProcessVotesMissingPod(t *testing.T) {
 	avgConnectionCount := float64(10)
 
 	// Register the first vote
-	pod := c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod := c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 
 	// Mutate the vote to reference a different pod that doesn't exist
-	c.votes.Value.(*evictionVote).PodName = "nope"
+	c.votes["target"].Value.(*evictionVote).PodName = "nope"
 
 	// The pod would have been evicted if both votes were considered
-	pod = c.processVotes(list, connectionCountByPod, avgConnectionCount)
+	pod = c.processVotes("target", list, connectionCountByPod, avgConnectionCount, c.votingPolicy(nil))
 	assert.Empty(t, pod, "no pod was evicted")
 }
 
@@ -415,12 +651,14 @@ func buildTestPods(n int) *corev1.PodList {
 
 func countVotes(c *ConcurrencyWatchdog, pod string) int {
 	var n int
-	c.votes.Do(func(obj interface{}) {
-		vote, ok := obj.(*evictionVote)
-		if ok && vote.PodName == pod {
-			n++
-		}
-	})
+	for _, votes := range c.votes {
+		votes.Do(func(obj interface{}) {
+			vote, ok := obj.(*evictionVote)
+			if ok && vote.PodName == pod {
+				n++
+			}
+		})
+	}
 	return n
 }
 
@@ -432,10 +670,23 @@ func newTestConcurrencyWatchdog() *ConcurrencyWatchdog {
 		voteTTL:                     time.Second,
 		minVotesBeforeEviction:      2,
 		minPercentOverAvgBeforeVote: 200,
-		votes:                       ring.New(20),
+		scrapeTimeout:               time.Minute,
+		scrapeWorkers:               5,
+		votes:                       map[string]*ring.Ring{},
+		evictions:                   map[string][]time.Time{},
+		events:                      record.NewFakeRecorder(10),
 	}
 }
 
+func TestConcurrencyWatchdogApplyConfig(t *testing.T) {
+	c := newTestConcurrencyWatchdog()
+	c.config.SetCurrent(&config.Config{ConcurrencyWatchdogVotes: 5, ConcurrencyWatchdogThres: 150})
+
+	c.applyConfig(c.config.Current())
+	require.Equal(t, 5, c.minVotesBeforeEviction)
+	require.Equal(t, float64(150), c.minPercentOverAvgBeforeVote)
+}
+
 func TestPodIsActive(t *testing.T) {
 	ctx := context.Background()
 	pods := buildTestPods(3)
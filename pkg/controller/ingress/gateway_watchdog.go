@@ -0,0 +1,140 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	prommodel "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/spc"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/podmetrics"
+)
+
+const (
+	// gatewayNameLabel is set by Istio on the pods of the deployment it runs for a Gateway
+	gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+
+	// watchdogDisabledAnnotation opts a Gateway out of the concurrency watchdog when set to "true"
+	watchdogDisabledAnnotation = "kubernetes.azure.com/concurrency-watchdog-disabled"
+
+	// envoyStatsPort is the port the istio-proxy serves Envoy's stats on in the Prometheus format
+	envoyStatsPort = "15090"
+
+	envoyListenerActiveMetric    = "envoy_listener_downstream_cx_active"
+	envoyServerConnectionsMetric = "envoy_server_total_connections"
+)
+
+// envoyInternalListenerPorts are the ports of the listeners the istio-proxy serves its own stats and health checks on, their
+// connections come from scrapers and probes rather than clients
+var envoyInternalListenerPorts = []string{envoyStatsPort, "15020", "15021"}
+
+// GetListGatewayWatchdogTargets returns a ListWatchdogTargets for the deployments Istio runs for Gateways of the managed GatewayClasses.
+// Gateways annotated with watchdogDisabledAnnotation are skipped and there are no targets while the Gateway API CRDs aren't installed.
+func GetListGatewayWatchdogTargets(cl client.Client) ListWatchdogTargets {
+	return func() ([]WatchdogTarget, error) {
+		gateways := &gatewayv1.GatewayList{}
+		if err := cl.List(context.Background(), gateways); err != nil {
+			if meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("listing Gateway objects: %w", err)
+		}
+
+		var targets []WatchdogTarget
+		for _, gw := range gateways.Items {
+			if !spc.IsManagedGateway(&gw) || gw.Annotations[watchdogDisabledAnnotation] == "true" {
+				continue
+			}
+
+			targets = append(targets, WatchdogTarget{
+				ScrapeFn:  EnvoyScrapeFn,
+				PodLabels: map[string]string{gatewayNameLabel: gw.Name},
+				Name:      fmt.Sprintf("gateway/%s/%s", gw.Namespace, gw.Name),
+				Namespace: gw.Namespace,
+				Object:    &gw,
+			})
+		}
+
+		return targets, nil
+	}
+}
+
+// EnvoyScrapeFn is the scrape function for the istio-proxy of a Gateway, it returns the active downstream connections of the pod
+func EnvoyScrapeFn(ctx context.Context, client rest.Interface, pod *corev1.Pod) (float64, error) {
+	families, err := scrapeEnvoyStats(ctx, client, pod)
+	if err != nil {
+		return 0, err
+	}
+
+	return envoyActiveConnections(families)
+}
+
+// scrapeEnvoyStats returns the stats of an istio-proxy keyed by metric name
+func scrapeEnvoyStats(ctx context.Context, client rest.Interface, pod *corev1.Pod) (map[string]*prommodel.MetricFamily, error) {
+	lgr := logr.FromContextOrDiscard(ctx)
+
+	lgr.Info("scraping pod", "pod", pod.Name)
+	resp, err := podmetrics.Get(ctx, client, pod, envoyStatsPort, "stats/prometheus")
+	if err != nil {
+		return nil, err
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(resp))
+	if err != nil {
+		return nil, fmt.Errorf("parsing envoy stats: %w", err)
+	}
+
+	return families, nil
+}
+
+// envoyActiveConnections returns the active downstream connections of an istio-proxy. Listener stats aren't in Istio's default stats
+// inclusions so the total connections of the server are used when they're missing.
+func envoyActiveConnections(families map[string]*prommodel.MetricFamily) (float64, error) {
+	if family, ok := families[envoyListenerActiveMetric]; ok {
+		var total float64
+		for _, metric := range family.Metric {
+			if isEnvoyInternalListener(metric) {
+				continue
+			}
+			total += metric.GetGauge().GetValue()
+		}
+		return total, nil
+	}
+
+	if family, ok := families[envoyServerConnectionsMetric]; ok && len(family.Metric) > 0 {
+		return family.Metric[0].GetGauge().GetValue(), nil
+	}
+
+	return 0, fmt.Errorf("active downstream connections metric not found")
+}
+
+// isEnvoyInternalListener returns whether a listener metric is of one of the istio-proxy's own listeners. Listener addresses look
+// like 0.0.0.0_15090.
+func isEnvoyInternalListener(metric *prommodel.Metric) bool {
+	for _, label := range metric.Label {
+		if label.GetName() != "listener_address" {
+			continue
+		}
+
+		for _, port := range envoyInternalListenerPorts {
+			if strings.HasSuffix(label.GetValue(), "_"+port) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	prommodel "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakecgo "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestGetListGatewayWatchdogTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gatewayv1.Install(scheme))
+
+	gateway := func(namespace, name, class string) *gatewayv1.Gateway {
+		return &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       gatewayv1.GatewaySpec{GatewayClassName: gatewayv1.ObjectName(class)},
+		}
+	}
+	optedOut := gateway("app", "opted-out", "approuting-istio")
+	optedOut.Annotations = map[string]string{watchdogDisabledAnnotation: "true"}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		gateway("app", "managed", "approuting-istio"),
+		gateway("other", "istio", "istio"),
+		gateway("app", "unmanaged", "some-other-class"),
+		optedOut,
+	).Build()
+
+	targets, err := GetListGatewayWatchdogTargets(cl)()
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	assert.Equal(t, "gateway/app/managed", targets[0].Name)
+	assert.Equal(t, "app", targets[0].Namespace)
+	assert.Equal(t, map[string]string{gatewayNameLabel: "managed"}, targets[0].PodLabels)
+	assert.Equal(t, "managed", targets[0].Object.GetName())
+	assert.Equal(t, reflect.ValueOf(EnvoyScrapeFn).Pointer(), reflect.ValueOf(targets[0].ScrapeFn).Pointer())
+	assert.Equal(t, "gateway/other/istio", targets[1].Name)
+
+	// gateway api types aren't registered
+	_, err = GetListGatewayWatchdogTargets(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())()
+	require.Error(t, err)
+
+	// gateway api crds aren't installed
+	noCrds := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+			return &meta.NoKindMatchError{GroupKind: gatewayv1.SchemeGroupVersion.WithKind("Gateway").GroupKind()}
+		},
+	}).Build()
+	targets, err = GetListGatewayWatchdogTargets(noCrds)()
+	require.NoError(t, err)
+	require.Empty(t, targets)
+}
+
+func TestCombineListWatchdogTargets(t *testing.T) {
+	source := func(names ...string) ListWatchdogTargets {
+		return func() ([]WatchdogTarget, error) {
+			var targets []WatchdogTarget
+			for _, name := range names {
+				targets = append(targets, WatchdogTarget{Name: name})
+			}
+			return targets, nil
+		}
+	}
+
+	targets, err := CombineListWatchdogTargets(source("a", "b"), source(), source("c"))()
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+	assert.Equal(t, "c", targets[2].Name)
+
+	_, err = CombineListWatchdogTargets(source("a"), func() ([]WatchdogTarget, error) { return nil, fmt.Errorf("failed") })()
+	require.EqualError(t, err, "failed")
+}
+
+func TestEnvoyActiveConnections(t *testing.T) {
+	parse := func(lines ...string) map[string]*prommodel.MetricFamily {
+		parser := expfmt.NewTextParser(model.UTF8Validation)
+		families, err := parser.TextToMetricFamilies(strings.NewReader(strings.Join(append(lines, ""), "\n")))
+		require.NoError(t, err)
+		return families
+	}
+
+	connections, err := envoyActiveConnections(parse(
+		"# TYPE envoy_listener_downstream_cx_active gauge",
+		`envoy_listener_downstream_cx_active{listener_address="0.0.0.0_80"} 10`,
+		`envoy_listener_downstream_cx_active{listener_address="0.0.0.0_443"} 25`,
+		`envoy_listener_downstream_cx_active{listener_address="0.0.0.0_15090"} 1`,
+		`envoy_listener_downstream_cx_active{listener_address="0.0.0.0_15021"} 2`,
+		"# TYPE envoy_server_total_connections gauge",
+		"envoy_server_total_connections 38",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, float64(35), connections, "the proxy's own listeners are ignored")
+
+	connections, err = envoyActiveConnections(parse(
+		"# TYPE envoy_server_total_connections gauge",
+		"envoy_server_total_connections 38",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, float64(38), connections, "falls back to the server's connections")
+
+	_, err = envoyActiveConnections(parse(
+		"# TYPE envoy_cluster_upstream_cx_active gauge",
+		"envoy_cluster_upstream_cx_active 3",
+	))
+	require.EqualError(t, err, "active downstream connections metric not found")
+}
+
+func TestEnvoyScrapeFn(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/test-ns/pods/test-pod:15090/proxy/stats/prometheus", r.URL.Path)
+
+		io.WriteString(w, strings.Join([]string{
+			"# TYPE envoy_listener_downstream_cx_active gauge",
+			`envoy_listener_downstream_cx_active{listener_address="0.0.0.0_443"} 123`,
+			"",
+		}, "\n"))
+	}))
+	defer svr.Close()
+
+	u, err := url.Parse(svr.URL)
+	require.NoError(t, err)
+	restClient, err := rest.NewRESTClient(u, "", rest.ClientContentConfig{}, nil, http.DefaultClient)
+	require.NoError(t, err)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "test-ns"}}
+	value, err := EnvoyScrapeFn(context.Background(), restClient, pod)
+	require.NoError(t, err)
+	assert.Equal(t, float64(123), value)
+}
+
+func TestConcurrencyWatchdogTargetNamespace(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	for i := range list.Items {
+		list.Items[i].Namespace = "gateway-ns"
+	}
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+	cs := fakecgo.NewSimpleClientset()
+
+	c := newTestConcurrencyWatchdog()
+	c.config.NS = "app-routing-system"
+	c.clientset = cs
+	c.client = cli
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+				if pod.Name == "pod-1" {
+					return 2000, nil
+				}
+				return 1, nil
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+			Namespace: "gateway-ns",
+		}}, nil
+	}
+
+	require.NoError(t, c.tick(ctx))
+	require.NoError(t, c.tick(ctx))
+	require.Len(t, cs.Fake.Actions(), 1)
+	eviction := cs.Fake.Actions()[0].(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+	assert.Equal(t, "pod-1", eviction.Name)
+	assert.Equal(t, "gateway-ns", eviction.Namespace)
+}
@@ -0,0 +1,293 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/podmetrics"
+	prommodel "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	nginxConnectionsMetric     = "nginx_ingress_controller_nginx_process_connections"
+	nginxRequestsMetric        = "nginx_ingress_controller_nginx_process_requests_total"
+	nginxUpstreamLatencyMetric = "nginx_ingress_controller_response_duration_seconds"
+	nginxMemoryMetric          = "nginx_ingress_controller_nginx_process_resident_memory_bytes"
+
+	// scrapeCacheTTL is how long a pod's metrics are reused so every signal of a Weighted load signal reads the same scrape. It's
+	// well below the watchdog's interval so every tick scrapes again.
+	scrapeCacheTTL = 10 * time.Second
+	// scrapeTTL is how long a pod's metrics are kept to compute rates once the pod isn't scraped anymore
+	scrapeTTL = 10 * time.Minute
+)
+
+// WeightedScrapeFn is a ScrapeFn with its weight in a combined load signal
+type WeightedScrapeFn struct {
+	ScrapeFn ScrapeFn
+	Weight   float64
+}
+
+// nginxSignal reads a load signal from the current and previous metrics of a pod. previous is nil on the first scrape of the pod.
+type nginxSignal func(pod *corev1.Pod, current, previous *nginxScrape) (float64, error)
+
+// nginxScrape is the metrics of an ingress-nginx pod at a point in time
+type nginxScrape struct {
+	time     time.Time
+	families map[string]*prommodel.MetricFamily
+}
+
+// nginxScraper reads load signals from the metrics of ingress-nginx pods. Signals of a pod share a scrape so combining signals doesn't
+// multiply requests, and counters are turned into rates using the previous scrape of the pod.
+type nginxScraper struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	scrape   func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (map[string]*prommodel.MetricFamily, error)
+	current  map[types.UID]*nginxScrape
+	previous map[types.UID]*nginxScrape
+}
+
+func newNginxScraper() *nginxScraper {
+	return &nginxScraper{
+		now:      time.Now,
+		scrape:   podmetrics.Nginx,
+		current:  map[types.UID]*nginxScrape{},
+		previous: map[types.UID]*nginxScrape{},
+	}
+}
+
+// Signals returns the scrape functions of a NginxIngressController's load signal. Nil means the default, active connections.
+func (n *nginxScraper) Signals(signal *approutingv1alpha1.LoadSignal) []WeightedScrapeFn {
+	if signal == nil {
+		return []WeightedScrapeFn{{ScrapeFn: NginxScrapeFn, Weight: 1}}
+	}
+
+	switch signal.Type {
+	case approutingv1alpha1.RequestsPerSecondLoadSignalType:
+		return []WeightedScrapeFn{{ScrapeFn: n.scrapeFn(requestsPerSecond), Weight: 1}}
+	case approutingv1alpha1.UpstreamLatencyLoadSignalType:
+		return []WeightedScrapeFn{{ScrapeFn: n.scrapeFn(upstreamLatency), Weight: 1}}
+	case approutingv1alpha1.MemoryLoadSignalType:
+		return []WeightedScrapeFn{{ScrapeFn: n.scrapeFn(memory), Weight: 1}}
+	case approutingv1alpha1.WeightedLoadSignalType:
+		if signal.Weights == nil {
+			break
+		}
+
+		var ret []WeightedScrapeFn
+		for _, weighted := range []struct {
+			weight *int32
+			signal nginxSignal
+		}{
+			{signal.Weights.Connections, connections},
+			{signal.Weights.RequestsPerSecond, requestsPerSecond},
+			{signal.Weights.UpstreamLatency, upstreamLatency},
+			{signal.Weights.Memory, memory},
+		} {
+			if weighted.weight == nil || *weighted.weight <= 0 {
+				continue
+			}
+			ret = append(ret, WeightedScrapeFn{ScrapeFn: n.scrapeFn(weighted.signal), Weight: float64(*weighted.weight)})
+		}
+		if len(ret) > 0 {
+			return ret
+		}
+	}
+
+	return []WeightedScrapeFn{{ScrapeFn: NginxScrapeFn, Weight: 1}}
+}
+
+// scrapeFn returns a ScrapeFn that reads signal from the pod's latest scrape
+func (n *nginxScraper) scrapeFn(signal nginxSignal) ScrapeFn {
+	return func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (float64, error) {
+		current, previous, err := n.get(ctx, client, pod)
+		if err != nil {
+			return 0, err
+		}
+
+		return signal(pod, current, previous)
+	}
+}
+
+// get returns the latest and previous scrapes of pod, scraping it again when the latest is older than scrapeCacheTTL. Pods are
+// scraped without holding the lock so they can be scraped in parallel, the signals of a pod are read one after the other.
+func (n *nginxScraper) get(ctx context.Context, client rest.Interface, pod *corev1.Pod) (*nginxScrape, *nginxScrape, error) {
+	n.mu.Lock()
+	now := n.now()
+	if current, ok := n.current[pod.UID]; ok && now.Sub(current.time) < scrapeCacheTTL {
+		defer n.mu.Unlock()
+		return current, n.previous[pod.UID], nil
+	}
+	n.mu.Unlock()
+
+	families, err := n.scrape(ctx, client, pod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if current, ok := n.current[pod.UID]; ok {
+		n.previous[pod.UID] = current
+	}
+	n.current[pod.UID] = &nginxScrape{time: now, families: families}
+
+	// forget pods that are gone
+	for uid, scrape := range n.current {
+		if now.Sub(scrape.time) > scrapeTTL {
+			delete(n.current, uid)
+			delete(n.previous, uid)
+		}
+	}
+
+	return n.current[pod.UID], n.previous[pod.UID], nil
+}
+
+func connections(_ *corev1.Pod, current, _ *nginxScrape) (float64, error) {
+	return activeConnections(current.families)
+}
+
+// activeConnections returns the active connections of an ingress-nginx pod
+func activeConnections(families map[string]*prommodel.MetricFamily) (float64, error) {
+	if family, ok := families[nginxConnectionsMetric]; ok {
+		for _, metric := range family.Metric {
+			if metric.Gauge == nil || !metricHasLabel(metric, "state", "active") {
+				continue
+			}
+			return metric.Gauge.GetValue(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("active connections metric not found")
+}
+
+func requestsPerSecond(pod *corev1.Pod, current, previous *nginxScrape) (float64, error) {
+	family, ok := current.families[nginxRequestsMetric]
+	if !ok {
+		return 0, fmt.Errorf("requests metric not found")
+	}
+	requests := sumCounters(family)
+
+	// a counter lower than before means the pod restarted, rate over its lifetime instead
+	if previous != nil {
+		if prevFamily, ok := previous.families[nginxRequestsMetric]; ok {
+			if prev := sumCounters(prevFamily); requests >= prev && current.time.After(previous.time) {
+				return (requests - prev) / current.time.Sub(previous.time).Seconds(), nil
+			}
+		}
+	}
+
+	lifetime := current.time.Sub(podStartTime(pod)).Seconds()
+	if lifetime <= 0 {
+		return 0, nil
+	}
+	return requests / lifetime, nil
+}
+
+func upstreamLatency(_ *corev1.Pod, current, previous *nginxScrape) (float64, error) {
+	// the histogram only exists once requests have been proxied
+	sum, count := sumHistograms(current.families[nginxUpstreamLatencyMetric])
+
+	if previous != nil {
+		prevSum, prevCount := sumHistograms(previous.families[nginxUpstreamLatencyMetric])
+		if count >= prevCount && sum >= prevSum {
+			sum, count = sum-prevSum, count-prevCount
+		}
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+	return sum / float64(count), nil
+}
+
+func memory(_ *corev1.Pod, current, _ *nginxScrape) (float64, error) {
+	family, ok := current.families[nginxMemoryMetric]
+	if !ok {
+		return 0, fmt.Errorf("resident memory metric not found")
+	}
+
+	var total float64
+	for _, metric := range family.Metric {
+		total += metric.GetGauge().GetValue()
+	}
+	return total, nil
+}
+
+func sumCounters(family *prommodel.MetricFamily) float64 {
+	var total float64
+	for _, metric := range family.Metric {
+		total += metric.GetCounter().GetValue()
+	}
+	return total
+}
+
+func sumHistograms(family *prommodel.MetricFamily) (float64, uint64) {
+	if family == nil {
+		return 0, 0
+	}
+
+	var sum float64
+	var count uint64
+	for _, metric := range family.Metric {
+		sum += metric.GetHistogram().GetSampleSum()
+		count += metric.GetHistogram().GetSampleCount()
+	}
+	return sum, count
+}
+
+func podStartTime(pod *corev1.Pod) time.Time {
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// combineSignals returns the load of each pod relative to the average of the ready pods. A single signal is returned as is with its
+// average. Several signals are each divided by their average before they're weighted so the combined load of a pod is 1 when it's
+// average for every signal.
+func combineSignals(signals []WeightedScrapeFn, valuesBySignal [][]float64, ready []bool, nReadyPods int) ([]float64, float64) {
+	if len(signals) == 1 {
+		var total float64
+		for _, value := range valuesBySignal[0] {
+			total += value
+		}
+		return valuesBySignal[0], total / float64(nReadyPods)
+	}
+
+	loads := make([]float64, len(ready))
+	var totalWeight float64
+	for s, signal := range signals {
+		var total float64
+		for _, value := range valuesBySignal[s] {
+			total += value
+		}
+		avg := total / float64(nReadyPods)
+
+		totalWeight += signal.Weight
+		for i := range ready {
+			if !ready[i] {
+				continue
+			}
+
+			relative := 1.0 // a signal that's zero everywhere is balanced
+			if avg > 0 {
+				relative = valuesBySignal[s][i] / avg
+			}
+			loads[i] += signal.Weight * relative
+		}
+	}
+
+	for i := range loads {
+		loads[i] /= totalWeight
+	}
+	return loads, 1
+}
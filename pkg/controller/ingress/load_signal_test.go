@@ -0,0 +1,225 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	prommodel "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakecgo "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// nginxMetrics returns the metric families of an ingress-nginx pod in the text format
+func nginxMetrics(t *testing.T, connections, requests, latencySum float64, latencyCount int, memory float64) map[string]*prommodel.MetricFamily {
+	text := strings.Join([]string{
+		"# TYPE nginx_ingress_controller_nginx_process_connections gauge",
+		`nginx_ingress_controller_nginx_process_connections{state="active"} ` + formatFloat(connections),
+		"# TYPE nginx_ingress_controller_nginx_process_requests_total counter",
+		"nginx_ingress_controller_nginx_process_requests_total " + formatFloat(requests),
+		"# TYPE nginx_ingress_controller_response_duration_seconds histogram",
+		`nginx_ingress_controller_response_duration_seconds_bucket{ingress="a",le="+Inf"} ` + formatFloat(float64(latencyCount)),
+		`nginx_ingress_controller_response_duration_seconds_sum{ingress="a"} ` + formatFloat(latencySum),
+		`nginx_ingress_controller_response_duration_seconds_count{ingress="a"} ` + formatFloat(float64(latencyCount)),
+		"# TYPE nginx_ingress_controller_nginx_process_resident_memory_bytes gauge",
+		"nginx_ingress_controller_nginx_process_resident_memory_bytes " + formatFloat(memory),
+		"",
+	}, "\n")
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	require.NoError(t, err)
+	return families
+}
+
+func formatFloat(f float64) string {
+	return model.SampleValue(f).String()
+}
+
+func TestNginxScraperSignals(t *testing.T) {
+	n := newNginxScraper()
+
+	signals := n.Signals(nil)
+	require.Len(t, signals, 1)
+	require.Equal(t, reflect.ValueOf(NginxScrapeFn).Pointer(), reflect.ValueOf(signals[0].ScrapeFn).Pointer())
+
+	for _, signalType := range []approutingv1alpha1.LoadSignalType{
+		approutingv1alpha1.RequestsPerSecondLoadSignalType,
+		approutingv1alpha1.UpstreamLatencyLoadSignalType,
+		approutingv1alpha1.MemoryLoadSignalType,
+	} {
+		signals := n.Signals(&approutingv1alpha1.LoadSignal{Type: signalType})
+		require.Len(t, signals, 1, signalType)
+		require.Equal(t, float64(1), signals[0].Weight)
+	}
+
+	signals = n.Signals(&approutingv1alpha1.LoadSignal{
+		Type: approutingv1alpha1.WeightedLoadSignalType,
+		Weights: &approutingv1alpha1.LoadSignalWeights{
+			UpstreamLatency: util.Int32Ptr(2),
+			Memory:          util.Int32Ptr(5),
+		},
+	})
+	require.Len(t, signals, 2)
+	require.Equal(t, float64(2), signals[0].Weight)
+	require.Equal(t, float64(5), signals[1].Weight)
+
+	// weighted without weights is rejected by the CRD, fall back to connections anyway
+	signals = n.Signals(&approutingv1alpha1.LoadSignal{Type: approutingv1alpha1.WeightedLoadSignalType})
+	require.Equal(t, reflect.ValueOf(NginxScrapeFn).Pointer(), reflect.ValueOf(signals[0].ScrapeFn).Pointer())
+}
+
+func TestNginxScraper(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: types.UID("uid")},
+		Status:     corev1.PodStatus{StartTime: &metav1.Time{Time: now.Add(-100 * time.Second)}},
+	}
+
+	scrapes := 0
+	families := nginxMetrics(t, 10, 1000, 5, 10, 2048)
+	n := newNginxScraper()
+	n.now = func() time.Time { return now }
+	n.scrape = func(context.Context, rest.Interface, *corev1.Pod) (map[string]*prommodel.MetricFamily, error) {
+		scrapes++
+		return families, nil
+	}
+
+	read := func(signal nginxSignal) float64 {
+		value, err := n.scrapeFn(signal)(ctx, nil, pod)
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("first scrape", func(t *testing.T) {
+		assert.Equal(t, float64(10), read(connections))
+		assert.Equal(t, float64(10), read(requestsPerSecond), "rate over the pod's lifetime")
+		assert.Equal(t, 0.5, read(upstreamLatency), "average over the pod's lifetime")
+		assert.Equal(t, float64(2048), read(memory))
+		assert.Equal(t, 1, scrapes, "signals share a scrape")
+	})
+
+	t.Run("rates use the previous scrape", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		families = nginxMetrics(t, 20, 1600, 25, 20, 4096)
+
+		assert.Equal(t, float64(20), read(connections))
+		assert.Equal(t, float64(10), read(requestsPerSecond))
+		assert.Equal(t, float64(2), read(upstreamLatency))
+		assert.Equal(t, float64(4096), read(memory))
+		assert.Equal(t, 2, scrapes)
+	})
+
+	t.Run("restarted pod", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		pod.Status.StartTime = &metav1.Time{Time: now.Add(-10 * time.Second)}
+		families = nginxMetrics(t, 1, 50, 1, 4, 1024)
+
+		assert.Equal(t, float64(5), read(requestsPerSecond))
+		assert.Equal(t, 0.25, read(upstreamLatency))
+	})
+
+	t.Run("missing metric", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		families = map[string]*prommodel.MetricFamily{}
+
+		_, err := n.scrapeFn(requestsPerSecond)(ctx, nil, pod)
+		require.EqualError(t, err, "requests metric not found")
+		_, err = n.scrapeFn(memory)(ctx, nil, pod)
+		require.EqualError(t, err, "resident memory metric not found")
+		assert.Equal(t, float64(0), read(upstreamLatency), "no requests were proxied")
+	})
+
+	t.Run("gone pods are forgotten", func(t *testing.T) {
+		now = now.Add(scrapeTTL + time.Minute)
+		other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: types.UID("other")}}
+		families = nginxMetrics(t, 1, 1, 1, 1, 1)
+
+		_, err := n.scrapeFn(connections)(ctx, nil, other)
+		require.NoError(t, err)
+		require.NotContains(t, n.current, pod.UID)
+		require.NotContains(t, n.previous, pod.UID)
+	})
+}
+
+func TestCombineSignals(t *testing.T) {
+	ready := []bool{true, true, true, false}
+
+	loads, avg := combineSignals([]WeightedScrapeFn{{Weight: 1}}, [][]float64{{10, 20, 30, 0}}, ready, 3)
+	assert.Equal(t, []float64{10, 20, 30, 0}, loads)
+	assert.Equal(t, float64(20), avg)
+
+	loads, avg = combineSignals(
+		[]WeightedScrapeFn{{Weight: 1}, {Weight: 3}, {Weight: 1}},
+		[][]float64{
+			{10, 10, 10, 0}, // balanced connections
+			{90, 0, 0, 0},   // one pod handles every request
+			{0, 0, 0, 0},    // a signal that's zero everywhere is balanced
+		},
+		ready, 3,
+	)
+	assert.Equal(t, float64(1), avg)
+	assert.InDelta(t, 2.2, loads[0], 0.0001)
+	assert.InDelta(t, 0.4, loads[1], 0.0001)
+	assert.InDelta(t, 0.4, loads[2], 0.0001)
+	assert.Equal(t, float64(0), loads[3], "pods that aren't ready have no load")
+}
+
+func TestConcurrencyWatchdogWeightedSignals(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(5)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+	cs := fakecgo.NewSimpleClientset()
+
+	c := newTestConcurrencyWatchdog()
+	c.clientset = cs
+	c.client = cli
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			Signals: []WeightedScrapeFn{
+				{
+					// connections look balanced
+					ScrapeFn: func(context.Context, rest.Interface, *corev1.Pod) (float64, error) { return 100, nil },
+					Weight:   1,
+				},
+				{
+					// but pod-1 holds the heavy streams
+					ScrapeFn: func(_ context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+						if pod.Name == "pod-1" {
+							return 1000, nil
+						}
+						return 10, nil
+					},
+					Weight: 1,
+				},
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+		}}, nil
+	}
+
+	require.NoError(t, c.tick(ctx))
+	assert.Len(t, cs.Fake.Actions(), 0)
+	assert.Equal(t, 1, countVotes(c, "pod-1"))
+
+	require.NoError(t, c.tick(ctx))
+	require.Len(t, cs.Fake.Actions(), 1)
+	assert.Equal(t, "pod-1", cs.Fake.Actions()[0].(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction).Name)
+}
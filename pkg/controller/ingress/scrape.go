@@ -0,0 +1,19 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"time"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+)
+
+// observeScrape records the duration of the watchdog's scrapes of a pod
+func observeScrape(mode string, start time.Time, err error) {
+	result := metrics.LabelSuccess
+	if err != nil {
+		result = metrics.LabelError
+	}
+	metrics.ConcurrencyWatchdogScrapeDuration.WithLabelValues(mode, result).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package ingress
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	fakecgo "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConcurrencyWatchdogScrapesInParallel(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(10)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+
+	c := newTestConcurrencyWatchdog()
+	c.clientset = fakecgo.NewSimpleClientset()
+	c.client = cli
+	c.scrapeWorkers = 3
+
+	var running, maxRunning atomic.Int32
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(context.Context, rest.Interface, *corev1.Pod) (float64, error) {
+				cur := running.Add(1)
+				defer running.Add(-1)
+				for {
+					prev := maxRunning.Load()
+					if cur <= prev || maxRunning.CompareAndSwap(prev, cur) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return 1, nil
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+		}}, nil
+	}
+
+	require.NoError(t, c.tick(ctx))
+	assert.Equal(t, int32(3), maxRunning.Load(), "scrapes are bounded by the workers")
+}
+
+func TestConcurrencyWatchdogScrapeDeadline(t *testing.T) {
+	ctx := context.Background()
+	list := buildTestPods(3)
+	cli := fake.NewClientBuilder().WithLists(list).Build()
+
+	c := newTestConcurrencyWatchdog()
+	c.clientset = fakecgo.NewSimpleClientset()
+	c.client = cli
+	c.scrapeTimeout = 50 * time.Millisecond
+	c.listWatchdogTargets = func() ([]WatchdogTarget, error) {
+		return []WatchdogTarget{{
+			ScrapeFn: func(ctx context.Context, _ rest.Interface, pod *corev1.Pod) (float64, error) {
+				if pod.Name != "pod-1" {
+					return 1, nil
+				}
+				<-ctx.Done() // a pod that never answers
+				return 0, ctx.Err()
+			},
+			PodLabels: testLabelGetter{}.PodLabels(),
+		}}, nil
+	}
+
+	start := time.Now()
+	err := c.tick(ctx)
+	require.ErrorContains(t, err, `scraping pod "pod-1": context deadline exceeded`)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
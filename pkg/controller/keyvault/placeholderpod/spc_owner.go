@@ -104,7 +104,7 @@ func getIngressSpcOwner(ingressManager util.IngressManager, cfg *config.Config)
 		ownerNameAnnotation: ingressOwnerAnnotation,
 		namespace:           func(spc *secv1.SecretProviderClass) string { return spc.Namespace },
 		shouldReconcile: func(spc *secv1.SecretProviderClass, ing *netv1.Ingress) (bool, error) {
-			managed, err := spcpkg.ShouldReconcileIngress(ingressManager, ing)
+			managed, err := spcpkg.ShouldReconcileIngressSpc(ingressManager, ing, spc.Name)
 			if err != nil {
 				return false, fmt.Errorf("determining if ingress is managed: %w", err)
 			}
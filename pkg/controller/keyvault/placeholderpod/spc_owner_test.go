@@ -10,6 +10,7 @@ import (
 
 	"github.com/Azure/aks-app-routing-operator/api/v1alpha1"
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	spcpkg "github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/spc"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -718,6 +719,106 @@ func TestGetIngressSpcOwner(t *testing.T) {
 			isManaged:     true,
 			wantReconcile: true,
 		},
+		{
+			name: "managed ingress, keyvault secrets spc with keyvault secrets annotation",
+			ingress: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngress,
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						spcpkg.KeyVaultSecretsAnnotation: "auth=https://kv.vault.azure.net/secrets/auth",
+					},
+				},
+			},
+			spc: &secv1.SecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kv-secrets-" + testIngress,
+					Namespace: testNamespace,
+				},
+			},
+			isManaged:     true,
+			wantReconcile: true,
+		},
+		{
+			name: "managed ingress, keyvault secrets spc with only the certificate annotation",
+			ingress: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngress,
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						"kubernetes.azure.com/tls-cert-keyvault-uri": "https://kv.vault.azure.net/secrets/cert",
+					},
+				},
+			},
+			spc: &secv1.SecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kv-secrets-" + testIngress,
+					Namespace: testNamespace,
+				},
+			},
+			isManaged:     true,
+			wantReconcile: false,
+		},
+		{
+			name: "managed ingress, certificate spc with only the keyvault secrets annotation",
+			ingress: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngress,
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						spcpkg.KeyVaultSecretsAnnotation: "auth=https://kv.vault.azure.net/secrets/auth",
+					},
+				},
+			},
+			spc: &secv1.SecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "keyvault-" + testIngress,
+					Namespace: testNamespace,
+				},
+			},
+			isManaged:     true,
+			wantReconcile: false,
+		},
+		{
+			name: "managed ingress, spc of a host that was removed",
+			ingress: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngress,
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						spcpkg.KeyVaultHostUrisAnnotation: "api.contoso.com=https://kv.vault.azure.net/certificates/api",
+					},
+				},
+			},
+			spc: &secv1.SecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      spcpkg.GetIngressHostSpcName(&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: testIngress}}, "www.contoso.com"),
+					Namespace: testNamespace,
+				},
+			},
+			isManaged:     true,
+			wantReconcile: false,
+		},
+		{
+			name: "managed ingress, spc of a host",
+			ingress: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngress,
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						spcpkg.KeyVaultHostUrisAnnotation: "api.contoso.com=https://kv.vault.azure.net/certificates/api",
+					},
+				},
+			},
+			spc: &secv1.SecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      spcpkg.GetIngressHostSpcName(&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: testIngress}}, "api.contoso.com"),
+					Namespace: testNamespace,
+				},
+			},
+			isManaged:     true,
+			wantReconcile: true,
+		},
 		{
 			name: "should not reconcile unmanaged ingress",
 			ingress: &netv1.Ingress{
@@ -6,6 +6,13 @@ const (
 	keyVaultUriKey           = "kubernetes.azure.com/tls-cert-keyvault-uri"
 	certUriTLSOption         = keyVaultUriKey
 	tlsCertManagedAnnotation = "kubernetes.azure.com/tls-cert-keyvault-managed"
+	// KeyVaultHostUrisAnnotation is the annotation of comma separated host=uri pairs of Keyvault certificates, each host gets its own
+	// certificate, e.g. api.contoso.com=https://myvault.vault.azure.net/certificates/api. It can't be combined with keyVaultUriKey.
+	KeyVaultHostUrisAnnotation = "kubernetes.azure.com/tls-cert-keyvault-host-uris"
+	// KeyVaultSecretsAnnotation is the annotation of comma separated key=uri pairs of Keyvault secrets synced into an Opaque secret
+	// for the Ingress, e.g. auth=https://myvault.vault.azure.net/secrets/basic-auth. The secret is named by GetIngressSecretsSecretName
+	// and can be referenced by nginx annotations like nginx.ingress.kubernetes.io/auth-secret.
+	KeyVaultSecretsAnnotation = "kubernetes.azure.com/keyvault-secret-uris"
 	// IngressServiceAccountTLSAnnotation is the annotation used to specify the TLS workload identity sa
 	IngressServiceAccountTLSAnnotation = util.ServiceAccountTLSOption
 )
@@ -2,6 +2,8 @@ package spc
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"iter"
@@ -13,6 +15,7 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/go-logr/logr"
 	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
@@ -85,7 +88,15 @@ func ingressToSpcOpts(ctx context.Context, cl client.Client, conf *config.Config
 
 		if !reconcile {
 			opts.action = actionCleanup
-			yield(opts, nil)
+			if !yield(opts, nil) {
+				return
+			}
+			yieldIngressHostCleanup(ctx, cl, ing, nil, yield)
+			return
+		}
+
+		if _, ok := ing.Annotations[KeyVaultHostUrisAnnotation]; ok {
+			ingressHostsToSpcOpts(ctx, cl, ing, opts, yield)
 			return
 		}
 
@@ -96,15 +107,9 @@ func ingressToSpcOpts(ctx context.Context, cl client.Client, conf *config.Config
 			return
 		}
 
-		if sa := ing.Annotations[IngressServiceAccountTLSAnnotation]; sa != "" {
-			clientId, err := util.GetServiceAccountWorkloadIdentityClientId(ctx, cl, sa, ing.Namespace)
-			if err != nil {
-				yield(opts, err)
-				return
-			}
-
-			opts.clientId = clientId
-			opts.workloadIdentity = true
+		if opts, err = withIngressWorkloadIdentity(ctx, cl, ing, opts); err != nil {
+			yield(opts, err)
+			return
 		}
 
 		opts.vaultName = certRef.vaultName
@@ -117,7 +122,117 @@ func ingressToSpcOpts(ctx context.Context, cl client.Client, conf *config.Config
 			}
 		}
 
-		yield(opts, nil)
+		if !yield(opts, nil) {
+			return
+		}
+		yieldIngressHostCleanup(ctx, cl, ing, nil, yield)
+	}
+}
+
+// ingressHostsToSpcOpts yields a SecretProviderClass for the certificate of each host of the KeyVaultHostUrisAnnotation. The
+// SecretProviderClasses of the single certificate annotation and of hosts that were removed are cleaned up. opts has the fields
+// shared by every host.
+func ingressHostsToSpcOpts(ctx context.Context, cl client.Client, ing *netv1.Ingress, opts spcOpts, yield func(spcOpts, error) bool) {
+	if err := checkCertAnnotationConflict(ing); err != nil {
+		yield(spcOpts{}, err)
+		return
+	}
+
+	hostCerts, err := parseKeyVaultHostCertURIs(ing.Annotations[KeyVaultHostUrisAnnotation])
+	if err != nil {
+		yield(spcOpts{}, err)
+		return
+	}
+
+	if opts, err = withIngressWorkloadIdentity(ctx, cl, ing, opts); err != nil {
+		yield(opts, err)
+		return
+	}
+
+	cleanup := opts
+	cleanup.action = actionCleanup
+	if !yield(cleanup, nil) {
+		return
+	}
+
+	var tls []netv1.IngressTLS
+	keep := map[string]bool{}
+	for _, hostCert := range hostCerts {
+		name := GetIngressHostSpcName(ing, hostCert.host)
+		tls = append(tls, netv1.IngressTLS{Hosts: []string{hostCert.host}, SecretName: name})
+		keep[name] = true
+	}
+
+	for _, hostCert := range hostCerts {
+		hostOpts := opts
+		hostOpts.name = GetIngressHostSpcName(ing, hostCert.host)
+		hostOpts.secretName = hostOpts.name
+		hostOpts.vaultName = hostCert.ref.vaultName
+		hostOpts.certName = hostCert.ref.certName
+		hostOpts.objectVersion = hostCert.ref.objectVersion
+
+		if strings.ToLower(ing.Annotations[tlsCertManagedAnnotation]) == "true" {
+			hostOpts.modifyOwner = func(obj client.Object) error {
+				return setTlsRefs(obj, tls)
+			}
+		}
+
+		if !yield(hostOpts, nil) {
+			return
+		}
+	}
+
+	yieldIngressHostCleanup(ctx, cl, ing, keep, yield)
+}
+
+// checkCertAnnotationConflict returns a user error if the ingress has both the single certificate and the per host certificates
+// annotation
+func checkCertAnnotationConflict(ing *netv1.Ingress) error {
+	_, hasUri := ing.Annotations[keyVaultUriKey]
+	_, hasHostUris := ing.Annotations[KeyVaultHostUrisAnnotation]
+	if hasUri && hasHostUris {
+		return util.NewUserError(
+			fmt.Errorf("both %s and %s are set", keyVaultUriKey, KeyVaultHostUrisAnnotation),
+			fmt.Sprintf("only one of the %s and %s annotations can be set", keyVaultUriKey, KeyVaultHostUrisAnnotation),
+		)
+	}
+
+	return nil
+}
+
+// withIngressWorkloadIdentity returns opts using the workload identity of the ingress's service account annotation if it has one
+func withIngressWorkloadIdentity(ctx context.Context, cl client.Client, ing *netv1.Ingress, opts spcOpts) (spcOpts, error) {
+	sa := ing.Annotations[IngressServiceAccountTLSAnnotation]
+	if sa == "" {
+		return opts, nil
+	}
+
+	clientId, err := util.GetServiceAccountWorkloadIdentityClientId(ctx, cl, sa, ing.Namespace)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.clientId = clientId
+	opts.workloadIdentity = true
+	return opts, nil
+}
+
+// yieldIngressHostCleanup yields cleanup of the host SecretProviderClasses owned by the ingress that aren't in keep
+func yieldIngressHostCleanup(ctx context.Context, cl client.Client, ing *netv1.Ingress, keep map[string]bool, yield func(spcOpts, error) bool) {
+	spcs := &secv1.SecretProviderClassList{}
+	if err := cl.List(ctx, spcs, client.InNamespace(ing.Namespace)); err != nil {
+		yield(spcOpts{}, fmt.Errorf("listing SecretProviderClasses: %w", err))
+		return
+	}
+
+	for _, spc := range spcs.Items {
+		if keep[spc.Name] || !isIngressHostSpc(ing, &spc) {
+			continue
+		}
+
+		if !yield(spcOpts{action: actionCleanup, name: spc.Name, namespace: spc.Namespace}, nil) {
+			return
+		}
 	}
 }
 
@@ -136,13 +251,48 @@ func ShouldReconcileIngress(ingressManager util.IngressManager, ing *netv1.Ingre
 		return false, nil
 	}
 
-	if _, ok := ing.Annotations[keyVaultUriKey]; !ok {
+	_, hasUri := ing.Annotations[keyVaultUriKey]
+	_, hasHostUris := ing.Annotations[KeyVaultHostUrisAnnotation]
+	if !hasUri && !hasHostUris {
 		return false, nil
 	}
 
 	return isManaged, nil
 }
 
+// ShouldReconcileIngressSpc checks if the SecretProviderClass named spcName should be reconciled for the ingress that owns it
+func ShouldReconcileIngressSpc(ingressManager util.IngressManager, ing *netv1.Ingress, spcName string) (bool, error) {
+	if spcName == GetIngressSecretsSpcName(ing) {
+		return ShouldReconcileIngressSecrets(ingressManager, ing)
+	}
+
+	reconcile, err := ShouldReconcileIngress(ingressManager, ing)
+	if err != nil || !reconcile {
+		return false, err
+	}
+
+	if !strings.HasPrefix(spcName, ingressHostSpcPrefix(ing)) {
+		return true, nil
+	}
+
+	if _, ok := ing.Annotations[KeyVaultHostUrisAnnotation]; !ok {
+		return false, nil
+	}
+
+	hostCerts, err := parseKeyVaultHostCertURIs(ing.Annotations[KeyVaultHostUrisAnnotation])
+	if err != nil {
+		return false, fmt.Errorf("parsing Keyvault host certificates: %w", err)
+	}
+
+	for _, hostCert := range hostCerts {
+		if spcName == GetIngressHostSpcName(ing, hostCert.host) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 var getIngressCertSecretName = getIngressSpcName
 
 func getIngressSpcName(ing *netv1.Ingress) string {
@@ -158,6 +308,38 @@ func getIngressSpcName(ing *netv1.Ingress) string {
 	return ret
 }
 
+// GetIngressHostSpcName returns the name of the SecretProviderClass and secret of the certificate of a host of the ingress. The hash
+// of the ingress and host keeps names of different ingresses and hosts apart once the ingress name is truncated. Names fit in a label
+// value since the placeholder pod Deployment is labeled with them.
+func GetIngressHostSpcName(ing *netv1.Ingress, host string) string {
+	if ing == nil {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(ing.Name + "/" + host))
+	return ingressHostSpcPrefix(ing) + hex.EncodeToString(sum[:4])
+}
+
+func ingressHostSpcPrefix(ing *netv1.Ingress) string {
+	// leave room for the hash
+	name := ing.Name
+	if maxLen := 63 - len("kv-host-cert--") - 8; len(name) > maxLen {
+		name = name[:maxLen]
+	}
+
+	return "kv-host-cert-" + name + "-"
+}
+
+// isIngressHostSpc returns whether spc is a host SecretProviderClass of the ingress
+func isIngressHostSpc(ing *netv1.Ingress, spc *secv1.SecretProviderClass) bool {
+	owner := metav1.GetControllerOf(spc)
+	if owner == nil || owner.Kind != "Ingress" || owner.UID != ing.UID {
+		return false
+	}
+
+	return strings.HasPrefix(spc.Name, ingressHostSpcPrefix(ing))
+}
+
 func addTlsRef(obj client.Object, secretName string) error {
 	ingress, ok := obj.(*netv1.Ingress)
 	if !ok {
@@ -180,3 +362,14 @@ func addTlsRef(obj client.Object, secretName string) error {
 
 	return nil
 }
+
+// setTlsRefs replaces the TLS entries of the ingress with tls
+func setTlsRefs(obj client.Object, tls []netv1.IngressTLS) error {
+	ingress, ok := obj.(*netv1.Ingress)
+	if !ok {
+		return fmt.Errorf("object is not an Ingress: %T", obj)
+	}
+
+	ingress.Spec.TLS = tls
+	return nil
+}
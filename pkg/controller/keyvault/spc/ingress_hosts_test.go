@@ -0,0 +1,271 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package spc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+const ingressTestHostUris = "www.fabrikam.com=https://other-vault.vault.azure.net/certificates/www/v2,api.contoso.com=https://test-vault.vault.azure.net/certificates/api"
+
+func hostTestIngress(annotations map[string]string) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressTestIngressName,
+			Namespace:   ingressTestNamespace,
+			UID:         types.UID("ingress-uid"),
+			Annotations: annotations,
+		},
+	}
+}
+
+// hostTestSpc returns a SecretProviderClass controlled by the object with uid
+func hostTestSpc(name string, uid types.UID) *secv1.SecretProviderClass {
+	return &secv1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ingressTestNamespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "networking.k8s.io/v1",
+				Kind:       "Ingress",
+				Name:       ingressTestIngressName,
+				UID:        uid,
+				Controller: util.ToPtr(true),
+			}},
+		},
+	}
+}
+
+func hostTestClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, secv1.AddToScheme(scheme))
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func collectSpcOpts(t *testing.T, seq func(func(spcOpts, error) bool)) ([]spcOpts, error) {
+	t.Helper()
+
+	var got []spcOpts
+	for opts, err := range seq {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, opts)
+	}
+	return got, nil
+}
+
+func TestIngressHostsToSpcOpts(t *testing.T) {
+	conf := &config.Config{MSIClientID: ingressTestClientID, TenantID: ingressTestTenantID, Cloud: ingressTestCloud}
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+
+	ing := hostTestIngress(map[string]string{KeyVaultHostUrisAnnotation: ingressTestHostUris, tlsCertManagedAnnotation: "true"})
+	apiName := GetIngressHostSpcName(ing, "api.contoso.com")
+	wwwName := GetIngressHostSpcName(ing, "www.fabrikam.com")
+	removedName := GetIngressHostSpcName(ing, "old.contoso.com")
+
+	cl := hostTestClient(t,
+		hostTestSpc(apiName, ing.UID),
+		hostTestSpc(removedName, ing.UID),
+		// same prefix but owned by another ingress
+		hostTestSpc(GetIngressHostSpcName(ing, "other.contoso.com"), types.UID("other-uid")),
+		// the secrets of the ingress aren't host certificates
+		hostTestSpc(GetIngressSecretsSpcName(ing), ing.UID),
+	)
+
+	got, err := collectSpcOpts(t, ingressToSpcOpts(context.Background(), cl, conf, ing, managed))
+	require.NoError(t, err)
+	require.Len(t, got, 4)
+
+	assert.Equal(t, actionCleanup, got[0].action, "the single certificate is cleaned up")
+	assert.Equal(t, getIngressSpcName(ing), got[0].name)
+
+	assert.Equal(t, actionReconcile, got[1].action)
+	assert.Equal(t, apiName, got[1].name)
+	assert.Equal(t, apiName, got[1].secretName)
+	assert.Equal(t, ingressTestVaultName, got[1].vaultName)
+	assert.Equal(t, "api", got[1].certName)
+	assert.Empty(t, got[1].objectVersion)
+	assert.Equal(t, ingressTestClientID, got[1].clientId)
+
+	assert.Equal(t, actionReconcile, got[2].action)
+	assert.Equal(t, wwwName, got[2].name)
+	assert.Equal(t, "other-vault", got[2].vaultName)
+	assert.Equal(t, "www", got[2].certName)
+	assert.Equal(t, "v2", got[2].objectVersion)
+
+	assert.Equal(t, spcOpts{action: actionCleanup, name: removedName, namespace: ingressTestNamespace}, got[3], "removed hosts are cleaned up")
+
+	// every host sets the same tls entries
+	for _, opts := range got[1:3] {
+		owner := hostTestIngress(nil)
+		owner.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{"stale.contoso.com"}, SecretName: "stale"}}
+		require.NotNil(t, opts.modifyOwner)
+		require.NoError(t, opts.modifyOwner(owner))
+		assert.Equal(t, []netv1.IngressTLS{
+			{Hosts: []string{"api.contoso.com"}, SecretName: apiName},
+			{Hosts: []string{"www.fabrikam.com"}, SecretName: wwwName},
+		}, owner.Spec.TLS)
+	}
+}
+
+func TestIngressHostsToSpcOptsCleanup(t *testing.T) {
+	conf := &config.Config{MSIClientID: ingressTestClientID, TenantID: ingressTestTenantID}
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+	ing := hostTestIngress(map[string]string{keyVaultUriKey: ingressTestKVUriPublic})
+	hostName := GetIngressHostSpcName(ing, "api.contoso.com")
+	cl := hostTestClient(t, hostTestSpc(hostName, ing.UID))
+
+	t.Run("single certificate cleans up every host", func(t *testing.T) {
+		got, err := collectSpcOpts(t, ingressToSpcOpts(context.Background(), cl, conf, ing, managed))
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, actionReconcile, got[0].action)
+		assert.Equal(t, getIngressSpcName(ing), got[0].name)
+		assert.Equal(t, spcOpts{action: actionCleanup, name: hostName, namespace: ingressTestNamespace}, got[1])
+	})
+
+	t.Run("ingress that's no longer managed cleans up every host", func(t *testing.T) {
+		unmanaged := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, nil })
+		got, err := collectSpcOpts(t, ingressToSpcOpts(context.Background(), cl, conf, ing, unmanaged))
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, actionCleanup, got[0].action)
+		assert.Equal(t, hostName, got[1].name)
+		assert.Equal(t, actionCleanup, got[1].action)
+	})
+}
+
+func TestIngressHostsToSpcOptsErrors(t *testing.T) {
+	conf := &config.Config{MSIClientID: ingressTestClientID, TenantID: ingressTestTenantID}
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectedErr string
+	}{
+		{
+			name:        "both annotations",
+			annotations: map[string]string{keyVaultUriKey: ingressTestKVUriPublic, KeyVaultHostUrisAnnotation: ingressTestHostUris},
+			expectedErr: "only one of the kubernetes.azure.com/tls-cert-keyvault-uri and kubernetes.azure.com/tls-cert-keyvault-host-uris annotations can be set",
+		},
+		{
+			name:        "invalid uri",
+			annotations: map[string]string{KeyVaultHostUrisAnnotation: "api.contoso.com=" + ingressTestInvalidUri},
+			expectedErr: "invalid Keyvault certificate URI for host api.contoso.com: " + ingressTestInvalidUri,
+		},
+		{
+			name:        "missing service account",
+			annotations: map[string]string{KeyVaultHostUrisAnnotation: ingressTestHostUris, IngressServiceAccountTLSAnnotation: "missing"},
+			expectedErr: "serviceAccount missing does not exist in namespace " + ingressTestNamespace,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := collectSpcOpts(t, ingressToSpcOpts(context.Background(), hostTestClient(t), conf, hostTestIngress(tc.annotations), managed))
+			require.Empty(t, got, "nothing is reconciled or cleaned up")
+			var userErr util.UserError
+			require.ErrorAs(t, err, &userErr)
+			assert.Equal(t, tc.expectedErr, userErr.UserError())
+		})
+	}
+}
+
+func TestParseKeyVaultHostCertURIs(t *testing.T) {
+	got, err := parseKeyVaultHostCertURIs(ingressTestHostUris + ", *.contoso.com=https://test-vault.vault.azure.net/certificates/wildcard")
+	require.NoError(t, err)
+	assert.Equal(t, []hostCertificate{
+		{host: "*.contoso.com", ref: certReference{vaultName: ingressTestVaultName, certName: "wildcard"}},
+		{host: "api.contoso.com", ref: certReference{vaultName: ingressTestVaultName, certName: "api"}},
+		{host: "www.fabrikam.com", ref: certReference{vaultName: "other-vault", certName: "www", objectVersion: "v2"}},
+	}, got)
+
+	for value, expectedErr := range map[string]string{
+		"":                                     "no certificates",
+		"api.contoso.com":                      "isn't in the key=uri format",
+		"Not_A_Host=" + ingressTestKVUriPublic: `invalid host "Not_A_Host"`,
+		"a.com=" + ingressTestKVUriPublic + ",a.com=" + ingressTestKVUriPublic: `duplicate key "a.com"`,
+	} {
+		_, err := parseKeyVaultHostCertURIs(value)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), expectedErr)
+	}
+}
+
+func TestGetIngressHostSpcName(t *testing.T) {
+	ing := hostTestIngress(nil)
+	assert.Equal(t, "", GetIngressHostSpcName(nil, "api.contoso.com"))
+
+	name := GetIngressHostSpcName(ing, "api.contoso.com")
+	assert.True(t, strings.HasPrefix(name, "kv-host-cert-"+ingressTestIngressName+"-"))
+	assert.Equal(t, name, GetIngressHostSpcName(ing, "api.contoso.com"), "names are stable")
+	assert.NotEqual(t, name, GetIngressHostSpcName(ing, "www.contoso.com"))
+	assert.NotEqual(t, name, getIngressSpcName(ing))
+
+	// a wildcard host still makes a valid name
+	assert.NotContains(t, GetIngressHostSpcName(ing, "*.contoso.com"), "*")
+
+	// names fit in the app label of the placeholder pod Deployment
+	long := hostTestIngress(nil)
+	long.Name = strings.Repeat("a", 253)
+	assert.Len(t, GetIngressHostSpcName(long, "api.contoso.com"), 63)
+	assert.NotEqual(t, GetIngressHostSpcName(long, "api.contoso.com"), GetIngressHostSpcName(long, "www.contoso.com"), "the hash survives truncation")
+
+	longer := hostTestIngress(nil)
+	longer.Name = long.Name[:252] + "b"
+	assert.NotEqual(t, GetIngressHostSpcName(long, "api.contoso.com"), GetIngressHostSpcName(longer, "api.contoso.com"), "truncated ingress names stay apart")
+}
+
+func TestShouldReconcileIngressSpc(t *testing.T) {
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+	hosts := hostTestIngress(map[string]string{KeyVaultHostUrisAnnotation: ingressTestHostUris})
+	single := hostTestIngress(map[string]string{keyVaultUriKey: ingressTestKVUriPublic})
+	secrets := hostTestIngress(map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs})
+
+	cases := []struct {
+		name    string
+		ing     *netv1.Ingress
+		spcName string
+		want    bool
+	}{
+		{name: "current host", ing: hosts, spcName: GetIngressHostSpcName(hosts, "api.contoso.com"), want: true},
+		{name: "removed host", ing: hosts, spcName: GetIngressHostSpcName(hosts, "old.contoso.com"), want: false},
+		{name: "host with the single certificate", ing: single, spcName: GetIngressHostSpcName(single, "api.contoso.com"), want: false},
+		{name: "single certificate", ing: single, spcName: getIngressSpcName(single), want: true},
+		{name: "secrets", ing: secrets, spcName: GetIngressSecretsSpcName(secrets), want: true},
+		{name: "secrets without the annotation", ing: single, spcName: GetIngressSecretsSpcName(single), want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ShouldReconcileIngressSpc(managed, tc.ing, tc.spcName)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := ShouldReconcileIngressSpc(util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, errors.New("boom") }), hosts, "name")
+	require.Error(t, err)
+}
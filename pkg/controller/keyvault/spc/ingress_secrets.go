@@ -0,0 +1,133 @@
+package spc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	netv1 "k8s.io/api/networking/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+var ingressSecretsProviderControllerName = controllername.New("keyvault", "ingress", "secrets", "provider")
+
+// NewIngressSecretsProviderClassReconciler syncs the Keyvault secrets of the KeyVaultSecretsAnnotation of Ingresses into Opaque secrets
+func NewIngressSecretsProviderClassReconciler(manager ctrl.Manager, conf *config.Config, ingressManager util.IngressManager) error {
+	metrics.InitControllerMetrics(ingressSecretsProviderControllerName)
+	if conf.DisableKeyvault {
+		return nil
+	}
+
+	spcReconciler := &secretProviderClassReconciler[*netv1.Ingress]{
+		name: ingressSecretsProviderControllerName,
+		toSpcOpts: func(ctx context.Context, cl client.Client, ing *netv1.Ingress) iter.Seq2[spcOpts, error] {
+			return ingressToSecretsSpcOpts(ctx, cl, conf, ing, ingressManager)
+		},
+		client: manager.GetClient(),
+		events: manager.GetEventRecorderFor("aks-app-routing-operator"),
+		config: conf,
+	}
+
+	return ingressSecretsProviderControllerName.AddToController(
+		ctrl.
+			NewControllerManagedBy(manager).
+			For(&netv1.Ingress{}).
+			Owns(&secv1.SecretProviderClass{}),
+		manager.GetLogger(),
+	).Complete(spcReconciler)
+}
+
+func ingressToSecretsSpcOpts(ctx context.Context, cl client.Client, conf *config.Config, ing *netv1.Ingress, ingressManager util.IngressManager) iter.Seq2[spcOpts, error] {
+	return func(yield func(spcOpts, error) bool) {
+		if conf == nil {
+			yield(spcOpts{}, errors.New("config is nil"))
+			return
+		}
+
+		if ing == nil {
+			yield(spcOpts{}, errors.New("ingress is nil"))
+			return
+		}
+
+		opts := spcOpts{
+			action:     actionReconcile,
+			name:       GetIngressSecretsSpcName(ing),
+			namespace:  ing.GetNamespace(),
+			clientId:   conf.MSIClientID,
+			tenantId:   conf.TenantID,
+			secretName: GetIngressSecretsSecretName(ing),
+			cloud:      conf.Cloud,
+		}
+
+		reconcile, err := ShouldReconcileIngressSecrets(ingressManager, ing)
+		if err != nil {
+			yield(spcOpts{}, fmt.Errorf("checking if ingress is managed: %w", err))
+			return
+		}
+
+		if !reconcile {
+			opts.action = actionCleanup
+			yield(opts, nil)
+			return
+		}
+
+		secrets, err := parseKeyVaultSecretURIs(ing.Annotations[KeyVaultSecretsAnnotation])
+		if err != nil {
+			yield(spcOpts{}, err)
+			return
+		}
+
+		if opts, err = withIngressWorkloadIdentity(ctx, cl, ing, opts); err != nil {
+			yield(opts, err)
+			return
+		}
+
+		opts.vaultName = secrets[0].ref.vaultName
+		opts.secrets = secrets
+
+		yield(opts, nil)
+	}
+}
+
+// ShouldReconcileIngressSecrets checks if the Keyvault secrets of the ingress should be reconciled
+func ShouldReconcileIngressSecrets(ingressManager util.IngressManager, ing *netv1.Ingress) (bool, error) {
+	if ing == nil {
+		return false, fmt.Errorf("ingress is nil")
+	}
+
+	isManaged, err := ingressManager.IsManaging(ing)
+	if err != nil {
+		return false, fmt.Errorf("checking if ingress %s is managed: %w", ing.Name, err)
+	}
+
+	if _, ok := ing.Annotations[KeyVaultSecretsAnnotation]; !ok {
+		return false, nil
+	}
+
+	return isManaged, nil
+}
+
+// GetIngressSecretsSecretName returns the name of the Opaque secret the Keyvault secrets of the ingress are synced into
+var GetIngressSecretsSecretName = GetIngressSecretsSpcName
+
+// GetIngressSecretsSpcName returns the name of the SecretProviderClass for the Keyvault secrets of the ingress. The prefix differs
+// from the certificate's so the names can't collide.
+func GetIngressSecretsSpcName(ing *netv1.Ingress) string {
+	if ing == nil {
+		return ""
+	}
+
+	ret := "kv-secrets-" + ing.Name
+	if len(ret) > 253 {
+		ret = ret[:253]
+	}
+
+	return ret
+}
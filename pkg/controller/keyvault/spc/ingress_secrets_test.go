@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package spc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const ingressTestSecretURIs = "auth=https://test-vault.vault.azure.net/secrets/basic-auth,ca.crt=https://test-vault.vault.azure.net/secrets/client-ca/v1"
+
+func TestIngressToSecretsSpcOpts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, netv1.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressTestServiceAccount,
+			Namespace:   ingressTestNamespace,
+			Annotations: map[string]string{util.WiSaClientIdAnnotation: ingressTestWIClientID},
+		},
+	}).Build()
+
+	conf := &config.Config{MSIClientID: ingressTestClientID, TenantID: ingressTestTenantID, Cloud: ingressTestCloud}
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+	unmanaged := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, nil })
+
+	ingress := func(annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: ingressTestIngressName, Namespace: ingressTestNamespace, Annotations: annotations}}
+	}
+
+	cases := []struct {
+		name           string
+		ing            *netv1.Ingress
+		ingressManager util.IngressManager
+		expected       spcOpts
+		expectedErr    string
+	}{
+		{
+			name:           "secrets",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs}),
+			ingressManager: managed,
+			expected: spcOpts{
+				action:     actionReconcile,
+				name:       "kv-secrets-" + ingressTestIngressName,
+				namespace:  ingressTestNamespace,
+				clientId:   ingressTestClientID,
+				tenantId:   ingressTestTenantID,
+				vaultName:  ingressTestVaultName,
+				secretName: "kv-secrets-" + ingressTestIngressName,
+				cloud:      ingressTestCloud,
+				secrets: []keyvaultSecret{
+					{key: "auth", ref: certReference{vaultName: ingressTestVaultName, certName: "basic-auth"}},
+					{key: "ca.crt", ref: certReference{vaultName: ingressTestVaultName, certName: "client-ca", objectVersion: "v1"}},
+				},
+			},
+		},
+		{
+			name:           "secrets with workload identity",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "auth=https://test-vault.vault.azure.net/secrets/basic-auth", IngressServiceAccountTLSAnnotation: ingressTestServiceAccount}),
+			ingressManager: managed,
+			expected: spcOpts{
+				action:           actionReconcile,
+				name:             "kv-secrets-" + ingressTestIngressName,
+				namespace:        ingressTestNamespace,
+				clientId:         ingressTestWIClientID,
+				tenantId:         ingressTestTenantID,
+				vaultName:        ingressTestVaultName,
+				secretName:       "kv-secrets-" + ingressTestIngressName,
+				cloud:            ingressTestCloud,
+				workloadIdentity: true,
+				secrets: []keyvaultSecret{
+					{key: "auth", ref: certReference{vaultName: ingressTestVaultName, certName: "basic-auth"}},
+				},
+			},
+		},
+		{
+			name:           "certificate only is cleaned up",
+			ing:            ingress(map[string]string{keyVaultUriKey: ingressTestKVUriPublic}),
+			ingressManager: managed,
+			expected: spcOpts{
+				action:     actionCleanup,
+				name:       "kv-secrets-" + ingressTestIngressName,
+				namespace:  ingressTestNamespace,
+				clientId:   ingressTestClientID,
+				tenantId:   ingressTestTenantID,
+				secretName: "kv-secrets-" + ingressTestIngressName,
+				cloud:      ingressTestCloud,
+			},
+		},
+		{
+			name:           "unmanaged ingress is cleaned up",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs}),
+			ingressManager: unmanaged,
+			expected: spcOpts{
+				action:     actionCleanup,
+				name:       "kv-secrets-" + ingressTestIngressName,
+				namespace:  ingressTestNamespace,
+				clientId:   ingressTestClientID,
+				tenantId:   ingressTestTenantID,
+				secretName: "kv-secrets-" + ingressTestIngressName,
+				cloud:      ingressTestCloud,
+			},
+		},
+		{
+			name:           "invalid secrets",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "auth=" + ingressTestInvalidUri}),
+			ingressManager: managed,
+			expectedErr:    "invalid Keyvault secret URI: " + ingressTestInvalidUri,
+		},
+		{
+			name:           "missing service account",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs, IngressServiceAccountTLSAnnotation: "missing"}),
+			ingressManager: managed,
+			expectedErr:    "serviceAccount missing does not exist in namespace " + ingressTestNamespace,
+		},
+		{
+			name:           "ingress manager error",
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs}),
+			ingressManager: util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, errors.New("boom") }),
+			expectedErr:    "boom",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []spcOpts
+			for opts, err := range ingressToSecretsSpcOpts(context.Background(), cl, conf, tc.ing, tc.ingressManager) {
+				if tc.expectedErr != "" {
+					require.Error(t, err)
+					var userErr util.UserError
+					if errors.As(err, &userErr) {
+						assert.Contains(t, userErr.UserError(), tc.expectedErr)
+					} else {
+						assert.Contains(t, err.Error(), tc.expectedErr)
+					}
+					return
+				}
+
+				require.NoError(t, err)
+				got = append(got, opts)
+			}
+
+			require.Empty(t, tc.expectedErr, "expected an error")
+			require.Len(t, got, 1)
+			assert.Equal(t, tc.expected, got[0])
+		})
+	}
+}
+
+func TestShouldReconcileIngressSecrets(t *testing.T) {
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+
+	_, err := ShouldReconcileIngressSecrets(managed, nil)
+	require.Error(t, err)
+
+	reconcile, err := ShouldReconcileIngressSecrets(managed, &netv1.Ingress{})
+	require.NoError(t, err)
+	assert.False(t, reconcile, "no annotations")
+
+	ing := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{KeyVaultSecretsAnnotation: ingressTestSecretURIs}}}
+	reconcile, err = ShouldReconcileIngressSecrets(managed, ing)
+	require.NoError(t, err)
+	assert.True(t, reconcile)
+
+	reconcile, err = ShouldReconcileIngressSecrets(util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, nil }), ing)
+	require.NoError(t, err)
+	assert.False(t, reconcile, "unmanaged ingress")
+}
+
+func TestGetIngressSecretsSpcName(t *testing.T) {
+	assert.Equal(t, "", GetIngressSecretsSpcName(nil))
+	assert.Equal(t, "kv-secrets-ingress", GetIngressSecretsSpcName(&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ingress"}}))
+	assert.Len(t, GetIngressSecretsSpcName(&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 253)}}), 253)
+
+	// the certificate's SecretProviderClass of another ingress can't share the name
+	ing := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	other := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "secrets-app"}}
+	assert.NotEqual(t, GetIngressSecretsSpcName(ing), getIngressSpcName(other))
+	assert.Equal(t, GetIngressSecretsSpcName(ing), GetIngressSecretsSecretName(ing))
+}
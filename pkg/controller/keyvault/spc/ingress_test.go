@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	secv1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 )
 
 const (
@@ -45,6 +46,7 @@ func TestIngressToSpcOpts(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, corev1.AddToScheme(scheme))
 	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, secv1.AddToScheme(scheme))
 
 	// Create test service accounts
 	validServiceAccount := &corev1.ServiceAccount{
@@ -672,7 +674,10 @@ func TestAddTlsRef(t *testing.T) {
 }
 
 func TestModifyOwner(t *testing.T) {
-	client := fake.NewClientBuilder().Build()
+	scheme := runtime.NewScheme()
+	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, secv1.AddToScheme(scheme))
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
 
 	conf := &config.Config{
 		MSIClientID: ingressTestClientID,
@@ -718,6 +723,7 @@ func TestIngressAnnotationLogging(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, corev1.AddToScheme(scheme))
 	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, secv1.AddToScheme(scheme))
 
 	conf := &config.Config{
 		MSIClientID: ingressTestClientID,
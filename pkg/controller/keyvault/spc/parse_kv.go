@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type certReference struct {
@@ -47,3 +49,108 @@ func parseKeyVaultCertURI(certURI string) (certReference, error) {
 		objectVersion: objectVersion,
 	}, nil
 }
+
+// keyvaultSecret is a Keyvault secret synced into a Kubernetes secret under key
+type keyvaultSecret struct {
+	// key is the key of the secret in the Kubernetes secret
+	key string
+	// ref is the secret in Keyvault, certName is the name of the secret
+	ref certReference
+}
+
+// parseKeyVaultSecretURIs parses a comma separated list of key=uri pairs of Keyvault secrets. Secrets are sorted by key, every secret
+// must be in the same Keyvault since a SecretProviderClass reads from a single Keyvault.
+func parseKeyVaultSecretURIs(secretURIs string) ([]keyvaultSecret, error) {
+	pairs, err := splitKeyURIPairs(secretURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []keyvaultSecret
+	for _, pair := range pairs {
+		key, secretURI := pair[0], pair[1]
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, util.NewUserError(fmt.Errorf("invalid secret key %q: %s", key, strings.Join(errs, ", ")), fmt.Sprintf("invalid secret key %q: %s", key, strings.Join(errs, ", ")))
+		}
+
+		ref, err := parseKeyVaultCertURI(secretURI)
+		if err != nil {
+			return nil, util.NewUserError(err, fmt.Sprintf("invalid Keyvault secret URI: %s", secretURI))
+		}
+
+		if len(secrets) > 0 && secrets[0].ref.vaultName != ref.vaultName {
+			return nil, util.NewUserError(fmt.Errorf("secrets are in Keyvaults %s and %s", secrets[0].ref.vaultName, ref.vaultName), "all Keyvault secrets of an Ingress must be in the same Keyvault")
+		}
+
+		secrets = append(secrets, keyvaultSecret{key: key, ref: ref})
+	}
+
+	if len(secrets) == 0 {
+		return nil, util.NewUserError(errors.New("no secrets"), "no Keyvault secrets specified")
+	}
+
+	return secrets, nil
+}
+
+// hostCertificate is the Keyvault certificate of a TLS host
+type hostCertificate struct {
+	host string
+	ref  certReference
+}
+
+// parseKeyVaultHostCertURIs parses a comma separated list of host=uri pairs of Keyvault certificates sorted by host. Hosts can be
+// wildcards like *.contoso.com and each certificate can be in a different Keyvault.
+func parseKeyVaultHostCertURIs(certURIs string) ([]hostCertificate, error) {
+	pairs, err := splitKeyURIPairs(certURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []hostCertificate
+	for _, pair := range pairs {
+		host, certURI := pair[0], pair[1]
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, "*.")); len(errs) > 0 {
+			return nil, util.NewUserError(fmt.Errorf("invalid host %q: %s", host, strings.Join(errs, ", ")), fmt.Sprintf("invalid host %q: %s", host, strings.Join(errs, ", ")))
+		}
+
+		ref, err := parseKeyVaultCertURI(certURI)
+		if err != nil {
+			return nil, util.NewUserError(err, fmt.Sprintf("invalid Keyvault certificate URI for host %s: %s", host, certURI))
+		}
+
+		certs = append(certs, hostCertificate{host: host, ref: ref})
+	}
+
+	if len(certs) == 0 {
+		return nil, util.NewUserError(errors.New("no certificates"), "no Keyvault certificates specified")
+	}
+
+	return certs, nil
+}
+
+// splitKeyURIPairs splits a comma separated list of key=uri pairs sorted by key. Empty entries are ignored and keys must be unique.
+func splitKeyURIPairs(value string) ([][2]string, error) {
+	var pairs [][2]string
+	seen := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, uri, ok := strings.Cut(pair, "=")
+		key, uri = strings.TrimSpace(key), strings.TrimSpace(uri)
+		if !ok || key == "" {
+			return nil, util.NewUserError(fmt.Errorf("entry %q isn't in the key=uri format", pair), fmt.Sprintf("invalid entry %q, expected key=uri", pair))
+		}
+		if seen[key] {
+			return nil, util.NewUserError(fmt.Errorf("duplicate key %q", key), fmt.Sprintf("%q is used more than once", key))
+		}
+		seen[key] = true
+
+		pairs = append(pairs, [2]string{key, uri})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs, nil
+}
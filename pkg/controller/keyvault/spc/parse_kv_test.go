@@ -87,3 +87,77 @@ func TestParseKeyVaultCertURI(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKeyVaultSecretURIs(t *testing.T) {
+	secretURI := func(name string) string {
+		return "https://" + parseTestVaultName + "." + parseTestVaultDomain + "/secrets/" + name
+	}
+
+	tests := []struct {
+		name           string
+		secretURIs     string
+		expected       []keyvaultSecret
+		expectErrorStr string
+	}{
+		{
+			name:       "single secret",
+			secretURIs: "auth=" + secretURI("basic-auth"),
+			expected: []keyvaultSecret{
+				{key: "auth", ref: certReference{vaultName: parseTestVaultName, certName: "basic-auth"}},
+			},
+		},
+		{
+			name:       "multiple secrets are sorted by key",
+			secretURIs: " tls.crt=" + secretURI("upstream-crt") + ", ca.crt = " + secretURI("client-ca/"+parseTestVersion) + ",",
+			expected: []keyvaultSecret{
+				{key: "ca.crt", ref: certReference{vaultName: parseTestVaultName, certName: "client-ca", objectVersion: parseTestVersion}},
+				{key: "tls.crt", ref: certReference{vaultName: parseTestVaultName, certName: "upstream-crt"}},
+			},
+		},
+		{
+			name:           "empty",
+			secretURIs:     " , ",
+			expectErrorStr: "no secrets",
+		},
+		{
+			name:           "missing key",
+			secretURIs:     secretURI("basic-auth"),
+			expectErrorStr: "isn't in the key=uri format",
+		},
+		{
+			name:           "invalid key",
+			secretURIs:     "a/b=" + secretURI("basic-auth"),
+			expectErrorStr: `invalid secret key "a/b"`,
+		},
+		{
+			name:           "duplicate key",
+			secretURIs:     "auth=" + secretURI("a") + ",auth=" + secretURI("b"),
+			expectErrorStr: `duplicate key "auth"`,
+		},
+		{
+			name:           "invalid uri",
+			secretURIs:     "auth=" + parseTestInvalidUri,
+			expectErrorStr: "uri path contains too few segments",
+		},
+		{
+			name:           "different keyvaults",
+			secretURIs:     "auth=" + secretURI("a") + ",ca.crt=https://othervault." + parseTestVaultDomain + "/secrets/b",
+			expectErrorStr: "secrets are in Keyvaults myvault and othervault",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseKeyVaultSecretURIs(tt.secretURIs)
+
+			if tt.expectErrorStr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErrorStr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -58,6 +58,8 @@ type spcOpts struct {
 	secretName string
 	// cloud is the cloud environment to use, if empty, the default Azure cloud will be used
 	cloud string
+	// secrets are the Keyvault secrets synced into an Opaque secret instead of the certificate, keyed by the key they're stored under
+	secrets []keyvaultSecret
 
 	// workloadIdentity indicates whether the SPC should use workload identity or not
 	workloadIdentity bool
@@ -217,20 +219,48 @@ func (s *secretProviderClassReconciler[objectType]) cleanupSpc(ctx context.Conte
 }
 
 func (s *secretProviderClassReconciler[objectType]) buildSpc(obj client.Object, opts spcOpts) (*secv1.SecretProviderClass, error) {
-	p := map[string]interface{}{
-		"objectName": opts.certName,
-		"objectType": "secret",
-	}
-	if opts.objectVersion != "" {
-		p["objectVersion"] = opts.objectVersion
+	var objectsArray []string
+	secretObject := &secv1.SecretObject{
+		SecretName: opts.secretName,
+		Type:       "kubernetes.io/tls",
+		Data: []*secv1.SecretObjectData{
+			{
+				ObjectName: opts.certName,
+				Key:        "tls.key",
+			},
+			{
+				ObjectName: opts.certName,
+				Key:        "tls.crt",
+			},
+		},
 	}
 
-	objectParams, err := json.Marshal(p)
-	if err != nil {
-		return nil, fmt.Errorf("marshalling parameters: %w", err)
+	if len(opts.secrets) > 0 {
+		// secrets are mounted under their key so secrets with the same name in Keyvault can't collide
+		secretObject = &secv1.SecretObject{
+			SecretName: opts.secretName,
+			Type:       string(corev1.SecretTypeOpaque),
+		}
+		for _, secret := range opts.secrets {
+			objectParams, err := marshalObjectParams(secret.ref.certName, secret.key, secret.ref.objectVersion)
+			if err != nil {
+				return nil, err
+			}
+			objectsArray = append(objectsArray, objectParams)
+			secretObject.Data = append(secretObject.Data, &secv1.SecretObjectData{
+				ObjectName: secret.key,
+				Key:        secret.key,
+			})
+		}
+	} else {
+		objectParams, err := marshalObjectParams(opts.certName, "", opts.objectVersion)
+		if err != nil {
+			return nil, err
+		}
+		objectsArray = append(objectsArray, objectParams)
 	}
 
-	objects, err := json.Marshal(map[string]interface{}{"array": []string{string(objectParams)}})
+	objects, err := json.Marshal(map[string]interface{}{"array": objectsArray})
 	if err != nil {
 		return nil, fmt.Errorf("marshalling objects: %w", err)
 	}
@@ -266,21 +296,8 @@ func (s *secretProviderClassReconciler[objectType]) buildSpc(obj client.Object,
 			}},
 		},
 		Spec: secv1.SecretProviderClassSpec{
-			Provider: secv1.Provider("azure"),
-			SecretObjects: []*secv1.SecretObject{{
-				SecretName: opts.secretName,
-				Type:       "kubernetes.io/tls",
-				Data: []*secv1.SecretObjectData{
-					{
-						ObjectName: opts.certName,
-						Key:        "tls.key",
-					},
-					{
-						ObjectName: opts.certName,
-						Key:        "tls.crt",
-					},
-				},
-			}},
+			Provider:      secv1.Provider("azure"),
+			SecretObjects: []*secv1.SecretObject{secretObject},
 			// https://azure.github.io/secrets-store-csi-driver-provider-azure/docs/getting-started/usage/#create-your-own-secretproviderclass-object
 			Parameters: params,
 		},
@@ -292,3 +309,25 @@ func (s *secretProviderClassReconciler[objectType]) buildSpc(obj client.Object,
 
 	return spc, nil
 }
+
+// marshalObjectParams returns the parameters of a Keyvault object in the SecretProviderClass. The object is mounted under alias when
+// it's set.
+func marshalObjectParams(name, alias, version string) (string, error) {
+	p := map[string]interface{}{
+		"objectName": name,
+		"objectType": "secret",
+	}
+	if alias != "" {
+		p["objectAlias"] = alias
+	}
+	if version != "" {
+		p["objectVersion"] = version
+	}
+
+	objectParams, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("marshalling parameters: %w", err)
+	}
+
+	return string(objectParams), nil
+}
@@ -760,6 +760,35 @@ func TestBuildSpc(t *testing.T) {
 				assert.Equal(t, "test-cert", secretObj.Data[1].ObjectName)
 			},
 		},
+		{
+			name: "keyvault secrets into an opaque secret",
+			opts: spcOpts{
+				name:       reconcileTestSPC,
+				namespace:  reconcileTestNamespace,
+				clientId:   reconcileTestClientId,
+				tenantId:   reconcileTestTenantId,
+				vaultName:  reconcileTestVaultName,
+				secretName: reconcileTestSecret,
+				secrets: []keyvaultSecret{
+					{key: "auth", ref: certReference{vaultName: reconcileTestVaultName, certName: "basic-auth"}},
+					{key: "ca.crt", ref: certReference{vaultName: reconcileTestVaultName, certName: "client-ca", objectVersion: "1234"}},
+				},
+			},
+			verify: func(t *testing.T, spc *secv1.SecretProviderClass) {
+				require.Len(t, spc.Spec.SecretObjects, 1)
+				secretObj := spc.Spec.SecretObjects[0]
+				assert.Equal(t, reconcileTestSecret, secretObj.SecretName)
+				assert.Equal(t, "Opaque", secretObj.Type)
+				require.Len(t, secretObj.Data, 2)
+				assert.Equal(t, secv1.SecretObjectData{ObjectName: "auth", Key: "auth"}, *secretObj.Data[0])
+				assert.Equal(t, secv1.SecretObjectData{ObjectName: "ca.crt", Key: "ca.crt"}, *secretObj.Data[1])
+
+				assert.JSONEq(t, `{"array": [
+					"{\"objectAlias\":\"auth\",\"objectName\":\"basic-auth\",\"objectType\":\"secret\"}",
+					"{\"objectAlias\":\"ca.crt\",\"objectName\":\"client-ca\",\"objectType\":\"secret\",\"objectVersion\":\"1234\"}"
+				]}`, spc.Spec.Parameters["objects"])
+			},
+		},
 		{
 			name: "verify owner references",
 			opts: spcOpts{
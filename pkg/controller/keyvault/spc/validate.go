@@ -0,0 +1,107 @@
+package spc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	netv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ValidateIngress returns an error if the Key Vault certificate or secret annotations of an Ingress App Routing manages can't be
+// reconciled. Problems the user has to fix are returned as a util.UserError. These are the same checks the Ingress reconcilers make.
+func ValidateIngress(ctx context.Context, cl client.Client, ingressManager util.IngressManager, ing *netv1.Ingress) error {
+	reconcileCert, err := ShouldReconcileIngress(ingressManager, ing)
+	if err != nil {
+		return fmt.Errorf("checking if ingress is managed: %w", err)
+	}
+	reconcileSecrets, err := ShouldReconcileIngressSecrets(ingressManager, ing)
+	if err != nil {
+		return fmt.Errorf("checking if ingress is managed: %w", err)
+	}
+	if !reconcileCert && !reconcileSecrets {
+		return nil
+	}
+
+	if reconcileCert {
+		if err := validateIngressCertAnnotations(ing); err != nil {
+			return err
+		}
+	}
+
+	if reconcileSecrets {
+		if _, err := parseKeyVaultSecretURIs(ing.Annotations[KeyVaultSecretsAnnotation]); err != nil {
+			return err
+		}
+	}
+
+	if sa := ing.Annotations[IngressServiceAccountTLSAnnotation]; sa != "" {
+		if _, err := util.GetServiceAccountWorkloadIdentityClientId(ctx, cl, sa, ing.Namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateIngressCertAnnotations validates the single certificate or the per host certificates annotation of the ingress
+func validateIngressCertAnnotations(ing *netv1.Ingress) error {
+	if err := checkCertAnnotationConflict(ing); err != nil {
+		return err
+	}
+
+	if hostUris, ok := ing.Annotations[KeyVaultHostUrisAnnotation]; ok {
+		hostCerts, err := parseKeyVaultHostCertURIs(hostUris)
+		if err != nil {
+			return err
+		}
+
+		// a certificate for a host without a rule would never be served
+		hosts := map[string]bool{}
+		for _, rule := range ing.Spec.Rules {
+			hosts[rule.Host] = true
+		}
+		for _, hostCert := range hostCerts {
+			if !hosts[hostCert.host] {
+				msg := fmt.Sprintf("host %q in the %s annotation isn't a host of the Ingress rules", hostCert.host, KeyVaultHostUrisAnnotation)
+				return util.NewUserError(errors.New(msg), msg)
+			}
+		}
+
+		return nil
+	}
+
+	uri := ing.Annotations[keyVaultUriKey]
+	if _, err := parseKeyVaultCertURI(uri); err != nil {
+		return util.NewUserError(err, fmt.Sprintf("invalid Keyvault certificate URI: %s", uri))
+	}
+
+	return nil
+}
+
+// ValidateGateway returns an error if the Key Vault certificate TLS options of a Gateway App Routing manages can't be reconciled.
+// Problems the user has to fix are returned as a util.UserError. These are the same checks the Gateway reconciler makes.
+func ValidateGateway(ctx context.Context, cl client.Client, gw *gatewayv1.Gateway) error {
+	if !IsManagedGateway(gw) {
+		return nil
+	}
+
+	for _, listener := range gw.Spec.Listeners {
+		if !ListenerIsKvEnabled(listener) {
+			continue
+		}
+
+		if _, err := clientIdFromListener(ctx, cl, gw.Namespace, listener); err != nil {
+			return err
+		}
+
+		if _, err := parseKeyVaultCertURI(string(listener.TLS.Options[certUriTLSOption])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package spc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func validateTestClient(t *testing.T) *fake.ClientBuilder {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, netv1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "wi-sa", Namespace: "default", Annotations: map[string]string{util.WiSaClientIdAnnotation: "client-id"}},
+		},
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "plain-sa", Namespace: "default"},
+		},
+	)
+}
+
+func TestValidateIngress(t *testing.T) {
+	managed := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return true, nil })
+	unmanaged := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, nil })
+	failing := util.NewIngressManagerFromFn(func(*netv1.Ingress) (bool, error) { return false, errors.New("boom") })
+
+	ingress := func(annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: "default", Annotations: annotations}}
+	}
+	withRules := func(ing *netv1.Ingress, hosts ...string) *netv1.Ingress {
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, netv1.IngressRule{Host: host})
+		}
+		return ing
+	}
+
+	cases := []struct {
+		name           string
+		ingressManager util.IngressManager
+		ing            *netv1.Ingress
+		wantUserErr    string
+		wantErr        bool
+	}{
+		{
+			name:           "no annotations",
+			ingressManager: managed,
+			ing:            ingress(nil),
+		},
+		{
+			name:           "valid uri",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/certificates/cert"}),
+		},
+		{
+			name:           "invalid uri",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/"}),
+			wantUserErr:    "invalid Keyvault certificate URI: https://vault.vault.azure.net/",
+		},
+		{
+			name:           "invalid uri on unmanaged ingress",
+			ingressManager: unmanaged,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/"}),
+		},
+		{
+			name:           "workload identity service account",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/certificates/cert", IngressServiceAccountTLSAnnotation: "wi-sa"}),
+		},
+		{
+			name:           "missing service account",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/certificates/cert", IngressServiceAccountTLSAnnotation: "missing-sa"}),
+			wantUserErr:    "serviceAccount missing-sa does not exist in namespace default",
+		},
+		{
+			name:           "service account without workload identity",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/certificates/cert", IngressServiceAccountTLSAnnotation: "plain-sa"}),
+			wantUserErr:    "serviceAccount plain-sa was specified but does not include necessary annotation for workload identity",
+		},
+		{
+			name:           "valid host uris",
+			ingressManager: managed,
+			ing:            withRules(ingress(map[string]string{KeyVaultHostUrisAnnotation: "api.contoso.com=https://vault.vault.azure.net/certificates/api,www.fabrikam.com=https://other.vault.azure.net/certificates/www"}), "api.contoso.com", "www.fabrikam.com"),
+		},
+		{
+			name:           "host uris for a host without a rule",
+			ingressManager: managed,
+			ing:            withRules(ingress(map[string]string{KeyVaultHostUrisAnnotation: "api.contoso.com=https://vault.vault.azure.net/certificates/api,www.fabrikam.com=https://other.vault.azure.net/certificates/www"}), "api.contoso.com"),
+			wantUserErr:    `host "www.fabrikam.com" in the kubernetes.azure.com/tls-cert-keyvault-host-uris annotation isn't a host of the Ingress rules`,
+		},
+		{
+			name:           "invalid host uris",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{KeyVaultHostUrisAnnotation: "api.contoso.com=https://vault.vault.azure.net/"}),
+			wantUserErr:    "invalid Keyvault certificate URI for host api.contoso.com: https://vault.vault.azure.net/",
+		},
+		{
+			name:           "uri and host uris",
+			ingressManager: managed,
+			ing: ingress(map[string]string{
+				keyVaultUriKey:             "https://vault.vault.azure.net/certificates/cert",
+				KeyVaultHostUrisAnnotation: "api.contoso.com=https://vault.vault.azure.net/certificates/api",
+			}),
+			wantUserErr: "only one of the kubernetes.azure.com/tls-cert-keyvault-uri and kubernetes.azure.com/tls-cert-keyvault-host-uris annotations can be set",
+		},
+		{
+			name:           "valid secret uris",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "auth=https://vault.vault.azure.net/secrets/basic-auth"}),
+		},
+		{
+			name:           "invalid secret uris",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "https://vault.vault.azure.net/secrets/basic-auth"}),
+			wantUserErr:    `invalid entry "https://vault.vault.azure.net/secrets/basic-auth", expected key=uri`,
+		},
+		{
+			name:           "valid certificate with invalid secret uris",
+			ingressManager: managed,
+			ing: ingress(map[string]string{
+				keyVaultUriKey:            "https://vault.vault.azure.net/certificates/cert",
+				KeyVaultSecretsAnnotation: "auth=https://vault.vault.azure.net/secrets/a,ca.crt=https://other.vault.azure.net/secrets/b",
+			}),
+			wantUserErr: "all Keyvault secrets of an Ingress must be in the same Keyvault",
+		},
+		{
+			name:           "invalid secret uris on unmanaged ingress",
+			ingressManager: unmanaged,
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "invalid"}),
+		},
+		{
+			name:           "secret uris with missing service account",
+			ingressManager: managed,
+			ing:            ingress(map[string]string{KeyVaultSecretsAnnotation: "auth=https://vault.vault.azure.net/secrets/a", IngressServiceAccountTLSAnnotation: "missing-sa"}),
+			wantUserErr:    "serviceAccount missing-sa does not exist in namespace default",
+		},
+		{
+			name:           "ingress manager error",
+			ingressManager: failing,
+			ing:            ingress(map[string]string{keyVaultUriKey: "https://vault.vault.azure.net/certificates/cert"}),
+			wantErr:        true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateIngress(context.Background(), validateTestClient(t).Build(), tc.ingressManager, tc.ing)
+			requireValidationErr(t, err, tc.wantUserErr, tc.wantErr)
+		})
+	}
+}
+
+func TestValidateGateway(t *testing.T) {
+	gateway := func(class string, options map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue) *gatewayv1.Gateway {
+		return &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+			Spec: gatewayv1.GatewaySpec{
+				GatewayClassName: gatewayv1.ObjectName(class),
+				Listeners: []gatewayv1.Listener{
+					{Name: "http"},
+					{Name: "https", TLS: &gatewayv1.GatewayTLSConfig{Options: options}},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name        string
+		gw          *gatewayv1.Gateway
+		wantUserErr string
+	}{
+		{
+			name: "no key vault listeners",
+			gw:   gateway(appRoutingIstioGatewayClassName, nil),
+		},
+		{
+			name: "valid",
+			gw:   gateway(appRoutingIstioGatewayClassName, map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{certUriTLSOption: "https://vault.vault.azure.net/certificates/cert", util.ServiceAccountTLSOption: "wi-sa"}),
+		},
+		{
+			name:        "missing service account option",
+			gw:          gateway(appRoutingIstioGatewayClassName, map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{certUriTLSOption: "https://vault.vault.azure.net/certificates/cert"}),
+			wantUserErr: "KeyVault Cert URI provided, but the required ServiceAccount option was not. Please provide a ServiceAccount via the TLS option kubernetes.azure.com/tls-cert-service-account",
+		},
+		{
+			name:        "missing service account",
+			gw:          gateway(istioGatewayClassName, map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{certUriTLSOption: "https://vault.vault.azure.net/certificates/cert", util.ServiceAccountTLSOption: "missing-sa"}),
+			wantUserErr: "service account missing-sa does not exist in namespace default",
+		},
+		{
+			name:        "invalid uri",
+			gw:          gateway(appRoutingIstioGatewayClassName, map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{certUriTLSOption: "https://vault.vault.azure.net/", util.ServiceAccountTLSOption: "wi-sa"}),
+			wantUserErr: "invalid secret uri: https://vault.vault.azure.net/",
+		},
+		{
+			name: "unmanaged gateway class",
+			gw:   gateway("other", map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{certUriTLSOption: "https://vault.vault.azure.net/"}),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateGateway(context.Background(), validateTestClient(t).Build(), tc.gw)
+			requireValidationErr(t, err, tc.wantUserErr, false)
+		})
+	}
+}
+
+func requireValidationErr(t *testing.T, err error, wantUserErr string, wantErr bool) {
+	t.Helper()
+
+	switch {
+	case wantUserErr != "":
+		var userErr util.UserError
+		require.ErrorAs(t, err, &userErr)
+		require.Equal(t, wantUserErr, userErr.UserError())
+	case wantErr:
+		require.Error(t, err)
+		require.False(t, errors.As(err, &util.UserError{}), "expected a non-user error")
+	default:
+		require.NoError(t, err)
+	}
+}
@@ -36,6 +36,37 @@ var (
 		Name: "app_routing_default_domain_cert_expiry_seconds",
 		Help: "Number of seconds until the default domain TLS certificate expires. Negative values mean the certificate has already expired. Value is NaN until a certificate is successfully fetched.",
 	})
+
+	TLSCertExpirySeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "app_routing_tls_cert_expiry_seconds",
+		Help: "Number of seconds until the certificate of each App Routing managed TLS secret expires, labeled with the resource the certificate is for. Negative values mean the certificate has already expired.",
+	}, []string{"namespace", "secret", "owner_kind", "owner_name"})
+
+	ConfigReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "app_routing_config_reloads_total",
+		Help: "Total number of reloads of the mounted operator config file and CA bundles",
+	}, []string{"source", "result"})
+
+	ConcurrencyWatchdogEvictionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "app_routing_concurrency_watchdog_evictions_total",
+		Help: "Total number of ingress controller pods the concurrency watchdog evicted or would have evicted per target",
+	}, []string{"target", "result"})
+
+	ConcurrencyWatchdogPodLoad = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "app_routing_concurrency_watchdog_pod_load",
+		Help: "Load of each ready ingress controller pod at the concurrency watchdog's last check, active connections unless the target uses another load signal",
+	}, []string{"target", "pod"})
+
+	ConcurrencyWatchdogPodVotes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "app_routing_concurrency_watchdog_pod_votes",
+		Help: "Number of unexpired concurrency watchdog votes to evict each ingress controller pod",
+	}, []string{"target", "pod"})
+
+	ConcurrencyWatchdogScrapeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "app_routing_concurrency_watchdog_scrape_duration_seconds",
+		Help:    "Duration of the concurrency watchdog's scrapes of ingress controller pods per mode, direct to the pod IP or through the API server",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"mode", "result"})
 )
 
 const (
@@ -44,10 +75,13 @@ const (
 	LabelRequeue      = "requeue"
 	LabelSuccess      = "success"
 	LabelNotFound     = "not_found"
+	LabelRejected     = "rejected"
+	LabelDryRun       = "dry_run"
+	LabelRateLimited  = "rate_limited"
 )
 
 func init() {
-	metrics.Registry.MustRegister(AppRoutingReconcileErrors, AppRoutingReconcileTotal, DefaultDomainClientCallsTotal, DefaultDomainClientErrors, DefaultDomainCertExpirySeconds)
+	metrics.Registry.MustRegister(AppRoutingReconcileErrors, AppRoutingReconcileTotal, DefaultDomainClientCallsTotal, DefaultDomainClientErrors, DefaultDomainCertExpirySeconds, TLSCertExpirySeconds, ConfigReloadsTotal, ConcurrencyWatchdogEvictionsTotal, ConcurrencyWatchdogPodLoad, ConcurrencyWatchdogPodVotes, ConcurrencyWatchdogScrapeDuration)
 	DefaultDomainCertExpirySeconds.Set(math.NaN())
 }
 
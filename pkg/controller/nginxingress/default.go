@@ -31,17 +31,12 @@ const (
 	retryInterval     = time.Second
 )
 
-const internalLbAnnotation = "service.beta.kubernetes.io/azure-load-balancer-internal"
-
 func NewDefaultReconciler(mgr ctrl.Manager, conf *config.Config) error {
 	if conf == nil {
 		return errors.New("nil config")
 	}
 
-	if conf.DefaultController == config.Off {
-		return nil
-	}
-
+	// the reconciler is added even when the default controller is off because an AppRoutingConfiguration can turn it on at runtime
 	name := controllername.New("default", "nginx", "ingress", "controller", "reconciler")
 	metrics.InitControllerMetrics(name)
 	if err := mgr.Add(&defaultNicReconciler{
@@ -65,11 +60,13 @@ type defaultNicReconciler struct {
 
 func (d *defaultNicReconciler) Start(ctx context.Context) error {
 	d.lgr.Info("starting default nginx ingress controller reconciler")
+	updates := d.conf.Subscribe()
 	interval := time.Nanosecond
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-updates:
 		case <-time.After(util.Jitter(interval, 0.3)):
 		}
 
@@ -91,15 +88,31 @@ func (d *defaultNicReconciler) tick(ctx context.Context) (err error) {
 		metrics.HandleControllerReconcileMetrics(d.name, ctrl.Result{}, err)
 	}()
 
+	defaultController := d.conf.Current().DefaultController
+	if defaultController == config.Off {
+		d.lgr.Info("default nginx ingress controller is off, skipping upsert")
+		return nil
+	}
+
+	existing := &approutingv1alpha1.NginxIngressController{}
+	if err := d.client.Get(ctx, types.NamespacedName{Name: DefaultNicName}, existing); client.IgnoreNotFound(err) != nil {
+		d.lgr.Error(err, "getting default nginx ingress controller")
+		return fmt.Errorf("getting default nginx ingress controller: %w", err)
+	}
+	if IsSuspended(existing) {
+		d.lgr.Info("default nginx ingress controller is suspended, skipping upsert")
+		return nil
+	}
+
 	nic := GetDefaultNginxIngressController()
-	switch d.conf.DefaultController {
+	switch defaultController {
 	case config.Public:
-		nic.Spec.LoadBalancerAnnotations = map[string]string{
-			internalLbAnnotation: "false",
+		nic.Spec.LoadBalancer = &approutingv1alpha1.LoadBalancer{
+			Type: util.ToPtr(approutingv1alpha1.PublicLoadBalancerType),
 		}
 	case config.Private:
-		nic.Spec.LoadBalancerAnnotations = map[string]string{
-			internalLbAnnotation: "true",
+		nic.Spec.LoadBalancer = &approutingv1alpha1.LoadBalancer{
+			Type: util.ToPtr(approutingv1alpha1.InternalLoadBalancerType),
 		}
 	}
 
@@ -9,6 +9,8 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/testutils"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
 	netv1 "k8s.io/api/networking/v1"
@@ -20,15 +22,20 @@ import (
 )
 
 func TestNewDefaultReconciler(t *testing.T) {
+	added := 0
 	fakeManager := &testutils.FakeManager{
 		AddFn: func(runnable manager.Runnable) error {
-			require.Fail(t, "expected manager.Add not to be called")
+			added++
 			return nil
 		},
 	}
 
 	require.Equal(t, errors.New("nil config"), NewDefaultReconciler(fakeManager, nil))
+	require.Equal(t, 0, added)
+
+	// an AppRoutingConfiguration can turn the default controller on at runtime
 	require.Nil(t, NewDefaultReconciler(fakeManager, &config.Config{DefaultController: config.Off}))
+	require.Equal(t, 1, added)
 }
 
 func TestDefaultNicReconciler(t *testing.T) {
@@ -72,13 +79,38 @@ func TestDefaultNicReconciler(t *testing.T) {
 	d.conf.DefaultController = config.Private
 	require.NoError(t, d.tick(context.Background()))
 	require.NoError(t, d.client.Get(context.Background(), types.NamespacedName{Name: nic.Name}, nic))
-	require.Equal(t, map[string]string{internalLbAnnotation: "true"}, nic.Spec.LoadBalancerAnnotations, "default nic service annotations should have private lb annotation")
+	require.Empty(t, nic.Spec.LoadBalancerAnnotations, "default nic service annotations should not be used for the lb type")
+	require.Equal(t, &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.InternalLoadBalancerType)}, nic.Spec.LoadBalancer, "default nic should have a private lb")
+	require.Equal(t, "true", ToNginxIngressConfig(nic, "").ServiceConfig.LoadBalancer.Annotations()[manifests.AzureLoadBalancerInternalAnnotation])
 
 	// prove that a public nic lb service annotation is used when the configuration specifies
 	d.conf.DefaultController = config.Public
 	require.NoError(t, d.tick(context.Background()))
 	require.NoError(t, d.client.Get(context.Background(), types.NamespacedName{Name: nic.Name}, nic))
-	require.Equal(t, map[string]string{internalLbAnnotation: "false"}, nic.Spec.LoadBalancerAnnotations, "default nic service annotations should have private lb annotation")
+	require.Equal(t, &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.PublicLoadBalancerType)}, nic.Spec.LoadBalancer, "default nic should have a public lb")
+	require.Equal(t, "false", ToNginxIngressConfig(nic, "").ServiceConfig.LoadBalancer.Annotations()[manifests.AzureLoadBalancerInternalAnnotation])
+
+	// prove we don't touch the default nic while it's suspended
+	nic.Annotations = map[string]string{SuspendAnnotation: "true"}
+	require.NoError(t, d.client.Update(context.Background(), nic))
+	d.conf.DefaultController = config.Private
+	require.NoError(t, d.tick(context.Background()))
+	require.NoError(t, d.client.Get(context.Background(), types.NamespacedName{Name: nic.Name}, nic))
+	require.Equal(t, &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.PublicLoadBalancerType)}, nic.Spec.LoadBalancer, "suspended default nic should not be updated")
+	nic.Annotations = nil
+	require.NoError(t, d.client.Update(context.Background(), nic))
+
+	// prove the configuration in effect is used over the flags
+	d.conf.SetCurrent(&config.Config{DefaultController: config.Private})
+	require.NoError(t, d.tick(context.Background()))
+	require.NoError(t, d.client.Get(context.Background(), types.NamespacedName{Name: nic.Name}, nic))
+	require.Equal(t, &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.InternalLoadBalancerType)}, nic.Spec.LoadBalancer, "default nic should follow the configuration in effect")
+
+	// prove we don't touch the default nic while the default controller is off
+	d.conf.SetCurrent(&config.Config{DefaultController: config.Off})
+	require.NoError(t, d.tick(context.Background()))
+	require.NoError(t, d.client.Get(context.Background(), types.NamespacedName{Name: nic.Name}, nic))
+	require.Equal(t, &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.InternalLoadBalancerType)}, nic.Spec.LoadBalancer, "default nic should not be updated while off")
 }
 
 func TestGetDefaultIngressClassControllerClass(t *testing.T) {
@@ -0,0 +1,289 @@
+package nginxingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// deletionFinalizer keeps a deleted NginxIngressController, and through garbage collection its load balancer, until its Ingresses
+	// are handled and its connections are drained
+	deletionFinalizer = "approuting.kubernetes.azure.com/deletion-protection"
+
+	deletionBlockedReason        = "DeletionBlocked"
+	fallbackNotFoundReason       = "FallbackIngressClassNotFound"
+	drainingReason               = "Draining"
+	terminatingControllerReason  = "TerminatingController"
+	maxDeletionIngressesInEvents = 10
+)
+
+var (
+	// deletionBlockedRequeue is how often a blocked deletion is rechecked. Ingress changes also trigger a recheck
+	deletionBlockedRequeue = time.Minute
+	// terminatingControllerRequeue is how often the controller pods are checked while they finish their in-flight requests
+	terminatingControllerRequeue = 5 * time.Second
+)
+
+// updateDeletionFinalizer adds the finalizer to NginxIngressControllers with deletion protection and removes it from the others so
+// NginxIngressControllers that don't opt in are deleted right away
+func (n *nginxIngressControllerReconciler) updateDeletionFinalizer(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) error {
+	var changed bool
+	if nic.Spec.DeletionProtection != nil {
+		changed = controllerutil.AddFinalizer(nic, deletionFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(nic, deletionFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("updating deletion protection finalizer")
+	return n.client.Update(ctx, nic)
+}
+
+// finalize runs the deletion flow of a deleted NginxIngressController and removes the finalizer once its load balancer can be released
+func (n *nginxIngressControllerReconciler) finalize(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) (ctrl.Result, error) {
+	lgr := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(nic, deletionFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// the finalizer is released right away when deletion protection was removed before the NginxIngressController was deleted
+	if nic.Spec.DeletionProtection != nil {
+		res, err := n.prepareDeletion(ctx, nic)
+		if err != nil || !res.IsZero() {
+			if statusErr := n.client.Status().Update(ctx, nic); statusErr != nil {
+				if apierrors.IsConflict(statusErr) || apierrors.IsNotFound(statusErr) {
+					lgr.Info("unable to update status of deleted NginxIngressController, requeuing")
+					return ctrl.Result{Requeue: true}, err
+				}
+				if err == nil {
+					lgr.Error(statusErr, "unable to update NginxIngressController status")
+					err = fmt.Errorf("updating status: %w", statusErr)
+				}
+			}
+
+			return res, err
+		}
+	}
+
+	lgr.Info("removing deletion protection finalizer")
+	controllerutil.RemoveFinalizer(nic, deletionFinalizer)
+	if err := n.client.Update(ctx, nic); client.IgnoreNotFound(err) != nil {
+		if apierrors.IsConflict(err) {
+			lgr.Info("conflict removing finalizer, requeuing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		lgr.Error(err, "unable to remove deletion protection finalizer")
+		return ctrl.Result{}, fmt.Errorf("removing finalizer: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// prepareDeletion handles the Ingresses of the IngressClass, drains connections and shuts down the controller pods. It returns a non-zero
+// result while deletion has to wait.
+func (n *nginxIngressControllerReconciler) prepareDeletion(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) (ctrl.Result, error) {
+	lgr := log.FromContext(ctx)
+	current := nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting)
+	draining := current != nil && (current.Reason == drainingReason || current.Reason == terminatingControllerReason)
+
+	if !draining {
+		ingresses, err := n.classIngresses(ctx, nic)
+		if err != nil {
+			lgr.Error(err, "unable to list ingresses")
+			return ctrl.Result{}, fmt.Errorf("listing ingresses: %w", err)
+		}
+
+		if len(ingresses) > 0 {
+			if fallback := getFallbackIngressClassName(nic); fallback != "" {
+				return n.migrateIngresses(ctx, nic, ingresses, fallback)
+			}
+
+			if getDeletionProtectionMode(nic) == approutingv1alpha1.BlockDeletionProtectionMode {
+				lgr.Info("deletion blocked by ingresses", "count", len(ingresses))
+				msg := fmt.Sprintf("Deletion is blocked until no Ingresses use IngressClass %s, %d still do: %s", nic.Spec.IngressClassName, len(ingresses), ingressNames(ingresses))
+				n.setDeleting(nic, deletionBlockedReason, msg, corev1.EventTypeWarning)
+				return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, nil
+			}
+
+			lgr.Info("deleting despite ingresses", "count", len(ingresses))
+			n.events.Eventf(nic, corev1.EventTypeWarning, "DeletingWithIngresses", "Deleting although %d Ingresses use IngressClass %s, they won't be served: %s", len(ingresses), nic.Spec.IngressClassName, ingressNames(ingresses))
+		}
+	}
+
+	drainPeriod := getDrainPeriod(nic)
+	if !draining {
+		n.setDeleting(nic, drainingReason, fmt.Sprintf("Draining connections for %s before shutting down the NGINX Ingress Controller", drainPeriod.String()), corev1.EventTypeNormal)
+		current = nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting)
+	}
+	if current.Reason == drainingReason {
+		// the Deleting condition is already True while deletion is blocked so its transition time can't tell when draining started
+		if nic.Status.DrainStartTime == nil {
+			nic.Status.DrainStartTime = util.ToPtr(metav1.Now())
+		}
+		if remaining := drainPeriod - time.Since(nic.Status.DrainStartTime.Time); remaining > 0 {
+			lgr.Info("draining connections", "remaining", remaining.String())
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+	}
+
+	terminating, err := n.terminateController(ctx, nic)
+	if err != nil {
+		lgr.Error(err, "unable to shut down the nginx ingress controller")
+		return ctrl.Result{}, fmt.Errorf("shutting down nginx ingress controller: %w", err)
+	}
+	if terminating {
+		n.setDeleting(nic, terminatingControllerReason, "Waiting for the NGINX Ingress Controller pods to finish their in-flight requests before releasing the load balancer", corev1.EventTypeNormal)
+		return ctrl.Result{RequeueAfter: terminatingControllerRequeue}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// migrateIngresses moves the Ingresses to the fallback IngressClass
+func (n *nginxIngressControllerReconciler) migrateIngresses(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, ingresses []netv1.Ingress, fallback string) (ctrl.Result, error) {
+	lgr := log.FromContext(ctx).WithValues("fallbackIngressClassName", fallback)
+
+	if err := n.client.Get(ctx, client.ObjectKey{Name: fallback}, &netv1.IngressClass{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting fallback IngressClass %s: %w", fallback, err)
+		}
+
+		lgr.Info("fallback IngressClass not found")
+		n.setDeleting(nic, fallbackNotFoundReason, fmt.Sprintf("Deletion is blocked because fallback IngressClass %s doesn't exist and %d Ingresses use IngressClass %s", fallback, len(ingresses), nic.Spec.IngressClassName), corev1.EventTypeWarning)
+		return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, nil
+	}
+
+	lgr.Info("moving ingresses to fallback IngressClass", "count", len(ingresses))
+	for i := range ingresses {
+		ing := &ingresses[i]
+		patch := client.MergeFrom(ing.DeepCopy())
+		ing.Spec.IngressClassName = &fallback
+		// the annotation would otherwise keep naming the deleted IngressClass
+		delete(ing.Annotations, ingressClassAnnotation)
+		if err := n.client.Patch(ctx, ing, patch); client.IgnoreNotFound(err) != nil {
+			n.events.Eventf(nic, corev1.EventTypeWarning, "MigratingIngressFailed", "Failed to move Ingress %s/%s to IngressClass %s: %s", ing.Namespace, ing.Name, fallback, err.Error())
+			return ctrl.Result{}, fmt.Errorf("moving ingress %s/%s to IngressClass %s: %w", ing.Namespace, ing.Name, fallback, err)
+		}
+	}
+
+	n.events.Eventf(nic, corev1.EventTypeNormal, "IngressesMigrated", "Moved %d Ingresses to IngressClass %s: %s", len(ingresses), fallback, ingressNames(ingresses))
+
+	// recheck once the cache has caught up in case Ingresses were created in the meantime
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// terminateController deletes the controller Deployment and returns true while its pods are still shutting down
+func (n *nginxIngressControllerReconciler) terminateController(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) (bool, error) {
+	res := n.ManagedResources(nic)
+	if res == nil {
+		return false, fmt.Errorf("unable to get managed resources")
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := n.client.Get(ctx, client.ObjectKeyFromObject(res.Deployment), deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return n.controllerPodsRunning(ctx, res.Deployment)
+		}
+
+		return false, fmt.Errorf("getting deployment: %w", err)
+	}
+
+	// the default NginxIngressController can adopt a Deployment it doesn't control, that's left for the user
+	if !metav1.IsControlledBy(deployment, nic) {
+		return false, nil
+	}
+
+	if deployment.DeletionTimestamp.IsZero() {
+		log.FromContext(ctx).Info("deleting nginx ingress controller deployment")
+		if err := n.client.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("deleting deployment: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// controllerPodsRunning returns true if pods of the controller Deployment still exist
+func (n *nginxIngressControllerReconciler) controllerPodsRunning(ctx context.Context, deployment *appsv1.Deployment) (bool, error) {
+	if deployment.Spec.Selector == nil {
+		return false, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := n.client.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		return false, fmt.Errorf("listing pods: %w", err)
+	}
+
+	return len(pods.Items) > 0, nil
+}
+
+// setDeleting sets the Deleting condition and emits an event when the reason or message changes
+func (n *nginxIngressControllerReconciler) setDeleting(nic *approutingv1alpha1.NginxIngressController, reason, msg, eventType string) {
+	if current := nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting); current == nil || current.Reason != reason || current.Message != msg {
+		n.events.Event(nic, eventType, reason, msg)
+	}
+
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeDeleting,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: msg,
+	})
+}
+
+// ingressNames returns the first Ingresses as namespace/name for messages
+func ingressNames(ingresses []netv1.Ingress) string {
+	names := make([]string, 0, maxDeletionIngressesInEvents)
+	for _, ing := range ingresses {
+		if len(names) == maxDeletionIngressesInEvents {
+			names = append(names, fmt.Sprintf("and %d more", len(ingresses)-maxDeletionIngressesInEvents))
+			break
+		}
+
+		names = append(names, ing.Namespace+"/"+ing.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func getDeletionProtectionMode(nic *approutingv1alpha1.NginxIngressController) approutingv1alpha1.DeletionProtectionMode {
+	if nic.Spec.DeletionProtection == nil || nic.Spec.DeletionProtection.Mode == nil {
+		return approutingv1alpha1.BlockDeletionProtectionMode
+	}
+
+	return *nic.Spec.DeletionProtection.Mode
+}
+
+func getFallbackIngressClassName(nic *approutingv1alpha1.NginxIngressController) string {
+	if nic.Spec.DeletionProtection == nil || nic.Spec.DeletionProtection.FallbackIngressClassName == nil {
+		return ""
+	}
+
+	return *nic.Spec.DeletionProtection.FallbackIngressClassName
+}
+
+func getDrainPeriod(nic *approutingv1alpha1.NginxIngressController) time.Duration {
+	if nic.Spec.DeletionProtection == nil || nic.Spec.DeletionProtection.DrainPeriodSeconds == nil {
+		return 0
+	}
+
+	return time.Duration(*nic.Spec.DeletionProtection.DrainPeriodSeconds) * time.Second
+}
@@ -0,0 +1,314 @@
+package nginxingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const deletionTestIndex = "testIndex"
+
+func deletingNic(protection *approutingv1alpha1.DeletionProtection) *approutingv1alpha1.NginxIngressController {
+	nic := dualLoadBalancerNic()
+	nic.Spec.LoadBalancer = nil
+	nic.Spec.DeletionProtection = protection
+	nic.Finalizers = []string{deletionFinalizer}
+	nic.DeletionTimestamp = util.ToPtr(metav1.Now())
+	return nic
+}
+
+func classIngress(name, class string) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       netv1.IngressSpec{IngressClassName: util.ToPtr(class)},
+	}
+}
+
+func newDeletionTestReconciler(t *testing.T, objs ...client.Object) (*nginxIngressControllerReconciler, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&approutingv1alpha1.NginxIngressController{}).
+		WithIndex(&netv1.Ingress{}, deletionTestIndex, ingressIngressClassNameIndexFn).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	return &nginxIngressControllerReconciler{
+		client:                cl,
+		conf:                  &config.Config{NS: "app-routing-system"},
+		events:                recorder,
+		ingressClassNameIndex: deletionTestIndex,
+	}, cl, recorder
+}
+
+func getDeletingNic(t *testing.T, cl client.Client) *approutingv1alpha1.NginxIngressController {
+	nic := &approutingv1alpha1.NginxIngressController{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "dual"}, nic))
+	return nic
+}
+
+func requireDeleted(t *testing.T, cl client.Client) {
+	err := cl.Get(context.Background(), client.ObjectKey{Name: "dual"}, &approutingv1alpha1.NginxIngressController{})
+	require.True(t, apierrors.IsNotFound(err), "expected NginxIngressController to be deleted, got %v", err)
+}
+
+func TestFinalizeBlocked(t *testing.T) {
+	n, cl, recorder := newDeletionTestReconciler(t, deletingNic(&approutingv1alpha1.DeletionProtection{}), classIngress("a", "dual-class"), classIngress("other", "other-class"))
+
+	res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{RequeueAfter: deletionBlockedRequeue}, res)
+
+	nic := getDeletingNic(t, cl)
+	require.Contains(t, nic.Finalizers, deletionFinalizer)
+	cond := nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting)
+	require.NotNil(t, cond)
+	require.Equal(t, deletionBlockedReason, cond.Reason)
+	require.Contains(t, cond.Message, "default/a")
+	require.NotContains(t, cond.Message, "default/other")
+	require.Contains(t, <-recorder.Events, "Warning DeletionBlocked")
+
+	// unchanged, no new event
+	_, err = n.finalize(context.Background(), nic)
+	require.NoError(t, err)
+	require.Len(t, recorder.Events, 0)
+
+	// the ingress is gone, deletion continues
+	require.NoError(t, cl.Delete(context.Background(), classIngress("a", "dual-class")))
+	res, err = n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	requireDeleted(t, cl)
+}
+
+func TestFinalizeWarn(t *testing.T) {
+	protection := &approutingv1alpha1.DeletionProtection{Mode: util.ToPtr(approutingv1alpha1.WarnDeletionProtectionMode)}
+	n, cl, recorder := newDeletionTestReconciler(t, deletingNic(protection), classIngress("a", "dual-class"))
+
+	res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	require.Contains(t, <-recorder.Events, "Warning DeletingWithIngresses")
+	requireDeleted(t, cl)
+}
+
+func TestFinalizeFallback(t *testing.T) {
+	protection := &approutingv1alpha1.DeletionProtection{FallbackIngressClassName: util.ToPtr("fallback-class")}
+
+	t.Run("fallback not found", func(t *testing.T) {
+		n, cl, _ := newDeletionTestReconciler(t, deletingNic(protection), classIngress("a", "dual-class"))
+
+		res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+		require.NoError(t, err)
+		require.Equal(t, ctrl.Result{RequeueAfter: deletionBlockedRequeue}, res)
+		require.Equal(t, fallbackNotFoundReason, getDeletingNic(t, cl).GetCondition(approutingv1alpha1.ConditionTypeDeleting).Reason)
+
+		ing := &netv1.Ingress{}
+		require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "a"}, ing))
+		require.Equal(t, "dual-class", *ing.Spec.IngressClassName)
+	})
+
+	t.Run("ingresses migrated", func(t *testing.T) {
+		fallback := &netv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "fallback-class"}}
+		annotated := classIngress("c", "")
+		annotated.Spec.IngressClassName = nil
+		annotated.Annotations = map[string]string{"kubernetes.io/ingress.class": "dual-class"}
+		n, cl, recorder := newDeletionTestReconciler(t, deletingNic(protection), fallback, classIngress("a", "dual-class"), classIngress("b", "dual-class"), annotated)
+
+		res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+		require.NoError(t, err)
+		require.Equal(t, ctrl.Result{Requeue: true}, res)
+		require.Contains(t, <-recorder.Events, "Normal IngressesMigrated")
+
+		for _, name := range []string{"a", "b", "c"} {
+			ing := &netv1.Ingress{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, ing))
+			require.Equal(t, "fallback-class", *ing.Spec.IngressClassName)
+			require.NotContains(t, ing.Annotations, "kubernetes.io/ingress.class")
+		}
+
+		res, err = n.finalize(context.Background(), getDeletingNic(t, cl))
+		require.NoError(t, err)
+		require.Equal(t, ctrl.Result{}, res)
+		requireDeleted(t, cl)
+	})
+}
+
+func TestFinalizeDrain(t *testing.T) {
+	protection := &approutingv1alpha1.DeletionProtection{DrainPeriodSeconds: util.Int32Ptr(60)}
+	n, cl, _ := newDeletionTestReconciler(t, deletingNic(protection))
+
+	res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Greater(t, res.RequeueAfter, 55*time.Second)
+	require.LessOrEqual(t, res.RequeueAfter, 60*time.Second)
+
+	nic := getDeletingNic(t, cl)
+	require.Equal(t, drainingReason, nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting).Reason)
+	require.NotNil(t, nic.Status.DrainStartTime)
+
+	// drain period passed
+	nic.Status.DrainStartTime = util.ToPtr(metav1.NewTime(time.Now().Add(-time.Minute)))
+	res, err = n.finalize(context.Background(), nic)
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	requireDeleted(t, cl)
+}
+
+func TestFinalizeDrainAfterBlocked(t *testing.T) {
+	protection := &approutingv1alpha1.DeletionProtection{DrainPeriodSeconds: util.Int32Ptr(60)}
+	n, cl, _ := newDeletionTestReconciler(t, deletingNic(protection), classIngress("a", "dual-class"))
+
+	res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{RequeueAfter: deletionBlockedRequeue}, res)
+
+	// deletion was blocked for longer than the drain period
+	nic := getDeletingNic(t, cl)
+	nic.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	require.NoError(t, cl.Status().Update(context.Background(), nic))
+	require.NoError(t, cl.Delete(context.Background(), classIngress("a", "dual-class")))
+
+	res, err = n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Greater(t, res.RequeueAfter, 55*time.Second)
+	nic = getDeletingNic(t, cl)
+	require.Equal(t, drainingReason, nic.GetCondition(approutingv1alpha1.ConditionTypeDeleting).Reason)
+	require.WithinDuration(t, time.Now(), nic.Status.DrainStartTime.Time, time.Minute)
+}
+
+func TestFinalizeTerminatesController(t *testing.T) {
+	nic := deletingNic(&approutingv1alpha1.DeletionProtection{})
+	n, _, _ := newDeletionTestReconciler(t)
+	res := n.ManagedResources(nic)
+
+	deployment := res.Deployment.DeepCopy()
+	deployment.OwnerReferences = manifests.GetOwnerRefs(nic, true)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx-pod",
+			Namespace: deployment.Namespace,
+			Labels:    deployment.Spec.Selector.MatchLabels,
+		},
+	}
+	n, cl, _ := newDeletionTestReconciler(t, nic, deployment, pod)
+
+	result, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{RequeueAfter: terminatingControllerRequeue}, result)
+	require.Equal(t, terminatingControllerReason, getDeletingNic(t, cl).GetCondition(approutingv1alpha1.ConditionTypeDeleting).Reason)
+	err = cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})
+	require.True(t, apierrors.IsNotFound(err))
+
+	// pods are still finishing their requests
+	result, err = n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{RequeueAfter: terminatingControllerRequeue}, result)
+
+	require.NoError(t, cl.Delete(context.Background(), pod))
+	result, err = n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, result)
+	requireDeleted(t, cl)
+}
+
+func TestFinalizeUncontrolledDeployment(t *testing.T) {
+	nic := deletingNic(&approutingv1alpha1.DeletionProtection{})
+	n, _, _ := newDeletionTestReconciler(t)
+	deployment := n.ManagedResources(nic).Deployment.DeepCopy()
+	deployment.OwnerReferences = nil
+
+	n, cl, _ := newDeletionTestReconciler(t, nic, deployment)
+	result, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, result)
+	requireDeleted(t, cl)
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+}
+
+func TestFinalizeWithoutDeletionProtection(t *testing.T) {
+	// deletion protection was removed after the finalizer was added, the Ingresses don't block the deletion
+	n, cl, recorder := newDeletionTestReconciler(t, deletingNic(nil), classIngress("a", "dual-class"))
+
+	res, err := n.finalize(context.Background(), getDeletingNic(t, cl))
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	require.Len(t, recorder.Events, 0)
+	requireDeleted(t, cl)
+}
+
+func TestReconcileDeletesSuspended(t *testing.T) {
+	nic := deletingNic(&approutingv1alpha1.DeletionProtection{})
+	nic.Spec.Suspend = util.ToPtr(true)
+	n, cl, _ := newDeletionTestReconciler(t, nic)
+
+	res, err := n.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	requireDeleted(t, cl)
+}
+
+func TestUpdateDeletionFinalizer(t *testing.T) {
+	cases := []struct {
+		name       string
+		protection *approutingv1alpha1.DeletionProtection
+		finalizers []string
+		expected   []string
+	}{
+		{
+			name: "no deletion protection",
+		},
+		{
+			name:       "deletion protection",
+			protection: &approutingv1alpha1.DeletionProtection{},
+			expected:   []string{deletionFinalizer},
+		},
+		{
+			name:       "deletion protection removed",
+			finalizers: []string{"other", deletionFinalizer},
+			expected:   []string{"other"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nic := dualLoadBalancerNic()
+			nic.Spec.DeletionProtection = tc.protection
+			nic.Finalizers = tc.finalizers
+			n, cl, _ := newDeletionTestReconciler(t, nic)
+
+			require.NoError(t, n.updateDeletionFinalizer(context.Background(), getDeletingNic(t, cl)))
+			require.Equal(t, tc.expected, getDeletingNic(t, cl).Finalizers)
+		})
+	}
+}
+
+func TestIngressNames(t *testing.T) {
+	ingresses := make([]netv1.Ingress, maxDeletionIngressesInEvents+2)
+	for i := range ingresses {
+		ingresses[i] = *classIngress(string(rune('a'+i)), "dual-class")
+	}
+
+	require.Equal(t, "default/a", ingressNames(ingresses[:1]))
+	require.Equal(t, "default/a, default/b, default/c, default/d, default/e, default/f, default/g, default/h, default/i, default/j, and 2 more", ingressNames(ingresses))
+}
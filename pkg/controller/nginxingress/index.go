@@ -12,12 +12,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// AddIngressClassNameIndex adds an index for the ingress class name to the indexer
+// ingressClassAnnotation is the deprecated annotation Ingresses can use instead of spec.ingressClassName
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// AddIngressClassNameIndex adds an index for the ingress class name of NginxIngressControllers and Ingresses to the indexer
 func AddIngressClassNameIndex(indexer client.FieldIndexer, indexName string) error {
 	if err := indexer.IndexField(context.Background(), &approutingv1alpha1.NginxIngressController{}, indexName, ingressClassNameIndexFn); err != nil {
 		return fmt.Errorf("adding Nginx Ingress Controller IngressClass indexer: %w", err)
 	}
 
+	if err := indexer.IndexField(context.Background(), &netv1.Ingress{}, indexName, ingressIngressClassNameIndexFn); err != nil {
+		return fmt.Errorf("adding Ingress IngressClass indexer: %w", err)
+	}
+
 	return nil
 }
 
@@ -30,6 +37,30 @@ func ingressClassNameIndexFn(object client.Object) []string {
 	return []string{nic.Spec.IngressClassName}
 }
 
+func ingressIngressClassNameIndexFn(object client.Object) []string {
+	ing, ok := object.(*netv1.Ingress)
+	if !ok {
+		return nil
+	}
+
+	className := ingressClassName(ing)
+	if className == "" {
+		return nil
+	}
+
+	return []string{className}
+}
+
+// ingressClassName returns the IngressClass NGINX Ingress Controllers see the Ingress as using. Like ingress-nginx, the
+// kubernetes.io/ingress.class annotation is only used when spec.ingressClassName isn't set.
+func ingressClassName(ing *netv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+
+	return ing.Annotations[ingressClassAnnotation]
+}
+
 // IsIngressManaged returns true if the ingress is managed by the operator
 func IsIngressManaged(ctx context.Context, cl client.Client, ing *netv1.Ingress, ingressClassNameIndex string) (bool, error) {
 	ic := ing.Spec.IngressClassName
@@ -12,6 +12,7 @@ import (
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -53,6 +54,61 @@ func TestIngressClassNameIndexFn(t *testing.T) {
 	}
 }
 
+func TestIngressIngressClassNameIndexFn(t *testing.T) {
+	cases := []struct {
+		name     string
+		object   client.Object
+		expected []string
+	}{
+		{
+			name:     "no ingress class name",
+			object:   &netv1.Ingress{},
+			expected: nil,
+		},
+		{
+			name: "ingress class name",
+			object: &netv1.Ingress{
+				Spec: netv1.IngressSpec{
+					IngressClassName: util.ToPtr("foo"),
+				},
+			},
+			expected: []string{"foo"},
+		},
+		{
+			name: "ingress class annotation",
+			object: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"kubernetes.io/ingress.class": "foo"},
+				},
+			},
+			expected: []string{"foo"},
+		},
+		{
+			name: "ingress class name takes precedence over the annotation",
+			object: &netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"kubernetes.io/ingress.class": "bar"},
+				},
+				Spec: netv1.IngressSpec{
+					IngressClassName: util.ToPtr("foo"),
+				},
+			},
+			expected: []string{"foo"},
+		},
+		{
+			name:     "not an ingress",
+			object:   &approutingv1alpha1.NginxIngressController{},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ingressIngressClassNameIndexFn(tc.object))
+		})
+	}
+}
+
 func TestIsIngressManaged(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/spc"
@@ -16,17 +18,21 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
 	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/keymutex"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -47,8 +53,10 @@ const (
 )
 
 var (
-	icCollisionErr   = errors.New("collision on the IngressClass")
-	maxCollisionsErr = errors.New("max collisions reached")
+	icCollisionErr        = errors.New("collision on the IngressClass")
+	maxCollisionsErr      = errors.New("max collisions reached")
+	versionUnavailableErr = errors.New("nginx version unavailable")
+	lbConflictErr         = errors.New("load balancer configuration conflict")
 )
 
 var (
@@ -59,6 +67,9 @@ var (
 	// This is the field used to key into this mutex. Using this mutex prevents a race condition of multiple nginxIngressController resources attempting
 	// to determine their collisionCount at the same time then both attempting to create and take ownership of the same resources.
 	collisionCountMu = keymutex.NewHashed(6) // 6 is the number of "buckets". It's not too big, not too small
+
+	// loadBalancerProvisioningTimeout is how long a load balancer Service can go without an address before the LoadBalancerReady condition is False
+	loadBalancerProvisioningTimeout = 10 * time.Minute
 )
 
 // nginxIngressControllerReconciler reconciles a NginxIngressController object
@@ -67,10 +78,11 @@ type nginxIngressControllerReconciler struct {
 	conf                      *config.Config
 	events                    record.EventRecorder
 	defaultNicControllerClass string
+	ingressClassNameIndex     string
 }
 
-// NewReconciler sets up the controller with the Manager.
-func NewReconciler(conf *config.Config, mgr ctrl.Manager, defaultIngressClassControllerClass string) error {
+// NewReconciler sets up the controller with the Manager. ingressClassNameIndex must index both NginxIngressControllers and Ingresses by ingress class name, see AddIngressClassNameIndex.
+func NewReconciler(conf *config.Config, mgr ctrl.Manager, defaultIngressClassControllerClass, ingressClassNameIndex string) error {
 	metrics.InitControllerMetrics(nginxIngressControllerReconcilerName)
 
 	reconciler := &nginxIngressControllerReconciler{
@@ -78,12 +90,16 @@ func NewReconciler(conf *config.Config, mgr ctrl.Manager, defaultIngressClassCon
 		conf:                      conf,
 		events:                    mgr.GetEventRecorderFor("aks-app-routing-operator"),
 		defaultNicControllerClass: defaultIngressClassControllerClass,
+		ingressClassNameIndex:     ingressClassNameIndex,
 	}
 
 	if err := nginxIngressControllerReconcilerName.AddToController(
 		ctrl.NewControllerManagedBy(mgr).
 			For(&approutingv1alpha1.NginxIngressController{}).
-			Owns(&appsv1.Deployment{}),
+			Owns(&appsv1.Deployment{}).
+			Owns(&corev1.Service{}).
+			Owns(&admissionregistrationv1.ValidatingWebhookConfiguration{}).
+			Watches(&netv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(reconciler.nicsForIngress)),
 		mgr.GetLogger(),
 	).Complete(reconciler); err != nil {
 		return err
@@ -116,9 +132,34 @@ func (n *nginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 	lgr = lgr.WithValues("generation", nginxIngressController.Generation)
 	ctx = log.IntoContext(ctx, lgr)
 
+	// deletion is handled even while suspended, otherwise a suspended NginxIngressController could never be finalized
+	if !nginxIngressController.DeletionTimestamp.IsZero() {
+		lgr.Info("NginxIngressController is being deleted")
+		return n.finalize(ctx, &nginxIngressController)
+	}
+
+	if IsSuspended(&nginxIngressController) {
+		lgr.Info("NginxIngressController is suspended, leaving managed resources as they are")
+		return n.suspend(ctx, &nginxIngressController)
+	}
+
+	if err := n.updateDeletionFinalizer(ctx, &nginxIngressController); err != nil {
+		if apierrors.IsConflict(err) {
+			lgr.Info("conflict updating finalizer, requeuing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		lgr.Error(err, "unable to update deletion protection finalizer")
+		return ctrl.Result{}, fmt.Errorf("updating finalizer: %w", err)
+	}
+
 	var managedRes []approutingv1alpha1.ManagedObjectReference = nil
 	var controllerDeployment *appsv1.Deployment = nil
 	var ingressClass *netv1.IngressClass = nil
+	var versionErr error = nil
+	var lbErr error = nil
+	var loadBalancers []*corev1.Service = nil
+	var ingresses []netv1.Ingress = nil
 
 	lockKey := nginxIngressController.Spec.ControllerNamePrefix
 	collisionCountMu.LockKey(lockKey)
@@ -138,7 +179,7 @@ func (n *nginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 	}
 	defer func() { // defer is before checking err so that we can update status even if there is an error
 		lgr.Info("updating status")
-		n.updateStatus(&nginxIngressController, controllerDeployment, ingressClass, managedRes, collisionCountErr)
+		n.updateStatus(&nginxIngressController, controllerDeployment, ingressClass, loadBalancers, ingresses, managedRes, errors.Join(collisionCountErr, versionErr, lbErr))
 		if statusErr := n.client.Status().Update(ctx, &nginxIngressController); statusErr != nil {
 			if apierrors.IsConflict(statusErr) {
 				lgr.Info("conflict updating status, requeuing")
@@ -162,6 +203,21 @@ func (n *nginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, fmt.Errorf("determining collision count: %w", collisionCountErr)
 	}
 
+	lgr.Info("resolving nginx version")
+	if _, err := manifests.ResolveNginxVersion(n.conf, ToNginxIngressConfig(&nginxIngressController, n.defaultNicControllerClass)); err != nil {
+		versionErr = fmt.Errorf("%w: %w", versionUnavailableErr, err)
+		lgr.Info("unreconcilable nginx version", "reason", err.Error())
+		return ctrl.Result{RequeueAfter: time.Minute}, nil // requeue in case cx fixes the unreconcilable reason
+	}
+
+	lgr.Info("validating load balancer configuration")
+	ingCfg := ToNginxIngressConfig(&nginxIngressController, n.defaultNicControllerClass)
+	if err := errors.Join(manifests.ValidateLoadBalancerAnnotations(ingCfg.ServiceConfig), manifests.ValidateLoadBalancerAnnotations(ingCfg.SecondaryServiceConfig)); err != nil {
+		lbErr = fmt.Errorf("%w: %w", lbConflictErr, err)
+		lgr.Info("unreconcilable load balancer configuration", "reason", err.Error())
+		return ctrl.Result{RequeueAfter: time.Minute}, nil // requeue in case cx fixes the unreconcilable reason
+	}
+
 	lgr.Info("calculating managed resources")
 	resources := n.ManagedResources(&nginxIngressController)
 	if resources == nil {
@@ -170,17 +226,68 @@ func (n *nginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 	controllerDeployment = resources.Deployment
 	ingressClass = resources.IngressClass
 
+	lgr.Info("listing ingresses")
+	ingresses, err = n.classIngresses(ctx, &nginxIngressController)
+	if err != nil {
+		lgr.Error(err, "unable to list ingresses")
+		return ctrl.Result{}, fmt.Errorf("listing ingresses: %w", err)
+	}
+
+	zoneType := ""
+	if resources.SecondaryService != nil && n.conf.EnableNginxSecondaryLoadBalancerDNS {
+		resources.SetDNSHostnames(ingressHostnames(ingresses))
+		zoneType = resources.IngressZoneType()
+	}
+
+	lgr.Info("setting ingress dns zone types")
+	if err := n.setIngressZoneTypes(ctx, ingresses, zoneType); err != nil {
+		lgr.Error(err, "unable to set ingress dns zone types")
+		return ctrl.Result{}, fmt.Errorf("setting ingress dns zone types: %w", err)
+	}
+
+	lgr.Info("ensuring admission webhook certificate")
+	webhookCertRenewal, err := n.ensureWebhookCertificate(ctx, &nginxIngressController, resources)
+	if err != nil {
+		lgr.Error(err, "unable to ensure admission webhook certificate")
+		return ctrl.Result{}, fmt.Errorf("ensuring admission webhook certificate: %w", err)
+	}
+
+	if cond := nginxIngressController.GetCondition(approutingv1alpha1.ConditionTypeSuspended); cond != nil && cond.Status == metav1.ConditionTrue {
+		lgr.Info("resuming NginxIngressController")
+		if err := n.resume(ctx, &nginxIngressController, resources); err != nil {
+			lgr.Error(err, "unable to resume NginxIngressController")
+			return ctrl.Result{}, fmt.Errorf("resuming: %w", err)
+		}
+	}
+
 	lgr.Info("reconciling managed resources")
 	managedRes, err = n.ReconcileResource(ctx, &nginxIngressController, resources)
 	if err != nil {
 		lgr.Error(err, "unable to reconcile resource")
 		return ctrl.Result{}, fmt.Errorf("reconciling resource: %w", err)
 	}
+	loadBalancers = []*corev1.Service{resources.Service}
+	if resources.SecondaryService != nil {
+		loadBalancers = append(loadBalancers, resources.SecondaryService)
+	}
+
+	lgr.Info("cleaning up unused load balancers")
+	if err := n.cleanupSecondaryServices(ctx, &nginxIngressController, resources); err != nil {
+		lgr.Error(err, "unable to clean up unused load balancers")
+		return ctrl.Result{}, fmt.Errorf("cleaning up unused load balancers: %w", err)
+	}
 	if replicas := resources.Deployment.Spec.Replicas; replicas != nil {
 		lgr.Info(fmt.Sprintf("nginx deployment targets %d replicas", *replicas), "replicas", *replicas)
 	}
 
-	return ctrl.Result{}, nil
+	// Services are owned so an address being assigned triggers a reconcile, but nothing triggers one when the timeout passes
+	if remaining := loadBalancerProvisioningRemaining(loadBalancers); remaining > 0 && remaining < webhookCertRenewal {
+		lgr.Info("load balancer has no address yet, requeuing", "after", remaining.String())
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	lgr.Info("requeuing to renew the admission webhook certificate", "after", webhookCertRenewal.String())
+	return ctrl.Result{RequeueAfter: webhookCertRenewal}, nil
 }
 
 // ReconcileResource reconciles the NginxIngressController resources returning a list of managed resources.
@@ -229,6 +336,10 @@ func (n *nginxIngressControllerReconciler) ManagedResources(nic *approutingv1alp
 		return nil
 	}
 
+	if n.conf.EnableStagedNginxRollout {
+		holdNginxVersion(n.conf, nic, nginxIngressCfg)
+	}
+
 	res := manifests.GetNginxResources(n.conf, nginxIngressCfg)
 	owner := manifests.GetOwnerRefs(nic, true)
 	for _, obj := range res.Objects() {
@@ -240,6 +351,117 @@ func (n *nginxIngressControllerReconciler) ManagedResources(nic *approutingv1alp
 	return res
 }
 
+// classIngresses returns the Ingresses using the NginxIngressController's IngressClass, through spec.ingressClassName or the
+// kubernetes.io/ingress.class annotation, sorted by namespace and name
+func (n *nginxIngressControllerReconciler) classIngresses(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) ([]netv1.Ingress, error) {
+	ingresses := &netv1.IngressList{}
+	if err := n.client.List(ctx, ingresses, client.MatchingFields{n.ingressClassNameIndex: nic.Spec.IngressClassName}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ingresses.Items, func(i, j int) bool {
+		if ingresses.Items[i].Namespace != ingresses.Items[j].Namespace {
+			return ingresses.Items[i].Namespace < ingresses.Items[j].Namespace
+		}
+		return ingresses.Items[i].Name < ingresses.Items[j].Name
+	})
+
+	if ingresses.Items == nil {
+		return []netv1.Ingress{}, nil // nil means unknown to updateStatusIngresses
+	}
+
+	return ingresses.Items, nil
+}
+
+// ingressHostnames returns the sorted, unique hostnames of the Ingresses
+func ingressHostnames(ingresses []netv1.Ingress) []string {
+	hostnames := make(map[string]struct{})
+	for _, ing := range ingresses {
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				hostnames[rule.Host] = struct{}{}
+			}
+		}
+	}
+
+	ret := util.Keys(hostnames)
+	sort.Strings(ret)
+	return ret
+}
+
+// setIngressZoneTypes restricts the DNS zones the Ingresses are published into by external-dns to the zoneType, or lifts the
+// restriction when zoneType is empty. See manifests.NginxResources.IngressZoneType.
+func (n *nginxIngressControllerReconciler) setIngressZoneTypes(ctx context.Context, ingresses []netv1.Ingress, zoneType string) error {
+	for i := range ingresses {
+		ing := &ingresses[i]
+		patch := client.MergeFrom(ing.DeepCopy())
+		if !manifests.SetIngressZoneType(ing, zoneType) {
+			continue
+		}
+
+		if err := n.client.Patch(ctx, ing, patch); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("patching ingress %s/%s: %w", ing.Namespace, ing.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// cleanupSecondaryServices deletes second load balancer Services the NginxIngressController no longer uses
+func (n *nginxIngressControllerReconciler) cleanupSecondaryServices(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, res *manifests.NginxResources) error {
+	ingCfg := ToNginxIngressConfig(nic, n.defaultNicControllerClass)
+	for _, name := range []string{ingCfg.ResourceName + "-internal", ingCfg.ResourceName + "-public"} {
+		if res.SecondaryService != nil && res.SecondaryService.Name == name {
+			continue
+		}
+
+		svc := &corev1.Service{}
+		if err := n.client.Get(ctx, client.ObjectKey{Namespace: n.conf.NS, Name: name}, svc); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("getting service %s: %w", name, err)
+		}
+
+		if !metav1.IsControlledBy(svc, nic) {
+			continue
+		}
+
+		if err := n.client.Delete(ctx, svc); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting service %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// nicsForIngress maps an Ingress to the NginxIngressControllers of its IngressClass, which report it in their status and publish its hostnames
+// on a second load balancer
+func (n *nginxIngressControllerReconciler) nicsForIngress(ctx context.Context, obj client.Object) []reconcile.Request {
+	ing, ok := obj.(*netv1.Ingress)
+	if !ok {
+		return nil
+	}
+
+	className := ingressClassName(ing)
+	if className == "" {
+		return nil
+	}
+
+	nics := &approutingv1alpha1.NginxIngressControllerList{}
+	if err := n.client.List(ctx, nics, client.MatchingFields{n.ingressClassNameIndex: className}); err != nil {
+		log.FromContext(ctx).Error(err, "listing NginxIngressControllers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, nic := range nics.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: nic.Name}})
+	}
+
+	return requests
+}
+
 func (n *nginxIngressControllerReconciler) GetCollisionCount(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) (int32, error) {
 	lgr := log.FromContext(ctx)
 
@@ -334,10 +556,14 @@ func (n *nginxIngressControllerReconciler) collides(ctx context.Context, nic *ap
 }
 
 // updateStatus updates the status of the NginxIngressController resource. If a nil controller Deployment or IngressClass is passed, the status is defaulted for those fields if they are not already set.
-func (n *nginxIngressControllerReconciler) updateStatus(nic *approutingv1alpha1.NginxIngressController, controllerDeployment *appsv1.Deployment, ic *netv1.IngressClass, managedResourceRefs []approutingv1alpha1.ManagedObjectReference, err error) {
+func (n *nginxIngressControllerReconciler) updateStatus(nic *approutingv1alpha1.NginxIngressController, controllerDeployment *appsv1.Deployment, ic *netv1.IngressClass, loadBalancers []*corev1.Service, ingresses []netv1.Ingress, managedResourceRefs []approutingv1alpha1.ManagedObjectReference, err error) {
 	n.updateStatusManagedResourceRefs(nic, managedResourceRefs)
+	n.updateStatusLoadBalancers(nic, loadBalancers)
+	n.updateStatusIngresses(nic, ingresses)
 
 	n.updateStatusIngressClass(nic, ic)
+	n.updateStatusConfig(nic)
+	n.updateStatusSuspended(nic)
 
 	// default conditions
 	if controllerDeployment == nil || controllerDeployment.CreationTimestamp.IsZero() {
@@ -354,6 +580,7 @@ func (n *nginxIngressControllerReconciler) updateStatus(nic *approutingv1alpha1.
 	}
 
 	n.updateStatusControllerReplicas(nic, controllerDeployment)
+	n.updateStatusVersion(nic, controllerDeployment)
 	n.updateStatusAvailable(nic)
 
 	// error checking at end to take precedence over other conditions
@@ -368,6 +595,131 @@ func (n *nginxIngressControllerReconciler) updateStatusManagedResourceRefs(nic *
 	nic.Status.ManagedResourceRefs = managedResourceRefs
 }
 
+// updateStatusLoadBalancers reports the type and addresses of each load balancer Service
+func (n *nginxIngressControllerReconciler) updateStatusLoadBalancers(nic *approutingv1alpha1.NginxIngressController, loadBalancers []*corev1.Service) {
+	if loadBalancers == nil {
+		return
+	}
+
+	statuses := make([]approutingv1alpha1.LoadBalancerStatus, 0, len(loadBalancers))
+	for _, svc := range loadBalancers {
+		status := approutingv1alpha1.LoadBalancerStatus{
+			Type:        approutingv1alpha1.PublicLoadBalancerType,
+			ServiceName: svc.Name,
+		}
+		if manifests.IsInternalLoadBalancer(svc) {
+			status.Type = approutingv1alpha1.InternalLoadBalancerType
+		}
+
+		for _, ing := range svc.Status.LoadBalancer.Ingress {
+			switch {
+			case ing.IP != "":
+				status.Addresses = append(status.Addresses, ing.IP)
+			case ing.Hostname != "":
+				status.Addresses = append(status.Addresses, ing.Hostname)
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	nic.Status.LoadBalancers = statuses
+	nic.Status.LoadBalancerIngress = loadBalancers[0].Status.LoadBalancer.Ingress
+	n.updateStatusLoadBalancerReady(nic, loadBalancers)
+}
+
+// updateStatusLoadBalancerReady sets the LoadBalancerReady condition, which is False once a load balancer Service has gone without an address
+// for longer than the provisioning timeout
+func (n *nginxIngressControllerReconciler) updateStatusLoadBalancerReady(nic *approutingv1alpha1.NginxIngressController, loadBalancers []*corev1.Service) {
+	var pending, timedOut []string
+	for _, svc := range loadBalancers {
+		if len(svc.Status.LoadBalancer.Ingress) > 0 {
+			continue
+		}
+
+		if svc.CreationTimestamp.IsZero() || time.Since(svc.CreationTimestamp.Time) < loadBalancerProvisioningTimeout {
+			pending = append(pending, svc.Name)
+			continue
+		}
+
+		timedOut = append(timedOut, svc.Name)
+	}
+
+	switch {
+	case len(timedOut) > 0:
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeLoadBalancerReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "LoadBalancerAddressTimeout",
+			Message: fmt.Sprintf("Load balancer Service %s has no address after %s, check the Service events for the reason Azure could not provision it", strings.Join(timedOut, ", "), loadBalancerProvisioningTimeout.String()),
+		})
+	case len(pending) > 0:
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeLoadBalancerReady,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "LoadBalancerProvisioning",
+			Message: fmt.Sprintf("Waiting for load balancer Service %s to be assigned an address", strings.Join(pending, ", ")),
+		})
+	default:
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeLoadBalancerReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "LoadBalancerReady",
+			Message: "Load balancers have an address",
+		})
+	}
+}
+
+// loadBalancerProvisioningRemaining returns the shortest time left before a load balancer Service without an address times out, or zero if none are waiting
+func loadBalancerProvisioningRemaining(loadBalancers []*corev1.Service) time.Duration {
+	var remaining time.Duration
+	for _, svc := range loadBalancers {
+		if len(svc.Status.LoadBalancer.Ingress) > 0 || svc.CreationTimestamp.IsZero() {
+			continue
+		}
+
+		left := loadBalancerProvisioningTimeout - time.Since(svc.CreationTimestamp.Time)
+		if left > 0 && (remaining == 0 || left < remaining) {
+			remaining = left
+		}
+	}
+
+	return remaining
+}
+
+// updateStatusIngresses reports the Ingresses using the NginxIngressController's IngressClass. The list is truncated to keep the status small
+func (n *nginxIngressControllerReconciler) updateStatusIngresses(nic *approutingv1alpha1.NginxIngressController, ingresses []netv1.Ingress) {
+	if ingresses == nil {
+		return
+	}
+
+	refs := make([]approutingv1alpha1.IngressReference, 0, min(len(ingresses), approutingv1alpha1.MaxIngressReferences))
+	for _, ing := range ingresses {
+		if len(refs) == approutingv1alpha1.MaxIngressReferences {
+			break
+		}
+
+		refs = append(refs, approutingv1alpha1.IngressReference{Name: ing.Name, Namespace: ing.Namespace})
+	}
+
+	nic.Status.IngressCount = int32(len(ingresses))
+	nic.Status.Ingresses = refs
+}
+
+// updateStatusSuspended defaults the Suspended condition. Suspending and resuming set it themselves
+func (n *nginxIngressControllerReconciler) updateStatusSuspended(nic *approutingv1alpha1.NginxIngressController) {
+	if nic.GetCondition(approutingv1alpha1.ConditionTypeSuspended) != nil {
+		return
+	}
+
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeSuspended,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NotSuspended",
+		Message: "Managed resources are reconciled",
+	})
+}
+
 func (n *nginxIngressControllerReconciler) updateStatusIngressClass(nic *approutingv1alpha1.NginxIngressController, ic *netv1.IngressClass) {
 	if ic == nil || ic.CreationTimestamp.IsZero() {
 		nic.SetCondition(metav1.Condition{
@@ -386,6 +738,44 @@ func (n *nginxIngressControllerReconciler) updateStatusIngressClass(nic *approut
 	}
 }
 
+func (n *nginxIngressControllerReconciler) updateStatusConfig(nic *approutingv1alpha1.NginxIngressController) {
+	rejected := rejectedConfigKeys(nic)
+	if len(rejected) == 0 {
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeConfigAccepted,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ConfigAccepted",
+			Message: "All spec.config keys were merged into the NGINX ConfigMap",
+		})
+		return
+	}
+
+	msg := "Rejected spec.config keys: " + strings.Join(rejected, "; ")
+	if current := nic.GetCondition(approutingv1alpha1.ConditionTypeConfigAccepted); current == nil || current.Status != metav1.ConditionFalse || current.Message != msg {
+		n.events.Event(nic, corev1.EventTypeWarning, "ConfigRejected", msg)
+	}
+
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeConfigAccepted,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ConfigKeysRejected",
+		Message: msg,
+	})
+}
+
+// rejectedConfigKeys returns a sorted description of each spec.config key that isn't merged into the NGINX ConfigMap
+func rejectedConfigKeys(nic *approutingv1alpha1.NginxIngressController) []string {
+	var rejected []string
+	for k, v := range nic.Spec.Config {
+		if err := manifests.ValidateNginxConfigOverride(k, v); err != nil {
+			rejected = append(rejected, fmt.Sprintf("%q (%s)", k, err.Error()))
+		}
+	}
+
+	sort.Strings(rejected)
+	return rejected
+}
+
 func (n *nginxIngressControllerReconciler) updateStatusNilDeployment(nic *approutingv1alpha1.NginxIngressController) {
 	nic.SetCondition(metav1.Condition{
 		Type:    approutingv1alpha1.ConditionTypeControllerAvailable,
@@ -412,6 +802,24 @@ func (n *nginxIngressControllerReconciler) updateStatusControllerReplicas(nic *a
 	nic.Status.ControllerReplicas = deployment.Status.Replicas
 }
 
+// updateStatusVersion reports the newest version available to the NginxIngressController and the version that's fully rolled out
+func (n *nginxIngressControllerReconciler) updateStatusVersion(nic *approutingv1alpha1.NginxIngressController, deployment *appsv1.Deployment) {
+	nic.Status.AvailableVersion = manifests.LatestAvailableNginxVersion(n.conf, ToNginxIngressConfig(nic, n.defaultNicControllerClass)).Name()
+
+	if deployment == nil || deployment.CreationTimestamp.IsZero() || len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return
+	}
+
+	// the version is only current once every replica runs it, otherwise we keep reporting the previous one
+	if !deploymentRolledOut(deployment) {
+		return
+	}
+
+	if tag := imageTag(deployment); tag != "" {
+		nic.Status.CurrentVersion = tag
+	}
+}
+
 func (n *nginxIngressControllerReconciler) updateStatusAvailable(nic *approutingv1alpha1.NginxIngressController) {
 	controllerAvailable := nic.GetCondition(approutingv1alpha1.ConditionTypeControllerAvailable)
 	icAvailable := nic.GetCondition(approutingv1alpha1.ConditionTypeIngressClassReady)
@@ -451,6 +859,24 @@ func (n *nginxIngressControllerReconciler) updateStatusFromError(nic *approuting
 		})
 		n.events.Event(nic, corev1.EventTypeWarning, "TooManyCollisions", "Too many collisions with existing resources. Change the spec.ControllerNamePrefix to something more unique in a new NginxIngressController.")
 	}
+	if errors.Is(err, versionUnavailableErr) {
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeProgressing,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VersionUnavailable",
+			Message: "The requested NGINX Ingress Controller version is not available",
+		})
+		n.events.Event(nic, corev1.EventTypeWarning, "VersionUnavailable", fmt.Sprintf("The requested NGINX Ingress Controller version is not available. Change spec.version to an available version: %s", err.Error()))
+	}
+	if errors.Is(err, lbConflictErr) {
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeProgressing,
+			Status:  metav1.ConditionFalse,
+			Reason:  "LoadBalancerConflict",
+			Message: "spec.loadBalancer disagrees with spec.loadBalancerAnnotations",
+		})
+		n.events.Event(nic, corev1.EventTypeWarning, "LoadBalancerConflict", fmt.Sprintf("spec.loadBalancer disagrees with spec.loadBalancerAnnotations. Remove the conflicting annotations: %s", err.Error()))
+	}
 }
 
 func (n *nginxIngressControllerReconciler) updateStatusControllerAvailable(nic *approutingv1alpha1.NginxIngressController, availableCondition appsv1.DeploymentCondition) {
@@ -520,7 +946,7 @@ func (n *nginxIngressControllerReconciler) updateStatusControllerProgressing(nic
 }
 
 func isUnreconcilableError(err error) bool {
-	return errors.Is(err, icCollisionErr) || errors.Is(err, maxCollisionsErr)
+	return errors.Is(err, icCollisionErr) || errors.Is(err, maxCollisionsErr) || errors.Is(err, versionUnavailableErr) || errors.Is(err, lbConflictErr)
 }
 
 func ToNginxIngressConfig(nic *approutingv1alpha1.NginxIngressController, defaultNicControllerClass string) *manifests.NginxIngressConfig {
@@ -566,12 +992,22 @@ func ToNginxIngressConfig(nic *approutingv1alpha1.NginxIngressController, defaul
 		ServiceConfig: &manifests.ServiceConfig{
 			Annotations:              nic.Spec.LoadBalancerAnnotations,
 			LoadBalancerSourceRanges: nic.Spec.LoadBalancerSourceRanges,
+			LoadBalancer:             getLoadBalancerConfig(nic),
 		},
+		SecondaryServiceConfig:         getSecondaryServiceConfig(nic),
 		HTTPDisabled:                   nic.Spec.HTTPDisabled,
 		EnableSSLPassthrough:           nic.Spec.EnableSSLPassthrough,
 		MinReplicas:                    minReplicas,
 		MaxReplicas:                    maxReplicas,
 		TargetCPUUtilizationPercentage: getTargetCPUUtilizationPercentage(nic),
+		ScalingMetric:                  getScalingMetric(nic, resourceName),
+	}
+
+	if scaling != nil {
+		if scaling.TargetMemoryUtilization != nil {
+			nginxIng.TargetMemoryUtilizationPercentage = *scaling.TargetMemoryUtilization
+		}
+		nginxIng.ScalingBehavior = scaling.Behavior
 	}
 
 	if cert := nic.Spec.DefaultSSLCertificate; cert != nil {
@@ -607,6 +1043,34 @@ func ToNginxIngressConfig(nic *approutingv1alpha1.NginxIngressController, defaul
 		nginxIng.LogFormat = *nic.Spec.LogFormat
 	}
 
+	if len(nic.Spec.Config) != 0 {
+		nginxIng.Config = nic.Spec.Config
+	}
+
+	if nic.Spec.ImageFlavor != nil {
+		nginxIng.ImageFlavor = manifests.NginxImageFlavor(*nic.Spec.ImageFlavor)
+	}
+
+	if nic.Spec.UpgradeChannel != nil {
+		nginxIng.UpgradeChannel = manifests.NginxUpgradeChannel(*nic.Spec.UpgradeChannel)
+	}
+
+	if nic.Spec.Version != nil {
+		nginxIng.PinnedVersion = *nic.Spec.Version
+	}
+
+	if tmpl := nic.Spec.PodTemplate; tmpl != nil {
+		nginxIng.PodTemplate = &manifests.PodTemplateConfig{
+			Labels:                    tmpl.Labels,
+			Annotations:               tmpl.Annotations,
+			Resources:                 tmpl.Resources,
+			NodeSelector:              tmpl.NodeSelector,
+			Tolerations:               tmpl.Tolerations,
+			TopologySpreadConstraints: tmpl.TopologySpreadConstraints,
+			PriorityClassName:         tmpl.PriorityClassName,
+		}
+	}
+
 	return nginxIng
 }
 
@@ -637,3 +1101,127 @@ func getTargetCPUUtilizationPercentage(nic *approutingv1alpha1.NginxIngressContr
 		return defaultTargetCPUUtilization
 	}
 }
+
+// getLoadBalancerConfig translates spec.loadBalancer into the load balancer configuration of the Service
+func getLoadBalancerConfig(nic *approutingv1alpha1.NginxIngressController) *manifests.LoadBalancerConfig {
+	if nic == nil || nic.Spec.LoadBalancer == nil {
+		return nil
+	}
+
+	lb := nic.Spec.LoadBalancer
+
+	ret := &manifests.LoadBalancerConfig{}
+	if lb.Type != nil {
+		ret.Internal = util.ToPtr(*lb.Type == approutingv1alpha1.InternalLoadBalancerType)
+	}
+	if lb.PublicIP != nil {
+		ret.PublicIPName = lb.PublicIP.Name
+		if lb.PublicIP.ResourceGroup != nil {
+			ret.PublicIPResourceGroup = *lb.PublicIP.ResourceGroup
+		}
+	}
+	if lb.DNSLabelName != nil {
+		ret.DNSLabelName = *lb.DNSLabelName
+	}
+	if lb.Subnet != nil {
+		ret.Subnet = *lb.Subnet
+	}
+	if lb.HealthProbeRequestPath != nil {
+		ret.HealthProbeRequestPath = *lb.HealthProbeRequestPath
+	}
+	if lb.ExternalTrafficPolicy != nil {
+		ret.ExternalTrafficPolicy = *lb.ExternalTrafficPolicy
+	}
+	if pls := lb.PrivateLinkService; pls != nil {
+		ret.PrivateLinkService = &manifests.PrivateLinkServiceConfig{
+			VisibilitySubscriptions:   pls.VisibilitySubscriptions,
+			AutoApprovalSubscriptions: pls.AutoApprovalSubscriptions,
+		}
+		if pls.Name != nil {
+			ret.PrivateLinkService.Name = *pls.Name
+		}
+		if pls.IPConfigurationSubnet != nil {
+			ret.PrivateLinkService.IPConfigurationSubnet = *pls.IPConfigurationSubnet
+		}
+	}
+
+	return ret
+}
+
+// getSecondaryServiceConfig translates spec.loadBalancer.secondary into the configuration of the second load balancer Service which
+// has the opposite type of the first one
+func getSecondaryServiceConfig(nic *approutingv1alpha1.NginxIngressController) *manifests.ServiceConfig {
+	if nic == nil || nic.Spec.LoadBalancer == nil || nic.Spec.LoadBalancer.Secondary == nil || nic.Spec.LoadBalancer.Type == nil {
+		return nil
+	}
+
+	primary := getLoadBalancerConfig(nic)
+	secondary := nic.Spec.LoadBalancer.Secondary
+	lb := &manifests.LoadBalancerConfig{
+		Internal:               util.ToPtr(!*primary.Internal),
+		HealthProbeRequestPath: primary.HealthProbeRequestPath,
+		ExternalTrafficPolicy:  primary.ExternalTrafficPolicy,
+	}
+	if secondary.PublicIP != nil {
+		lb.PublicIPName = secondary.PublicIP.Name
+		if secondary.PublicIP.ResourceGroup != nil {
+			lb.PublicIPResourceGroup = *secondary.PublicIP.ResourceGroup
+		}
+	}
+	if secondary.DNSLabelName != nil {
+		lb.DNSLabelName = *secondary.DNSLabelName
+	}
+	if secondary.Subnet != nil {
+		lb.Subnet = *secondary.Subnet
+	}
+
+	return &manifests.ServiceConfig{
+		Annotations:              secondary.Annotations,
+		LoadBalancerSourceRanges: nic.Spec.LoadBalancerSourceRanges,
+		LoadBalancer:             lb,
+	}
+}
+
+// getScalingMetric translates spec.scaling.metric into the custom or external metric the HPA scales on
+func getScalingMetric(nic *approutingv1alpha1.NginxIngressController, resourceName string) *manifests.ScalingMetricConfig {
+	if nic == nil || nic.Spec.Scaling == nil || nic.Spec.Scaling.Metric == nil {
+		return nil
+	}
+
+	metric := nic.Spec.Scaling.Metric
+	ret := &manifests.ScalingMetricConfig{
+		Name:               metric.Name,
+		External:           metric.Source == approutingv1alpha1.ExternalScalingMetricSource,
+		TargetAverageValue: metric.TargetAverageValue,
+	}
+
+	matchLabels := map[string]string{}
+	switch metric.Type {
+	case approutingv1alpha1.ActiveConnectionsScalingMetric:
+		if ret.Name == "" {
+			ret.Name = manifests.NginxActiveConnectionsMetric
+			// the nginx connections metric reports every connection state, we only scale on active ones
+			matchLabels["state"] = "active"
+		}
+	default:
+		if ret.Name == "" {
+			ret.Name = manifests.NginxRequestsPerSecondMetric
+		}
+	}
+
+	if ret.External {
+		if metric.Selector != nil {
+			ret.Selector = metric.Selector.DeepCopy()
+			return ret
+		}
+
+		// external metrics aren't scoped to the scale target so we select the series of our pods
+		matchLabels["app"] = resourceName
+	}
+
+	if len(matchLabels) > 0 {
+		ret.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+
+	return ret
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
@@ -13,12 +14,18 @@ import (
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestReconcileResources(t *testing.T) {
@@ -470,6 +477,351 @@ func TestUpdateStatusManagedResourceRefs(t *testing.T) {
 	})
 }
 
+func TestUpdateStatusLoadBalancers(t *testing.T) {
+	t.Run("nil load balancers", func(t *testing.T) {
+		nic := &approutingv1alpha1.NginxIngressController{
+			Status: approutingv1alpha1.NginxIngressControllerStatus{
+				LoadBalancers: []approutingv1alpha1.LoadBalancerStatus{{Type: approutingv1alpha1.PublicLoadBalancerType, ServiceName: "nginx-0"}},
+			},
+		}
+		n := &nginxIngressControllerReconciler{}
+		n.updateStatusLoadBalancers(nic, nil)
+
+		require.Equal(t, []approutingv1alpha1.LoadBalancerStatus{{Type: approutingv1alpha1.PublicLoadBalancerType, ServiceName: "nginx-0"}}, nic.Status.LoadBalancers)
+	})
+
+	t.Run("public and internal load balancers", func(t *testing.T) {
+		nic := &approutingv1alpha1.NginxIngressController{}
+		n := &nginxIngressControllerReconciler{}
+		n.updateStatusLoadBalancers(nic, []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx-0"},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{IP: "20.1.2.3"}, {Hostname: "lb.example.com"}},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "nginx-0-internal",
+					Annotations: map[string]string{manifests.AzureLoadBalancerInternalAnnotation: "true"},
+				},
+			},
+		})
+
+		require.Equal(t, []approutingv1alpha1.LoadBalancerStatus{
+			{
+				Type:        approutingv1alpha1.PublicLoadBalancerType,
+				ServiceName: "nginx-0",
+				Addresses:   []string{"20.1.2.3", "lb.example.com"},
+			},
+			{
+				Type:        approutingv1alpha1.InternalLoadBalancerType,
+				ServiceName: "nginx-0-internal",
+			},
+		}, nic.Status.LoadBalancers)
+		require.Equal(t, []corev1.LoadBalancerIngress{{IP: "20.1.2.3"}, {Hostname: "lb.example.com"}}, nic.Status.LoadBalancerIngress)
+		require.Equal(t, metav1.ConditionUnknown, nic.GetCondition(approutingv1alpha1.ConditionTypeLoadBalancerReady).Status)
+	})
+}
+
+func TestUpdateStatusLoadBalancerReady(t *testing.T) {
+	service := func(name string, age time.Duration, addresses ...string) *corev1.Service {
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if age > 0 {
+			svc.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		}
+		for _, address := range addresses {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: address})
+		}
+		return svc
+	}
+
+	cases := []struct {
+		name          string
+		loadBalancers []*corev1.Service
+		status        metav1.ConditionStatus
+		reason        string
+		remaining     bool
+	}{
+		{
+			name:          "all load balancers have an address",
+			loadBalancers: []*corev1.Service{service("nginx-0", time.Hour, "20.1.2.3"), service("nginx-0-internal", time.Minute, "10.0.0.4")},
+			status:        metav1.ConditionTrue,
+			reason:        "LoadBalancerReady",
+		},
+		{
+			name:          "load balancer provisioning",
+			loadBalancers: []*corev1.Service{service("nginx-0", time.Minute)},
+			status:        metav1.ConditionUnknown,
+			reason:        "LoadBalancerProvisioning",
+			remaining:     true,
+		},
+		{
+			name:          "load balancer not created yet",
+			loadBalancers: []*corev1.Service{service("nginx-0", 0)},
+			status:        metav1.ConditionUnknown,
+			reason:        "LoadBalancerProvisioning",
+		},
+		{
+			name:          "load balancer timed out",
+			loadBalancers: []*corev1.Service{service("nginx-0", time.Hour, "20.1.2.3"), service("nginx-0-internal", time.Hour)},
+			status:        metav1.ConditionFalse,
+			reason:        "LoadBalancerAddressTimeout",
+		},
+		{
+			name:          "timed out takes precedence over provisioning",
+			loadBalancers: []*corev1.Service{service("nginx-0", time.Hour), service("nginx-0-internal", time.Minute)},
+			status:        metav1.ConditionFalse,
+			reason:        "LoadBalancerAddressTimeout",
+			remaining:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nic := &approutingv1alpha1.NginxIngressController{}
+			n := &nginxIngressControllerReconciler{}
+			n.updateStatusLoadBalancerReady(nic, tc.loadBalancers)
+
+			got := nic.GetCondition(approutingv1alpha1.ConditionTypeLoadBalancerReady)
+			require.NotNil(t, got)
+			require.Equal(t, tc.status, got.Status)
+			require.Equal(t, tc.reason, got.Reason)
+
+			remaining := loadBalancerProvisioningRemaining(tc.loadBalancers)
+			if tc.remaining {
+				require.Greater(t, remaining, time.Duration(0))
+				require.LessOrEqual(t, remaining, loadBalancerProvisioningTimeout-time.Minute)
+			} else {
+				require.Zero(t, remaining)
+			}
+		})
+	}
+}
+
+func TestUpdateStatusIngresses(t *testing.T) {
+	t.Run("nil ingresses", func(t *testing.T) {
+		nic := &approutingv1alpha1.NginxIngressController{
+			Status: approutingv1alpha1.NginxIngressControllerStatus{
+				IngressCount: 1,
+				Ingresses:    []approutingv1alpha1.IngressReference{{Name: "a", Namespace: "default"}},
+			},
+		}
+		n := &nginxIngressControllerReconciler{}
+		n.updateStatusIngresses(nic, nil)
+
+		require.Equal(t, int32(1), nic.Status.IngressCount)
+		require.Equal(t, []approutingv1alpha1.IngressReference{{Name: "a", Namespace: "default"}}, nic.Status.Ingresses)
+	})
+
+	t.Run("no ingresses", func(t *testing.T) {
+		nic := &approutingv1alpha1.NginxIngressController{
+			Status: approutingv1alpha1.NginxIngressControllerStatus{
+				IngressCount: 1,
+				Ingresses:    []approutingv1alpha1.IngressReference{{Name: "a", Namespace: "default"}},
+			},
+		}
+		n := &nginxIngressControllerReconciler{}
+		n.updateStatusIngresses(nic, []netv1.Ingress{})
+
+		require.Zero(t, nic.Status.IngressCount)
+		require.Empty(t, nic.Status.Ingresses)
+	})
+
+	t.Run("ingresses over the limit", func(t *testing.T) {
+		ingresses := make([]netv1.Ingress, approutingv1alpha1.MaxIngressReferences+10)
+		for i := range ingresses {
+			ingresses[i].Name = fmt.Sprintf("ingress-%03d", i)
+			ingresses[i].Namespace = "default"
+		}
+
+		nic := &approutingv1alpha1.NginxIngressController{}
+		n := &nginxIngressControllerReconciler{}
+		n.updateStatusIngresses(nic, ingresses)
+
+		require.Equal(t, int32(approutingv1alpha1.MaxIngressReferences+10), nic.Status.IngressCount)
+		require.Len(t, nic.Status.Ingresses, approutingv1alpha1.MaxIngressReferences)
+		require.Equal(t, approutingv1alpha1.IngressReference{Name: "ingress-000", Namespace: "default"}, nic.Status.Ingresses[0])
+		require.Equal(t, approutingv1alpha1.IngressReference{Name: "ingress-049", Namespace: "default"}, nic.Status.Ingresses[approutingv1alpha1.MaxIngressReferences-1])
+	})
+}
+
+func dualLoadBalancerNic() *approutingv1alpha1.NginxIngressController {
+	return &approutingv1alpha1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "dual",
+			UID:  "dual-uid",
+		},
+		Spec: approutingv1alpha1.NginxIngressControllerSpec{
+			IngressClassName:     "dual-class",
+			ControllerNamePrefix: "dual",
+			LoadBalancer: &approutingv1alpha1.LoadBalancer{
+				Type:      util.ToPtr(approutingv1alpha1.PublicLoadBalancerType),
+				Secondary: &approutingv1alpha1.SecondaryLoadBalancer{Subnet: util.ToPtr("ingress-subnet")},
+			},
+		},
+	}
+}
+
+func TestClassIngresses(t *testing.T) {
+	ingress := func(namespace, name, class string, hosts ...string) *netv1.Ingress {
+		ing := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+		if class != "" {
+			ing.Spec.IngressClassName = util.ToPtr(class)
+		}
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, netv1.IngressRule{Host: host})
+		}
+		return ing
+	}
+	annotated := func(ing *netv1.Ingress, class string) *netv1.Ingress {
+		ing.Annotations = map[string]string{"kubernetes.io/ingress.class": class}
+		return ing
+	}
+
+	cl := fake.NewClientBuilder().WithIndex(&netv1.Ingress{}, "testIndex", ingressIngressClassNameIndexFn).WithObjects(
+		ingress("default", "b", "dual-class", "a.example.com", ""),
+		ingress("default", "a", "dual-class", "b.example.com", "a.example.com"),
+		ingress("app", "c", "dual-class"),
+		ingress("default", "other-class", "other", "other.example.com"),
+		ingress("default", "no-class", "", "none.example.com"),
+		annotated(ingress("legacy", "d", "", "d.example.com"), "dual-class"),
+		annotated(ingress("legacy", "e", "other", "e.example.com"), "dual-class"),
+	).Build()
+	n := &nginxIngressControllerReconciler{client: cl, ingressClassNameIndex: "testIndex"}
+
+	ingresses, err := n.classIngresses(context.Background(), dualLoadBalancerNic())
+	require.NoError(t, err)
+	names := make([]string, 0, len(ingresses))
+	for _, ing := range ingresses {
+		names = append(names, ing.Namespace+"/"+ing.Name)
+	}
+	require.Equal(t, []string{"app/c", "default/a", "default/b", "legacy/d"}, names)
+	require.Equal(t, []string{"a.example.com", "b.example.com", "d.example.com"}, ingressHostnames(ingresses))
+
+	none := dualLoadBalancerNic()
+	none.Spec.IngressClassName = "unused-class"
+	ingresses, err = n.classIngresses(context.Background(), none)
+	require.NoError(t, err)
+	require.NotNil(t, ingresses)
+	require.Empty(t, ingresses)
+}
+
+func TestSetIngressZoneTypes(t *testing.T) {
+	const annotation = "kubernetes.azure.com/external-dns-zone-type"
+	ingress := func(name string, annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+
+	cl := fake.NewClientBuilder().WithObjects(
+		ingress("unset", nil),
+		ingress("public", map[string]string{annotation: "public"}),
+		ingress("private", map[string]string{annotation: "private", "other": "kept"}),
+	).Build()
+	n := &nginxIngressControllerReconciler{client: cl, conf: &config.Config{NS: "app-routing-system"}}
+
+	list := func() []netv1.Ingress {
+		ingresses := &netv1.IngressList{}
+		require.NoError(t, cl.List(context.Background(), ingresses))
+		return ingresses.Items
+	}
+
+	// the dual load balancer nic's primary is public
+	res := n.ManagedResources(dualLoadBalancerNic())
+	require.Equal(t, "public", res.IngressZoneType())
+	require.NoError(t, n.setIngressZoneTypes(context.Background(), list(), res.IngressZoneType()))
+	for _, ing := range list() {
+		require.Equal(t, "public", ing.Annotations[annotation], ing.Name)
+	}
+
+	require.NoError(t, n.setIngressZoneTypes(context.Background(), list(), ""))
+	for _, ing := range list() {
+		require.NotContains(t, ing.Annotations, annotation, ing.Name)
+		if ing.Name == "private" {
+			require.Equal(t, "kept", ing.Annotations["other"])
+		}
+	}
+}
+
+func TestCleanupSecondaryServices(t *testing.T) {
+	nic := dualLoadBalancerNic()
+	owned := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "app-routing-system",
+				OwnerReferences: manifests.GetOwnerRefs(nic, true),
+			},
+		}
+	}
+	notOwned := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-0-public", Namespace: "app-routing-system"},
+	}
+
+	t.Run("secondary load balancer in use", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithObjects(owned("dual-0-internal"), notOwned.DeepCopy()).Build()
+		n := &nginxIngressControllerReconciler{client: cl, conf: &config.Config{NS: "app-routing-system"}}
+
+		res := n.ManagedResources(nic)
+		require.Equal(t, "dual-0-internal", res.SecondaryService.Name)
+		require.NoError(t, n.cleanupSecondaryServices(context.Background(), nic, res))
+
+		require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: "app-routing-system", Name: "dual-0-internal"}, &corev1.Service{}))
+		require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: "app-routing-system", Name: "dual-0-public"}, &corev1.Service{}))
+	})
+
+	t.Run("secondary load balancer removed", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithObjects(owned("dual-0-internal"), notOwned.DeepCopy()).Build()
+		n := &nginxIngressControllerReconciler{client: cl, conf: &config.Config{NS: "app-routing-system"}}
+
+		removed := nic.DeepCopy()
+		removed.Spec.LoadBalancer.Secondary = nil
+		res := n.ManagedResources(removed)
+		require.Nil(t, res.SecondaryService)
+		require.NoError(t, n.cleanupSecondaryServices(context.Background(), removed, res))
+
+		err := cl.Get(context.Background(), types.NamespacedName{Namespace: "app-routing-system", Name: "dual-0-internal"}, &corev1.Service{})
+		require.True(t, apierrors.IsNotFound(err), "expected unused secondary load balancer to be deleted")
+		require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Namespace: "app-routing-system", Name: "dual-0-public"}, &corev1.Service{}), "expected service not owned by the nic to be kept")
+	})
+}
+
+func TestNicsForIngress(t *testing.T) {
+	single := dualLoadBalancerNic()
+	single.Name = "single"
+	single.Spec.IngressClassName = "single-class"
+	single.Spec.LoadBalancer.Secondary = nil
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+	require.NoError(t, netv1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithIndex(
+		&approutingv1alpha1.NginxIngressController{}, "testIndex", ingressClassNameIndexFn,
+	).WithObjects(dualLoadBalancerNic(), single).Build()
+	n := &nginxIngressControllerReconciler{client: cl, ingressClassNameIndex: "testIndex"}
+
+	require.Nil(t, n.nicsForIngress(context.Background(), &netv1.Ingress{}))
+	require.Nil(t, n.nicsForIngress(context.Background(), &netv1.Ingress{Spec: netv1.IngressSpec{IngressClassName: util.ToPtr("other")}}))
+	require.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "dual"}}},
+		n.nicsForIngress(context.Background(), &netv1.Ingress{Spec: netv1.IngressSpec{IngressClassName: util.ToPtr("dual-class")}}),
+	)
+	require.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "single"}}},
+		n.nicsForIngress(context.Background(), &netv1.Ingress{Spec: netv1.IngressSpec{IngressClassName: util.ToPtr("single-class")}}),
+	)
+	require.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "single"}}},
+		n.nicsForIngress(context.Background(), &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"kubernetes.io/ingress.class": "single-class"},
+		}}),
+	)
+}
+
 func TestUpdateStatusIngressClass(t *testing.T) {
 	t.Run("nil ingress class", func(t *testing.T) {
 		nic := &approutingv1alpha1.NginxIngressController{}
@@ -507,6 +859,62 @@ func TestUpdateStatusIngressClass(t *testing.T) {
 	})
 }
 
+func TestUpdateStatusConfig(t *testing.T) {
+	t.Run("no config", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		nic := &approutingv1alpha1.NginxIngressController{}
+		n := &nginxIngressControllerReconciler{events: recorder}
+		n.updateStatusConfig(nic)
+
+		got := nic.GetCondition(approutingv1alpha1.ConditionTypeConfigAccepted)
+		require.NotNil(t, got)
+		require.Equal(t, metav1.ConditionTrue, got.Status)
+		require.Len(t, recorder.Events, 0)
+	})
+
+	t.Run("accepted config", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		nic := &approutingv1alpha1.NginxIngressController{
+			Spec: approutingv1alpha1.NginxIngressControllerSpec{
+				Config: map[string]string{"proxy-body-size": "8m"},
+			},
+		}
+		n := &nginxIngressControllerReconciler{events: recorder}
+		n.updateStatusConfig(nic)
+
+		got := nic.GetCondition(approutingv1alpha1.ConditionTypeConfigAccepted)
+		require.NotNil(t, got)
+		require.Equal(t, metav1.ConditionTrue, got.Status)
+		require.Len(t, recorder.Events, 0)
+	})
+
+	t.Run("rejected config", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		nic := &approutingv1alpha1.NginxIngressController{
+			Spec: approutingv1alpha1.NginxIngressControllerSpec{
+				Config: map[string]string{
+					"proxy-body-size":           "8m",
+					"server-snippet":            "return 200;",
+					"allow-snippet-annotations": "false",
+				},
+			},
+		}
+		n := &nginxIngressControllerReconciler{events: recorder}
+		n.updateStatusConfig(nic)
+
+		got := nic.GetCondition(approutingv1alpha1.ConditionTypeConfigAccepted)
+		require.NotNil(t, got)
+		require.Equal(t, metav1.ConditionFalse, got.Status)
+		require.Equal(t, "ConfigKeysRejected", got.Reason)
+		require.Equal(t, `Rejected spec.config keys: "allow-snippet-annotations" (managed by App Routing); "server-snippet" (snippets are not allowed)`, got.Message)
+		require.Equal(t, "Warning ConfigRejected "+got.Message, <-recorder.Events)
+
+		// unchanged rejection shouldn't publish another event
+		n.updateStatusConfig(nic)
+		require.Len(t, recorder.Events, 0)
+	})
+}
+
 func TestUpdateStatusNilDeployment(t *testing.T) {
 	nic := &approutingv1alpha1.NginxIngressController{}
 	n := &nginxIngressControllerReconciler{}
@@ -736,6 +1144,103 @@ func TestUpdateStatusFromError(t *testing.T) {
 		event := <-recorder.Events
 		require.Equal(t, event, "Warning TooManyCollisions Too many collisions with existing resources. Change the spec.ControllerNamePrefix to something more unique in a new NginxIngressController.")
 	})
+
+	t.Run("version unavailable error", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		n := &nginxIngressControllerReconciler{
+			events: recorder,
+		}
+		nic := &approutingv1alpha1.NginxIngressController{}
+		nic.Generation = 1
+		n.updateStatusFromError(nic, fmt.Errorf("%w: version \"v0.0.1\" is not available", versionUnavailableErr))
+		got := nic.GetCondition(approutingv1alpha1.ConditionTypeProgressing)
+		require.True(t, got.Status == metav1.ConditionFalse)
+		require.Equal(t, "VersionUnavailable", got.Reason)
+		require.True(t, got.ObservedGeneration == nic.Generation)
+
+		event := <-recorder.Events
+		require.Equal(t, event, `Warning VersionUnavailable The requested NGINX Ingress Controller version is not available. Change spec.version to an available version: nginx version unavailable: version "v0.0.1" is not available`)
+	})
+
+	t.Run("load balancer conflict error", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		n := &nginxIngressControllerReconciler{
+			events: recorder,
+		}
+		nic := &approutingv1alpha1.NginxIngressController{}
+		nic.Generation = 1
+		n.updateStatusFromError(nic, fmt.Errorf("%w: annotation foo is \"true\" but spec.loadBalancer sets it to \"false\"", lbConflictErr))
+		got := nic.GetCondition(approutingv1alpha1.ConditionTypeProgressing)
+		require.True(t, got.Status == metav1.ConditionFalse)
+		require.Equal(t, "LoadBalancerConflict", got.Reason)
+		require.True(t, got.ObservedGeneration == nic.Generation)
+
+		event := <-recorder.Events
+		require.Equal(t, event, `Warning LoadBalancerConflict spec.loadBalancer disagrees with spec.loadBalancerAnnotations. Remove the conflicting annotations: load balancer configuration conflict: annotation foo is "true" but spec.loadBalancer sets it to "false"`)
+	})
+}
+
+func TestUpdateStatusVersion(t *testing.T) {
+	conf := &config.Config{}
+	deployment := func(generation, observedGeneration int64, replicas, updated, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Now(),
+				Generation:        generation,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Image: "registry/oss/kubernetes/ingress/nginx-ingress-controller:v1.13.7"}},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				Replicas:           replicas,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+
+	t.Run("nil deployment", func(t *testing.T) {
+		n := &nginxIngressControllerReconciler{conf: conf}
+		nic := &approutingv1alpha1.NginxIngressController{}
+		n.updateStatusVersion(nic, nil)
+		require.Equal(t, manifests.LatestNginxVersion.Name(), nic.Status.AvailableVersion)
+		require.Equal(t, "", nic.Status.CurrentVersion)
+	})
+
+	t.Run("dalec flavor", func(t *testing.T) {
+		n := &nginxIngressControllerReconciler{conf: conf}
+		nic := &approutingv1alpha1.NginxIngressController{
+			Spec: approutingv1alpha1.NginxIngressControllerSpec{
+				ImageFlavor: util.ToPtr(approutingv1alpha1.DalecImageFlavor),
+			},
+		}
+		n.updateStatusVersion(nic, nil)
+		require.Equal(t, manifests.LatestDalecNginxVersion.Name(), nic.Status.AvailableVersion)
+	})
+
+	t.Run("rolled out deployment", func(t *testing.T) {
+		n := &nginxIngressControllerReconciler{conf: conf}
+		nic := &approutingv1alpha1.NginxIngressController{}
+		n.updateStatusVersion(nic, deployment(2, 2, 3, 3, 3))
+		require.Equal(t, "v1.13.7", nic.Status.CurrentVersion)
+	})
+
+	t.Run("rolling out deployment keeps previous version", func(t *testing.T) {
+		n := &nginxIngressControllerReconciler{conf: conf}
+		nic := &approutingv1alpha1.NginxIngressController{
+			Status: approutingv1alpha1.NginxIngressControllerStatus{CurrentVersion: "v1.12.1"},
+		}
+		n.updateStatusVersion(nic, deployment(2, 2, 4, 2, 3))
+		require.Equal(t, "v1.12.1", nic.Status.CurrentVersion)
+
+		n.updateStatusVersion(nic, deployment(3, 2, 3, 3, 3))
+		require.Equal(t, "v1.12.1", nic.Status.CurrentVersion)
+	})
 }
 
 func TestUpdateStatusControllerAvailable(t *testing.T) {
@@ -871,6 +1376,21 @@ func TestIsUnreconcilableError(t *testing.T) {
 			err:  fmt.Errorf("wrapped: %w", icCollisionErr),
 			want: true,
 		},
+		{
+			name: "version unavailable error",
+			err:  fmt.Errorf("%w: not available", versionUnavailableErr),
+			want: true,
+		},
+		{
+			name: "joined unreconcilable error",
+			err:  errors.Join(nil, versionUnavailableErr),
+			want: true,
+		},
+		{
+			name: "load balancer conflict error",
+			err:  fmt.Errorf("%w: conflict", lbConflictErr),
+			want: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -1582,6 +2102,154 @@ func TestToNginxIngressConfig(t *testing.T) {
 				TargetCPUUtilizationPercentage: defaultTargetCPUUtilization,
 			},
 		},
+		{
+			name: "custom fields with config",
+			nic: &approutingv1alpha1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "nicName",
+				},
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					IngressClassName:     "ingressClassName",
+					ControllerNamePrefix: "controllerNamePrefix",
+					Config: map[string]string{
+						"proxy-body-size":           "8m",
+						"allow-snippet-annotations": "false",
+					},
+				},
+			},
+			want: manifests.NginxIngressConfig{
+				ControllerClass:                "approuting.kubernetes.azure.com/nicName",
+				ResourceName:                   "controllerNamePrefix-0",
+				ServiceConfig:                  &manifests.ServiceConfig{},
+				IcName:                         "ingressClassName",
+				MaxReplicas:                    defaultMaxReplicas,
+				MinReplicas:                    defaultMinReplicas,
+				TargetCPUUtilizationPercentage: defaultTargetCPUUtilization,
+				Config: map[string]string{
+					"proxy-body-size":           "8m",
+					"allow-snippet-annotations": "false",
+				},
+			},
+		},
+		{
+			name: "custom fields with pod template",
+			nic: &approutingv1alpha1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "nicName",
+				},
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					IngressClassName:     "ingressClassName",
+					ControllerNamePrefix: "controllerNamePrefix",
+					PodTemplate: &approutingv1alpha1.PodTemplate{
+						Labels:       map[string]string{"team": "ingress"},
+						Annotations:  map[string]string{"example.com/owner": "ingress-team"},
+						NodeSelector: map[string]string{"agentpool": "ingress"},
+						Tolerations: []corev1.Toleration{{
+							Key:      "dedicated",
+							Operator: corev1.TolerationOpExists,
+						}},
+						TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+							MaxSkew:     1,
+							TopologyKey: "topology.kubernetes.io/zone",
+						}},
+						PriorityClassName: "ingress-critical",
+					},
+				},
+			},
+			want: manifests.NginxIngressConfig{
+				ControllerClass:                "approuting.kubernetes.azure.com/nicName",
+				ResourceName:                   "controllerNamePrefix-0",
+				ServiceConfig:                  &manifests.ServiceConfig{},
+				IcName:                         "ingressClassName",
+				MaxReplicas:                    defaultMaxReplicas,
+				MinReplicas:                    defaultMinReplicas,
+				TargetCPUUtilizationPercentage: defaultTargetCPUUtilization,
+				PodTemplate: &manifests.PodTemplateConfig{
+					Labels:       map[string]string{"team": "ingress"},
+					Annotations:  map[string]string{"example.com/owner": "ingress-team"},
+					NodeSelector: map[string]string{"agentpool": "ingress"},
+					Tolerations: []corev1.Toleration{{
+						Key:      "dedicated",
+						Operator: corev1.TolerationOpExists,
+					}},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:     1,
+						TopologyKey: "topology.kubernetes.io/zone",
+					}},
+					PriorityClassName: "ingress-critical",
+				},
+			},
+		},
+		{
+			name: "custom fields with scaling metrics and behavior",
+			nic: &approutingv1alpha1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "nicName",
+				},
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					IngressClassName:     "ingressClassName",
+					ControllerNamePrefix: "controllerNamePrefix",
+					Scaling: &approutingv1alpha1.Scaling{
+						TargetMemoryUtilization: util.Int32Ptr(75),
+						Metric: &approutingv1alpha1.ScalingMetric{
+							Type:               approutingv1alpha1.RequestsPerSecondScalingMetric,
+							TargetAverageValue: resource.MustParse("100"),
+						},
+						Behavior: &autov2.HorizontalPodAutoscalerBehavior{
+							ScaleDown: &autov2.HPAScalingRules{
+								StabilizationWindowSeconds: util.Int32Ptr(600),
+							},
+						},
+					},
+				},
+			},
+			want: manifests.NginxIngressConfig{
+				ControllerClass:                   "approuting.kubernetes.azure.com/nicName",
+				ResourceName:                      "controllerNamePrefix-0",
+				ServiceConfig:                     &manifests.ServiceConfig{},
+				IcName:                            "ingressClassName",
+				MaxReplicas:                       defaultMaxReplicas,
+				MinReplicas:                       defaultMinReplicas,
+				TargetCPUUtilizationPercentage:    defaultTargetCPUUtilization,
+				TargetMemoryUtilizationPercentage: 75,
+				ScalingMetric: &manifests.ScalingMetricConfig{
+					Name:               manifests.NginxRequestsPerSecondMetric,
+					TargetAverageValue: resource.MustParse("100"),
+				},
+				ScalingBehavior: &autov2.HorizontalPodAutoscalerBehavior{
+					ScaleDown: &autov2.HPAScalingRules{
+						StabilizationWindowSeconds: util.Int32Ptr(600),
+					},
+				},
+			},
+		},
+		{
+			name: "custom fields with pinned version and image flavor",
+			nic: &approutingv1alpha1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "nicName",
+				},
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					IngressClassName:     "ingressClassName",
+					ControllerNamePrefix: "controllerNamePrefix",
+					UpgradeChannel:       util.ToPtr(approutingv1alpha1.PinnedUpgradeChannel),
+					Version:              util.ToPtr("v1.13.7"),
+					ImageFlavor:          util.ToPtr(approutingv1alpha1.UpstreamImageFlavor),
+				},
+			},
+			want: manifests.NginxIngressConfig{
+				ControllerClass:                "approuting.kubernetes.azure.com/nicName",
+				ResourceName:                   "controllerNamePrefix-0",
+				ServiceConfig:                  &manifests.ServiceConfig{},
+				IcName:                         "ingressClassName",
+				MaxReplicas:                    defaultMaxReplicas,
+				MinReplicas:                    defaultMinReplicas,
+				TargetCPUUtilizationPercentage: defaultTargetCPUUtilization,
+				UpgradeChannel:                 manifests.PinnedNginxUpgradeChannel,
+				PinnedVersion:                  "v1.13.7",
+				ImageFlavor:                    manifests.UpstreamNginxImageFlavor,
+			},
+		},
 		{
 			name: "default controller class without LogFormat",
 			nic: &approutingv1alpha1.NginxIngressController{
@@ -1687,6 +2355,259 @@ func TestGetTargetCPUUtilizationPercentage(t *testing.T) {
 	}
 }
 
+func TestGetScalingMetric(t *testing.T) {
+	cases := []struct {
+		name string
+		nic  *approutingv1alpha1.NginxIngressController
+		want *manifests.ScalingMetricConfig
+	}{
+		{
+			name: "nil NginxIngressController",
+			nic:  nil,
+			want: nil,
+		},
+		{
+			name: "nil metric",
+			nic: &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					Scaling: &approutingv1alpha1.Scaling{},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "requests per second pods metric",
+			nic: &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					Scaling: &approutingv1alpha1.Scaling{
+						Metric: &approutingv1alpha1.ScalingMetric{
+							Type:               approutingv1alpha1.RequestsPerSecondScalingMetric,
+							Source:             approutingv1alpha1.PodsScalingMetricSource,
+							TargetAverageValue: resource.MustParse("100"),
+						},
+					},
+				},
+			},
+			want: &manifests.ScalingMetricConfig{
+				Name:               manifests.NginxRequestsPerSecondMetric,
+				TargetAverageValue: resource.MustParse("100"),
+			},
+		},
+		{
+			name: "active connections pods metric",
+			nic: &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					Scaling: &approutingv1alpha1.Scaling{
+						Metric: &approutingv1alpha1.ScalingMetric{
+							Type:               approutingv1alpha1.ActiveConnectionsScalingMetric,
+							TargetAverageValue: resource.MustParse("500"),
+						},
+					},
+				},
+			},
+			want: &manifests.ScalingMetricConfig{
+				Name:               manifests.NginxActiveConnectionsMetric,
+				Selector:           &metav1.LabelSelector{MatchLabels: map[string]string{"state": "active"}},
+				TargetAverageValue: resource.MustParse("500"),
+			},
+		},
+		{
+			name: "active connections external metric",
+			nic: &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					Scaling: &approutingv1alpha1.Scaling{
+						Metric: &approutingv1alpha1.ScalingMetric{
+							Type:               approutingv1alpha1.ActiveConnectionsScalingMetric,
+							Source:             approutingv1alpha1.ExternalScalingMetricSource,
+							TargetAverageValue: resource.MustParse("500"),
+						},
+					},
+				},
+			},
+			want: &manifests.ScalingMetricConfig{
+				Name:               manifests.NginxActiveConnectionsMetric,
+				External:           true,
+				Selector:           &metav1.LabelSelector{MatchLabels: map[string]string{"state": "active", "app": "nginx-0"}},
+				TargetAverageValue: resource.MustParse("500"),
+			},
+		},
+		{
+			name: "custom name and selector",
+			nic: &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					Scaling: &approutingv1alpha1.Scaling{
+						Metric: &approutingv1alpha1.ScalingMetric{
+							Type:               approutingv1alpha1.RequestsPerSecondScalingMetric,
+							Source:             approutingv1alpha1.ExternalScalingMetricSource,
+							Name:               "ingress_rps",
+							Selector:           &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "edge"}},
+							TargetAverageValue: resource.MustParse("1k"),
+						},
+					},
+				},
+			},
+			want: &manifests.ScalingMetricConfig{
+				Name:               "ingress_rps",
+				External:           true,
+				Selector:           &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "edge"}},
+				TargetAverageValue: resource.MustParse("1k"),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := getScalingMetric(c.nic, "nginx-0")
+			require.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestGetLoadBalancerConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		lb   *approutingv1alpha1.LoadBalancer
+		want *manifests.LoadBalancerConfig
+	}{
+		{
+			name: "nil load balancer",
+			lb:   nil,
+			want: nil,
+		},
+		{
+			name: "empty load balancer",
+			lb:   &approutingv1alpha1.LoadBalancer{},
+			want: &manifests.LoadBalancerConfig{},
+		},
+		{
+			name: "public load balancer",
+			lb: &approutingv1alpha1.LoadBalancer{
+				Type: util.ToPtr(approutingv1alpha1.PublicLoadBalancerType),
+				PublicIP: &approutingv1alpha1.PublicIP{
+					Name:          "ingress-ip",
+					ResourceGroup: util.ToPtr("network-rg"),
+				},
+				DNSLabelName:           util.ToPtr("my-ingress"),
+				HealthProbeRequestPath: util.ToPtr("/healthz"),
+				ExternalTrafficPolicy:  util.ToPtr(corev1.ServiceExternalTrafficPolicyCluster),
+			},
+			want: &manifests.LoadBalancerConfig{
+				Internal:               util.ToPtr(false),
+				PublicIPName:           "ingress-ip",
+				PublicIPResourceGroup:  "network-rg",
+				DNSLabelName:           "my-ingress",
+				HealthProbeRequestPath: "/healthz",
+				ExternalTrafficPolicy:  corev1.ServiceExternalTrafficPolicyCluster,
+			},
+		},
+		{
+			name: "internal load balancer with private link service",
+			lb: &approutingv1alpha1.LoadBalancer{
+				Type:   util.ToPtr(approutingv1alpha1.InternalLoadBalancerType),
+				Subnet: util.ToPtr("ingress-subnet"),
+				PrivateLinkService: &approutingv1alpha1.PrivateLinkService{
+					Name:                      util.ToPtr("ingress-pls"),
+					IPConfigurationSubnet:     util.ToPtr("pls-subnet"),
+					VisibilitySubscriptions:   []string{"*"},
+					AutoApprovalSubscriptions: []string{"sub1", "sub2"},
+				},
+			},
+			want: &manifests.LoadBalancerConfig{
+				Internal: util.ToPtr(true),
+				Subnet:   "ingress-subnet",
+				PrivateLinkService: &manifests.PrivateLinkServiceConfig{
+					Name:                      "ingress-pls",
+					IPConfigurationSubnet:     "pls-subnet",
+					VisibilitySubscriptions:   []string{"*"},
+					AutoApprovalSubscriptions: []string{"sub1", "sub2"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nic := &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{LoadBalancer: c.lb},
+			}
+			require.Equal(t, c.want, getLoadBalancerConfig(nic))
+		})
+	}
+}
+
+func TestGetSecondaryServiceConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		lb   *approutingv1alpha1.LoadBalancer
+		want *manifests.ServiceConfig
+	}{
+		{
+			name: "nil load balancer",
+			lb:   nil,
+			want: nil,
+		},
+		{
+			name: "no secondary load balancer",
+			lb:   &approutingv1alpha1.LoadBalancer{Type: util.ToPtr(approutingv1alpha1.PublicLoadBalancerType)},
+			want: nil,
+		},
+		{
+			name: "internal secondary load balancer",
+			lb: &approutingv1alpha1.LoadBalancer{
+				Type:                   util.ToPtr(approutingv1alpha1.PublicLoadBalancerType),
+				PublicIP:               &approutingv1alpha1.PublicIP{Name: "ingress-ip"},
+				HealthProbeRequestPath: util.ToPtr("/healthz"),
+				Secondary: &approutingv1alpha1.SecondaryLoadBalancer{
+					Annotations: map[string]string{"service.beta.kubernetes.io/azure-load-balancer-ipv4": "10.0.0.10"},
+					Subnet:      util.ToPtr("ingress-subnet"),
+				},
+			},
+			want: &manifests.ServiceConfig{
+				Annotations:              map[string]string{"service.beta.kubernetes.io/azure-load-balancer-ipv4": "10.0.0.10"},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				LoadBalancer: &manifests.LoadBalancerConfig{
+					Internal:               util.ToPtr(true),
+					Subnet:                 "ingress-subnet",
+					HealthProbeRequestPath: "/healthz",
+				},
+			},
+		},
+		{
+			name: "public secondary load balancer",
+			lb: &approutingv1alpha1.LoadBalancer{
+				Type:                  util.ToPtr(approutingv1alpha1.InternalLoadBalancerType),
+				ExternalTrafficPolicy: util.ToPtr(corev1.ServiceExternalTrafficPolicyCluster),
+				Secondary: &approutingv1alpha1.SecondaryLoadBalancer{
+					PublicIP:     &approutingv1alpha1.PublicIP{Name: "ingress-ip", ResourceGroup: util.ToPtr("network-rg")},
+					DNSLabelName: util.ToPtr("my-ingress"),
+				},
+			},
+			want: &manifests.ServiceConfig{
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				LoadBalancer: &manifests.LoadBalancerConfig{
+					Internal:              util.ToPtr(false),
+					PublicIPName:          "ingress-ip",
+					PublicIPResourceGroup: "network-rg",
+					DNSLabelName:          "my-ingress",
+					ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyCluster,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nic := &approutingv1alpha1.NginxIngressController{
+				Spec: approutingv1alpha1.NginxIngressControllerSpec{
+					LoadBalancer:             c.lb,
+					LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				},
+			}
+			require.Equal(t, c.want, getSecondaryServiceConfig(nic))
+		})
+	}
+}
+
 func getFakeDefaultSSLCert(name, namespace string) *approutingv1alpha1.DefaultSSLCertificate {
 	fakecert := &approutingv1alpha1.DefaultSSLCertificate{
 		Secret: &approutingv1alpha1.Secret{
@@ -0,0 +1,488 @@
+package nginxingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/podmetrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// rolloutVersionAnnotation is the nginx version the staged rollout allows a NginxIngressController to run
+	rolloutVersionAnnotation = "kubernetes.azure.com/nginx-rollout-version"
+	// rolloutPreviousVersionAnnotation is the nginx version a NginxIngressController is reverted to if its rollout fails
+	rolloutPreviousVersionAnnotation = "kubernetes.azure.com/nginx-rollout-previous-version"
+	// rolloutFailedVersionAnnotation is the nginx version that failed to roll out. The staged rollout is halted until it's removed
+	// or a newer version is available
+	rolloutFailedVersionAnnotation = "kubernetes.azure.com/nginx-rollout-failed-version"
+	// rolloutStartedAnnotation is when the NginxIngressController started rolling out its version, the bake period is measured from it.
+	// The VersionRollout condition can't be used because it doesn't transition when a pending rollout starts.
+	rolloutStartedAnnotation = "kubernetes.azure.com/nginx-rollout-started"
+
+	rolloutInterval = time.Minute
+
+	// reloadSuccessMetric is 1 when the last nginx configuration reload of a pod succeeded and 0 otherwise
+	reloadSuccessMetric = "nginx_ingress_controller_config_last_reload_successful"
+)
+
+const (
+	rolloutPendingReason     = "RolloutPending"
+	rolloutInProgressReason  = "RolloutInProgress"
+	rolloutSucceededReason   = "RolloutSucceeded"
+	rolloutRolledBackReason  = "RolledBack"
+	rolloutHaltedReason      = "RolloutHalted"
+	rolloutUpToDateReason    = "UpToDate"
+	rolloutEventRolledBack   = "NginxRolloutRolledBack"
+	rolloutEventStarted      = "NginxRolloutStarted"
+	rolloutEventSucceeded    = "NginxRolloutSucceeded"
+	progressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+// ReloadCheckFn returns whether the last nginx configuration reload of the pod succeeded
+type ReloadCheckFn func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (bool, error)
+
+// NginxReloadCheckFn scrapes the nginx metrics of the pod
+func NginxReloadCheckFn(ctx context.Context, client rest.Interface, pod *corev1.Pod) (bool, error) {
+	families, err := podmetrics.Nginx(ctx, client, pod)
+	if err != nil {
+		return false, err
+	}
+
+	if family, ok := families[reloadSuccessMetric]; ok {
+		for _, metric := range family.Metric {
+			if metric.Gauge == nil {
+				continue
+			}
+			return metric.Gauge.GetValue() == 1, nil
+		}
+	}
+
+	return false, fmt.Errorf("reload success metric not found")
+}
+
+// NewStagedRollout sets up the staged nginx rollout which upgrades NginxIngressControllers to new nginx versions one at a time
+func NewStagedRollout(mgr ctrl.Manager, conf *config.Config, defaultNicControllerClass string) error {
+	if conf == nil {
+		return errors.New("nil config")
+	}
+
+	if !conf.EnableStagedNginxRollout {
+		return nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("creating clientset: %w", err)
+	}
+
+	name := controllername.New("staged", "nginx", "rollout")
+	metrics.InitControllerMetrics(name)
+	rollout := &stagedRollout{
+		name:                      name,
+		lgr:                       name.AddToLogger(mgr.GetLogger()),
+		client:                    mgr.GetClient(),
+		restClient:                clientset.CoreV1().RESTClient(),
+		events:                    mgr.GetEventRecorderFor("aks-app-routing-operator"),
+		conf:                      conf,
+		defaultNicControllerClass: defaultNicControllerClass,
+		reloadCheck:               NginxReloadCheckFn,
+	}
+	if conf.ConcurrencyWatchdogScrapeMode == config.ConcurrencyWatchdogScrapeModeDirect {
+		rollout.directClient = &http.Client{Timeout: podmetrics.DirectTimeout}
+	}
+	if err := mgr.Add(rollout); err != nil {
+		return fmt.Errorf("adding staged nginx rollout: %w", err)
+	}
+
+	return nil
+}
+
+// stagedRollout upgrades NginxIngressControllers to a new nginx version one at a time. Each upgraded NginxIngressController bakes
+// for conf.StagedNginxRolloutBakePeriod while its Deployment availability and nginx reloads are watched. If it regresses, the
+// NginxIngressController is reverted to its previous version and the rollout halts.
+type stagedRollout struct {
+	name                      controllername.ControllerNamer
+	lgr                       logr.Logger
+	client                    client.Client
+	restClient                rest.Interface
+	directClient              *http.Client
+	events                    record.EventRecorder
+	conf                      *config.Config
+	defaultNicControllerClass string
+	reloadCheck               ReloadCheckFn
+}
+
+func (s *stagedRollout) Start(ctx context.Context) error {
+	s.lgr.Info("starting staged nginx rollout")
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(util.Jitter(rolloutInterval, 0.3)):
+		}
+
+		if err := s.tick(ctx); err != nil {
+			s.lgr.Error(err, "progressing staged nginx rollout")
+		}
+	}
+}
+
+func (s *stagedRollout) NeedLeaderElection() bool {
+	return true
+}
+
+// rolloutNic is a NginxIngressController along with the nginx versions the staged rollout tracks for it
+type rolloutNic struct {
+	nic     *approutingv1alpha1.NginxIngressController
+	target  string // version the upgrade channel resolves to
+	allowed string // version the NginxIngressController is allowed to run
+}
+
+func (r rolloutNic) pending() bool {
+	return r.allowed != "" && r.allowed != r.target
+}
+
+func (r rolloutNic) failed() bool {
+	return r.nic.Annotations[rolloutFailedVersionAnnotation] == r.target
+}
+
+func (r rolloutNic) inProgress() bool {
+	cond := r.nic.GetCondition(approutingv1alpha1.ConditionTypeVersionRollout)
+	return cond != nil && cond.Reason == rolloutInProgressReason && r.allowed == r.target
+}
+
+func (s *stagedRollout) tick(ctx context.Context) (err error) {
+	start := time.Now()
+	s.lgr.Info("starting to progress staged nginx rollout")
+	defer func() {
+		s.lgr.Info("finished progressing staged nginx rollout", "latencySec", time.Since(start).Seconds())
+		metrics.HandleControllerReconcileMetrics(s.name, ctrl.Result{}, err)
+	}()
+
+	list := &approutingv1alpha1.NginxIngressControllerList{}
+	if err := s.client.List(ctx, list); err != nil {
+		return fmt.Errorf("listing NginxIngressControllers: %w", err)
+	}
+
+	nics := make([]rolloutNic, 0, len(list.Items))
+	for i := range list.Items {
+		nic := &list.Items[i]
+		if IsSuspended(nic) {
+			// suspended NginxIngressControllers can't change version, they take their turn once resumed
+			continue
+		}
+
+		target, err := manifests.ResolveNginxVersion(s.conf, ToNginxIngressConfig(nic, s.defaultNicControllerClass))
+		if err != nil {
+			// the NginxIngressController reconciler reports unavailable versions, there's nothing to roll out
+			continue
+		}
+
+		nics = append(nics, rolloutNic{
+			nic:     nic,
+			target:  target.Name(),
+			allowed: allowedNginxVersion(nic),
+		})
+	}
+	s.sort(nics)
+
+	for _, r := range nics {
+		if r.inProgress() {
+			return s.bake(ctx, r)
+		}
+	}
+
+	var halted *rolloutNic
+	for i, r := range nics {
+		if r.pending() && r.failed() {
+			halted = &nics[i]
+			break
+		}
+	}
+
+	var next *rolloutNic
+	for i, r := range nics {
+		switch {
+		case !r.pending():
+			if cond := r.nic.GetCondition(approutingv1alpha1.ConditionTypeVersionRollout); cond != nil && cond.Reason == rolloutSucceededReason {
+				continue
+			}
+			if err := s.setCondition(ctx, r.nic, metav1.ConditionTrue, rolloutUpToDateReason, fmt.Sprintf("Running nginx version %s", r.target)); err != nil {
+				return err
+			}
+		case r.failed():
+			msg := fmt.Sprintf("Rolled back to nginx version %s because version %s was unhealthy. Remove the %s annotation to retry", r.allowed, r.target, rolloutFailedVersionAnnotation)
+			if err := s.setCondition(ctx, r.nic, metav1.ConditionFalse, rolloutRolledBackReason, msg); err != nil {
+				return err
+			}
+		case halted != nil:
+			msg := fmt.Sprintf("Upgrade to nginx version %s is halted because the rollout to NginxIngressController %s failed", r.target, halted.nic.Name)
+			if err := s.setCondition(ctx, r.nic, metav1.ConditionFalse, rolloutHaltedReason, msg); err != nil {
+				return err
+			}
+		case next == nil:
+			next = &nics[i]
+		default:
+			msg := fmt.Sprintf("Upgrade to nginx version %s is waiting for NginxIngressController %s", r.target, next.nic.Name)
+			if err := s.setCondition(ctx, r.nic, metav1.ConditionFalse, rolloutPendingReason, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if next == nil {
+		s.lgr.Info("no NginxIngressController to upgrade")
+		return nil
+	}
+
+	return s.begin(ctx, *next)
+}
+
+// sort orders NginxIngressControllers by name with the default NginxIngressController first or last
+func (s *stagedRollout) sort(nics []rolloutNic) {
+	defaultFirst := s.conf.StagedNginxRolloutDefaultNic == config.StagedNginxRolloutDefaultNicFirst
+	sort.SliceStable(nics, func(i, j int) bool {
+		iDefault, jDefault := IsDefaultNic(nics[i].nic), IsDefaultNic(nics[j].nic)
+		if iDefault != jDefault {
+			return iDefault == defaultFirst
+		}
+		return nics[i].nic.Name < nics[j].nic.Name
+	})
+}
+
+// begin allows the NginxIngressController to run its target version and starts its bake period
+func (s *stagedRollout) begin(ctx context.Context, r rolloutNic) error {
+	lgr := s.lgr.WithValues("nginxIngressController", r.nic.Name, "from", r.allowed, "to", r.target)
+	lgr.Info("starting nginx rollout")
+
+	if err := s.setAnnotations(ctx, r.nic, map[string]string{
+		rolloutVersionAnnotation:         r.target,
+		rolloutPreviousVersionAnnotation: r.allowed,
+		rolloutStartedAnnotation:         time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Upgrading from nginx version %s to %s, baking for %s", r.allowed, r.target, s.conf.StagedNginxRolloutBakePeriod)
+	if err := s.setCondition(ctx, r.nic, metav1.ConditionFalse, rolloutInProgressReason, msg); err != nil {
+		return err
+	}
+	s.events.Event(r.nic, corev1.EventTypeNormal, rolloutEventStarted, msg)
+
+	return nil
+}
+
+// bake watches the health of the NginxIngressController being upgraded. It's marked as succeeded once it's been healthy for the
+// whole bake period and rolled back as soon as it regresses
+func (s *stagedRollout) bake(ctx context.Context, r rolloutNic) error {
+	lgr := s.lgr.WithValues("nginxIngressController", r.nic.Name, "version", r.target)
+	lgr.Info("checking health of nginx rollout")
+
+	ingCfg := ToNginxIngressConfig(r.nic, s.defaultNicControllerClass)
+	deployment := &appsv1.Deployment{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: s.conf.NS, Name: ingCfg.ResourceName}, deployment); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting deployment: %w", err)
+		}
+		deployment = nil
+	}
+
+	if reason, err := s.regression(ctx, deployment, ingCfg.PodLabels(), r.target); err != nil {
+		return err
+	} else if reason != "" {
+		return s.rollback(ctx, r, reason)
+	}
+
+	started, err := time.Parse(time.RFC3339, r.nic.Annotations[rolloutStartedAnnotation])
+	if err != nil {
+		// the start is missing or was changed, baking starts over rather than being skipped
+		lgr.Info("nginx rollout start is unknown, restarting bake period")
+		return s.setAnnotations(ctx, r.nic, map[string]string{rolloutStartedAnnotation: time.Now().UTC().Format(time.RFC3339)})
+	}
+	if time.Since(started) < s.conf.StagedNginxRolloutBakePeriod {
+		lgr.Info("nginx rollout is baking")
+		return nil
+	}
+
+	if deployment == nil || !deploymentRolledOut(deployment) || imageTag(deployment) != r.target {
+		return s.rollback(ctx, r, fmt.Sprintf("Deployment didn't become available with nginx version %s within the %s bake period", r.target, s.conf.StagedNginxRolloutBakePeriod))
+	}
+
+	lgr.Info("nginx rollout succeeded")
+	msg := fmt.Sprintf("Upgraded to nginx version %s", r.target)
+	if err := s.setCondition(ctx, r.nic, metav1.ConditionTrue, rolloutSucceededReason, msg); err != nil {
+		return err
+	}
+	s.events.Event(r.nic, corev1.EventTypeNormal, rolloutEventSucceeded, msg)
+
+	return nil
+}
+
+// regression returns why the upgraded NginxIngressController is unhealthy or an empty string if it isn't
+func (s *stagedRollout) regression(ctx context.Context, deployment *appsv1.Deployment, podLabels map[string]string, version string) (string, error) {
+	if deployment == nil {
+		return "", nil
+	}
+
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse && cond.Reason == progressDeadlineExceeded {
+			return fmt.Sprintf("Deployment exceeded its progress deadline rolling out nginx version %s", version), nil
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := s.client.List(ctx, pods, client.InNamespace(s.conf.NS), client.MatchingLabels(podLabels)); err != nil {
+		return "", fmt.Errorf("listing pods: %w", err)
+	}
+
+	scrapeCtx := ctx
+	if s.directClient != nil {
+		scrapeCtx = podmetrics.WithDirectClient(ctx, s.directClient)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !podRunsVersion(pod, version) || !podIsReady(pod) {
+			continue
+		}
+
+		ok, err := s.reloadCheck(scrapeCtx, s.restClient, pod)
+		if err != nil {
+			// a single failed scrape isn't a regression, the pod might be shutting down
+			s.lgr.Error(err, "checking nginx reload", "pod", pod.Name)
+			continue
+		}
+		if !ok {
+			return fmt.Sprintf("Pod %s failed to reload its nginx configuration with nginx version %s", pod.Name, version), nil
+		}
+	}
+
+	return "", nil
+}
+
+// rollback reverts the NginxIngressController to its previous version which halts the rollout
+func (s *stagedRollout) rollback(ctx context.Context, r rolloutNic, reason string) error {
+	previous := r.nic.Annotations[rolloutPreviousVersionAnnotation]
+	s.lgr.Info("rolling back nginx rollout", "nginxIngressController", r.nic.Name, "version", r.target, "previous", previous, "reason", reason)
+
+	if err := s.setAnnotations(ctx, r.nic, map[string]string{
+		rolloutVersionAnnotation:       previous,
+		rolloutFailedVersionAnnotation: r.target,
+	}); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Rolled back to nginx version %s: %s. Remove the %s annotation to retry", previous, reason, rolloutFailedVersionAnnotation)
+	if err := s.setCondition(ctx, r.nic, metav1.ConditionFalse, rolloutRolledBackReason, msg); err != nil {
+		return err
+	}
+	s.events.Event(r.nic, corev1.EventTypeWarning, rolloutEventRolledBack, msg)
+
+	return nil
+}
+
+func (s *stagedRollout) setAnnotations(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, annotations map[string]string) error {
+	patch := client.MergeFrom(nic.DeepCopy())
+	nic.Annotations = util.MergeMaps(nic.Annotations, annotations)
+	if err := s.client.Patch(ctx, nic, patch); err != nil {
+		return fmt.Errorf("patching NginxIngressController %s annotations: %w", nic.Name, err)
+	}
+
+	return nil
+}
+
+// setCondition updates the VersionRollout condition, skipping the write if nothing changed
+func (s *stagedRollout) setCondition(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, status metav1.ConditionStatus, reason, msg string) error {
+	if cur := nic.GetCondition(approutingv1alpha1.ConditionTypeVersionRollout); cur != nil && cur.Status == status && cur.Reason == reason && cur.Message == msg {
+		return nil
+	}
+
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeVersionRollout,
+		Status:  status,
+		Reason:  reason,
+		Message: msg,
+	})
+	if err := s.client.Status().Update(ctx, nic); err != nil {
+		return fmt.Errorf("updating NginxIngressController %s status: %w", nic.Name, err)
+	}
+
+	return nil
+}
+
+// allowedNginxVersion returns the nginx version the staged rollout allows the NginxIngressController to run. NginxIngressControllers
+// the rollout hasn't touched yet stay on the version they run, new ones are allowed any version
+func allowedNginxVersion(nic *approutingv1alpha1.NginxIngressController) string {
+	if v := nic.Annotations[rolloutVersionAnnotation]; v != "" {
+		return v
+	}
+
+	return nic.Status.CurrentVersion
+}
+
+// holdNginxVersion keeps the NginxIngressController on the version the staged rollout allows until the rollout reaches it
+func holdNginxVersion(conf *config.Config, nic *approutingv1alpha1.NginxIngressController, ingCfg *manifests.NginxIngressConfig) {
+	allowed := allowedNginxVersion(nic)
+	if allowed == "" {
+		return
+	}
+
+	held := *ingCfg
+	held.UpgradeChannel = manifests.PinnedNginxUpgradeChannel
+	held.PinnedVersion = allowed
+	version, err := manifests.ResolveNginxVersion(conf, &held)
+	if err != nil {
+		// the allowed version isn't shipped anymore so there's nothing to hold on to
+		return
+	}
+
+	ingCfg.Version = version
+}
+
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == deployment.Status.Replicas &&
+		deployment.Status.AvailableReplicas == deployment.Status.Replicas
+}
+
+// imageTag returns the image tag of the nginx container of the Deployment
+func imageTag(deployment *appsv1.Deployment) string {
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+
+	return manifests.NginxImageTag(deployment.Spec.Template.Spec.Containers[0].Image)
+}
+
+func podRunsVersion(pod *corev1.Pod, version string) bool {
+	return len(pod.Spec.Containers) > 0 && manifests.NginxImageTag(pod.Spec.Containers[0].Image) == version
+}
+
+func podIsReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,431 @@
+package nginxingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/testutils"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	rolloutTestNs       = "app-routing-system"
+	rolloutTestPrevious = "v1.12.0"
+)
+
+var rolloutTestTarget = manifests.LatestNginxVersion.Name()
+
+func TestNewStagedRollout(t *testing.T) {
+	fakeManager := &testutils.FakeManager{
+		AddFn: func(runnable manager.Runnable) error {
+			require.Fail(t, "expected manager.Add not to be called")
+			return nil
+		},
+	}
+
+	require.Equal(t, errors.New("nil config"), NewStagedRollout(fakeManager, nil, "controller-class"))
+	require.Nil(t, NewStagedRollout(fakeManager, &config.Config{EnableStagedNginxRollout: false}, "controller-class"))
+}
+
+func rolloutTestNic(name string, currentVersion string) *approutingv1alpha1.NginxIngressController {
+	nic := &approutingv1alpha1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: approutingv1alpha1.NginxIngressControllerSpec{
+			IngressClassName:     name,
+			ControllerNamePrefix: name,
+		},
+		Status: approutingv1alpha1.NginxIngressControllerStatus{CurrentVersion: currentVersion},
+	}
+	if name == DefaultNicName {
+		nic.Spec.IngressClassName = DefaultIcName
+	}
+
+	return nic
+}
+
+func newTestStagedRollout(t *testing.T, conf *config.Config, objs ...client.Object) *stagedRollout {
+	scheme := runtime.NewScheme()
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	conf.NS = rolloutTestNs
+	return &stagedRollout{
+		name:   controllername.New("testing"),
+		lgr:    logr.Discard(),
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&approutingv1alpha1.NginxIngressController{}).Build(),
+		events: record.NewFakeRecorder(10),
+		conf:   conf,
+		reloadCheck: func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (bool, error) {
+			return true, nil
+		},
+	}
+}
+
+func getRolloutNic(t *testing.T, s *stagedRollout, name string) *approutingv1alpha1.NginxIngressController {
+	nic := &approutingv1alpha1.NginxIngressController{}
+	require.NoError(t, s.client.Get(context.Background(), types.NamespacedName{Name: name}, nic))
+	return nic
+}
+
+func rolloutReason(nic *approutingv1alpha1.NginxIngressController) string {
+	cond := nic.GetCondition(approutingv1alpha1.ConditionTypeVersionRollout)
+	if cond == nil {
+		return ""
+	}
+	return cond.Reason
+}
+
+func TestStagedRolloutOrder(t *testing.T) {
+	cases := []struct {
+		name       string
+		defaultNic string
+		expected   string
+	}{
+		{
+			name:       "default nic last",
+			defaultNic: config.StagedNginxRolloutDefaultNicLast,
+			expected:   "a",
+		},
+		{
+			name:       "default nic first",
+			defaultNic: config.StagedNginxRolloutDefaultNicFirst,
+			expected:   DefaultNicName,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestStagedRollout(t,
+				&config.Config{StagedNginxRolloutDefaultNic: tc.defaultNic, StagedNginxRolloutBakePeriod: time.Hour},
+				rolloutTestNic("b", rolloutTestPrevious),
+				rolloutTestNic(DefaultNicName, rolloutTestPrevious),
+				rolloutTestNic("a", rolloutTestPrevious),
+			)
+			require.NoError(t, s.tick(context.Background()))
+
+			for _, name := range []string{"a", "b", DefaultNicName} {
+				nic := getRolloutNic(t, s, name)
+				if name == tc.expected {
+					require.Equal(t, rolloutInProgressReason, rolloutReason(nic))
+					require.Equal(t, rolloutTestTarget, nic.Annotations[rolloutVersionAnnotation])
+					require.Equal(t, rolloutTestPrevious, nic.Annotations[rolloutPreviousVersionAnnotation])
+					continue
+				}
+
+				require.Equal(t, rolloutPendingReason, rolloutReason(nic))
+				require.Empty(t, nic.Annotations[rolloutVersionAnnotation])
+			}
+
+			// only one nic rolls out at a time, the next tick keeps baking the first one
+			require.NoError(t, s.tick(context.Background()))
+			for _, name := range []string{"a", "b", DefaultNicName} {
+				nic := getRolloutNic(t, s, name)
+				if name != tc.expected {
+					require.Equal(t, rolloutPendingReason, rolloutReason(nic))
+				}
+			}
+		})
+	}
+}
+
+func TestStagedRolloutSkipsSuspended(t *testing.T) {
+	suspended := rolloutTestNic("a", rolloutTestPrevious)
+	suspended.Spec.Suspend = util.ToPtr(true)
+
+	s := newTestStagedRollout(t,
+		&config.Config{StagedNginxRolloutDefaultNic: config.StagedNginxRolloutDefaultNicLast, StagedNginxRolloutBakePeriod: time.Hour},
+		suspended,
+		rolloutTestNic("b", rolloutTestPrevious),
+	)
+	require.NoError(t, s.tick(context.Background()))
+
+	nic := getRolloutNic(t, s, "a")
+	require.Empty(t, rolloutReason(nic))
+	require.Empty(t, nic.Annotations[rolloutVersionAnnotation])
+	require.Equal(t, rolloutInProgressReason, rolloutReason(getRolloutNic(t, s, "b")))
+}
+
+func TestStagedRolloutUpToDate(t *testing.T) {
+	s := newTestStagedRollout(t,
+		&config.Config{StagedNginxRolloutDefaultNic: config.StagedNginxRolloutDefaultNicLast},
+		rolloutTestNic("current", rolloutTestTarget),
+		rolloutTestNic("new", ""),
+	)
+	require.NoError(t, s.tick(context.Background()))
+
+	for _, name := range []string{"current", "new"} {
+		nic := getRolloutNic(t, s, name)
+		require.Equal(t, rolloutUpToDateReason, rolloutReason(nic))
+		require.Equal(t, metav1.ConditionTrue, nic.GetCondition(approutingv1alpha1.ConditionTypeVersionRollout).Status)
+	}
+}
+
+func rolloutTestDeployment(nic *approutingv1alpha1.NginxIngressController, version string, rolledOut bool) *appsv1.Deployment {
+	ingCfg := ToNginxIngressConfig(nic, "")
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       ingCfg.ResourceName,
+			Namespace:  rolloutTestNs,
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "controller", Image: "mcr.microsoft.com/oss/kubernetes/ingress/nginx-ingress-controller:" + version}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	if !rolledOut {
+		deployment.Status.UpdatedReplicas = 1
+	}
+
+	return deployment
+}
+
+func rolloutTestPod(nic *approutingv1alpha1.NginxIngressController, version string) *corev1.Pod {
+	ingCfg := ToNginxIngressConfig(nic, "")
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ingCfg.ResourceName + "-pod",
+			Namespace: rolloutTestNs,
+			Labels:    ingCfg.PodLabels(),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "controller", Image: "mcr.microsoft.com/oss/kubernetes/ingress/nginx-ingress-controller:" + version}},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestStagedRolloutBake(t *testing.T) {
+	cases := []struct {
+		name           string
+		rolledOut      bool
+		reloadOk       bool
+		bakePeriod     time.Duration
+		expectedReason string
+		expectedAllow  string
+	}{
+		{
+			name:           "baking",
+			rolledOut:      true,
+			reloadOk:       true,
+			bakePeriod:     time.Hour,
+			expectedReason: rolloutInProgressReason,
+			expectedAllow:  rolloutTestTarget,
+		},
+		{
+			name:           "succeeded after bake period",
+			rolledOut:      true,
+			reloadOk:       true,
+			expectedReason: rolloutSucceededReason,
+			expectedAllow:  rolloutTestTarget,
+		},
+		{
+			name:           "not rolled out after bake period",
+			rolledOut:      false,
+			reloadOk:       true,
+			expectedReason: rolloutRolledBackReason,
+			expectedAllow:  rolloutTestPrevious,
+		},
+		{
+			name:           "reload failure during bake period",
+			rolledOut:      true,
+			reloadOk:       false,
+			bakePeriod:     time.Hour,
+			expectedReason: rolloutRolledBackReason,
+			expectedAllow:  rolloutTestPrevious,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nic := rolloutTestNic("a", rolloutTestPrevious)
+			s := newTestStagedRollout(t,
+				&config.Config{StagedNginxRolloutDefaultNic: config.StagedNginxRolloutDefaultNicLast, StagedNginxRolloutBakePeriod: tc.bakePeriod},
+				nic,
+				rolloutTestNic("b", rolloutTestPrevious),
+				rolloutTestDeployment(nic, rolloutTestTarget, tc.rolledOut),
+				rolloutTestPod(nic, rolloutTestTarget),
+			)
+			s.reloadCheck = func(ctx context.Context, client rest.Interface, pod *corev1.Pod) (bool, error) {
+				return tc.reloadOk, nil
+			}
+
+			// first tick starts the rollout, the second one bakes it
+			require.NoError(t, s.tick(context.Background()))
+			require.Equal(t, rolloutInProgressReason, rolloutReason(getRolloutNic(t, s, "a")))
+			require.NoError(t, s.tick(context.Background()))
+
+			got := getRolloutNic(t, s, "a")
+			require.Equal(t, tc.expectedReason, rolloutReason(got))
+			require.Equal(t, tc.expectedAllow, got.Annotations[rolloutVersionAnnotation])
+
+			// a rolled back version halts the rollout for the other nics
+			require.NoError(t, s.tick(context.Background()))
+			other := getRolloutNic(t, s, "b")
+			switch tc.expectedReason {
+			case rolloutRolledBackReason:
+				require.Equal(t, rolloutTestTarget, got.Annotations[rolloutFailedVersionAnnotation])
+				require.Equal(t, rolloutHaltedReason, rolloutReason(other))
+				require.Equal(t, rolloutRolledBackReason, rolloutReason(getRolloutNic(t, s, "a")))
+			case rolloutSucceededReason:
+				require.Equal(t, rolloutInProgressReason, rolloutReason(other))
+			default:
+				require.Equal(t, rolloutPendingReason, rolloutReason(other))
+			}
+		})
+	}
+}
+
+func TestStagedRolloutBakeWaits(t *testing.T) {
+	// the nic waited for another nic's rollout so its VersionRollout condition transitioned long before its own rollout starts
+	nic := rolloutTestNic("a", rolloutTestPrevious)
+	nic.Status.Conditions = []metav1.Condition{{
+		Type:               approutingv1alpha1.ConditionTypeVersionRollout,
+		Status:             metav1.ConditionFalse,
+		Reason:             rolloutPendingReason,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}}
+	s := newTestStagedRollout(t,
+		&config.Config{StagedNginxRolloutDefaultNic: config.StagedNginxRolloutDefaultNicLast, StagedNginxRolloutBakePeriod: time.Hour},
+		nic,
+		rolloutTestDeployment(nic, rolloutTestTarget, false),
+		rolloutTestPod(nic, rolloutTestTarget),
+	)
+
+	require.NoError(t, s.tick(context.Background()))
+	got := getRolloutNic(t, s, "a")
+	require.Equal(t, rolloutInProgressReason, rolloutReason(got))
+	started, err := time.Parse(time.RFC3339, got.Annotations[rolloutStartedAnnotation])
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), started, time.Minute)
+
+	// the deployment is still rolling out but the bake period hasn't passed
+	for range 3 {
+		require.NoError(t, s.tick(context.Background()))
+		got = getRolloutNic(t, s, "a")
+		require.Equal(t, rolloutInProgressReason, rolloutReason(got))
+		require.Equal(t, rolloutTestTarget, got.Annotations[rolloutVersionAnnotation])
+	}
+
+	// a missing start restarts the bake period rather than ending it
+	patch := client.MergeFrom(got.DeepCopy())
+	delete(got.Annotations, rolloutStartedAnnotation)
+	require.NoError(t, s.client.Patch(context.Background(), got, patch))
+	require.NoError(t, s.tick(context.Background()))
+	got = getRolloutNic(t, s, "a")
+	require.Equal(t, rolloutInProgressReason, rolloutReason(got))
+	require.NotEmpty(t, got.Annotations[rolloutStartedAnnotation])
+
+	// once the bake period passed the deployment has to be rolled out
+	patch = client.MergeFrom(got.DeepCopy())
+	got.Annotations[rolloutStartedAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, s.client.Patch(context.Background(), got, patch))
+	require.NoError(t, s.tick(context.Background()))
+	require.Equal(t, rolloutRolledBackReason, rolloutReason(getRolloutNic(t, s, "a")))
+}
+
+func TestStagedRolloutProgressDeadline(t *testing.T) {
+	nic := rolloutTestNic("a", rolloutTestPrevious)
+	deployment := rolloutTestDeployment(nic, rolloutTestTarget, false)
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:   appsv1.DeploymentProgressing,
+		Status: corev1.ConditionFalse,
+		Reason: progressDeadlineExceeded,
+	}}
+	s := newTestStagedRollout(t,
+		&config.Config{StagedNginxRolloutDefaultNic: config.StagedNginxRolloutDefaultNicLast, StagedNginxRolloutBakePeriod: time.Hour},
+		nic,
+		deployment,
+	)
+
+	require.NoError(t, s.tick(context.Background()))
+	require.NoError(t, s.tick(context.Background()))
+
+	got := getRolloutNic(t, s, "a")
+	require.Equal(t, rolloutRolledBackReason, rolloutReason(got))
+	require.Equal(t, rolloutTestPrevious, got.Annotations[rolloutVersionAnnotation])
+	require.Equal(t, rolloutTestTarget, got.Annotations[rolloutFailedVersionAnnotation])
+}
+
+func TestHoldNginxVersion(t *testing.T) {
+	conf := &config.Config{EnableDalecNginx: true}
+
+	cases := []struct {
+		name     string
+		nic      *approutingv1alpha1.NginxIngressController
+		expected *manifests.NginxIngressVersion
+	}{
+		{
+			name:     "new nic isn't held",
+			nic:      rolloutTestNic("a", ""),
+			expected: nil,
+		},
+		{
+			name:     "held on current version",
+			nic:      rolloutTestNic("a", manifests.LatestDalecNginxVersion.Name()),
+			expected: &manifests.LatestDalecNginxVersion,
+		},
+		{
+			name: "current version of another image flavor isn't held",
+			nic: func() *approutingv1alpha1.NginxIngressController {
+				nic := rolloutTestNic("a", manifests.LatestDalecNginxVersion.Name())
+				nic.Spec.ImageFlavor = ptr.To(approutingv1alpha1.UpstreamImageFlavor)
+				return nic
+			}(),
+			expected: nil,
+		},
+		{
+			name: "held on allowed version",
+			nic: func() *approutingv1alpha1.NginxIngressController {
+				nic := rolloutTestNic("a", rolloutTestPrevious)
+				nic.Annotations = map[string]string{rolloutVersionAnnotation: manifests.LatestDalecNginxVersion.Name()}
+				return nic
+			}(),
+			expected: &manifests.LatestDalecNginxVersion,
+		},
+		{
+			name:     "unavailable version isn't held",
+			nic:      rolloutTestNic("a", rolloutTestPrevious),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingCfg := ToNginxIngressConfig(tc.nic, "")
+			holdNginxVersion(conf, tc.nic, ingCfg)
+			require.Equal(t, tc.expected, ingCfg.Version)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package nginxingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SuspendAnnotation suspends a NginxIngressController when set to "true". It's the only way to suspend the default NginxIngressController
+// because its spec is managed by the App Routing Operator.
+const SuspendAnnotation = "kubernetes.azure.com/nginx-suspend"
+
+// IsSuspended returns true if writes to the NginxIngressController's managed resources are suspended
+func IsSuspended(nic *approutingv1alpha1.NginxIngressController) bool {
+	if nic == nil {
+		return false
+	}
+
+	if nic.Spec.Suspend != nil && *nic.Spec.Suspend {
+		return true
+	}
+
+	return strings.EqualFold(nic.Annotations[SuspendAnnotation], "true")
+}
+
+// suspend records that the NginxIngressController is suspended without touching its managed resources
+func (n *nginxIngressControllerReconciler) suspend(ctx context.Context, nic *approutingv1alpha1.NginxIngressController) (ctrl.Result, error) {
+	lgr := log.FromContext(ctx)
+
+	msg := "Managed resources are not reconciled because spec.suspend is true"
+	if nic.Spec.Suspend == nil || !*nic.Spec.Suspend {
+		msg = fmt.Sprintf("Managed resources are not reconciled because the %s annotation is true", SuspendAnnotation)
+	}
+
+	if cond := nic.GetCondition(approutingv1alpha1.ConditionTypeSuspended); cond == nil || cond.Status != metav1.ConditionTrue {
+		n.events.Event(nic, corev1.EventTypeNormal, "Suspended", msg)
+	}
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeSuspended,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Suspended",
+		Message: msg,
+	})
+
+	if err := n.client.Status().Update(ctx, nic); err != nil {
+		if apierrors.IsConflict(err) {
+			lgr.Info("conflict updating status, requeuing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		lgr.Error(err, "unable to update NginxIngressController status")
+		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// resume reports the managed resources that were changed while the NginxIngressController was suspended. They are overwritten by the
+// reconcile that follows.
+func (n *nginxIngressControllerReconciler) resume(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, res *manifests.NginxResources) error {
+	lgr := log.FromContext(ctx)
+
+	drifted, err := n.driftedResources(ctx, res)
+	if err != nil {
+		return fmt.Errorf("determining drift: %w", err)
+	}
+
+	if len(drifted) == 0 {
+		lgr.Info("no drift found in managed resources")
+		n.events.Event(nic, corev1.EventTypeNormal, "Resumed", "Resumed reconciling managed resources, none were changed while suspended")
+		nic.SetCondition(metav1.Condition{
+			Type:    approutingv1alpha1.ConditionTypeSuspended,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Resumed",
+			Message: "Managed resources are reconciled, none were changed while suspended",
+		})
+		return nil
+	}
+
+	lgr.Info("overwriting drift in managed resources", "resources", drifted)
+	n.events.Eventf(nic, corev1.EventTypeWarning, "DriftDetected", "Resumed reconciling managed resources, overwriting changes made while suspended to %s", strings.Join(drifted, ", "))
+	nic.SetCondition(metav1.Condition{
+		Type:    approutingv1alpha1.ConditionTypeSuspended,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ResumedWithDrift",
+		Message: fmt.Sprintf("Managed resources are reconciled, changes made while suspended to %s were overwritten", strings.Join(drifted, ", ")),
+	})
+	return nil
+}
+
+// driftedResources returns the managed resources whose live state doesn't contain their desired state as "Kind namespace/name". Fields
+// the API server defaults are ignored, so only changes to fields the App Routing Operator sets and deleted resources count as drift.
+func (n *nginxIngressControllerReconciler) driftedResources(ctx context.Context, res *manifests.NginxResources) ([]string, error) {
+	var drifted []string
+	for _, obj := range res.Objects() {
+		if !manifests.HasTopLevelLabels(obj.GetLabels()) {
+			continue
+		}
+
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		name := fmt.Sprintf("%s %s", gvk.Kind, client.ObjectKeyFromObject(obj).String())
+
+		desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s: %w", name, err)
+		}
+		delete(desired, "status")
+		if metadata, ok := desired["metadata"].(map[string]interface{}); ok {
+			desired["metadata"] = map[string]interface{}{
+				"labels":      metadata["labels"],
+				"annotations": metadata["annotations"],
+			}
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		if err := n.client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			if apierrors.IsNotFound(err) {
+				drifted = append(drifted, name)
+				continue
+			}
+
+			return nil, fmt.Errorf("getting %s: %w", name, err)
+		}
+
+		if !containsFields(live.Object, desired) {
+			drifted = append(drifted, name)
+		}
+	}
+
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+// containsFields returns true if every field set in desired has the same value in live. Lists must have the same length.
+func containsFields(live, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+
+		for k, v := range d {
+			lv, ok := l[k]
+			if !ok {
+				if isZero(v) {
+					continue
+				}
+				return false
+			}
+
+			if !containsFields(lv, v) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		if len(l) != len(d) {
+			return false
+		}
+
+		for i := range d {
+			if !containsFields(l[i], d[i]) {
+				return false
+			}
+		}
+
+		return true
+	case nil:
+		return true
+	default:
+		// numbers decode as int64 or float64 depending on the source so compare their string form
+		return fmt.Sprint(live) == fmt.Sprint(desired)
+	}
+}
+
+// isZero returns true if v is a value the API server omits when serializing
+func isZero(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case int64:
+		return t == 0
+	case float64:
+		return t == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+
+	return false
+}
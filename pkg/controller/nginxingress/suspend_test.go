@@ -0,0 +1,203 @@
+package nginxingress
+
+import (
+	"context"
+	"testing"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsSuspended(t *testing.T) {
+	cases := []struct {
+		name     string
+		nic      *approutingv1alpha1.NginxIngressController
+		expected bool
+	}{
+		{
+			name:     "nil",
+			nic:      nil,
+			expected: false,
+		},
+		{
+			name:     "not suspended",
+			nic:      &approutingv1alpha1.NginxIngressController{},
+			expected: false,
+		},
+		{
+			name:     "spec.suspend false",
+			nic:      &approutingv1alpha1.NginxIngressController{Spec: approutingv1alpha1.NginxIngressControllerSpec{Suspend: util.ToPtr(false)}},
+			expected: false,
+		},
+		{
+			name:     "spec.suspend true",
+			nic:      &approutingv1alpha1.NginxIngressController{Spec: approutingv1alpha1.NginxIngressControllerSpec{Suspend: util.ToPtr(true)}},
+			expected: true,
+		},
+		{
+			name:     "annotation true",
+			nic:      &approutingv1alpha1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{SuspendAnnotation: "True"}}},
+			expected: true,
+		},
+		{
+			name:     "annotation false",
+			nic:      &approutingv1alpha1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{SuspendAnnotation: "false"}}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, IsSuspended(tc.nic))
+		})
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+
+	nic := dualLoadBalancerNic()
+	nic.Annotations = map[string]string{SuspendAnnotation: "true"}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nic).WithStatusSubresource(nic).Build()
+	recorder := record.NewFakeRecorder(10)
+	n := &nginxIngressControllerReconciler{client: cl, events: recorder}
+
+	res, err := n.suspend(context.Background(), nic)
+	require.NoError(t, err)
+	require.Equal(t, ctrl.Result{}, res)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, SuspendAnnotation)
+
+	got := &approutingv1alpha1.NginxIngressController{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(nic), got))
+	cond := got.GetCondition(approutingv1alpha1.ConditionTypeSuspended)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+
+	// already suspended, no new event
+	_, err = n.suspend(context.Background(), got)
+	require.NoError(t, err)
+	require.Len(t, recorder.Events, 0)
+}
+
+func TestResume(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, approutingv1alpha1.AddToScheme(scheme))
+	conf := &config.Config{NS: "app-routing-system", Registry: "mcr.microsoft.com"}
+
+	newReconciler := func() (*nginxIngressControllerReconciler, client.Client, *record.FakeRecorder) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		recorder := record.NewFakeRecorder(10)
+		n := &nginxIngressControllerReconciler{client: cl, conf: conf, events: recorder}
+
+		res := n.ManagedResources(dualLoadBalancerNic())
+		_, err := n.ReconcileResource(context.Background(), dualLoadBalancerNic(), res)
+		require.NoError(t, err)
+		return n, cl, recorder
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		n, _, recorder := newReconciler()
+		nic := dualLoadBalancerNic()
+
+		require.NoError(t, n.resume(context.Background(), nic, n.ManagedResources(nic)))
+		cond := nic.GetCondition(approutingv1alpha1.ConditionTypeSuspended)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, "Resumed", cond.Reason)
+		require.Contains(t, <-recorder.Events, "Normal Resumed")
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		n, cl, recorder := newReconciler()
+		nic := dualLoadBalancerNic()
+		res := n.ManagedResources(nic)
+
+		// hand edit the deployment and delete the secondary service
+		deployment := res.Deployment.DeepCopy()
+		require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), deployment))
+		deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, "--v=5")
+		require.NoError(t, cl.Update(context.Background(), deployment))
+		require.NoError(t, cl.Delete(context.Background(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: conf.NS, Name: res.SecondaryService.Name}}))
+
+		drifted, err := n.driftedResources(context.Background(), res)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Deployment app-routing-system/dual-0", "Service app-routing-system/dual-0-internal"}, drifted)
+
+		require.NoError(t, n.resume(context.Background(), nic, res))
+		cond := nic.GetCondition(approutingv1alpha1.ConditionTypeSuspended)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, "ResumedWithDrift", cond.Reason)
+		require.Contains(t, cond.Message, "Deployment app-routing-system/dual-0")
+		require.Contains(t, <-recorder.Events, "Warning DriftDetected")
+	})
+}
+
+func TestContainsFields(t *testing.T) {
+	cases := []struct {
+		name     string
+		live     interface{}
+		desired  interface{}
+		expected bool
+	}{
+		{
+			name:     "equal",
+			live:     map[string]interface{}{"a": "b", "n": int64(1)},
+			desired:  map[string]interface{}{"a": "b", "n": int64(1)},
+			expected: true,
+		},
+		{
+			name:     "defaulted fields in live",
+			live:     map[string]interface{}{"a": "b", "defaulted": "value", "list": []interface{}{map[string]interface{}{"name": "c", "protocol": "TCP"}}},
+			desired:  map[string]interface{}{"a": "b", "list": []interface{}{map[string]interface{}{"name": "c"}}},
+			expected: true,
+		},
+		{
+			name:     "zero value missing from live",
+			live:     map[string]interface{}{},
+			desired:  map[string]interface{}{"empty": "", "off": false, "none": nil, "obj": map[string]interface{}{}},
+			expected: true,
+		},
+		{
+			name:     "numbers of different types",
+			live:     map[string]interface{}{"n": float64(2)},
+			desired:  map[string]interface{}{"n": int64(2)},
+			expected: true,
+		},
+		{
+			name:     "changed value",
+			live:     map[string]interface{}{"a": "changed"},
+			desired:  map[string]interface{}{"a": "b"},
+			expected: false,
+		},
+		{
+			name:     "missing value",
+			live:     map[string]interface{}{},
+			desired:  map[string]interface{}{"a": "b"},
+			expected: false,
+		},
+		{
+			name:     "list item added",
+			live:     map[string]interface{}{"list": []interface{}{"a", "b"}},
+			desired:  map[string]interface{}{"list": []interface{}{"a"}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, containsFields(tc.live, tc.desired))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package nginxingress
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const webhookCAKey = "ca.crt"
+
+// these are vars so tests can change them
+var (
+	// webhookCertValidity is how long the admission webhook's serving certificates are valid
+	webhookCertValidity = 90 * 24 * time.Hour
+	// webhookCertRenewBefore is how long before expiry the admission webhook's serving certificate is replaced
+	webhookCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// ensureWebhookCertificate sets the serving certificate of the admission webhook on res. The current certificate is reused until it's
+// due for renewal. Returns how long until the certificate has to be renewed.
+func (n *nginxIngressControllerReconciler) ensureWebhookCertificate(ctx context.Context, nic *approutingv1alpha1.NginxIngressController, res *manifests.NginxResources) (time.Duration, error) {
+	lgr := log.FromContext(ctx)
+	now := time.Now()
+	dnsNames := res.WebhookDNSNames()
+
+	current := &corev1.Secret{}
+	if err := n.client.Get(ctx, client.ObjectKeyFromObject(res.WebhookSecret), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("getting webhook certificate secret: %w", err)
+		}
+		current = nil
+	}
+
+	if current != nil {
+		cert := current.Data[corev1.TLSCertKey]
+		key := current.Data[corev1.TLSPrivateKeyKey]
+		info, err := tls.ParseTLSCertificate(cert, key)
+		switch {
+		case err != nil:
+			lgr.Info("webhook certificate is invalid, issuing a new one", "reason", err.Error())
+		case !slices.Equal(info.DNSNames, dnsNames):
+			lgr.Info("webhook certificate is for different names, issuing a new one", "names", info.DNSNames)
+		case len(current.Data[webhookCAKey]) == 0:
+			lgr.Info("webhook certificate has no CA, issuing a new one")
+		default:
+			renewAt := info.NotAfter.Add(-webhookCertRenewBefore)
+			if now.Before(renewAt) {
+				res.SetWebhookCertificate(current.Data[webhookCAKey], cert, key)
+				return renewAt.Sub(now), nil
+			}
+
+			lgr.Info("webhook certificate is due for renewal, issuing a new one", "notAfter", info.NotAfter.String())
+		}
+	}
+
+	ca, cert, key, err := tls.GenerateServingCertificate(dnsNames, webhookCertValidity)
+	if err != nil {
+		return 0, fmt.Errorf("generating webhook certificate: %w", err)
+	}
+
+	caBundle := ca
+	if current != nil {
+		// pods serve the previous certificate until the kubelet syncs the Secret volume so its CA stays trusted until it expires
+		caBundle = append(caBundle, tls.UnexpiredCertificates(current.Data[webhookCAKey], now)...)
+		n.events.Event(nic, corev1.EventTypeNormal, "WebhookCertificateRotated", "Rotated the serving certificate of the admission webhook")
+	}
+
+	res.SetWebhookCertificate(caBundle, cert, key)
+	return webhookCertValidity - webhookCertRenewBefore, nil
+}
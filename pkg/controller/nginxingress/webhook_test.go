@@ -0,0 +1,124 @@
+package nginxingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func webhookTestResources(t *testing.T, n *nginxIngressControllerReconciler) *manifests.NginxResources {
+	res := n.ManagedResources(dualLoadBalancerNic())
+	require.NotNil(t, res)
+	return res
+}
+
+func webhookSecret(res *manifests.NginxResources, ca, cert, key []byte) *corev1.Secret {
+	secret := res.WebhookSecret.DeepCopy()
+	secret.Data = map[string][]byte{webhookCAKey: ca, corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key}
+	return secret
+}
+
+func countCertificates(bundle []byte) int {
+	count := 0
+	for rest := bundle; ; count++ {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return count
+		}
+	}
+}
+
+func TestEnsureWebhookCertificateIssues(t *testing.T) {
+	n, _, recorder := newDeletionTestReconciler(t)
+	res := webhookTestResources(t, n)
+
+	renewal, err := n.ensureWebhookCertificate(context.Background(), dualLoadBalancerNic(), res)
+	require.NoError(t, err)
+	require.Equal(t, webhookCertValidity-webhookCertRenewBefore, renewal)
+	require.Empty(t, recorder.Events, "first certificate isn't a rotation")
+
+	info, err := tls.ParseTLSCertificate(res.WebhookSecret.Data[corev1.TLSCertKey], res.WebhookSecret.Data[corev1.TLSPrivateKeyKey])
+	require.NoError(t, err)
+	require.Equal(t, res.WebhookDNSNames(), info.DNSNames)
+	require.Equal(t, 1, countCertificates(res.WebhookSecret.Data[webhookCAKey]))
+	for _, webhook := range res.ValidatingWebhookConfiguration.Webhooks {
+		require.Equal(t, res.WebhookSecret.Data[webhookCAKey], webhook.ClientConfig.CABundle)
+	}
+}
+
+func TestEnsureWebhookCertificateReuses(t *testing.T) {
+	n, _, _ := newDeletionTestReconciler(t)
+	res := webhookTestResources(t, n)
+	ca, cert, key, err := tls.GenerateServingCertificate(res.WebhookDNSNames(), webhookCertValidity)
+	require.NoError(t, err)
+	require.NoError(t, n.client.Create(context.Background(), webhookSecret(res, ca, cert, key)))
+
+	renewal, err := n.ensureWebhookCertificate(context.Background(), dualLoadBalancerNic(), res)
+	require.NoError(t, err)
+	require.Greater(t, renewal, webhookCertValidity-webhookCertRenewBefore-time.Hour)
+	require.LessOrEqual(t, renewal, webhookCertValidity-webhookCertRenewBefore)
+	require.Equal(t, cert, res.WebhookSecret.Data[corev1.TLSCertKey])
+	require.Equal(t, key, res.WebhookSecret.Data[corev1.TLSPrivateKeyKey])
+	require.Equal(t, ca, res.ValidatingWebhookConfiguration.Webhooks[0].ClientConfig.CABundle)
+}
+
+func TestEnsureWebhookCertificateRotates(t *testing.T) {
+	n, _, recorder := newDeletionTestReconciler(t)
+	res := webhookTestResources(t, n)
+
+	// expires within the renewal window
+	ca, cert, key, err := tls.GenerateServingCertificate(res.WebhookDNSNames(), time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, n.client.Create(context.Background(), webhookSecret(res, ca, cert, key)))
+
+	_, err = n.ensureWebhookCertificate(context.Background(), dualLoadBalancerNic(), res)
+	require.NoError(t, err)
+	require.NotEqual(t, cert, res.WebhookSecret.Data[corev1.TLSCertKey])
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "WebhookCertificateRotated")
+
+	// the previous CA is trusted alongside the new one while pods pick up the new certificate
+	bundle := res.WebhookSecret.Data[webhookCAKey]
+	require.Equal(t, 2, countCertificates(bundle))
+	require.True(t, bytes.HasSuffix(bundle, ca))
+	require.Equal(t, bundle, res.ValidatingWebhookConfiguration.Webhooks[0].ClientConfig.CABundle)
+}
+
+func TestEnsureWebhookCertificateReplacesInvalid(t *testing.T) {
+	n, _, _ := newDeletionTestReconciler(t)
+	res := webhookTestResources(t, n)
+
+	ca, cert, key, err := tls.GenerateServingCertificate([]string{"other.example.com"}, webhookCertValidity)
+	require.NoError(t, err)
+
+	cases := map[string]*corev1.Secret{
+		"different names": webhookSecret(res, ca, cert, key),
+		"no CA":           webhookSecret(res, nil, cert, key),
+		"garbage":         webhookSecret(res, []byte("ca"), []byte("cert"), []byte("key")),
+	}
+	for name, secret := range cases {
+		t.Run(name, func(t *testing.T) {
+			res := webhookTestResources(t, n)
+			existing := &corev1.Secret{}
+			if err := n.client.Get(context.Background(), client.ObjectKeyFromObject(secret), existing); err == nil {
+				require.NoError(t, n.client.Delete(context.Background(), existing))
+			}
+			require.NoError(t, n.client.Create(context.Background(), secret))
+
+			_, err := n.ensureWebhookCertificate(context.Background(), dualLoadBalancerNic(), res)
+			require.NoError(t, err)
+
+			info, err := tls.ParseTLSCertificate(res.WebhookSecret.Data[corev1.TLSCertKey], res.WebhookSecret.Data[corev1.TLSPrivateKeyKey])
+			require.NoError(t, err)
+			require.Equal(t, res.WebhookDNSNames(), info.DNSNames)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+// Package podmetrics scrapes the metrics endpoints of ingress controller pods
+package podmetrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	prommodel "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+)
+
+// DirectTimeout is how long a direct scrape can take before falling back to the API server. Pods answer quickly when they're
+// reachable so it's well below the API server's timeout.
+const DirectTimeout = 5 * time.Second
+
+// nginxMetricsPort is the port ingress-nginx serves its metrics on
+const nginxMetricsPort = "10254"
+
+// ObserveFn is called with the mode, direct or proxy, the start, and the result of every scrape
+type ObserveFn func(mode string, start time.Time, err error)
+
+type (
+	directClientKey struct{}
+	observeKey      struct{}
+)
+
+// WithDirectClient returns a context that makes Get scrape pods directly with client
+func WithDirectClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, directClientKey{}, client)
+}
+
+// WithObserver returns a context that makes Get report every scrape to observe
+func WithObserver(ctx context.Context, observe ObserveFn) context.Context {
+	return context.WithValue(ctx, observeKey{}, observe)
+}
+
+func directClientFromContext(ctx context.Context) *http.Client {
+	client, _ := ctx.Value(directClientKey{}).(*http.Client)
+	return client
+}
+
+func observe(ctx context.Context, mode string, start time.Time, err error) {
+	if fn, _ := ctx.Value(observeKey{}).(ObserveFn); fn != nil {
+		fn(mode, start, err)
+	}
+}
+
+// Get returns the response of path on port of pod. Pods are scraped directly by their IP when the context has a direct client,
+// falling back to the API server's pod proxy when that fails.
+func Get(ctx context.Context, client rest.Interface, pod *corev1.Pod, port, path string) ([]byte, error) {
+	lgr := logr.FromContextOrDiscard(ctx)
+
+	if direct := directClientFromContext(ctx); direct != nil && pod.Status.PodIP != "" {
+		start := time.Now()
+		resp, err := getDirect(ctx, direct, pod, port, path)
+		observe(ctx, config.ConcurrencyWatchdogScrapeModeDirect, start, err)
+		if err == nil {
+			return resp, nil
+		}
+
+		lgr.Info("scraping pod directly failed, falling back to the api server", "error", err.Error())
+	}
+
+	start := time.Now()
+	resp, err := client.Get().
+		AbsPath("/api/v1/namespaces", pod.Namespace, "pods", pod.Name+":"+port, "proxy/"+path).
+		Timeout(time.Second * 30).
+		MaxRetries(4).
+		DoRaw(ctx)
+	observe(ctx, config.ConcurrencyWatchdogScrapeModeProxy, start, err)
+	return resp, err
+}
+
+func getDirect(ctx context.Context, client *http.Client, pod *corev1.Pod, port, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, DirectTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://%s/%s", net.JoinHostPort(pod.Status.PodIP, port), path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Nginx returns the metric families of an ingress-nginx pod keyed by name
+func Nginx(ctx context.Context, client rest.Interface, pod *corev1.Pod) (map[string]*prommodel.MetricFamily, error) {
+	lgr := logr.FromContextOrDiscard(ctx)
+
+	lgr.Info("scraping pod", "pod", pod.Name)
+	resp, err := Get(ctx, client, pod, nginxMetricsPort, "metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := expfmt.NewOpenMetricsFormat(expfmt.OpenMetricsVersion_0_0_1)
+	if err != nil {
+		return nil, fmt.Errorf("creating open metrics format: %w", err)
+	}
+
+	families := map[string]*prommodel.MetricFamily{}
+	dec := expfmt.NewDecoder(bytes.NewReader(resp), format)
+	for {
+		family := &prommodel.MetricFamily{}
+		err = dec.Decode(family)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		families[family.GetName()] = family
+	}
+
+	return families, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package podmetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestGet(t *testing.T) {
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/metrics", r.URL.Path)
+		io.WriteString(w, "direct")
+	}))
+	defer direct.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/test-ns/pods/test-pod:"+portOf(t, direct)+"/proxy/metrics", r.URL.Path)
+		io.WriteString(w, "proxy")
+	}))
+	defer proxy.Close()
+
+	u, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	restClient, err := rest.NewRESTClient(u, "", rest.ClientContentConfig{}, nil, http.DefaultClient)
+	require.NoError(t, err)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "test-ns"},
+		Status:     corev1.PodStatus{PodIP: "127.0.0.1"},
+	}
+	ctx := context.Background()
+	port := portOf(t, direct)
+
+	t.Run("proxy", func(t *testing.T) {
+		resp, err := Get(ctx, restClient, pod, port, "metrics")
+		require.NoError(t, err)
+		assert.Equal(t, "proxy", string(resp))
+	})
+
+	t.Run("direct", func(t *testing.T) {
+		resp, err := Get(WithDirectClient(ctx, http.DefaultClient), restClient, pod, port, "metrics")
+		require.NoError(t, err)
+		assert.Equal(t, "direct", string(resp))
+	})
+
+	t.Run("direct falls back to proxy", func(t *testing.T) {
+		var observed []string
+		observeCtx := WithObserver(ctx, func(mode string, _ time.Time, err error) {
+			observed = append(observed, fmt.Sprintf("%s %t", mode, err == nil))
+		})
+		unreachable := pod.DeepCopy()
+		unreachable.Status.PodIP = "127.0.0.2" // nothing listens on the port there
+
+		resp, err := Get(WithDirectClient(observeCtx, http.DefaultClient), restClient, unreachable, port, "metrics")
+		require.NoError(t, err)
+		assert.Equal(t, "proxy", string(resp))
+		assert.Equal(t, []string{"direct false", "proxy true"}, observed)
+	})
+
+	t.Run("pods without an ip use the proxy", func(t *testing.T) {
+		noIP := pod.DeepCopy()
+		noIP.Status.PodIP = ""
+
+		resp, err := Get(WithDirectClient(ctx, http.DefaultClient), restClient, noIP, port, "metrics")
+		require.NoError(t, err)
+		assert.Equal(t, "proxy", string(resp))
+	})
+}
+
+func TestNginx(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/test-ns/pods/test-pod:10254/proxy/metrics", r.URL.Path)
+		io.WriteString(w, "# TYPE nginx_ingress_controller_config_last_reload_successful gauge\nnginx_ingress_controller_config_last_reload_successful 1\n# EOF\n")
+	}))
+	defer proxy.Close()
+
+	u, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	restClient, err := rest.NewRESTClient(u, "", rest.ClientContentConfig{}, nil, http.DefaultClient)
+	require.NoError(t, err)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "test-ns"}}
+	families, err := Nginx(context.Background(), restClient, pod)
+	require.NoError(t, err)
+	require.Contains(t, families, "nginx_ingress_controller_config_last_reload_successful")
+	assert.Equal(t, float64(1), families["nginx_ingress_controller_config_last_reload_successful"].Metric[0].GetGauge().GetValue())
+}
+
+func portOf(t *testing.T, svr *httptest.Server) string {
+	u, err := url.Parse(svr.URL)
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	return port
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	approutingv1 "github.com/Azure/aks-app-routing-operator/api/v1"
 	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
 	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
@@ -77,6 +78,7 @@ func NewTestScheme() *runtime.Scheme {
 	utilruntime.Must(cfgv1alpha2.AddToScheme(s))
 	utilruntime.Must(policyv1alpha1.AddToScheme(s))
 	utilruntime.Must(approutingv1alpha1.AddToScheme(s))
+	utilruntime.Must(approutingv1.AddToScheme(s))
 	utilruntime.Must(apiextensionsv1.AddToScheme(s))
 	utilruntime.Must(gatewayv1.Install(s))
 	return s
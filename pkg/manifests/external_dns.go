@@ -35,6 +35,14 @@ const (
 
 	// ExternalDNSVersion is the version of the external-dns image used
 	ExternalDNSVersion = "v0.21.0"
+
+	// externalDNSHostnameAnnotation lists the hostnames external-dns publishes for a LoadBalancer Service
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	// externalDNSZoneTypeAnnotation restricts which external-dns instances publish a resource. Resources with the annotation are only
+	// published into DNS zones of the same type, resources without it are published into zones of any type
+	externalDNSZoneTypeAnnotation = "kubernetes.azure.com/external-dns-zone-type"
+	publicZoneType                = "public"
+	privateZoneType               = "private"
 )
 
 type IdentityType int
@@ -119,6 +127,15 @@ const (
 	PrivateProvider
 )
 
+// zoneType returns the value of externalDNSZoneTypeAnnotation for resources published by the provider
+func (p Provider) zoneType() string {
+	if p == PrivateProvider {
+		return privateZoneType
+	}
+
+	return publicZoneType
+}
+
 func (p Provider) string() string {
 	switch p {
 	case PublicProvider:
@@ -147,6 +164,9 @@ type InputExternalDNSConfig struct {
 	IsNamespaced bool
 	// UID is an optional unique identifier to append to resource names to avoid conflicts
 	UID string
+	// LoadBalancerServices publishes the load balancer Services of NginxIngressControllers with a secondary load balancer into the
+	// zones matching their type. It's only used for cluster-wide Ingress instances
+	LoadBalancerServices bool
 }
 
 // ExternalDnsConfig contains externaldns resources based on input configuration
@@ -160,6 +180,8 @@ type ExternalDnsConfig struct {
 	provider      Provider
 	isNamespaced  bool
 
+	loadBalancerServices bool
+
 	// crd-specific specific fields
 	routeAndIngressLabelSelector string
 	gatewayLabelSelector         string
@@ -285,6 +307,8 @@ func NewExternalDNSConfig(conf *config.Config, inputConfig InputExternalDNSConfi
 		dnsZoneResourceIDs: inputConfig.DnsZoneresourceIDs,
 		isNamespaced:       inputConfig.IsNamespaced,
 		uid:                cleanUID,
+
+		loadBalancerServices: inputConfig.LoadBalancerServices,
 	}
 
 	if inputConfig.Filters != nil {
@@ -627,6 +651,7 @@ func newExternalDNSDeployment(conf *config.Config, externalDnsConfig *ExternalDn
 
 	sort.Slice(resourceTypeArgs, func(i, j int) bool { return resourceTypeArgs[i] < resourceTypeArgs[j] })
 	deploymentArgs = append(deploymentArgs, resourceTypeArgs...)
+	deploymentArgs = append(deploymentArgs, loadBalancerServiceArgs(externalDnsConfig)...)
 	deploymentArgs = append(deploymentArgs, domainFilters...)
 	deploymentArgs = append(deploymentArgs, namespaceFilterArgs(externalDnsConfig)...)
 
@@ -715,6 +740,26 @@ func labelSelectorDeploymentArgs(e *ExternalDnsConfig) []string {
 	return ret
 }
 
+// loadBalancerServiceArgs publishes the Services of NginxIngressControllers with a pair of load balancers. Their Ingresses only report
+// the address of the first one and are annotated with its zone type, so the filter keeps them out of the zones of the other type,
+// which get the hostnames from the second Service instead
+func loadBalancerServiceArgs(e *ExternalDnsConfig) []string {
+	if _, ok := e.resourceTypes[ResourceTypeIngress]; !ok || e.isNamespaced || !e.loadBalancerServices {
+		return nil
+	}
+
+	otherZoneType := PublicProvider.zoneType()
+	if e.provider == PublicProvider {
+		otherZoneType = PrivateProvider.zoneType()
+	}
+
+	return []string{
+		"--source=service",
+		"--service-type-filter=LoadBalancer",
+		fmt.Sprintf("--annotation-filter=%s notin (%s)", externalDNSZoneTypeAnnotation, otherZoneType),
+	}
+}
+
 func namespaceFilterArgs(e *ExternalDnsConfig) []string {
 	ret := []string{}
 	if e.isNamespaced {
@@ -2,6 +2,7 @@ package manifests
 
 import (
 	"errors"
+	"fmt"
 	"path"
 	"strings"
 	"testing"
@@ -260,6 +261,17 @@ var (
 			},
 			DnsConfigs: []*ExternalDnsConfig{publicDnsConfig, privateDnsConfig},
 		},
+		{
+			Name: "secondary-load-balancer-services",
+			Conf: &config.Config{ClusterUid: clusterUid, DnsSyncInterval: time.Minute * 3},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			DnsConfigs: []*ExternalDnsConfig{withLoadBalancerServices(publicDnsConfig), withLoadBalancerServices(privateDnsConfig)},
+		},
 		{
 			Name: "gateway-crd",
 			Conf: &config.Config{ClusterUid: clusterUid, DnsSyncInterval: time.Minute * 3},
@@ -378,6 +390,46 @@ var (
 	}
 )
 
+func withLoadBalancerServices(e *ExternalDnsConfig) *ExternalDnsConfig {
+	copy := *e
+	copy.loadBalancerServices = true
+	return &copy
+}
+
+func TestLoadBalancerServiceArgs(t *testing.T) {
+	t.Parallel()
+	const testConfigMapHash = "0123456789abcdef0123456789abcdef"
+
+	// each instance keeps publishing Ingresses but skips the ones and the Services annotated with the other zone type
+	tests := []struct {
+		name      string
+		config    *ExternalDnsConfig
+		otherType string
+	}{
+		{name: "public", config: withLoadBalancerServices(publicDnsConfig), otherType: "private"},
+		{name: "private", config: withLoadBalancerServices(privateDnsConfig), otherType: "public"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := newExternalDNSDeployment(&config.Config{}, tc.config, testConfigMapHash).Spec.Template.Spec.Containers[0].Args
+			for _, want := range []string{
+				"--source=ingress",
+				"--source=service",
+				"--service-type-filter=LoadBalancer",
+				fmt.Sprintf("--annotation-filter=%s notin (%s)", externalDNSZoneTypeAnnotation, tc.otherType),
+			} {
+				require.Contains(t, args, want)
+			}
+		})
+	}
+
+	args := newExternalDNSDeployment(&config.Config{}, publicDnsConfig, testConfigMapHash).Spec.Template.Spec.Containers[0].Args
+	require.NotContains(t, args, "--source=service")
+}
+
 func TestExternalDnsResources(t *testing.T) {
 	t.Parallel()
 
@@ -4,13 +4,18 @@
 package manifests
 
 import (
+	"errors"
+	"fmt"
 	"path"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
 	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
-	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -18,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 )
 
 var (
@@ -39,6 +45,106 @@ var (
 	dalecNginxImagePath = "/oss/v2/ingress-nginx/controller:"
 )
 
+// NginxImageFlavor is the build of the NGINX Ingress Controller image
+type NginxImageFlavor string
+
+const (
+	UpstreamNginxImageFlavor NginxImageFlavor = "upstream"
+	DalecNginxImageFlavor    NginxImageFlavor = "dalec"
+)
+
+// NginxUpgradeChannel determines which NGINX Ingress Controller version is used when a version isn't explicitly set
+type NginxUpgradeChannel string
+
+const (
+	// PinnedNginxUpgradeChannel uses the pinned version and never upgrades
+	PinnedNginxUpgradeChannel NginxUpgradeChannel = "pinned"
+	// StableNginxUpgradeChannel uses the newest patch of the minor version before the newest one
+	StableNginxUpgradeChannel NginxUpgradeChannel = "stable"
+	// LatestNginxUpgradeChannel uses the newest version
+	LatestNginxUpgradeChannel NginxUpgradeChannel = "latest"
+)
+
+func (v NginxIngressVersion) Name() string {
+	return v.name
+}
+
+// ResolveNginxVersion returns the version of the NGINX Ingress Controller that should be deployed for the ingressConfig. An explicit
+// Version takes precedence, otherwise the version is chosen from the versions of the image flavor by the upgrade channel.
+func ResolveNginxVersion(conf *config.Config, ingressConfig *NginxIngressConfig) (*NginxIngressVersion, error) {
+	if ingressConfig.Version != nil {
+		return ingressConfig.Version, nil
+	}
+
+	return resolveNginxVersion(nginxVersionsFor(conf, ingressConfig), ingressConfig.UpgradeChannel, ingressConfig.PinnedVersion)
+}
+
+// LatestAvailableNginxVersion returns the newest NGINX Ingress Controller version available for the ingressConfig's image flavor
+func LatestAvailableNginxVersion(conf *config.Config, ingressConfig *NginxIngressConfig) NginxIngressVersion {
+	versions := nginxVersionsFor(conf, ingressConfig)
+	return versions[len(versions)-1]
+}
+
+func resolveNginxVersion(versionsAscending []NginxIngressVersion, channel NginxUpgradeChannel, pinned string) (*NginxIngressVersion, error) {
+	switch channel {
+	case PinnedNginxUpgradeChannel:
+		for _, v := range versionsAscending {
+			if v.name == pinned {
+				return &v, nil
+			}
+		}
+
+		names := make([]string, 0, len(versionsAscending))
+		for _, v := range versionsAscending {
+			names = append(names, v.name)
+		}
+		return nil, fmt.Errorf("version %q is not available, available versions are %s", pinned, strings.Join(names, ", "))
+
+	case StableNginxUpgradeChannel:
+		latest, err := utilversion.ParseGeneric(versionsAscending[len(versionsAscending)-1].name)
+		if err != nil {
+			return nil, fmt.Errorf("parsing latest version: %w", err)
+		}
+
+		for i := len(versionsAscending) - 1; i >= 0; i-- {
+			v := versionsAscending[i]
+			parsed, err := utilversion.ParseGeneric(v.name)
+			if err != nil {
+				return nil, fmt.Errorf("parsing version %q: %w", v.name, err)
+			}
+
+			if parsed.Major() < latest.Major() || parsed.Minor() < latest.Minor() {
+				return &v, nil
+			}
+		}
+
+		// there's no previous minor version, the oldest version we have is the most stable
+		return &versionsAscending[0], nil
+
+	default:
+		return &versionsAscending[len(versionsAscending)-1], nil
+	}
+}
+
+func nginxVersionsFor(conf *config.Config, ingressConfig *NginxIngressConfig) []NginxIngressVersion {
+	if isDalecNginx(conf, ingressConfig) {
+		return dalecNginxVersionsAscending
+	}
+	return nginxVersionsAscending
+}
+
+// isDalecNginx returns whether the dalec-built image should be used. The ingressConfig's image flavor takes precedence over --enable-dalec-nginx
+func isDalecNginx(conf *config.Config, ingressConfig *NginxIngressConfig) bool {
+	switch ingressConfig.ImageFlavor {
+	case DalecNginxImageFlavor:
+		return true
+	case UpstreamNginxImageFlavor:
+		return false
+	default:
+		return conf.EnableDalecNginx
+	}
+}
+
 var nginxLabels = util.MergeMaps(
 	map[string]string{
 		k8sNameKey: "nginx",
@@ -49,6 +155,11 @@ var nginxLabels = util.MergeMaps(
 const (
 	prom                           = "prometheus"
 	IngressControllerComponentName = "ingress-controller"
+
+	// NginxRequestsPerSecondMetric is the default name a metrics adapter serves the per pod request rate of the Ingress Controller under
+	NginxRequestsPerSecondMetric = "nginx_ingress_controller_requests_per_second"
+	// NginxActiveConnectionsMetric is the NGINX metric for client connections, labelled with the connection state
+	NginxActiveConnectionsMetric = "nginx_ingress_controller_nginx_process_connections"
 )
 
 var (
@@ -65,19 +176,37 @@ var (
 		"prometheus.io/scrape": "true",
 		"prometheus.io/port":   strconv.Itoa(int(promServicePort.Port)),
 	}
+
+	webhookPodPort = corev1.ContainerPort{
+		Name:          "webhook",
+		ContainerPort: 8443,
+	}
+	webhookServicePort = corev1.ServicePort{
+		Name:       "https-webhook",
+		Port:       443,
+		TargetPort: intstr.FromString(webhookPodPort.Name),
+	}
 )
 
+// webhookCertDir is where the admission webhook's serving certificate is mounted in the NGINX Ingress Controller container
+const webhookCertDir = "/usr/local/certificates"
+
+// ingressClassAnnotation is the deprecated way of setting the IngressClass of an Ingress, still honored by NGINX
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
 const internalLogFormat = `{"remote_addr":"$remote_addr","remote_user":"$remote_user","time_local":"$time_local","request":"$request","status":"$status","body_bytes_sent":"$body_bytes_sent","http_referer":"$http_referer","http_user_agent":"$http_user_agent","request_length":"$request_length","request_time":"$request_time","proxy_upstream_name":"$proxy_upstream_name","proxy_alternative_upstream_name":"$proxy_alternative_upstream_name","upstream_addr":"$upstream_addr","upstream_response_length":"$upstream_response_length","upstream_response_time":"$upstream_response_time","upstream_status":"$upstream_status","req_id":"$req_id","http_x_forwarded_for":"$http_x_forwarded_for","http_x_ms_client_ip_address":"$http_x_ms_client_ip_address","http_x_ms_correlation_request_id":"$http_x_ms_correlation_request_id"}`
 
 func GetNginxResources(conf *config.Config, ingressConfig *NginxIngressConfig) *NginxResources {
 	if ingressConfig != nil && ingressConfig.Version == nil {
 		// copy to avoid mutating the caller's struct (prevents data races)
 		cfgCopy := *ingressConfig
-		if conf.EnableDalecNginx {
-			cfgCopy.Version = &LatestDalecNginxVersion
-		} else {
-			cfgCopy.Version = &LatestNginxVersion
+		version, err := ResolveNginxVersion(conf, &cfgCopy)
+		if err != nil {
+			// callers are expected to validate the version with ResolveNginxVersion first, fall back to the newest version
+			latest := LatestAvailableNginxVersion(conf, &cfgCopy)
+			version = &latest
 		}
+		cfgCopy.Version = version
 		ingressConfig = &cfgCopy
 	}
 
@@ -89,11 +218,16 @@ func GetNginxResources(conf *config.Config, ingressConfig *NginxIngressConfig) *
 		ClusterRoleBinding:      newNginxIngressControllerClusterRoleBinding(conf, ingressConfig),
 		RoleBinding:             newNginxIngressControllerRoleBinding(conf, ingressConfig),
 		Service:                 newNginxIngressControllerService(conf, ingressConfig),
+		SecondaryService:        newNginxIngressControllerSecondaryService(conf, ingressConfig),
 		PromService:             newNginxIngressControllerPromService(conf, ingressConfig),
 		Deployment:              newNginxIngressControllerDeployment(conf, ingressConfig),
 		ConfigMap:               newNginxIngressControllerConfigmap(conf, ingressConfig),
 		HorizontalPodAutoscaler: newNginxIngressControllerHPA(conf, ingressConfig),
 		PodDisruptionBudget:     newNginxIngressControllerPDB(conf, ingressConfig),
+
+		WebhookService:                 newNginxIngressControllerWebhookService(conf, ingressConfig),
+		WebhookSecret:                  newNginxIngressControllerWebhookSecret(conf, ingressConfig),
+		ValidatingWebhookConfiguration: newNginxIngressControllerValidatingWebhookConfiguration(conf, ingressConfig),
 	}
 
 	switch ingressConfig.Version {
@@ -321,15 +455,190 @@ func newNginxIngressControllerRoleBinding(conf *config.Config, ingressConfig *Ng
 	}
 }
 
+// Azure LoadBalancer annotations, see https://cloud-provider-azure.sigs.k8s.io/topics/loadbalancer/#loadbalancer-annotations
+const (
+	AzureLoadBalancerInternalAnnotation        = "service.beta.kubernetes.io/azure-load-balancer-internal"
+	azureLoadBalancerInternalSubnetAnnotation  = "service.beta.kubernetes.io/azure-load-balancer-internal-subnet"
+	azureLoadBalancerResourceGroupAnnotation   = "service.beta.kubernetes.io/azure-load-balancer-resource-group"
+	azureLoadBalancerHealthProbePathAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path"
+	azurePipNameAnnotation                     = "service.beta.kubernetes.io/azure-pip-name"
+	azureDNSLabelNameAnnotation                = "service.beta.kubernetes.io/azure-dns-label-name"
+	azurePlsCreateAnnotation                   = "service.beta.kubernetes.io/azure-pls-create"
+	azurePlsNameAnnotation                     = "service.beta.kubernetes.io/azure-pls-name"
+	azurePlsIPConfigurationSubnetAnnotation    = "service.beta.kubernetes.io/azure-pls-ip-configuration-subnet"
+	azurePlsVisibilityAnnotation               = "service.beta.kubernetes.io/azure-pls-visibility"
+	azurePlsAutoApprovalAnnotation             = "service.beta.kubernetes.io/azure-pls-auto-approval"
+)
+
+// boolLoadBalancerAnnotations are compared by their boolean value when checking for conflicts
+var boolLoadBalancerAnnotations = map[string]struct{}{
+	AzureLoadBalancerInternalAnnotation: {},
+	azurePlsCreateAnnotation:            {},
+}
+
+// Annotations returns the Azure LoadBalancer annotations the load balancer configuration translates to
+func (l *LoadBalancerConfig) Annotations() map[string]string {
+	annotations := make(map[string]string)
+	if l == nil {
+		return annotations
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+
+	if l.Internal != nil {
+		set(AzureLoadBalancerInternalAnnotation, strconv.FormatBool(*l.Internal))
+	}
+	set(azurePipNameAnnotation, l.PublicIPName)
+	set(azureLoadBalancerResourceGroupAnnotation, l.PublicIPResourceGroup)
+	set(azureDNSLabelNameAnnotation, l.DNSLabelName)
+	set(azureLoadBalancerInternalSubnetAnnotation, l.Subnet)
+	set(azureLoadBalancerHealthProbePathAnnotation, l.HealthProbeRequestPath)
+
+	if pls := l.PrivateLinkService; pls != nil {
+		set(azurePlsCreateAnnotation, "true")
+		set(azurePlsNameAnnotation, pls.Name)
+		set(azurePlsIPConfigurationSubnetAnnotation, pls.IPConfigurationSubnet)
+		set(azurePlsVisibilityAnnotation, strings.Join(pls.VisibilitySubscriptions, " "))
+		set(azurePlsAutoApprovalAnnotation, strings.Join(pls.AutoApprovalSubscriptions, " "))
+	}
+
+	return annotations
+}
+
+// ValidateLoadBalancerAnnotations returns an error describing every raw annotation of the ServiceConfig that disagrees with its typed
+// load balancer configuration or nil if they agree
+func ValidateLoadBalancerAnnotations(serviceConfig *ServiceConfig) error {
+	if serviceConfig == nil || serviceConfig.LoadBalancer == nil {
+		return nil
+	}
+
+	typed := serviceConfig.LoadBalancer.Annotations()
+	keys := make([]string, 0, len(typed))
+	for key := range typed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		raw, ok := serviceConfig.Annotations[key]
+		if !ok || loadBalancerAnnotationsEqual(key, raw, typed[key]) {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("annotation %s is %q but spec.loadBalancer sets it to %q", key, raw, typed[key]))
+	}
+
+	return errors.Join(errs...)
+}
+
+func loadBalancerAnnotationsEqual(key, a, b string) bool {
+	if _, ok := boolLoadBalancerAnnotations[key]; ok {
+		aBool, aErr := strconv.ParseBool(a)
+		bBool, bErr := strconv.ParseBool(b)
+		if aErr == nil && bErr == nil {
+			return aBool == bBool
+		}
+	}
+
+	return a == b
+}
+
+// SecondaryServiceName returns the name of the second load balancer Service of the ingressConfig
+func SecondaryServiceName(ingressConfig *NginxIngressConfig) string {
+	if svc := ingressConfig.SecondaryServiceConfig; svc != nil && svc.LoadBalancer != nil && svc.LoadBalancer.Internal != nil && !*svc.LoadBalancer.Internal {
+		return ingressConfig.ResourceName + "-public"
+	}
+
+	return ingressConfig.ResourceName + "-internal"
+}
+
+// IsInternalLoadBalancer returns true if the Azure cloud provider provisions an internal load balancer for the Service
+func IsInternalLoadBalancer(svc *corev1.Service) bool {
+	internal, _ := strconv.ParseBool(svc.GetAnnotations()[AzureLoadBalancerInternalAnnotation])
+	return internal
+}
+
+// SetDNSHostnames sets the hostnames external-dns publishes for the SecondaryService. Ingresses only report the address of the
+// Service passed to --publish-service so the zones of the SecondaryService's type get the hostnames from it instead. See
+// IngressZoneType.
+func (n *NginxResources) SetDNSHostnames(hostnames []string) {
+	if n.SecondaryService == nil || len(hostnames) == 0 {
+		return
+	}
+
+	n.SecondaryService.Annotations[externalDNSHostnameAnnotation] = strings.Join(hostnames, ",")
+}
+
+// IngressZoneType returns the type of the DNS zones the Ingresses of the Ingress Controller should only be published into, empty
+// when they can be published into zones of any type. With a SecondaryService it's the type of the Service since that's the address
+// the Ingresses report.
+func (n *NginxResources) IngressZoneType() string {
+	if n.SecondaryService == nil {
+		return ""
+	}
+
+	return n.Service.Annotations[externalDNSZoneTypeAnnotation]
+}
+
+// SetIngressZoneType restricts the DNS zones an Ingress is published into to the zoneType, or removes the restriction when zoneType
+// is empty. It returns whether the Ingress changed.
+func SetIngressZoneType(ing *netv1.Ingress, zoneType string) bool {
+	current, ok := ing.Annotations[externalDNSZoneTypeAnnotation]
+	if zoneType == "" {
+		if !ok {
+			return false
+		}
+		delete(ing.Annotations, externalDNSZoneTypeAnnotation)
+		return true
+	}
+
+	if ok && current == zoneType {
+		return false
+	}
+	if ing.Annotations == nil {
+		ing.Annotations = map[string]string{}
+	}
+	ing.Annotations[externalDNSZoneTypeAnnotation] = zoneType
+	return true
+}
+
 func newNginxIngressControllerService(conf *config.Config, ingressConfig *NginxIngressConfig) *corev1.Service {
+	return newNginxIngressControllerLoadBalancerService(conf, ingressConfig, ingressConfig.ResourceName, ingressConfig.ServiceConfig)
+}
+
+func newNginxIngressControllerSecondaryService(conf *config.Config, ingressConfig *NginxIngressConfig) *corev1.Service {
+	if ingressConfig.SecondaryServiceConfig == nil {
+		return nil
+	}
+
+	return newNginxIngressControllerLoadBalancerService(conf, ingressConfig, SecondaryServiceName(ingressConfig), ingressConfig.SecondaryServiceConfig)
+}
+
+func newNginxIngressControllerLoadBalancerService(conf *config.Config, ingressConfig *NginxIngressConfig, name string, serviceConfig *ServiceConfig) *corev1.Service {
 	annotations := make(map[string]string)
 	sourceRanges := []string{}
-	if ingressConfig != nil && ingressConfig.ServiceConfig != nil {
-		for k, v := range ingressConfig.ServiceConfig.Annotations {
+	externalTrafficPolicy := corev1.ServiceExternalTrafficPolicyLocal
+	if serviceConfig != nil {
+		for k, v := range serviceConfig.Annotations {
 			annotations[k] = v
 		}
 
-		sourceRanges = ingressConfig.ServiceConfig.LoadBalancerSourceRanges
+		sourceRanges = serviceConfig.LoadBalancerSourceRanges
+
+		if lb := serviceConfig.LoadBalancer; lb != nil {
+			for k, v := range lb.Annotations() {
+				annotations[k] = v
+			}
+
+			if lb.ExternalTrafficPolicy != "" {
+				externalTrafficPolicy = lb.ExternalTrafficPolicy
+			}
+		}
 	}
 
 	ret := &corev1.Service{
@@ -338,13 +647,13 @@ func newNginxIngressControllerService(conf *config.Config, ingressConfig *NginxI
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        ingressConfig.ResourceName,
+			Name:        name,
 			Namespace:   conf.NS,
 			Labels:      AddComponentLabel(GetTopLevelLabels(), "ingress-controller"),
 			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyTypeLocal,
+			ExternalTrafficPolicy:    externalTrafficPolicy,
 			Type:                     corev1.ServiceTypeLoadBalancer,
 			Selector:                 ingressConfig.PodLabels(),
 			LoadBalancerSourceRanges: sourceRanges,
@@ -358,6 +667,15 @@ func newNginxIngressControllerService(conf *config.Config, ingressConfig *NginxI
 		},
 	}
 
+	// each of a pair of load balancers is only published into the DNS zones of its type. See externalDNSZoneTypeAnnotation
+	if ingressConfig.SecondaryServiceConfig != nil {
+		provider := PublicProvider
+		if IsInternalLoadBalancer(ret) {
+			provider = PrivateProvider
+		}
+		annotations[externalDNSZoneTypeAnnotation] = provider.zoneType()
+	}
+
 	if !ingressConfig.HTTPDisabled {
 		ret.Spec.Ports = append([]corev1.ServicePort{
 			{
@@ -398,15 +716,145 @@ func newNginxIngressControllerPromService(conf *config.Config, ingressConfig *Ng
 	}
 }
 
+// WebhookName returns the name of the admission webhook Service, Secret, and ValidatingWebhookConfiguration for the resource name
+func WebhookName(resourceName string) string {
+	return resourceName + "-admission"
+}
+
+// WebhookDNSNames returns the names the API server uses to reach the admission webhook. The serving certificate must be valid for them.
+func (n *NginxResources) WebhookDNSNames() []string {
+	host := n.WebhookService.Name + "." + n.WebhookService.Namespace + ".svc"
+	return []string{host, host + ".cluster.local"}
+}
+
+// SetWebhookCertificate sets the serving certificate and key of the admission webhook and the CA bundle the API server uses to
+// verify it. The bundle can hold more than one CA so the previous certificate stays trusted while a new one is rolled out.
+func (n *NginxResources) SetWebhookCertificate(caBundle, cert, key []byte) {
+	n.WebhookSecret.Data = map[string][]byte{
+		"ca.crt":                caBundle,
+		corev1.TLSCertKey:       cert,
+		corev1.TLSPrivateKeyKey: key,
+	}
+
+	for i := range n.ValidatingWebhookConfiguration.Webhooks {
+		n.ValidatingWebhookConfiguration.Webhooks[i].ClientConfig.CABundle = caBundle
+	}
+}
+
+func newNginxIngressControllerWebhookService(conf *config.Config, ingressConfig *NginxIngressConfig) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      WebhookName(ingressConfig.ResourceName),
+			Namespace: conf.NS,
+			Labels:    AddComponentLabel(GetTopLevelLabels(), "ingress-controller"),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: ingressConfig.PodLabels(),
+			Ports: []corev1.ServicePort{
+				webhookServicePort,
+			},
+		},
+	}
+}
+
+func newNginxIngressControllerWebhookSecret(conf *config.Config, ingressConfig *NginxIngressConfig) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      WebhookName(ingressConfig.ResourceName),
+			Namespace: conf.NS,
+			Labels:    AddComponentLabel(GetTopLevelLabels(), "ingress-controller"),
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+}
+
+func newNginxIngressControllerValidatingWebhookConfiguration(conf *config.Config, ingressConfig *NginxIngressConfig) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	// unreachable webhooks are ignored, otherwise Ingresses couldn't be written while the controller is unavailable like when it's first created
+	failurePolicy := admissionregistrationv1.Ignore
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ValidatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   WebhookName(ingressConfig.ResourceName),
+			Labels: AddComponentLabel(GetTopLevelLabels(), "ingress-controller"),
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: "validate.nginx.ingress.kubernetes.io",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{
+						Namespace: conf.NS,
+						Name:      WebhookName(ingressConfig.ResourceName),
+						Path:      util.ToPtr("/networking/v1/ingresses"),
+						Port:      util.ToPtr(webhookServicePort.Port),
+					},
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{netv1.GroupName},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"ingresses"},
+						},
+					},
+				},
+				// every NGINX Ingress Controller only validates Ingresses of its own IngressClass, set by field or the deprecated
+				// annotation. Ingresses without either get the name of the default IngressClass set by the API server before they're
+				// validated.
+				MatchConditions: []admissionregistrationv1.MatchCondition{
+					{
+						Name: "ingress-class",
+						Expression: fmt.Sprintf(
+							"(has(object.spec.ingressClassName) && object.spec.ingressClassName == %[1]q) || (has(object.metadata.annotations) && %[2]q in object.metadata.annotations && object.metadata.annotations[%[2]q] == %[1]q)",
+							ingressConfig.IcName, ingressClassAnnotation,
+						),
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				MatchPolicy:             &matchPolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+				TimeoutSeconds:          util.Int32Ptr(10),
+			},
+		},
+	}
+}
+
 func nginxImage(conf *config.Config, ingressConfig *NginxIngressConfig) string {
-	if conf.EnableDalecNginx {
+	if isDalecNginx(conf, ingressConfig) {
 		return path.Join(conf.Registry, dalecNginxImagePath+ingressConfig.Version.tag)
 	}
 	return path.Join(conf.Registry, nginxImagePath+ingressConfig.Version.tag)
 }
 
-func nginxRunAsUser(conf *config.Config) *int64 {
-	if conf.EnableDalecNginx {
+// NginxImageTag returns the tag of an NGINX Ingress Controller image, the nginx version it runs, or an empty string if it has none
+func NginxImageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+
+	return image[i+1:]
+}
+
+func nginxRunAsUser(conf *config.Config, ingressConfig *NginxIngressConfig) *int64 {
+	if isDalecNginx(conf, ingressConfig) {
 		return util.Int64Ptr(1000)
 	}
 	return util.Int64Ptr(101)
@@ -415,20 +863,32 @@ func nginxRunAsUser(conf *config.Config) *int64 {
 func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *NginxIngressConfig) *appsv1.Deployment {
 	ingressControllerDeploymentLabels := AddComponentLabel(GetTopLevelLabels(), IngressControllerComponentName)
 
-	ingressControllerPodLabels := AddComponentLabel(GetTopLevelLabels(), IngressControllerComponentName)
+	// user provided labels and annotations are applied first so they can't override the ones we manage
+	ingressControllerPodLabels := map[string]string{}
+	podAnnotations := map[string]string{}
+	if tmpl := ingressConfig.PodTemplate; tmpl != nil {
+		for k, v := range tmpl.Labels {
+			ingressControllerPodLabels[k] = v
+		}
+		for k, v := range tmpl.Annotations {
+			podAnnotations[k] = v
+		}
+	}
+
+	for k, v := range AddComponentLabel(GetTopLevelLabels(), IngressControllerComponentName) {
+		ingressControllerPodLabels[k] = v
+	}
 	for k, v := range ingressConfig.PodLabels() {
 		ingressControllerPodLabels[k] = v
 	}
 
-	podAnnotations := map[string]string{
-		// https://learn.microsoft.com/en-us/azure/aks/outbound-rules-control-egress#required-outbound-network-rules-and-fqdns-for-aks-clusters
-		// helps with firewalls blocking communication to api server
-		"kubernetes.azure.com/set-kube-service-host-fqdn": "true",
-	}
+	// https://learn.microsoft.com/en-us/azure/aks/outbound-rules-control-egress#required-outbound-network-rules-and-fqdns-for-aks-clusters
+	// helps with firewalls blocking communication to api server
+	podAnnotations["kubernetes.azure.com/set-kube-service-host-fqdn"] = "true"
 	if !conf.DisableOSM {
 		podAnnotations["openservicemesh.io/sidecar-injection"] = "disabled"
 	}
-	if conf.EnableDalecNginx {
+	if isDalecNginx(conf, ingressConfig) {
 		// opt the pod out of Dynatrace injection; the namespace-level annotation
 		// is ignored by the Dynatrace operator, which only reads these from the
 		// pod template. dynatrace.com/inject is the master switch; the oneagent
@@ -457,6 +917,10 @@ func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *Ngi
 		"--shutdown-grace-period=15",
 		// this was a breaking change when moving to v1.12+, setting it explicitly for now to avoid breaking customers
 		"--enable-metrics=true",
+		// rejects Ingresses that would break the NGINX config before they're admitted instead of failing the reload for every Ingress
+		fmt.Sprintf("--validating-webhook=:%d", webhookPodPort.ContainerPort),
+		"--validating-webhook-certificate=" + path.Join(webhookCertDir, corev1.TLSCertKey),
+		"--validating-webhook-key=" + path.Join(webhookCertDir, corev1.TLSPrivateKeyKey),
 	}
 
 	if ingressConfig.DefaultSSLCertificate != "" {
@@ -504,6 +968,16 @@ func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *Ngi
 						},
 					},
 					ServiceAccountName: ingressConfig.ResourceName,
+					Volumes: []corev1.Volume{
+						{
+							Name: "webhook-cert",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: WebhookName(ingressConfig.ResourceName),
+								},
+							},
+						},
+					},
 					Containers: []corev1.Container{*withPodRefEnvVars(withLivenessProbeMatchingReadinessNewFailureThresh(withTypicalReadinessProbe(10254, &corev1.Container{
 						Name:  "controller",
 						Image: nginxImage(conf, ingressConfig),
@@ -515,7 +989,7 @@ func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *Ngi
 								Drop: []corev1.Capability{"ALL"},
 							},
 							RunAsNonRoot: util.ToPtr(true),
-							RunAsUser:    nginxRunAsUser(conf),
+							RunAsUser:    nginxRunAsUser(conf, ingressConfig),
 							SeccompProfile: &corev1.SeccompProfile{
 								Type: corev1.SeccompProfileTypeRuntimeDefault,
 							},
@@ -526,6 +1000,14 @@ func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *Ngi
 								ContainerPort: 443,
 							},
 							promPodPort,
+							webhookPodPort,
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{
+								Name:      "webhook-cert",
+								MountPath: webhookCertDir,
+								ReadOnly:  true,
+							},
 						},
 						Resources: corev1.ResourceRequirements{
 							Requests: corev1.ResourceList{
@@ -548,9 +1030,88 @@ func newNginxIngressControllerDeployment(conf *config.Config, ingressConfig *Ngi
 		}, ret.Spec.Template.Spec.Containers[0].Ports...)
 	}
 
+	if tmpl := ingressConfig.PodTemplate; tmpl != nil {
+		podSpec := &ret.Spec.Template.Spec
+		if tmpl.Resources != nil {
+			// resources that aren't overridden keep their defaults
+			overrides := tmpl.Resources.DeepCopy()
+			resources := &podSpec.Containers[0].Resources
+			resources.Requests = util.MergeMaps(resources.Requests, overrides.Requests)
+			if len(overrides.Limits) > 0 {
+				resources.Limits = util.MergeMaps(resources.Limits, overrides.Limits)
+			}
+			// a default request can't be above a limit that's set without a request or the pod is rejected
+			for name, limit := range overrides.Limits {
+				if _, ok := overrides.Requests[name]; ok {
+					continue
+				}
+				if request, ok := resources.Requests[name]; ok && request.Cmp(limit) > 0 {
+					resources.Requests[name] = limit
+				}
+			}
+			resources.Claims = append(resources.Claims, overrides.Claims...)
+		}
+		if len(tmpl.NodeSelector) > 0 {
+			podSpec.NodeSelector = tmpl.NodeSelector
+		}
+		if tmpl.PriorityClassName != "" {
+			podSpec.PriorityClassName = tmpl.PriorityClassName
+		}
+		podSpec.Tolerations = append(podSpec.Tolerations, tmpl.Tolerations...)
+		for _, tsc := range tmpl.TopologySpreadConstraints {
+			tsc := *tsc.DeepCopy()
+			if tsc.LabelSelector == nil {
+				tsc.LabelSelector = selector
+			}
+			podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, tsc)
+		}
+	}
+
 	return ret
 }
 
+// defaultAnnotationValueWordBlocklist is the comma separated list of words that are blocked in annotation values by default.
+// See: https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/#annotation-value-word-blocklist
+const defaultAnnotationValueWordBlocklist = "load_module,lua_package,_by_lua,location,root,proxy_pass,serviceaccount,{,},'"
+
+// reservedNginxConfigKeys are ConfigMap keys that can't be set through NginxIngressConfig.Config along with the reason why
+var reservedNginxConfigKeys = map[string]string{
+	"allow-snippet-annotations":       "managed by App Routing",
+	"annotations-risk-level":          "managed by App Routing",
+	"allow-cross-namespace-resources": "managed by App Routing",
+	"log-format-upstream":             "use spec.logFormat instead",
+	"custom-http-errors":              "use spec.customHTTPErrors instead",
+	"force-ssl-redirect":              "use spec.defaultSSLCertificate.forceSSLRedirect instead",
+}
+
+// ValidateNginxConfigOverride returns an error describing why the given ConfigMap key and value can't be merged into the
+// NGINX ConfigMap or nil if it can be. Security-critical keys are either reserved or may only be made stricter.
+func ValidateNginxConfigOverride(key, value string) error {
+	if reason, ok := reservedNginxConfigKeys[key]; ok {
+		return errors.New(reason)
+	}
+
+	// snippets are injected directly into nginx.conf and bypass the annotation-value-word-blocklist
+	if strings.HasSuffix(key, "-snippet") {
+		return errors.New("snippets are not allowed")
+	}
+
+	if key == "annotation-value-word-blocklist" {
+		words := make(map[string]struct{})
+		for _, word := range strings.Split(value, ",") {
+			words[strings.TrimSpace(word)] = struct{}{}
+		}
+
+		for _, word := range strings.Split(defaultAnnotationValueWordBlocklist, ",") {
+			if _, ok := words[word]; !ok {
+				return fmt.Errorf("must include the default blocklist %q", defaultAnnotationValueWordBlocklist)
+			}
+		}
+	}
+
+	return nil
+}
+
 func newNginxIngressControllerConfigmap(conf *config.Config, ingressConfig *NginxIngressConfig) *corev1.ConfigMap {
 	confMap := &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
@@ -567,7 +1128,7 @@ func newNginxIngressControllerConfigmap(conf *config.Config, ingressConfig *Ngin
 			// But we can still protect against leaked service account tokens.
 			// See: https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/#annotation-value-word-blocklist
 			"allow-snippet-annotations":       "true",
-			"annotation-value-word-blocklist": "load_module,lua_package,_by_lua,location,root,proxy_pass,serviceaccount,{,},'",
+			"annotation-value-word-blocklist": defaultAnnotationValueWordBlocklist,
 			// breaking change in v1.12+ so we need to explicitly set this. We should remove this in a future release
 			"allow-cross-namespace-resources": "true",
 			// breaking change in v1.12+, we need to explicitly set this so it's not a breaking change
@@ -575,6 +1136,14 @@ func newNginxIngressControllerConfigmap(conf *config.Config, ingressConfig *Ngin
 		},
 	}
 
+	for k, v := range ingressConfig.Config {
+		if err := ValidateNginxConfigOverride(k, v); err != nil {
+			continue
+		}
+
+		confMap.Data[k] = v
+	}
+
 	if ingressConfig.DefaultSSLCertificate != "" && ingressConfig.ForceSSLRedirect {
 		confMap.Data["force-ssl-redirect"] = "true"
 	}
@@ -613,26 +1182,74 @@ func newNginxIngressControllerPDB(conf *config.Config, ingressConfig *NginxIngre
 	}
 }
 
-func newNginxIngressControllerHPA(conf *config.Config, ingressConfig *NginxIngressConfig) *autov1.HorizontalPodAutoscaler {
-	return &autov1.HorizontalPodAutoscaler{
+func newNginxIngressControllerHPA(conf *config.Config, ingressConfig *NginxIngressConfig) *autov2.HorizontalPodAutoscaler {
+	metrics := []autov2.MetricSpec{resourceUtilizationMetric(corev1.ResourceCPU, ingressConfig.TargetCPUUtilizationPercentage)}
+	if ingressConfig.TargetMemoryUtilizationPercentage > 0 {
+		metrics = append(metrics, resourceUtilizationMetric(corev1.ResourceMemory, ingressConfig.TargetMemoryUtilizationPercentage))
+	}
+
+	if m := ingressConfig.ScalingMetric; m != nil {
+		identifier := autov2.MetricIdentifier{
+			Name:     m.Name,
+			Selector: m.Selector,
+		}
+		target := autov2.MetricTarget{
+			Type:         autov2.AverageValueMetricType,
+			AverageValue: util.ToPtr(m.TargetAverageValue),
+		}
+
+		if m.External {
+			metrics = append(metrics, autov2.MetricSpec{
+				Type: autov2.ExternalMetricSourceType,
+				External: &autov2.ExternalMetricSource{
+					Metric: identifier,
+					Target: target,
+				},
+			})
+		} else {
+			metrics = append(metrics, autov2.MetricSpec{
+				Type: autov2.PodsMetricSourceType,
+				Pods: &autov2.PodsMetricSource{
+					Metric: identifier,
+					Target: target,
+				},
+			})
+		}
+	}
+
+	return &autov2.HorizontalPodAutoscaler{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "HorizontalPodAutoscaler",
-			APIVersion: "autoscaling/v1",
+			APIVersion: "autoscaling/v2",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ingressConfig.ResourceName,
 			Namespace: conf.NS,
 			Labels:    AddComponentLabel(GetTopLevelLabels(), "ingress-controller"),
 		},
-		Spec: autov1.HorizontalPodAutoscalerSpec{
-			ScaleTargetRef: autov1.CrossVersionObjectReference{
+		Spec: autov2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autov2.CrossVersionObjectReference{
 				APIVersion: "apps/v1",
 				Kind:       "Deployment",
 				Name:       ingressConfig.ResourceName,
 			},
-			MinReplicas:                    util.Int32Ptr(ingressConfig.MinReplicas),
-			MaxReplicas:                    ingressConfig.MaxReplicas,
-			TargetCPUUtilizationPercentage: &ingressConfig.TargetCPUUtilizationPercentage,
+			MinReplicas: util.Int32Ptr(ingressConfig.MinReplicas),
+			MaxReplicas: ingressConfig.MaxReplicas,
+			Metrics:     metrics,
+			Behavior:    ingressConfig.ScalingBehavior,
+		},
+	}
+}
+
+func resourceUtilizationMetric(name corev1.ResourceName, averageUtilization int32) autov2.MetricSpec {
+	return autov2.MetricSpec{
+		Type: autov2.ResourceMetricSourceType,
+		Resource: &autov2.ResourceMetricSource{
+			Name: name,
+			Target: autov2.MetricTarget{
+				Type:               autov2.UtilizationMetricType,
+				AverageUtilization: util.Int32Ptr(averageUtilization),
+			},
 		},
 	}
 }
@@ -5,11 +5,21 @@ package manifests
 
 import (
 	"encoding/json"
+	"fmt"
 	"path"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -555,6 +565,245 @@ var (
 				return &copy
 			}(),
 		},
+		{
+			Name: "full-with-config",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.Config = map[string]string{
+					"proxy-body-size":                 "8m",
+					"keep-alive":                      "60",
+					"ssl-protocols":                   "TLSv1.3",
+					"annotation-value-word-blocklist": defaultAnnotationValueWordBlocklist + ",alias",
+					"allow-snippet-annotations":       "false", // reserved, should be ignored
+					"http-snippet":                    "server {}",
+				}
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-pod-template",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.PodTemplate = &PodTemplateConfig{
+					Labels: map[string]string{
+						"team": "ingress",
+						"app":  "overridden", // managed, should be ignored
+					},
+					Annotations: map[string]string{
+						"example.com/owner": "ingress-team",
+						"kubernetes.azure.com/set-kube-service-host-fqdn": "false", // managed, should be ignored
+					},
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+					},
+					NodeSelector: map[string]string{"agentpool": "ingress"},
+					Tolerations: []corev1.Toleration{{
+						Key:      "dedicated",
+						Operator: corev1.TolerationOpEqual,
+						Value:    "ingress",
+						Effect:   corev1.TaintEffectNoSchedule,
+					}},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+					}},
+					PriorityClassName: "ingress-critical",
+				}
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-scaling-metrics",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.TargetMemoryUtilizationPercentage = 75
+				copy.ScalingMetric = &ScalingMetricConfig{
+					Name: NginxActiveConnectionsMetric,
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"state": "active"},
+					},
+					TargetAverageValue: resource.MustParse("500"),
+				}
+				copy.ScalingBehavior = &autov2.HorizontalPodAutoscalerBehavior{
+					ScaleUp: &autov2.HPAScalingRules{
+						StabilizationWindowSeconds: util.Int32Ptr(0),
+						Policies: []autov2.HPAScalingPolicy{{
+							Type:          autov2.PercentScalingPolicy,
+							Value:         100,
+							PeriodSeconds: 15,
+						}},
+					},
+					ScaleDown: &autov2.HPAScalingRules{
+						StabilizationWindowSeconds: util.Int32Ptr(600),
+					},
+				}
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-upstream-image-flavor",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.ImageFlavor = UpstreamNginxImageFlavor
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-external-scaling-metric",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.ScalingMetric = &ScalingMetricConfig{
+					Name:     NginxRequestsPerSecondMetric,
+					External: true,
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "nginx"},
+					},
+					TargetAverageValue: resource.MustParse("1k"),
+				}
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-secondary-load-balancer",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.ServiceConfig = &ServiceConfig{
+					LoadBalancerSourceRanges: []string{"127.1.000.1"},
+					LoadBalancer:             &LoadBalancerConfig{Internal: util.ToPtr(false), DNSLabelName: "my-ingress"},
+				}
+				copy.SecondaryServiceConfig = &ServiceConfig{
+					LoadBalancerSourceRanges: []string{"127.1.000.1"},
+					LoadBalancer:             &LoadBalancerConfig{Internal: util.ToPtr(true), Subnet: "ingress-subnet"},
+				}
+				return &copy
+			}(),
+		},
+		{
+			Name: "full-with-load-balancer",
+			Conf: &config.Config{
+				NS:          "test-namespace",
+				Registry:    "test-registry",
+				MSIClientID: "test-msi-client-id",
+				TenantID:    "test-tenant-id",
+				Cloud:       "test-cloud",
+				Location:    "test-location",
+			},
+			Deploy: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-operator-deploy",
+					UID:  "test-operator-deploy-uid",
+				},
+			},
+			IngConfig: func() *NginxIngressConfig {
+				copy := *ingConfig
+				copy.ServiceConfig = &ServiceConfig{
+					Annotations: map[string]string{
+						"service.beta.kubernetes.io/azure-load-balancer-internal": "True",
+						"service.beta.kubernetes.io/azure-load-balancer-ipv4":     "10.0.0.10",
+					},
+					LoadBalancer: &LoadBalancerConfig{
+						Internal:               util.ToPtr(true),
+						Subnet:                 "ingress-subnet",
+						HealthProbeRequestPath: "/healthz",
+						ExternalTrafficPolicy:  corev1.ServiceExternalTrafficPolicyCluster,
+						PrivateLinkService: &PrivateLinkServiceConfig{
+							Name:                      "ingress-pls",
+							VisibilitySubscriptions:   []string{"*"},
+							AutoApprovalSubscriptions: []string{"sub1", "sub2"},
+						},
+					},
+				}
+				return &copy
+			}(),
+		},
 	}
 	classTestCases = []struct {
 		Name      string
@@ -712,7 +961,7 @@ func TestNginxRunAsUser(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			conf := &config.Config{EnableDalecNginx: tc.dalec}
 
-			got := nginxRunAsUser(conf)
+			got := nginxRunAsUser(conf, &NginxIngressConfig{})
 			if *got != tc.expected {
 				t.Errorf("nginxRunAsUser() = %d, want %d", *got, tc.expected)
 			}
@@ -720,6 +969,596 @@ func TestNginxRunAsUser(t *testing.T) {
 	}
 }
 
+func TestIsDalecNginx(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dalec    bool
+		flavor   NginxImageFlavor
+		expected bool
+	}{
+		{
+			name:     "operator default upstream",
+			expected: false,
+		},
+		{
+			name:     "operator default dalec",
+			dalec:    true,
+			expected: true,
+		},
+		{
+			name:     "dalec flavor overrides operator default",
+			flavor:   DalecNginxImageFlavor,
+			expected: true,
+		},
+		{
+			name:     "upstream flavor overrides operator default",
+			dalec:    true,
+			flavor:   UpstreamNginxImageFlavor,
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &config.Config{EnableDalecNginx: tc.dalec}
+			got := isDalecNginx(conf, &NginxIngressConfig{ImageFlavor: tc.flavor})
+			if got != tc.expected {
+				t.Errorf("isDalecNginx() = %t, want %t", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResolveNginxVersion(t *testing.T) {
+	t.Parallel()
+
+	v1_12_1 := NginxIngressVersion{name: "v1.12.1", tag: "v1.12.1"}
+	v1_12_3 := NginxIngressVersion{name: "v1.12.3", tag: "v1.12.3"}
+	v1_13_0 := NginxIngressVersion{name: "v1.13.0", tag: "v1.13.0"}
+	v1_13_7 := NginxIngressVersion{name: "v1.13.7", tag: "v1.13.7"}
+	versions := []NginxIngressVersion{v1_12_1, v1_12_3, v1_13_0, v1_13_7}
+
+	tests := []struct {
+		name     string
+		versions []NginxIngressVersion
+		channel  NginxUpgradeChannel
+		pinned   string
+		expected NginxIngressVersion
+		wantErr  string
+	}{
+		{
+			name:     "unset channel uses latest",
+			versions: versions,
+			expected: v1_13_7,
+		},
+		{
+			name:     "latest",
+			versions: versions,
+			channel:  LatestNginxUpgradeChannel,
+			expected: v1_13_7,
+		},
+		{
+			name:     "stable uses newest patch of previous minor",
+			versions: versions,
+			channel:  StableNginxUpgradeChannel,
+			expected: v1_12_3,
+		},
+		{
+			name:     "stable without previous minor uses oldest",
+			versions: []NginxIngressVersion{v1_13_0, v1_13_7},
+			channel:  StableNginxUpgradeChannel,
+			expected: v1_13_0,
+		},
+		{
+			name:     "pinned",
+			versions: versions,
+			channel:  PinnedNginxUpgradeChannel,
+			pinned:   "v1.12.1",
+			expected: v1_12_1,
+		},
+		{
+			name:     "pinned to unavailable version",
+			versions: versions,
+			channel:  PinnedNginxUpgradeChannel,
+			pinned:   "v1.11.0",
+			wantErr:  `version "v1.11.0" is not available, available versions are v1.12.1, v1.12.3, v1.13.0, v1.13.7`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveNginxVersion(tc.versions, tc.channel, tc.pinned)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("resolveNginxVersion() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveNginxVersion() unexpected error: %v", err)
+			}
+			if *got != tc.expected {
+				t.Errorf("resolveNginxVersion() = %v, want %v", *got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestResolveNginxVersionExplicitVersion(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{}
+	explicit := &NginxIngressVersion{name: "v0.0.1", tag: "v0.0.1"}
+	got, err := ResolveNginxVersion(conf, &NginxIngressConfig{
+		Version:        explicit,
+		UpgradeChannel: PinnedNginxUpgradeChannel,
+		PinnedVersion:  "v1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("ResolveNginxVersion() unexpected error: %v", err)
+	}
+	if got != explicit {
+		t.Errorf("ResolveNginxVersion() = %v, want %v", got, explicit)
+	}
+
+	latest := LatestAvailableNginxVersion(&config.Config{}, &NginxIngressConfig{ImageFlavor: DalecNginxImageFlavor})
+	if latest != LatestDalecNginxVersion {
+		t.Errorf("LatestAvailableNginxVersion() = %v, want %v", latest, LatestDalecNginxVersion)
+	}
+}
+
+func TestValidateNginxConfigOverride(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{
+			name:  "unmanaged key",
+			key:   "proxy-body-size",
+			value: "8m",
+		},
+		{
+			name:    "reserved key",
+			key:     "allow-snippet-annotations",
+			value:   "true",
+			wantErr: "managed by App Routing",
+		},
+		{
+			name:    "key configured through spec",
+			key:     "log-format-upstream",
+			value:   "$status",
+			wantErr: "use spec.logFormat instead",
+		},
+		{
+			name:    "snippet",
+			key:     "server-snippet",
+			value:   "return 200;",
+			wantErr: "snippets are not allowed",
+		},
+		{
+			name:  "stricter blocklist",
+			key:   "annotation-value-word-blocklist",
+			value: defaultAnnotationValueWordBlocklist + ",alias",
+		},
+		{
+			name:  "reordered blocklist with spaces",
+			key:   "annotation-value-word-blocklist",
+			value: "', }, {, serviceaccount, proxy_pass, root, location, _by_lua, lua_package, load_module",
+		},
+		{
+			name:    "weaker blocklist",
+			key:     "annotation-value-word-blocklist",
+			value:   "load_module,lua_package",
+			wantErr: "must include the default blocklist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateNginxConfigOverride(tc.key, tc.value)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateNginxConfigOverride() unexpected error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ValidateNginxConfigOverride() error = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNginxImageTag(t *testing.T) {
+	t.Parallel()
+
+	versioned := *ingConfig
+	versioned.Version = &nginxVersionsAscending[len(nginxVersionsAscending)-1]
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: nginxImage(&config.Config{Registry: "mcr.microsoft.com"}, &versioned), want: versioned.Version.tag},
+		{image: "mcr.microsoft.com/oss/v2/ingress-nginx/controller:v1.13.7", want: "v1.13.7"},
+		{image: "localhost:5000/ingress-nginx/controller:v1.13.7", want: "v1.13.7"},
+		{image: "localhost:5000/ingress-nginx/controller", want: ""},
+		{image: "mcr.microsoft.com/oss/v2/ingress-nginx/controller:v1.13.7@sha256:0123456789abcdef", want: "v1.13.7"},
+		{image: "mcr.microsoft.com/oss/v2/ingress-nginx/controller@sha256:0123456789abcdef", want: ""},
+	}
+
+	for _, tc := range tests {
+		if got := NginxImageTag(tc.image); got != tc.want {
+			t.Errorf("NginxImageTag(%q) = %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
+
+func TestPodTemplateResources(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		overrides *corev1.ResourceRequirements
+		want      corev1.ResourceRequirements
+	}{
+		{
+			name: "request and limit",
+			overrides: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+		},
+		{
+			name: "limits above the default requests",
+			overrides: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("127Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			name: "limits below the default requests",
+			overrides: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+		},
+		{
+			name: "limit below the default request with a request",
+			overrides: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("127Mi"),
+				},
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			copy := *ingConfig
+			copy.PodTemplate = &PodTemplateConfig{Resources: tc.overrides}
+
+			got := GetNginxResources(&config.Config{NS: "test-namespace"}, &copy).Deployment.Spec.Template.Spec.Containers[0].Resources
+			if !equality.Semantic.DeepEqual(got, tc.want) {
+				t.Errorf("got resources %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerConfigAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		lb   *LoadBalancerConfig
+		want map[string]string
+	}{
+		{
+			name: "nil",
+			want: map[string]string{},
+		},
+		{
+			name: "empty",
+			lb:   &LoadBalancerConfig{ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyCluster},
+			want: map[string]string{},
+		},
+		{
+			name: "public",
+			lb: &LoadBalancerConfig{
+				Internal:              util.ToPtr(false),
+				PublicIPName:          "ingress-ip",
+				PublicIPResourceGroup: "network-rg",
+				DNSLabelName:          "my-ingress",
+			},
+			want: map[string]string{
+				"service.beta.kubernetes.io/azure-load-balancer-internal":       "false",
+				"service.beta.kubernetes.io/azure-pip-name":                     "ingress-ip",
+				"service.beta.kubernetes.io/azure-load-balancer-resource-group": "network-rg",
+				"service.beta.kubernetes.io/azure-dns-label-name":               "my-ingress",
+			},
+		},
+		{
+			name: "internal with private link service",
+			lb: &LoadBalancerConfig{
+				Internal:               util.ToPtr(true),
+				Subnet:                 "ingress-subnet",
+				HealthProbeRequestPath: "/healthz",
+				PrivateLinkService: &PrivateLinkServiceConfig{
+					IPConfigurationSubnet:     "pls-subnet",
+					AutoApprovalSubscriptions: []string{"sub1", "sub2"},
+				},
+			},
+			want: map[string]string{
+				"service.beta.kubernetes.io/azure-load-balancer-internal":                  "true",
+				"service.beta.kubernetes.io/azure-load-balancer-internal-subnet":           "ingress-subnet",
+				"service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path": "/healthz",
+				"service.beta.kubernetes.io/azure-pls-create":                              "true",
+				"service.beta.kubernetes.io/azure-pls-ip-configuration-subnet":             "pls-subnet",
+				"service.beta.kubernetes.io/azure-pls-auto-approval":                       "sub1 sub2",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.lb.Annotations()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Annotations() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetWebhookCertificate(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{NS: "test-namespace"}
+	res := GetNginxResources(conf, ingConfig)
+
+	wantNames := []string{"nginx-admission.test-namespace.svc", "nginx-admission.test-namespace.svc.cluster.local"}
+	if got := res.WebhookDNSNames(); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("WebhookDNSNames() = %v, want %v", got, wantNames)
+	}
+
+	res.SetWebhookCertificate([]byte("ca"), []byte("cert"), []byte("key"))
+	wantData := map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert"), "tls.key": []byte("key")}
+	if !reflect.DeepEqual(res.WebhookSecret.Data, wantData) {
+		t.Errorf("WebhookSecret.Data = %v, want %v", res.WebhookSecret.Data, wantData)
+	}
+
+	for _, webhook := range res.ValidatingWebhookConfiguration.Webhooks {
+		if string(webhook.ClientConfig.CABundle) != "ca" {
+			t.Errorf("webhook %s CABundle = %s, want ca", webhook.Name, webhook.ClientConfig.CABundle)
+		}
+		if svc := webhook.ClientConfig.Service; svc.Name != res.WebhookService.Name || svc.Namespace != res.WebhookService.Namespace {
+			t.Errorf("webhook %s calls Service %s/%s, want %s/%s", webhook.Name, svc.Namespace, svc.Name, res.WebhookService.Namespace, res.WebhookService.Name)
+		}
+		if len(webhook.MatchConditions) != 1 || !strings.Contains(webhook.MatchConditions[0].Expression, fmt.Sprintf("%q", ingConfig.IcName)) {
+			t.Errorf("webhook %s isn't scoped to IngressClass %s: %v", webhook.Name, ingConfig.IcName, webhook.MatchConditions)
+		}
+		if len(webhook.MatchConditions) == 1 && !strings.Contains(webhook.MatchConditions[0].Expression, fmt.Sprintf("%q", ingressClassAnnotation)) {
+			t.Errorf("webhook %s doesn't match Ingresses using the %s annotation: %v", webhook.Name, ingressClassAnnotation, webhook.MatchConditions)
+		}
+	}
+}
+
+func TestSecondaryService(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{NS: "test-namespace"}
+	withSecondary := func(internal bool) *NginxIngressConfig {
+		copy := *ingConfig
+		copy.ServiceConfig = &ServiceConfig{LoadBalancer: &LoadBalancerConfig{Internal: util.ToPtr(!internal)}}
+		copy.SecondaryServiceConfig = &ServiceConfig{LoadBalancer: &LoadBalancerConfig{Internal: util.ToPtr(internal)}}
+		return &copy
+	}
+
+	if res := GetNginxResources(conf, ingConfig); res.SecondaryService != nil || res.IngressZoneType() != "" {
+		t.Errorf("SecondaryService = %v, IngressZoneType() = %q, want nil and empty", res.SecondaryService, res.IngressZoneType())
+	}
+
+	tests := []struct {
+		name              string
+		internal          bool
+		wantName          string
+		wantZoneTypes     [2]string
+		hostnames         []string
+		wantHostnameValue string
+	}{
+		{
+			name:              "internal secondary",
+			internal:          true,
+			wantName:          "nginx-internal",
+			wantZoneTypes:     [2]string{"public", "private"},
+			hostnames:         []string{"a.example.com", "b.example.com"},
+			wantHostnameValue: "a.example.com,b.example.com",
+		},
+		{
+			name:          "public secondary without hostnames",
+			internal:      false,
+			wantName:      "nginx-public",
+			wantZoneTypes: [2]string{"private", "public"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := GetNginxResources(conf, withSecondary(tc.internal))
+			res.SetDNSHostnames(tc.hostnames)
+
+			if res.SecondaryService == nil || res.SecondaryService.Name != tc.wantName {
+				t.Fatalf("SecondaryService = %v, want name %s", res.SecondaryService, tc.wantName)
+			}
+			if IsInternalLoadBalancer(res.SecondaryService) != tc.internal {
+				t.Errorf("IsInternalLoadBalancer() = %v, want %v", !tc.internal, tc.internal)
+			}
+
+			// Ingresses still report the address of the first load balancer
+			if !slices.Contains(res.Deployment.Spec.Template.Spec.Containers[0].Args, "--publish-service=$(POD_NAMESPACE)/nginx") {
+				t.Errorf("args %v don't publish the first load balancer", res.Deployment.Spec.Template.Spec.Containers[0].Args)
+			}
+
+			for i, svc := range []*corev1.Service{res.Service, res.SecondaryService} {
+				if got := svc.Annotations[externalDNSZoneTypeAnnotation]; got != tc.wantZoneTypes[i] {
+					t.Errorf("service %s zone type = %q, want %q", svc.Name, got, tc.wantZoneTypes[i])
+				}
+			}
+
+			// the Ingresses are published into the zones of the first load balancer's type, the second one publishes their
+			// hostnames into the others
+			if got := res.IngressZoneType(); got != tc.wantZoneTypes[0] {
+				t.Errorf("IngressZoneType() = %q, want %q", got, tc.wantZoneTypes[0])
+			}
+			if got, ok := res.Service.Annotations[externalDNSHostnameAnnotation]; ok {
+				t.Errorf("service %s hostnames = %q, want none", res.Service.Name, got)
+			}
+			if got := res.SecondaryService.Annotations[externalDNSHostnameAnnotation]; got != tc.wantHostnameValue {
+				t.Errorf("service %s hostnames = %q, want %q", res.SecondaryService.Name, got, tc.wantHostnameValue)
+			}
+		})
+	}
+}
+
+func TestSetIngressZoneType(t *testing.T) {
+	t.Parallel()
+
+	ing := &netv1.Ingress{}
+	if !SetIngressZoneType(ing, "public") || ing.Annotations[externalDNSZoneTypeAnnotation] != "public" {
+		t.Errorf("annotations = %v, want zone type public", ing.Annotations)
+	}
+	if SetIngressZoneType(ing, "public") {
+		t.Errorf("setting the same zone type changed the Ingress")
+	}
+	if !SetIngressZoneType(ing, "private") || ing.Annotations[externalDNSZoneTypeAnnotation] != "private" {
+		t.Errorf("annotations = %v, want zone type private", ing.Annotations)
+	}
+	if !SetIngressZoneType(ing, "") || len(ing.Annotations) != 0 {
+		t.Errorf("annotations = %v, want none", ing.Annotations)
+	}
+	if SetIngressZoneType(ing, "") {
+		t.Errorf("removing a missing zone type changed the Ingress")
+	}
+}
+
+func TestValidateLoadBalancerAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      *ServiceConfig
+		wantErrs    []string
+		notWantErrs []string
+	}{
+		{
+			name: "nil service config",
+		},
+		{
+			name: "no typed load balancer",
+			config: &ServiceConfig{
+				Annotations: map[string]string{"service.beta.kubernetes.io/azure-load-balancer-internal": "true"},
+			},
+		},
+		{
+			name: "agreeing annotations",
+			config: &ServiceConfig{
+				Annotations: map[string]string{
+					"service.beta.kubernetes.io/azure-load-balancer-internal": "True",
+					"service.beta.kubernetes.io/azure-pip-name":               "ingress-ip",
+					"service.beta.kubernetes.io/azure-load-balancer-ipv4":     "10.0.0.10",
+				},
+				LoadBalancer: &LoadBalancerConfig{Internal: util.ToPtr(true), PublicIPName: "ingress-ip"},
+			},
+		},
+		{
+			name: "conflicting annotations",
+			config: &ServiceConfig{
+				Annotations: map[string]string{
+					"service.beta.kubernetes.io/azure-load-balancer-internal": "true",
+					"service.beta.kubernetes.io/azure-dns-label-name":         "other",
+					"service.beta.kubernetes.io/azure-pip-name":               "ingress-ip",
+				},
+				LoadBalancer: &LoadBalancerConfig{Internal: util.ToPtr(false), DNSLabelName: "my-ingress", PublicIPName: "ingress-ip"},
+			},
+			wantErrs: []string{
+				`annotation service.beta.kubernetes.io/azure-load-balancer-internal is "true" but spec.loadBalancer sets it to "false"`,
+				`annotation service.beta.kubernetes.io/azure-dns-label-name is "other" but spec.loadBalancer sets it to "my-ingress"`,
+			},
+			notWantErrs: []string{"azure-pip-name"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateLoadBalancerAnnotations(tc.config)
+			if len(tc.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("ValidateLoadBalancerAnnotations() unexpected error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("ValidateLoadBalancerAnnotations() expected error")
+			}
+			for _, want := range tc.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ValidateLoadBalancerAnnotations() error = %v, want error containing %q", err, want)
+				}
+			}
+			for _, notWant := range tc.notWantErrs {
+				if strings.Contains(err.Error(), notWant) {
+					t.Errorf("ValidateLoadBalancerAnnotations() error = %v, want error not containing %q", err, notWant)
+				}
+			}
+		})
+	}
+}
+
 func TestMapAdditions(t *testing.T) {
 	t.Parallel()
 
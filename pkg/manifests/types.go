@@ -1,12 +1,15 @@
 package manifests
 
 import (
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
-	autov1 "k8s.io/api/autoscaling/v1"
+	autov2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,11 +24,18 @@ type NginxResources struct {
 	ClusterRoleBinding      *rbacv1.ClusterRoleBinding
 	RoleBinding             *rbacv1.RoleBinding
 	Service                 *corev1.Service
+	SecondaryService        *corev1.Service // load balancer of the opposite type, nil unless a SecondaryServiceConfig is set
 	PromService             *corev1.Service
 	Deployment              *appsv1.Deployment
 	ConfigMap               *corev1.ConfigMap
-	HorizontalPodAutoscaler *autov1.HorizontalPodAutoscaler
+	HorizontalPodAutoscaler *autov2.HorizontalPodAutoscaler
 	PodDisruptionBudget     *policyv1.PodDisruptionBudget
+
+	// WebhookService, WebhookSecret, and ValidatingWebhookConfiguration expose the ingress-nginx validating admission webhook. The serving
+	// certificate is issued by the caller, see SetWebhookCertificate
+	WebhookService                 *corev1.Service
+	WebhookSecret                  *corev1.Secret
+	ValidatingWebhookConfiguration *admissionregistrationv1.ValidatingWebhookConfiguration
 }
 
 func (n *NginxResources) Objects() []client.Object {
@@ -38,10 +48,17 @@ func (n *NginxResources) Objects() []client.Object {
 		n.RoleBinding,
 		n.Service,
 		n.PromService,
+		n.WebhookService,
+		n.WebhookSecret,
 		n.Deployment,
 		n.ConfigMap,
 		n.HorizontalPodAutoscaler,
 		n.PodDisruptionBudget,
+		n.ValidatingWebhookConfiguration,
+	}
+
+	if n.SecondaryService != nil {
+		objs = append(objs, n.SecondaryService)
 	}
 
 	if n.Namespace != nil {
@@ -53,24 +70,39 @@ func (n *NginxResources) Objects() []client.Object {
 
 // NginxIngressConfig defines configuration options for required resources for an Ingress
 type NginxIngressConfig struct {
-	Version               *NginxIngressVersion
-	ControllerClass       string         // controller class which is equivalent to controller field of IngressClass
-	ResourceName          string         // name given to all resources
-	IcName                string         // IngressClass name
-	ServiceConfig         *ServiceConfig // service config that specifies details about the LB, defaults if nil
-	ForceSSLRedirect      bool           // flag to sets all redirects to HTTPS if there is a default TLS certificate (requires DefaultSSLCertificate)
-	HTTPDisabled          bool           // flag to disable HTTP server
-	DefaultSSLCertificate string         // namespace/name used to create SSL certificate for the default HTTPS server (catch-all)
-	DefaultBackendService string         // namespace/name used to determine default backend service for / and /healthz endpoints
-	CustomHTTPErrors      string         // error codes passed to the configmap to configure nginx to send traffic with the specified headers to its defaultbackend service in case of error
+	Version               *NginxIngressVersion // resolved from ImageFlavor, UpgradeChannel and PinnedVersion if nil
+	ImageFlavor           NginxImageFlavor     // image build to use, defaults to the one selected by --enable-dalec-nginx if empty
+	UpgradeChannel        NginxUpgradeChannel  // how Version is chosen if nil, defaults to latest if empty
+	PinnedVersion         string               // version used by the pinned UpgradeChannel
+	ControllerClass       string               // controller class which is equivalent to controller field of IngressClass
+	ResourceName          string               // name given to all resources
+	IcName                string               // IngressClass name
+	ServiceConfig         *ServiceConfig       // service config that specifies details about the LB, defaults if nil
+	ForceSSLRedirect      bool                 // flag to sets all redirects to HTTPS if there is a default TLS certificate (requires DefaultSSLCertificate)
+	HTTPDisabled          bool                 // flag to disable HTTP server
+	DefaultSSLCertificate string               // namespace/name used to create SSL certificate for the default HTTPS server (catch-all)
+	DefaultBackendService string               // namespace/name used to determine default backend service for / and /healthz endpoints
+	CustomHTTPErrors      string               // error codes passed to the configmap to configure nginx to send traffic with the specified headers to its defaultbackend service in case of error
 	MinReplicas           int32
 	MaxReplicas           int32
 	// TargetCPUUtilizationPercentage is the target average CPU utilization of the Ingress Controller
 	TargetCPUUtilizationPercentage int32
+	// TargetMemoryUtilizationPercentage is the target average memory utilization of the Ingress Controller, memory isn't scaled on if 0
+	TargetMemoryUtilizationPercentage int32
+	// ScalingMetric is a traffic metric the Ingress Controller scales on in addition to resource utilization, unused if nil
+	ScalingMetric *ScalingMetricConfig
+	// ScalingBehavior configures the scale up and scale down behavior of the Ingress Controller, Kubernetes defaults are used if nil
+	ScalingBehavior *autov2.HorizontalPodAutoscalerBehavior
 	// Log format is the log format used by the Nginx Ingress Controller
 	// https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/log-format/
 	LogFormat            string
 	EnableSSLPassthrough bool
+	// Config is a map of ingress-nginx ConfigMap keys merged into the ConfigMap. Keys rejected by ValidateNginxConfigOverride are ignored
+	Config map[string]string
+	// PodTemplate holds scheduling and resource overrides for the controller pods, defaults are used if nil
+	PodTemplate *PodTemplateConfig
+	// SecondaryServiceConfig adds a second LB Service in front of the same pods, unused if nil. Its LoadBalancer.Internal must be set
+	SecondaryServiceConfig *ServiceConfig
 }
 
 func (n *NginxIngressConfig) PodLabels() map[string]string {
@@ -81,8 +113,51 @@ type NginxIngressVersion struct {
 	name, tag string
 }
 
+// ScalingMetricConfig defines a custom or external metric the Ingress Controller scales on
+type ScalingMetricConfig struct {
+	Name     string
+	External bool                  // reads the metric from the external metrics API instead of the custom metrics API
+	Selector *metav1.LabelSelector // selects the metric series, scoped to the Ingress Controller pods for custom metrics
+	// TargetAverageValue is the target value of the metric averaged across the Ingress Controller pods
+	TargetAverageValue resource.Quantity
+}
+
+// PodTemplateConfig defines scheduling and resource options for the Ingress Controller pods
+type PodTemplateConfig struct {
+	Labels                    map[string]string
+	Annotations               map[string]string
+	Resources                 *corev1.ResourceRequirements
+	NodeSelector              map[string]string
+	Tolerations               []corev1.Toleration
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+	PriorityClassName         string
+}
+
 // ServiceConfig defines configuration options for required resources for a Service that goes with an Ingress
 type ServiceConfig struct {
 	Annotations              map[string]string
 	LoadBalancerSourceRanges []string
+	// LoadBalancer is translated into Azure LoadBalancer annotations that take precedence over Annotations, unused if nil
+	LoadBalancer *LoadBalancerConfig
+}
+
+// LoadBalancerConfig defines the Azure Load Balancer that exposes the Ingress Controller. Empty fields aren't translated into annotations
+type LoadBalancerConfig struct {
+	Internal               *bool  // whether the load balancer is only reachable from the virtual network, the Azure default is used if nil
+	PublicIPName           string // existing static public IP
+	PublicIPResourceGroup  string // resource group of PublicIPName
+	DNSLabelName           string
+	Subnet                 string // subnet of an internal load balancer's frontend IP
+	PrivateLinkService     *PrivateLinkServiceConfig
+	HealthProbeRequestPath string
+	// ExternalTrafficPolicy is the externalTrafficPolicy of the Service, defaults to Local if empty
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy
+}
+
+// PrivateLinkServiceConfig defines an Azure Private Link Service created for an internal load balancer
+type PrivateLinkServiceConfig struct {
+	Name                      string
+	IPConfigurationSubnet     string
+	VisibilitySubscriptions   []string
+	AutoApprovalSubscriptions []string
 }
@@ -2,21 +2,40 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-logr/logr"
 )
 
+// dataDir is the symlink Kubernetes atomically swaps to update every file in a ConfigMap or Secret volume at once.
+// The files in the volume are symlinks through it, see https://ahmet.im/blog/kubernetes-inotify/
+const dataDir = "..data"
+
 // storedFile represents a file entry in the store
 type storedFile struct {
 	Path    string
 	Content []byte
+	Hash    [sha256.Size]byte
+
+	// dir is the directory the file was found in when it was added with AddDir, it's empty for files added with AddFile
+	dir string
+	// missing is set once the file's deletion has been reported so it's only reported once
+	missing bool
 }
 
-// RotationEvent represents a file rotation/change event
+// subscriber receives the rotation events of its paths
+type subscriber struct {
+	paths map[string]struct{}
+	ch    chan RotationEvent
+}
+
+// RotationEvent represents a file rotation/change event. Path is the directory for files added with AddDir.
 type RotationEvent struct {
 	Path string
 }
@@ -24,19 +43,25 @@ type RotationEvent struct {
 // Store manages local files with filesystem watching capabilities
 type Store interface {
 	AddFile(path string) error
+	AddDir(path string) error
 	GetContent(path string) ([]byte, bool)
+	Subscribe(paths ...string) <-chan RotationEvent
 	RotationEvents() <-chan RotationEvent
 	Errors() <-chan error
 }
 
 type store struct {
-	mu         *sync.RWMutex
-	files      map[string]*storedFile
-	watcher    *fsnotify.Watcher
-	ctx        context.Context
-	logger     logr.Logger
-	rotationCh chan RotationEvent
-	errorCh    chan error
+	mu          *sync.RWMutex
+	files       map[string]*storedFile
+	dirs        map[string]struct{}
+	watchedDirs map[string]struct{}
+	subscribers []*subscriber
+	stopped     bool
+	watcher     *fsnotify.Watcher
+	ctx         context.Context
+	logger      logr.Logger
+	rotationCh  chan RotationEvent // nil until RotationEvents is called so events aren't queued without a reader
+	errorCh     chan error
 }
 
 // New creates a new file store instance with filesystem watching
@@ -47,13 +72,14 @@ func New(logger logr.Logger, ctx context.Context) (Store, error) {
 	}
 
 	s := &store{
-		mu:         &sync.RWMutex{},
-		files:      make(map[string]*storedFile),
-		watcher:    watcher,
-		ctx:        ctx,
-		logger:     logger,
-		rotationCh: make(chan RotationEvent, 100), // Buffered to prevent blocking
-		errorCh:    make(chan error, 100),         // Buffered to prevent blocking
+		mu:          &sync.RWMutex{},
+		files:       make(map[string]*storedFile),
+		dirs:        make(map[string]struct{}),
+		watchedDirs: make(map[string]struct{}),
+		watcher:     watcher,
+		ctx:         ctx,
+		logger:      logger,
+		errorCh:     make(chan error, 100), // Buffered to prevent blocking
 	}
 
 	// Start watching for filesystem events
@@ -62,7 +88,8 @@ func New(logger logr.Logger, ctx context.Context) (Store, error) {
 	return s, nil
 }
 
-// AddFile adds a local file to the store for tracking
+// AddFile adds a local file to the store for tracking. Both the file and its directory are watched so writes to the file,
+// atomic renames over it and Kubernetes ..data symlink swaps are all seen.
 func (s *store) AddFile(path string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -91,10 +118,14 @@ func (s *store) AddFile(path string) error {
 	if err := s.watcher.Add(path); err != nil {
 		return fmt.Errorf("failed to add file to watcher %s: %w", path, err)
 	}
+	if err := s.watchDir(filepath.Dir(path)); err != nil {
+		return err
+	}
 
 	s.files[path] = &storedFile{
 		Path:    path,
 		Content: content,
+		Hash:    sha256.Sum256(content),
 	}
 
 	s.logger.Info("Added file to store", "path", path, "size", len(content))
@@ -104,6 +135,112 @@ func (s *store) AddFile(path string) error {
 	return nil
 }
 
+// AddDir adds every file in a local directory to the store for tracking, like the keys of a mounted ConfigMap or Secret.
+// Subdirectories and hidden files are skipped. Files added to or removed from the directory later are tracked too and a single
+// rotation event for the directory is sent per update.
+func (s *store) AddDir(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("directory does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat directory %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", path)
+	}
+
+	if _, exists := s.dirs[path]; exists {
+		return fmt.Errorf("directory already exists in store: %s", path)
+	}
+
+	contents, err := readDir(path)
+	if err != nil {
+		return err
+	}
+
+	if err := s.watchDir(path); err != nil {
+		return err
+	}
+
+	s.dirs[path] = struct{}{}
+	for name, content := range contents {
+		file := filepath.Join(path, name)
+		if _, exists := s.files[file]; exists {
+			continue // already added with AddFile
+		}
+		s.files[file] = &storedFile{
+			Path:    file,
+			Content: content,
+			Hash:    sha256.Sum256(content),
+			dir:     path,
+		}
+	}
+
+	s.logger.Info("Added directory to store", "path", path, "files", len(contents))
+
+	return nil
+}
+
+// watchDir adds a directory to the watcher once (must be called with lock held)
+func (s *store) watchDir(path string) error {
+	if _, exists := s.watchedDirs[path]; exists {
+		return nil
+	}
+
+	if err := s.watcher.Add(path); err != nil {
+		return fmt.Errorf("failed to add directory to watcher %s: %w", path, err)
+	}
+
+	s.watchedDirs[path] = struct{}{}
+	return nil
+}
+
+// readDir returns the content of every file in a directory keyed by name. Files in a Kubernetes volume are read through ..data
+// so the content is consistent with the last symlink swap even before the user-visible symlinks are updated.
+func readDir(path string) (map[string][]byte, error) {
+	root := path
+	if info, err := os.Stat(filepath.Join(path, dataDir)); err == nil && info.IsDir() {
+		root = filepath.Join(path, dataDir)
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", path, err)
+	}
+
+	contents := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		file := filepath.Join(root, entry.Name())
+		info, err := os.Stat(file) // follows symlinks
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // removed while listing
+			}
+			return nil, fmt.Errorf("failed to stat file %s: %w", file, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+		}
+		contents[entry.Name()] = content
+	}
+
+	return contents, nil
+}
+
 // GetContent returns just the content bytes for the given path
 func (s *store) GetContent(path string) ([]byte, bool) {
 	s.mu.RLock()
@@ -118,8 +255,43 @@ func (s *store) GetContent(path string) ([]byte, bool) {
 	return append([]byte(nil), file.Content...), true
 }
 
-// RotationEvents returns a read-only channel for rotation events
+// Subscribe returns a channel that receives the rotation events of paths. Paths are files added with AddFile or directories added
+// with AddDir. Unlike RotationEvents, every subscriber gets its own channel so subscribers don't take each other's events. The
+// channel is closed once the store stops watching.
+func (s *store) Subscribe(paths ...string) <-chan RotationEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subscriber{
+		paths: make(map[string]struct{}, len(paths)),
+		ch:    make(chan RotationEvent, 100), // Buffered to prevent blocking
+	}
+	for _, path := range paths {
+		sub.paths[filepath.Clean(path)] = struct{}{}
+	}
+
+	if s.stopped {
+		close(sub.ch)
+		return sub.ch
+	}
+
+	s.subscribers = append(s.subscribers, sub)
+	return sub.ch
+}
+
+// RotationEvents returns a read-only channel for rotation events of every path. The channel is shared so it should only have
+// one reader, use Subscribe otherwise. Events are only sent once RotationEvents has been called.
 func (s *store) RotationEvents() <-chan RotationEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rotationCh == nil {
+		s.rotationCh = make(chan RotationEvent, 100) // Buffered to prevent blocking
+		if s.stopped {
+			close(s.rotationCh)
+		}
+	}
+
 	return s.rotationCh
 }
 
@@ -136,7 +308,17 @@ func (s *store) startWatching() {
 		// Ensure cleanup when goroutine exits
 		defer func() {
 			s.logger.Info("Cleaning up filesystem watcher resources")
-			close(s.rotationCh)
+			s.mu.Lock()
+			s.stopped = true
+			for _, sub := range s.subscribers {
+				close(sub.ch)
+			}
+			s.subscribers = nil
+			if s.rotationCh != nil {
+				close(s.rotationCh)
+			}
+			s.mu.Unlock()
+
 			close(s.errorCh)
 			s.watcher.Close()
 		}()
@@ -158,13 +340,7 @@ func (s *store) startWatching() {
 					return
 				}
 				s.logger.Error(err, "Filesystem watcher error")
-
-				// Send error to error channel
-				select {
-				case s.errorCh <- err:
-				default:
-					s.logger.Info("Error channel full, dropping error")
-				}
+				s.sendError(err)
 			}
 		}
 	}()
@@ -176,10 +352,24 @@ func (s *store) handleFileEvent(event fsnotify.Event) {
 	defer s.mu.Unlock()
 
 	path := event.Name
+	dir, name := filepath.Dir(path), filepath.Base(path)
+
+	// A ..data swap updates every file in the directory at once
+	if name == dataDir && event.Has(fsnotify.Create) {
+		s.logger.Info("Data symlink swapped", "dir", dir)
+		s.refreshDir(dir)
+		return
+	}
+
+	// Any other change to a tracked directory may add, remove or update a file in it. Hidden files are the atomic writer's
+	// staging files so they're skipped.
+	if _, exists := s.dirs[dir]; exists && !strings.HasPrefix(name, ".") && !event.Has(fsnotify.Chmod) {
+		s.refreshDir(dir)
+	}
 
 	// Check if this file is being tracked
 	file, exists := s.files[path]
-	if !exists {
+	if !exists || file.dir != "" {
 		return
 	}
 
@@ -192,103 +382,177 @@ func (s *store) handleFileEvent(event fsnotify.Event) {
 		// For Kubernetes secret mounts, REMOVE events are part of the rotation process
 		// Check if the file still exists (it might be a symlink update)
 		if _, err := os.Stat(path); err == nil {
-			// File still exists, this is likely a K8s secret rotation
-			// Re-add the file to the watcher and refresh content
+			// File still exists, this is likely a K8s secret rotation or an atomic rename over the file
 			s.logger.Info("File removed but still exists, likely K8s secret rotation", "path", path)
-
-			// Re-add to watcher (the old watch was removed)
-			if err := s.watcher.Add(path); err != nil {
-				s.logger.Error(err, "Failed to re-add file to watcher after rotation", "path", path)
-
-				// Send error to error channel
-				select {
-				case s.errorCh <- fmt.Errorf("failed to re-add file to watcher after rotation %s: %w", path, err):
-				default:
-					s.logger.Info("Error channel full, dropping error")
-				}
-				return
-			}
-
-			// Refresh file content
-			if err := s.refreshFileContent(path, file); err != nil {
-				s.logger.Error(err, "Failed to refresh file content after rotation", "path", path)
-
-				// Send error to error channel
-				select {
-				case s.errorCh <- err:
-				default:
-					s.logger.Info("Error channel full, dropping error")
-				}
-			} else {
-				// Send rotation event for successful rotation
-				select {
-				case s.rotationCh <- RotationEvent{Path: path}:
-				default:
-					s.logger.Info("Rotation channel full, dropping event", "path", path)
-				}
-			}
-		} else {
+			s.rewatchFile(file)
+		} else if !file.missing {
 			// File actually doesn't exist anymore - this is a real deletion
 			// Generate error but keep the file in tracking (old behavior)
+			file.missing = true
 			err := fmt.Errorf("file was deleted or renamed: %s", path)
 			s.logger.Error(err, "File no longer exists")
-
-			// Send error to error channel
-			select {
-			case s.errorCh <- err:
-			default:
-				s.logger.Info("Error channel full, dropping error")
-			}
+			s.sendError(err)
 		}
 		return
 	}
 
 	if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
-		// File was modified or created, reload content
-		if err := s.refreshFileContent(path, file); err != nil {
-			s.logger.Error(err, "Failed to refresh file content", "path", path)
+		// File was modified or created, reload content. A file created again after it was deleted needs a new watch.
+		if file.missing {
+			s.rewatchFile(file)
+			return
+		}
+		s.refreshFile(file)
+	}
+}
 
-			// Send error to error channel
-			select {
-			case s.errorCh <- err:
-			default:
-				s.logger.Info("Error channel full, dropping error")
+// rewatchFile watches a file again after the watched inode was replaced then refreshes its content (must be called with lock held).
+// The watch follows symlinks so it's dropped whenever a symlink target is replaced.
+func (s *store) rewatchFile(file *storedFile) {
+	// the old watch may still exist if only the symlink changed
+	_ = s.watcher.Remove(file.Path)
+	if err := s.watcher.Add(file.Path); err != nil {
+		s.logger.Error(err, "Failed to re-add file to watcher after rotation", "path", file.Path)
+		s.sendError(fmt.Errorf("failed to re-add file to watcher after rotation %s: %w", file.Path, err))
+		return
+	}
+
+	file.missing = false
+	s.refreshFile(file)
+}
+
+// refreshFile reloads a file added with AddFile and sends a rotation event if its content changed (must be called with lock held)
+func (s *store) refreshFile(file *storedFile) {
+	changed, err := s.refreshFileContent(file.Path, file)
+	if err != nil {
+		s.logger.Error(err, "Failed to refresh file content", "path", file.Path)
+		s.sendError(err)
+		return
+	}
+
+	if changed {
+		s.sendRotation(file.Path)
+	}
+}
+
+// refreshDir reloads the files in a directory (must be called with lock held). Files added with AddFile each get a rotation event
+// when they changed, a directory added with AddDir gets a single rotation event when any file in it was added, removed or changed.
+func (s *store) refreshDir(dir string) {
+	for _, file := range s.files {
+		if file.dir == "" && filepath.Dir(file.Path) == dir {
+			s.rewatchFile(file)
+		}
+	}
+
+	if _, exists := s.dirs[dir]; !exists {
+		return
+	}
+
+	contents, err := readDir(dir)
+	if err != nil {
+		s.logger.Error(err, "Failed to refresh directory content", "path", dir)
+		s.sendError(err)
+		return
+	}
+
+	changed := false
+	for path, file := range s.files {
+		if file.dir != dir {
+			continue
+		}
+		if _, exists := contents[filepath.Base(path)]; !exists {
+			s.logger.Info("File removed from directory", "path", path)
+			delete(s.files, path)
+			changed = true
+		}
+	}
+
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		hash := sha256.Sum256(content)
+		if file, exists := s.files[path]; exists {
+			if file.dir != dir || file.Hash == hash {
+				continue // unchanged or added with AddFile
 			}
+			file.Content = content
+			file.Hash = hash
 		} else {
-			// Send rotation event for file update
-			select {
-			case s.rotationCh <- RotationEvent{Path: path}:
-			default:
-				s.logger.Info("Rotation channel full, dropping event", "path", path)
-			}
+			s.files[path] = &storedFile{Path: path, Content: content, Hash: hash, dir: dir}
+		}
+		changed = true
+	}
+
+	if changed {
+		s.logger.Info("Refreshed directory content", "path", dir, "files", len(contents))
+		s.sendRotation(dir)
+	}
+}
+
+// sendRotation sends a rotation event for path to RotationEvents, if it has been called, and every subscriber of path (must be called with lock held)
+func (s *store) sendRotation(path string) {
+	event := RotationEvent{Path: path}
+
+	if s.rotationCh != nil {
+		select {
+		case s.rotationCh <- event:
+		default:
+			s.logger.Info("Rotation channel full, dropping event", "path", path)
+		}
+	}
+
+	for _, sub := range s.subscribers {
+		if _, exists := sub.paths[path]; !exists {
+			continue
 		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			s.logger.Info("Subscriber channel full, dropping event", "path", path)
+		}
+	}
+}
+
+// sendError sends an error to the error channel without blocking
+func (s *store) sendError(err error) {
+	select {
+	case s.errorCh <- err:
+	default:
+		s.logger.Info("Error channel full, dropping error")
 	}
 }
 
-// refreshFileContent reloads the content of a specific file (must be called with lock held)
-func (s *store) refreshFileContent(path string, file *storedFile) error {
+// refreshFileContent reloads the content of a specific file and returns whether it changed (must be called with lock held)
+func (s *store) refreshFileContent(path string, file *storedFile) (bool, error) {
 	// Check if file still exists
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("file no longer exists: %s", path)
+		return false, fmt.Errorf("file no longer exists: %s", path)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to stat file %s: %w", path, err)
+		return false, fmt.Errorf("failed to stat file %s: %w", path, err)
 	}
 
 	// Read updated content
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read updated file %s: %w", path, err)
+		return false, fmt.Errorf("failed to read updated file %s: %w", path, err)
+	}
+
+	// Writes that don't change the content, like the same ConfigMap being applied again, aren't rotations
+	hash := sha256.Sum256(content)
+	if hash == file.Hash {
+		return false, nil
 	}
 
 	oldSize := len(file.Content)
 	file.Content = content
+	file.Hash = hash
 
 	s.logger.Info("Refreshed file content",
 		"path", path,
 		"oldSize", oldSize,
 		"newSize", len(content))
 
-	return nil
+	return true, nil
 }
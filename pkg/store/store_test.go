@@ -424,6 +424,8 @@ func TestStore_KubernetesSecretRotation(t *testing.T) {
 	require.True(t, exists)
 	require.Equal(t, initialContent, content)
 
+	rotationEvents := store.RotationEvents()
+
 	// Add a small delay to ensure the file system watcher is ready
 	time.Sleep(100 * time.Millisecond)
 
@@ -440,7 +442,7 @@ func TestStore_KubernetesSecretRotation(t *testing.T) {
 	timeout := time.After(5 * time.Second)
 	for !gotRotationEvent {
 		select {
-		case rotationEvent = <-store.RotationEvents():
+		case rotationEvent = <-rotationEvents:
 			if rotationEvent.Path == secretFile {
 				gotRotationEvent = true
 			}
@@ -532,3 +534,219 @@ func TestStore_RealFileDeletion(t *testing.T) {
 	_, exists := store.GetContent(tmpFile.Name())
 	assert.True(t, exists)
 }
+
+// writeVolume updates dir the way the kubelet's atomic writer updates ConfigMap and Secret volumes: the files are written to a
+// new timestamped directory, the ..data symlink is swapped to it and the user-visible symlinks point through ..data
+func writeVolume(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	oldTs, _ := os.Readlink(filepath.Join(dir, "..data"))
+	ts, err := os.MkdirTemp(dir, "..ts_")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(ts, name), []byte(content), 0o644))
+	}
+
+	require.NoError(t, os.Symlink(filepath.Base(ts), filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if _, exists := files[entry.Name()]; !exists && entry.Name()[0] != '.' {
+			require.NoError(t, os.Remove(filepath.Join(dir, entry.Name())))
+		}
+	}
+	for name := range files {
+		if _, err := os.Lstat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			require.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+		}
+	}
+
+	if oldTs != "" {
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, oldTs)))
+	}
+}
+
+// requireEvents requires exactly want rotation events for path on ch
+func requireEvents(t *testing.T, ch <-chan RotationEvent, path string, want int) {
+	t.Helper()
+
+	for i := 0; i < want; i++ {
+		select {
+		case event := <-ch:
+			require.Equal(t, path, event.Path)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %d rotation events, got %d", want, i)
+		}
+	}
+
+	select {
+	case event := <-ch:
+		t.Fatalf("unexpected rotation event %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestStore_AddDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("hidden"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+
+	store, err := New(logr.Discard(), t.Context())
+	require.NoError(t, err)
+	require.NoError(t, store.AddDir(dir))
+
+	content, exists := store.GetContent(filepath.Join(dir, "a"))
+	require.True(t, exists)
+	assert.Equal(t, "a", string(content))
+	_, exists = store.GetContent(filepath.Join(dir, ".hidden"))
+	assert.False(t, exists)
+	_, exists = store.GetContent(filepath.Join(dir, "sub"))
+	assert.False(t, exists)
+
+	assert.ErrorContains(t, store.AddDir(dir), "already exists")
+	assert.ErrorContains(t, store.AddDir(filepath.Join(dir, "missing")), "directory does not exist")
+	assert.ErrorContains(t, store.AddDir(filepath.Join(dir, "a")), "not a directory")
+
+	events := store.Subscribe(dir)
+
+	t.Run("file added", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("b"), 0o644))
+		requireEvents(t, events, dir, 1)
+
+		content, exists := store.GetContent(filepath.Join(dir, "b"))
+		require.True(t, exists)
+		assert.Equal(t, "b", string(content))
+	})
+
+	t.Run("file removed", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "b")))
+		requireEvents(t, events, dir, 1)
+
+		_, exists := store.GetContent(filepath.Join(dir, "b"))
+		assert.False(t, exists)
+	})
+}
+
+func TestStore_KubernetesVolume(t *testing.T) {
+	dir := t.TempDir()
+	writeVolume(t, dir, map[string]string{"ca.crt": "ca-1", "config.yaml": "config-1"})
+
+	store, err := New(logr.Discard(), t.Context())
+	require.NoError(t, err)
+	require.NoError(t, store.AddDir(dir))
+
+	events := store.Subscribe(dir)
+	content, exists := store.GetContent(filepath.Join(dir, "ca.crt"))
+	require.True(t, exists)
+	assert.Equal(t, "ca-1", string(content))
+
+	t.Run("update sends a single event", func(t *testing.T) {
+		writeVolume(t, dir, map[string]string{"ca.crt": "ca-2", "config.yaml": "config-2"})
+		requireEvents(t, events, dir, 1)
+
+		for name, want := range map[string]string{"ca.crt": "ca-2", "config.yaml": "config-2"} {
+			content, exists := store.GetContent(filepath.Join(dir, name))
+			require.True(t, exists)
+			assert.Equal(t, want, string(content))
+		}
+	})
+
+	t.Run("unchanged content doesn't send an event", func(t *testing.T) {
+		writeVolume(t, dir, map[string]string{"ca.crt": "ca-2", "config.yaml": "config-2"})
+		requireEvents(t, events, dir, 0)
+	})
+
+	t.Run("added and removed keys send a single event", func(t *testing.T) {
+		writeVolume(t, dir, map[string]string{"ca.crt": "ca-2", "extra.crt": "extra"})
+		requireEvents(t, events, dir, 1)
+
+		_, exists := store.GetContent(filepath.Join(dir, "config.yaml"))
+		assert.False(t, exists)
+		content, exists := store.GetContent(filepath.Join(dir, "extra.crt"))
+		require.True(t, exists)
+		assert.Equal(t, "extra", string(content))
+	})
+}
+
+func TestStore_AddFileKubernetesVolume(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeVolume(t, dir, map[string]string{"config.yaml": "config-1", "other": "other-1"})
+
+	store, err := New(logr.Discard(), t.Context())
+	require.NoError(t, err)
+	require.NoError(t, store.AddFile(file))
+	events := store.Subscribe(file)
+
+	for i := 2; i <= 3; i++ {
+		want := fmt.Sprintf("config-%d", i)
+		writeVolume(t, dir, map[string]string{"config.yaml": want, "other": "other-1"})
+		requireEvents(t, events, file, 1)
+
+		content, exists := store.GetContent(file)
+		require.True(t, exists)
+		assert.Equal(t, want, string(content))
+	}
+
+	// only other keys changed
+	writeVolume(t, dir, map[string]string{"config.yaml": "config-3", "other": "other-2"})
+	requireEvents(t, events, file, 0)
+
+	select {
+	case err := <-store.Errors():
+		t.Fatalf("unexpected error %s", err)
+	default:
+	}
+}
+
+func TestStore_UnchangedWriteIsSuppressed(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	require.NoError(t, os.WriteFile(testFile, []byte("content"), 0o644))
+
+	store, err := New(logr.Discard(), t.Context())
+	require.NoError(t, err)
+	require.NoError(t, store.AddFile(testFile))
+	events := store.Subscribe(testFile)
+
+	require.NoError(t, atomicWriteFile(filepath.Dir(testFile), "test.txt", []byte("content")))
+	requireEvents(t, events, testFile, 0)
+
+	require.NoError(t, atomicWriteFile(filepath.Dir(testFile), "test.txt", []byte("updated")))
+	requireEvents(t, events, testFile, 1)
+}
+
+func TestStore_Subscribe(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.txt")
+	second := filepath.Join(tempDir, "second.txt")
+	require.NoError(t, os.WriteFile(first, []byte("first"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("second"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	store, err := New(logr.Discard(), ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.AddFile(first))
+	require.NoError(t, store.AddFile(second))
+
+	firstEvents := store.Subscribe(first)
+	bothEvents := store.Subscribe(first, second)
+
+	require.NoError(t, atomicWriteFile(tempDir, "second.txt", []byte("updated")))
+	requireEvents(t, bothEvents, second, 1)
+	requireEvents(t, firstEvents, first, 0)
+
+	require.NoError(t, atomicWriteFile(tempDir, "first.txt", []byte("updated")))
+	requireEvents(t, firstEvents, first, 1)
+	requireEvents(t, bothEvents, first, 1)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, open := <-firstEvents
+		return !open
+	}, time.Second, 10*time.Millisecond)
+	_, open := <-store.Subscribe(first)
+	assert.False(t, open, "subscribing to a stopped store returns a closed channel")
+}
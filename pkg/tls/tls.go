@@ -1,10 +1,15 @@
 package tls
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
 	"time"
 )
 
@@ -19,19 +24,9 @@ type CertificateInfo struct {
 
 // ParseTLSCertificate parses and validates a TLS certificate from PEM-encoded cert and key data
 func ParseTLSCertificate(certPEM, keyPEM []byte) (*CertificateInfo, error) {
-	// Parse the certificate
-	certBlock, _ := pem.Decode(certPEM)
-	if certBlock == nil {
-		return nil, fmt.Errorf("failed to decode PEM certificate block")
-	}
-
-	if certBlock.Type != "CERTIFICATE" {
-		return nil, fmt.Errorf("invalid certificate block type: %s", certBlock.Type)
-	}
-
-	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	info, err := ParseCertificate(certPEM)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		return nil, err
 	}
 
 	// Parse the private key
@@ -46,6 +41,26 @@ func ParseTLSCertificate(certPEM, keyPEM []byte) (*CertificateInfo, error) {
 		return nil, fmt.Errorf("certificate and key do not match: %w", err)
 	}
 
+	return info, nil
+}
+
+// ParseCertificate parses the first certificate of PEM-encoded cert data, which is the leaf certificate of a chain
+func ParseCertificate(certPEM []byte) (*CertificateInfo, error) {
+	// Parse the certificate
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("failed to decode PEM certificate block")
+	}
+
+	if certBlock.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("invalid certificate block type: %s", certBlock.Type)
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
 	// Create certificate info
 	info := &CertificateInfo{
 		Subject:   cert.Subject.String(),
@@ -57,3 +72,131 @@ func ParseTLSCertificate(certPEM, keyPEM []byte) (*CertificateInfo, error) {
 
 	return info, nil
 }
+
+// GenerateServingCertificate creates a self-signed CA and a serving certificate for the DNS names signed by it. Both are valid for
+// the given duration. The CA certificate, serving certificate, and serving key are returned PEM-encoded.
+func GenerateServingCertificate(dnsNames []string, validity time.Duration) (caPEM, certPEM, keyPEM []byte, err error) {
+	if len(dnsNames) == 0 {
+		return nil, nil, nil, fmt.Errorf("at least one DNS name is required")
+	}
+
+	notBefore := time.Now().Add(-5 * time.Minute) // tolerate clock skew
+	notAfter := notBefore.Add(validity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	caSerial, err := serialNumber()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{CommonName: dnsNames[0] + "-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:              dnsNames,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return caPEM, certPEM, keyPEM, nil
+}
+
+// UnexpiredCertificates returns the PEM-encoded certificates in the bundle that haven't expired. Blocks that can't be parsed are dropped.
+func UnexpiredCertificates(bundlePEM []byte, now time.Time) []byte {
+	var ret []byte
+	for rest := bundlePEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return ret
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || now.After(cert.NotAfter) {
+			continue
+		}
+
+		ret = append(ret, pem.EncodeToMemory(block)...)
+	}
+}
+
+// ParseCABundle parses the PEM-encoded certificates in the bundle. It fails if the bundle has no certificates or a block can't be parsed.
+func ParseCABundle(bundlePEM []byte) ([]*x509.Certificate, error) {
+	var ret []*x509.Certificate
+	for rest := bundlePEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("invalid block type %s in CA bundle", block.Type)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in CA bundle: %w", err)
+		}
+		ret = append(ret, cert)
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no certificates found in CA bundle")
+	}
+
+	return ret, nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	return serial, nil
+}
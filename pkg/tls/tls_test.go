@@ -240,3 +240,119 @@ func TestParseTLSCertificate_SubjectIssuerParsing(t *testing.T) {
 		t.Error("Expected subject and issuer to be the same for self-signed certificate")
 	}
 }
+
+func TestParseCertificate(t *testing.T) {
+	notAfter := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	certPEM, keyPEM := generateTestCertificate(t, time.Now().Add(-24*time.Hour), notAfter, []string{"example.com"}, getDefaultSubject(), getDefaultIssuer())
+	otherPEM, _ := generateTestCertificate(t, time.Now().Add(-24*time.Hour), notAfter.Add(24*time.Hour), []string{"ca.example.com"}, getDefaultSubject(), getDefaultIssuer())
+
+	// the leaf certificate of a chain is parsed, no key is needed
+	info, err := ParseCertificate(append(certPEM, otherPEM...))
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+	if !info.NotAfter.Equal(notAfter) {
+		t.Errorf("Expected NotAfter %v, got %v", notAfter, info.NotAfter)
+	}
+	if len(info.DNSNames) != 1 || info.DNSNames[0] != "example.com" {
+		t.Errorf("Expected DNS names [example.com], got %v", info.DNSNames)
+	}
+
+	if _, err := ParseCertificate(keyPEM); err == nil || !strings.Contains(err.Error(), "invalid certificate block type") {
+		t.Errorf("Expected invalid certificate block type error, got %v", err)
+	}
+}
+
+func TestGenerateServingCertificate(t *testing.T) {
+	dnsNames := []string{"nginx-admission.app-routing-system.svc", "nginx-admission.app-routing-system.svc.cluster.local"}
+	caPEM, certPEM, keyPEM, err := GenerateServingCertificate(dnsNames, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateServingCertificate failed: %v", err)
+	}
+
+	info, err := ParseTLSCertificate(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("ParseTLSCertificate failed: %v", err)
+	}
+	if len(info.DNSNames) != len(dnsNames) || info.DNSNames[0] != dnsNames[0] || info.DNSNames[1] != dnsNames[1] {
+		t.Errorf("Expected DNS names %v, got %v", dnsNames, info.DNSNames)
+	}
+	if remaining := time.Until(info.NotAfter); remaining <= 50*time.Minute || remaining > time.Hour {
+		t.Errorf("Expected certificate to expire in about an hour, got %s", remaining)
+	}
+
+	// the serving certificate must verify against the CA for the API server to call the webhook
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		t.Fatal("Failed to add CA to pool")
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: dnsNames[1], Roots: pool}); err != nil {
+		t.Errorf("Certificate doesn't verify against CA: %v", err)
+	}
+
+	// each call creates a new CA
+	otherCA, _, _, err := GenerateServingCertificate(dnsNames, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateServingCertificate failed: %v", err)
+	}
+	if string(otherCA) == string(caPEM) {
+		t.Error("Expected a new CA for each call")
+	}
+
+	if _, _, _, err := GenerateServingCertificate(nil, time.Hour); err == nil {
+		t.Error("Expected error without DNS names")
+	}
+}
+
+func TestUnexpiredCertificates(t *testing.T) {
+	now := time.Now()
+	valid, _ := generateTestCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), []string{"valid.com"}, getDefaultSubject(), getDefaultIssuer())
+	expired, _ := generateTestCertificate(t, now.Add(-2*time.Hour), now.Add(-time.Hour), []string{"expired.com"}, getDefaultSubject(), getDefaultIssuer())
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("not a certificate")})
+
+	bundle := append(append(append([]byte{}, expired...), key...), valid...)
+	if got := UnexpiredCertificates(bundle, now); string(got) != string(valid) {
+		t.Errorf("Expected only the valid certificate, got %s", got)
+	}
+
+	if got := UnexpiredCertificates(expired, now); len(got) != 0 {
+		t.Errorf("Expected no certificates, got %s", got)
+	}
+
+	if got := UnexpiredCertificates(nil, now); len(got) != 0 {
+		t.Errorf("Expected no certificates, got %s", got)
+	}
+}
+
+func TestParseCABundle(t *testing.T) {
+	now := time.Now()
+	one, _ := generateTestCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), []string{"one.com"}, getDefaultSubject(), getDefaultIssuer())
+	two, _ := generateTestCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), []string{"two.com"}, getDefaultSubject(), getDefaultIssuer())
+
+	certs, err := ParseCABundle(append(append([]byte{}, one...), two...))
+	if err != nil {
+		t.Fatalf("ParseCABundle failed: %v", err)
+	}
+	if len(certs) != 2 || certs[0].DNSNames[0] != "one.com" || certs[1].DNSNames[0] != "two.com" {
+		t.Errorf("Expected both certificates in order, got %v", certs)
+	}
+
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("not a certificate")})
+	if _, err := ParseCABundle(append(append([]byte{}, one...), key...)); err == nil {
+		t.Error("Expected error for a bundle with a private key")
+	}
+
+	garbage := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})
+	if _, err := ParseCABundle(garbage); err == nil {
+		t.Error("Expected error for an unparseable certificate")
+	}
+
+	if _, err := ParseCABundle([]byte("not pem")); err == nil {
+		t.Error("Expected error for a bundle without certificates")
+	}
+}
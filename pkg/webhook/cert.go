@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const caKey = "ca.crt"
+
+var certManagerName = controllername.New("webhook", "cert", "manager")
+
+// these are vars so tests can change them
+var (
+	// certValidity is how long the webhook serving certificates are valid
+	certValidity = 90 * 24 * time.Hour
+	// certRenewBefore is how long before expiry the webhook serving certificate is replaced
+	certRenewBefore = 30 * 24 * time.Hour
+	// certCheckInterval is how often every replica syncs the serving certificate
+	certCheckInterval = 10 * time.Minute
+)
+
+// certManager keeps the webhook serving certificate in a Secret shared by every replica, writes it to the directory the webhook
+// server reads it from, and points the ValidatingWebhookConfiguration and CRD conversion at the webhook Service with the certificate's
+// CA bundle.
+// It runs on every replica because every replica serves the webhooks.
+type certManager struct {
+	client   client.Client
+	conf     *config.Config
+	webhooks []*webhook
+	logger   logr.Logger
+	now      func() time.Time
+}
+
+func newCertManager(cl client.Client, conf *config.Config, webhooks []*webhook, lgr logr.Logger) *certManager {
+	metrics.InitControllerMetrics(certManagerName)
+	return &certManager{
+		client:   cl,
+		conf:     conf,
+		webhooks: webhooks,
+		logger:   certManagerName.AddToLogger(lgr),
+		now:      time.Now,
+	}
+}
+
+func (c *certManager) NeedLeaderElection() bool {
+	return false
+}
+
+func (c *certManager) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(util.Jitter(certCheckInterval, 0.3)):
+		}
+
+		if err := c.tick(ctx); err != nil {
+			c.logger.Error(err, "error syncing webhook serving certificate")
+			continue
+		}
+	}
+}
+
+func (c *certManager) tick(ctx context.Context) (err error) {
+	defer func() {
+		metrics.HandleControllerReconcileMetrics(certManagerName, ctrl.Result{}, err)
+	}()
+
+	secret, err := c.ensureSecret(ctx)
+	if err != nil {
+		return fmt.Errorf("ensuring certificate secret: %w", err)
+	}
+
+	if err := c.writeCert(secret); err != nil {
+		return fmt.Errorf("writing certificate: %w", err)
+	}
+
+	vwc := validatingWebhookConfiguration(c.conf, c.webhooks, secret.Data[caKey])
+	if err := util.Upsert(ctx, c.client, vwc); err != nil {
+		return fmt.Errorf("upserting ValidatingWebhookConfiguration: %w", err)
+	}
+
+	if err := setConversion(ctx, c.client, c.conf, secret.Data[caKey]); err != nil {
+		return fmt.Errorf("setting CRD conversion webhook: %w", err)
+	}
+
+	return nil
+}
+
+// dnsNames returns the names the API server uses to reach the webhook Service
+func (c *certManager) dnsNames() []string {
+	host := c.conf.WebhookServiceName + "." + c.conf.WebhookServiceNamespace + ".svc"
+	return []string{host, host + ".cluster.local"}
+}
+
+// ensureSecret returns the Secret holding the current serving certificate, issuing a new one when it's missing, invalid, or due for
+// renewal. Replicas racing to issue one are resolved by the API server, the losers use the winner's certificate.
+func (c *certManager) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	now := c.now()
+	key := client.ObjectKey{Namespace: c.conf.WebhookServiceNamespace, Name: c.conf.WebhookServiceName + "-cert"}
+
+	current := &corev1.Secret{}
+	if err := c.client.Get(ctx, key, current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("getting secret: %w", err)
+		}
+		current = nil
+	}
+
+	if current != nil {
+		info, err := tls.ParseTLSCertificate(current.Data[corev1.TLSCertKey], current.Data[corev1.TLSPrivateKeyKey])
+		switch {
+		case err != nil:
+			c.logger.Info("webhook certificate is invalid, issuing a new one", "reason", err.Error())
+		case !slices.Equal(info.DNSNames, c.dnsNames()):
+			c.logger.Info("webhook certificate is for different names, issuing a new one", "names", info.DNSNames)
+		case len(current.Data[caKey]) == 0:
+			c.logger.Info("webhook certificate has no CA, issuing a new one")
+		case !now.Before(info.NotAfter.Add(-certRenewBefore)):
+			c.logger.Info("webhook certificate is due for renewal, issuing a new one", "notAfter", info.NotAfter.String())
+		default:
+			return current, nil
+		}
+	}
+
+	ca, cert, certKey, err := tls.GenerateServingCertificate(c.dnsNames(), certValidity)
+	if err != nil {
+		return nil, fmt.Errorf("generating certificate: %w", err)
+	}
+
+	if current == nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    manifests.GetTopLevelLabels(),
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{caKey: ca, corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: certKey},
+		}
+		if err := c.client.Create(ctx, secret); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				c.logger.Info("another replica issued the webhook certificate first")
+				return c.getSecret(ctx, key)
+			}
+			return nil, fmt.Errorf("creating secret: %w", err)
+		}
+
+		c.logger.Info("issued webhook certificate")
+		return secret, nil
+	}
+
+	// replicas serve the previous certificate until they sync so its CA stays trusted until it expires
+	caBundle := append(ca, tls.UnexpiredCertificates(current.Data[caKey], now)...)
+	current.Data = map[string][]byte{caKey: caBundle, corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: certKey}
+	if err := c.client.Update(ctx, current); err != nil {
+		if apierrors.IsConflict(err) {
+			c.logger.Info("another replica rotated the webhook certificate first")
+			return c.getSecret(ctx, key)
+		}
+		return nil, fmt.Errorf("updating secret: %w", err)
+	}
+
+	c.logger.Info("rotated webhook certificate")
+	return current, nil
+}
+
+// getSecret returns the Secret another replica won the race to write
+func (c *certManager) getSecret(ctx context.Context, key client.ObjectKey) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("getting secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// writeCert writes the serving certificate where the webhook server reads it from. The webhook server reloads it when it changes.
+func (c *certManager) writeCert(secret *corev1.Secret) error {
+	if err := os.MkdirAll(c.conf.WebhookCertDir, 0o700); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	// the key is written first so the certificate never refers to a key that isn't there yet
+	for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+		path := filepath.Join(c.conf.WebhookCertDir, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, secret.Data[name]) {
+			continue
+		}
+
+		// rename is atomic so the webhook server never reads a partially written file
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, secret.Data[name], 0o600); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("renaming %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
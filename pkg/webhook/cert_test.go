@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/tls"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestCertManagerTick(t *testing.T) {
+	ctx := context.Background()
+	conf := testConfig(t)
+	cl := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	wh := testWebhook(nil)
+	c := newCertManager(cl, conf, []*webhook{wh}, logr.Discard())
+
+	secretKey := client.ObjectKey{Namespace: conf.WebhookServiceNamespace, Name: conf.WebhookServiceName + "-cert"}
+	getSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		require.NoError(t, cl.Get(ctx, secretKey, secret))
+		return secret
+	}
+	requireSynced := func(secret *corev1.Secret) {
+		for _, name := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			written, err := os.ReadFile(filepath.Join(conf.WebhookCertDir, name))
+			require.NoError(t, err)
+			require.Equal(t, secret.Data[name], written)
+		}
+
+		vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		require.NoError(t, cl.Get(ctx, client.ObjectKey{Name: conf.WebhookServiceName}, vwc))
+		require.Len(t, vwc.Webhooks, 1)
+		require.Equal(t, secret.Data[caKey], vwc.Webhooks[0].ClientConfig.CABundle)
+	}
+
+	t.Run("bootstrap issues a certificate", func(t *testing.T) {
+		require.NoError(t, c.tick(ctx))
+
+		secret := getSecret()
+		info, err := tls.ParseTLSCertificate(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"app-routing-operator-webhook.kube-system.svc",
+			"app-routing-operator-webhook.kube-system.svc.cluster.local",
+		}, info.DNSNames)
+		requireSynced(secret)
+	})
+
+	t.Run("valid certificate is reused", func(t *testing.T) {
+		before := getSecret()
+		require.NoError(t, c.tick(ctx))
+
+		after := getSecret()
+		require.Equal(t, before.Data, after.Data)
+		requireSynced(after)
+	})
+
+	t.Run("certificate due for renewal is rotated", func(t *testing.T) {
+		before := getSecret()
+		c.now = func() time.Time { return time.Now().Add(certValidity - certRenewBefore) }
+		t.Cleanup(func() { c.now = time.Now })
+
+		require.NoError(t, c.tick(ctx))
+
+		after := getSecret()
+		require.NotEqual(t, before.Data[corev1.TLSCertKey], after.Data[corev1.TLSCertKey])
+		// the previous CA is still trusted
+		require.Contains(t, string(after.Data[caKey]), string(before.Data[caKey]))
+		require.Greater(t, len(after.Data[caKey]), len(before.Data[caKey]))
+		requireSynced(after)
+	})
+
+	t.Run("invalid certificate is replaced", func(t *testing.T) {
+		secret := getSecret()
+		secret.Data[corev1.TLSCertKey] = []byte("garbage")
+		require.NoError(t, cl.Update(ctx, secret))
+
+		require.NoError(t, c.tick(ctx))
+
+		after := getSecret()
+		_, err := tls.ParseTLSCertificate(after.Data[corev1.TLSCertKey], after.Data[corev1.TLSPrivateKeyKey])
+		require.NoError(t, err)
+		requireSynced(after)
+	})
+}
+
+func TestCertManagerRace(t *testing.T) {
+	ctx := context.Background()
+	conf := testConfig(t)
+	secretKey := client.ObjectKey{Namespace: conf.WebhookServiceNamespace, Name: conf.WebhookServiceName + "-cert"}
+
+	names := []string{
+		"app-routing-operator-webhook.kube-system.svc",
+		"app-routing-operator-webhook.kube-system.svc.cluster.local",
+	}
+	ca, cert, key, err := tls.GenerateServingCertificate(names, certValidity)
+	require.NoError(t, err)
+	winnerData := map[string][]byte{caKey: ca, corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key}
+
+	requireWinner := func(t *testing.T, cl client.Client, certDir string) {
+		secret := &corev1.Secret{}
+		require.NoError(t, cl.Get(ctx, secretKey, secret))
+		require.Equal(t, winnerData, secret.Data)
+
+		for _, name := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			written, err := os.ReadFile(filepath.Join(certDir, name))
+			require.NoError(t, err)
+			require.Equal(t, winnerData[name], written)
+		}
+	}
+
+	t.Run("create lost to another replica", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(testScheme(t)).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*corev1.Secret); !ok {
+					return cl.Create(ctx, obj, opts...)
+				}
+
+				winner := obj.DeepCopyObject().(*corev1.Secret)
+				winner.Data = winnerData
+				require.NoError(t, cl.Create(ctx, winner))
+				return apierrors.NewAlreadyExists(corev1.Resource("secrets"), obj.GetName())
+			},
+		}).Build()
+		conf := testConfig(t)
+		c := newCertManager(cl, conf, []*webhook{testWebhook(nil)}, logr.Discard())
+
+		require.NoError(t, c.tick(ctx))
+		requireWinner(t, cl, conf.WebhookCertDir)
+	})
+
+	t.Run("rotation lost to another replica", func(t *testing.T) {
+		expired := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("garbage")},
+		}
+		cl := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(expired).WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*corev1.Secret); !ok {
+					return cl.Update(ctx, obj, opts...)
+				}
+
+				winner := &corev1.Secret{}
+				require.NoError(t, cl.Get(ctx, secretKey, winner))
+				winner.Data = winnerData
+				require.NoError(t, cl.Update(ctx, winner))
+				return apierrors.NewConflict(corev1.Resource("secrets"), obj.GetName(), errors.New("the object has been modified"))
+			},
+		}).Build()
+		conf := testConfig(t)
+		c := newCertManager(cl, conf, []*webhook{testWebhook(nil)}, logr.Discard())
+
+		require.NoError(t, c.tick(ctx))
+		requireWinner(t, cl, conf.WebhookCertDir)
+	})
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conversionPath is where the webhook server converts between the versions of the App Routing CRDs
+const conversionPath = "/convert"
+
+// conversionCRDs are the CRDs served in more than one version. Versions of the same CRD share a schema so the API server can still
+// convert between them without the webhook while webhooks are disabled.
+var conversionCRDs = []string{
+	"nginxingresscontrollers.approuting.kubernetes.azure.com",
+	"externaldnses.approuting.kubernetes.azure.com",
+}
+
+// setConversion points the CRDs served in more than one version at the conversion webhook, or back at the API server when caBundle is nil.
+// CRDs whose versions share a schema are always converted by the API server so they don't depend on the webhook being available.
+// CRDs that aren't installed are skipped.
+func setConversion(ctx context.Context, cl client.Client, conf *config.Config, caBundle []byte) error {
+	none := &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter}
+	webhook := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: conf.WebhookServiceNamespace,
+					Name:      conf.WebhookServiceName,
+					Path:      util.ToPtr(conversionPath),
+					Port:      util.Int32Ptr(443),
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+
+	for _, name := range conversionCRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := cl.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("getting crd %s: %w", name, err)
+		}
+
+		desired := none
+		if caBundle != nil && !sameSchemas(crd) {
+			desired = webhook
+		}
+		if equality.Semantic.DeepEqual(crd.Spec.Conversion, desired) {
+			continue
+		}
+
+		patch := client.MergeFrom(crd.DeepCopy())
+		crd.Spec.Conversion = desired
+		if err := cl.Patch(ctx, crd, patch); err != nil {
+			return fmt.Errorf("patching conversion of crd %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// sameSchemas returns true if every version of the CRD has the same schema
+func sameSchemas(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, version := range crd.Spec.Versions {
+		if !equality.Semantic.DeepEqual(version.Schema, crd.Spec.Versions[0].Schema) {
+			return false
+		}
+	}
+
+	return true
+}
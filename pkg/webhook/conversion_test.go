@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetConversion(t *testing.T) {
+	ctx := context.Background()
+	conf := testConfig(t)
+	// the ExternalDNS CRD isn't installed when workload identity is disabled
+	nicCrd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: conversionCRDs[0]},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"}}},
+				{Name: "v1alpha1", Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"}}},
+			},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(nicCrd).Build()
+	getConversion := func() *apiextensionsv1.CustomResourceConversion {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nicCrd), crd))
+		return crd.Spec.Conversion
+	}
+
+	// versions with the same schema are converted by the API server
+	require.NoError(t, setConversion(ctx, cl, conf, []byte("ca")))
+	require.Equal(t, apiextensionsv1.NoneConverter, getConversion().Strategy)
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(nicCrd), crd))
+	crd.Spec.Versions[1].Schema.OpenAPIV3Schema.Description = "changed"
+	require.NoError(t, cl.Update(ctx, crd))
+
+	require.NoError(t, setConversion(ctx, cl, conf, []byte("ca")))
+	got := getConversion()
+	require.Equal(t, apiextensionsv1.WebhookConverter, got.Strategy)
+	require.Equal(t, []byte("ca"), got.Webhook.ClientConfig.CABundle)
+	require.Equal(t, conf.WebhookServiceName, got.Webhook.ClientConfig.Service.Name)
+	require.Equal(t, conf.WebhookServiceNamespace, got.Webhook.ClientConfig.Service.Namespace)
+	require.Equal(t, conversionPath, *got.Webhook.ClientConfig.Service.Path)
+
+	// unchanged config isn't patched again
+	require.NoError(t, setConversion(ctx, cl, conf, []byte("ca")))
+
+	require.NoError(t, setConversion(ctx, cl, conf, nil))
+	got = getConversion()
+	require.Equal(t, apiextensionsv1.NoneConverter, got.Strategy)
+	require.Nil(t, got.Webhook)
+}
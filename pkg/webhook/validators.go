@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	approutingv1alpha1 "github.com/Azure/aks-app-routing-operator/api/v1alpha1"
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/dns"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/keyvault/spc"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/nginxingress"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// keyVaultURIAnnotation is the Ingress annotation referencing the Key Vault certificate App Routing syncs
+const keyVaultURIAnnotation = "kubernetes.azure.com/tls-cert-keyvault-uri"
+
+var (
+	nginxIngressControllerWebhookName = controllername.New("nginx", "ingress", "controller", "webhook")
+	externalDNSWebhookName            = controllername.New("external", "dns", "webhook")
+	clusterExternalDNSWebhookName     = controllername.New("cluster", "external", "dns", "webhook")
+	ingressKeyVaultWebhookName        = controllername.New("ingress", "keyvault", "webhook")
+	gatewayKeyVaultWebhookName        = controllername.New("gateway", "keyvault", "webhook")
+)
+
+func rules(group, version, resource string, operations ...admissionregistrationv1.OperationType) []admissionregistrationv1.RuleWithOperations {
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: operations,
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{group},
+				APIVersions: []string{version},
+				Resources:   []string{resource},
+			},
+		},
+	}
+}
+
+// nginxIngressControllerWebhook rejects NginxIngressControllers whose IngressClass is already taken. Only creates are validated
+// because the IngressClass of an existing NginxIngressController can't change and already exists. The default NginxIngressController
+// is created by the operator and adopts an existing IngressClass.
+func nginxIngressControllerWebhook(cl client.Client, decoder admission.Decoder) *webhook {
+	return &webhook{
+		name:  nginxIngressControllerWebhookName,
+		path:  webhookPath(nginxIngressControllerWebhookName),
+		rules: rules(approutingv1alpha1.GroupVersion.Group, approutingv1alpha1.GroupVersion.Version, "nginxingresscontrollers", admissionregistrationv1.Create),
+		matchConditions: []admissionregistrationv1.MatchCondition{
+			{
+				Name:       "not-default",
+				Expression: fmt.Sprintf("object.metadata.name != %q", nginxingress.DefaultNicName),
+			},
+		},
+		validate: func(ctx context.Context, req admission.Request) error {
+			nic := &approutingv1alpha1.NginxIngressController{}
+			if err := decoder.Decode(req, nic); err != nil {
+				return fmt.Errorf("decoding NginxIngressController: %w", err)
+			}
+
+			collides, reason, err := nic.Collides(ctx, cl)
+			if err != nil {
+				return fmt.Errorf("checking for collisions: %w", err)
+			}
+			if collides {
+				return util.NewUserError(errors.New(reason), reason)
+			}
+
+			return nil
+		},
+	}
+}
+
+// externalDNSWebhook rejects ExternalDNSes the ExternalDNS reconciler can't deploy
+func externalDNSWebhook(cl client.Client, conf *config.Config, decoder admission.Decoder) *webhook {
+	return &webhook{
+		name:           externalDNSWebhookName,
+		path:           webhookPath(externalDNSWebhookName),
+		rules:          rules(approutingv1alpha1.GroupVersion.Group, approutingv1alpha1.GroupVersion.Version, "externaldnses", admissionregistrationv1.Create, admissionregistrationv1.Update),
+		objectSelector: notManagedByOperator(),
+		validate: func(ctx context.Context, req admission.Request) error {
+			obj := &approutingv1alpha1.ExternalDNS{}
+			if err := decoder.Decode(req, obj); err != nil {
+				return fmt.Errorf("decoding ExternalDNS: %w", err)
+			}
+
+			return dns.ValidateExternalDNSConfiguration(ctx, cl, conf, obj)
+		},
+	}
+}
+
+// clusterExternalDNSWebhook rejects ClusterExternalDNSes the ClusterExternalDNS reconciler can't deploy
+func clusterExternalDNSWebhook(cl client.Client, conf *config.Config, decoder admission.Decoder) *webhook {
+	return &webhook{
+		name:           clusterExternalDNSWebhookName,
+		path:           webhookPath(clusterExternalDNSWebhookName),
+		rules:          rules(approutingv1alpha1.GroupVersion.Group, approutingv1alpha1.GroupVersion.Version, "clusterexternaldnses", admissionregistrationv1.Create, admissionregistrationv1.Update),
+		objectSelector: notManagedByOperator(),
+		validate: func(ctx context.Context, req admission.Request) error {
+			obj := &approutingv1alpha1.ClusterExternalDNS{}
+			if err := decoder.Decode(req, obj); err != nil {
+				return fmt.Errorf("decoding ClusterExternalDNS: %w", err)
+			}
+
+			return dns.ValidateExternalDNSConfiguration(ctx, cl, conf, obj)
+		},
+	}
+}
+
+// ingressKeyVaultWebhook rejects Ingresses App Routing manages with Key Vault certificate or secret annotations that can't be
+// reconciled. Only Ingresses with one of the annotations are sent to the webhook.
+func ingressKeyVaultWebhook(cl client.Client, ingressManager util.IngressManager, decoder admission.Decoder) *webhook {
+	return &webhook{
+		name:  ingressKeyVaultWebhookName,
+		path:  webhookPath(ingressKeyVaultWebhookName),
+		rules: rules(netv1.GroupName, "v1", "ingresses", admissionregistrationv1.Create, admissionregistrationv1.Update),
+		matchConditions: []admissionregistrationv1.MatchCondition{
+			{
+				Name:       "keyvault-annotation",
+				Expression: hasAnyAnnotation(keyVaultURIAnnotation, spc.KeyVaultHostUrisAnnotation, spc.KeyVaultSecretsAnnotation),
+			},
+		},
+		validate: func(ctx context.Context, req admission.Request) error {
+			ing := &netv1.Ingress{}
+			if err := decoder.Decode(req, ing); err != nil {
+				return fmt.Errorf("decoding Ingress: %w", err)
+			}
+
+			return spc.ValidateIngress(ctx, cl, ingressManager, ing)
+		},
+	}
+}
+
+// gatewayKeyVaultWebhook rejects Gateways App Routing manages with Key Vault certificate TLS options that can't be reconciled
+func gatewayKeyVaultWebhook(cl client.Client, decoder admission.Decoder) *webhook {
+	return &webhook{
+		name:  gatewayKeyVaultWebhookName,
+		path:  webhookPath(gatewayKeyVaultWebhookName),
+		rules: rules(gatewayv1.GroupName, gatewayv1.GroupVersion.Version, "gateways", admissionregistrationv1.Create, admissionregistrationv1.Update),
+		validate: func(ctx context.Context, req admission.Request) error {
+			gw := &gatewayv1.Gateway{}
+			if err := decoder.Decode(req, gw); err != nil {
+				return fmt.Errorf("decoding Gateway: %w", err)
+			}
+
+			return spc.ValidateGateway(ctx, cl, gw)
+		},
+	}
+}
+
+// hasAnyAnnotation returns a CEL expression that matches objects with any of the annotations
+func hasAnyAnnotation(keys ...string) string {
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		conditions = append(conditions, fmt.Sprintf("%q in object.metadata.annotations", key))
+	}
+
+	return fmt.Sprintf("has(object.metadata.annotations) && (%s)", strings.Join(conditions, " || "))
+}
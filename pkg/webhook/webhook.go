@@ -0,0 +1,191 @@
+// Package webhook serves the App Routing Operator's validating admission webhooks. They reject resources the controllers would only
+// report as invalid through events after the fact.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/metrics"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// unvalidatedWarning is returned to clients when a request is allowed because it couldn't be validated. The error isn't returned
+// because it isn't meant for users.
+const unvalidatedWarning = "App Routing couldn't validate this request, it was allowed without validation"
+
+// webhook is a validating admission webhook served by the App Routing Operator
+type webhook struct {
+	name  controllername.ControllerNamer
+	path  string
+	rules []admissionregistrationv1.RuleWithOperations
+	// objectSelector and matchConditions narrow down the requests sent to the webhook beyond the rules
+	objectSelector  *metav1.LabelSelector
+	matchConditions []admissionregistrationv1.MatchCondition
+
+	// validate returns a util.UserError to deny the request. Other errors allow the request with a warning so App Routing being
+	// unable to validate doesn't block writes.
+	validate func(ctx context.Context, req admission.Request) error
+}
+
+// Handle implements admission.Handler
+func (w *webhook) Handle(ctx context.Context, req admission.Request) (resp admission.Response) {
+	var err error
+	defer func() {
+		metrics.HandleWebhookHandlerMetrics(w.name, resp, err)
+	}()
+
+	lgr := w.name.AddToLogger(log.FromContext(ctx)).WithValues("namespace", req.Namespace, "name", req.Name, "operation", req.Operation)
+	ctx = log.IntoContext(ctx, lgr)
+
+	if err = w.validate(ctx, req); err != nil {
+		var userErr util.UserError
+		if errors.As(err, &userErr) {
+			lgr.Info("denying request", "reason", userErr.UserError())
+			return admission.Denied(userErr.UserError())
+		}
+
+		lgr.Error(err, "unable to validate request, allowing it")
+		return admission.Allowed("").WithWarnings(unvalidatedWarning)
+	}
+
+	return admission.Allowed("")
+}
+
+// Setup registers the webhooks enabled by the config and the CRD conversion webhook with the manager's webhook server and keeps their
+// serving certificate, ValidatingWebhookConfiguration and CRD conversion config up to date. cl must work before the manager starts. When
+// webhooks are disabled, a ValidatingWebhookConfiguration left behind from when they were enabled is removed and the CRDs are converted
+// by the API server again.
+func Setup(mgr ctrl.Manager, conf *config.Config, cl client.Client, ingressManager util.IngressManager, lgr logr.Logger) error {
+	if !conf.EnableWebhooks {
+		lgr.Info("webhooks are disabled, removing ValidatingWebhookConfiguration")
+		vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		vwc.SetName(conf.WebhookServiceName)
+		if err := cl.Delete(context.Background(), vwc); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting ValidatingWebhookConfiguration: %w", err)
+		}
+
+		lgr.Info("webhooks are disabled, removing CRD conversion webhook")
+		if err := setConversion(context.Background(), cl, conf, nil); err != nil {
+			return fmt.Errorf("removing CRD conversion webhook: %w", err)
+		}
+
+		return nil
+	}
+
+	decoder := admission.NewDecoder(mgr.GetScheme())
+	webhooks := []*webhook{}
+	if !conf.DisableIngressNginx {
+		webhooks = append(webhooks, nginxIngressControllerWebhook(mgr.GetClient(), decoder))
+	}
+	if conf.EnabledWorkloadIdentity {
+		webhooks = append(webhooks, externalDNSWebhook(mgr.GetClient(), conf, decoder))
+	}
+	if conf.EnabledWorkloadIdentity || conf.EnableDefaultDomain {
+		webhooks = append(webhooks, clusterExternalDNSWebhook(mgr.GetClient(), conf, decoder))
+	}
+	if !conf.DisableKeyvault && ingressManager != nil {
+		webhooks = append(webhooks, ingressKeyVaultWebhook(mgr.GetClient(), ingressManager, decoder))
+	}
+	if !conf.DisableKeyvault && conf.EnableGatewayTLS {
+		webhooks = append(webhooks, gatewayKeyVaultWebhook(mgr.GetClient(), decoder))
+	}
+
+	certs := newCertManager(cl, conf, webhooks, lgr)
+	lgr.Info("bootstrapping webhook serving certificate")
+	if err := certs.tick(context.Background()); err != nil {
+		return fmt.Errorf("bootstrapping webhook serving certificate: %w", err)
+	}
+	if err := mgr.Add(certs); err != nil {
+		return fmt.Errorf("adding webhook certificate manager: %w", err)
+	}
+
+	server := mgr.GetWebhookServer()
+	lgr.Info("registering CRD conversion webhook", "path", conversionPath)
+	server.Register(conversionPath, conversion.NewWebhookHandler(mgr.GetScheme()))
+	for _, wh := range webhooks {
+		lgr.Info("registering webhook", "path", wh.path)
+		metrics.InitControllerMetrics(wh.name)
+		server.Register(wh.path, &admission.Webhook{Handler: wh})
+	}
+
+	return nil
+}
+
+// validatingWebhookConfiguration returns the ValidatingWebhookConfiguration that sends admission requests for the webhooks to the
+// webhook Service and trusts the CA bundle
+func validatingWebhookConfiguration(conf *config.Config, webhooks []*webhook, caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	// unreachable webhooks are ignored so the operator being unavailable doesn't block writes. The controllers still report invalid resources.
+	failurePolicy := admissionregistrationv1.Ignore
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+
+	ret := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ValidatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   conf.WebhookServiceName,
+			Labels: manifests.GetTopLevelLabels(),
+		},
+	}
+
+	for _, wh := range webhooks {
+		ret.Webhooks = append(ret.Webhooks, admissionregistrationv1.ValidatingWebhook{
+			Name: wh.name.LoggerName() + ".approuting.kubernetes.azure.com",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: conf.WebhookServiceNamespace,
+					Name:      conf.WebhookServiceName,
+					Path:      util.ToPtr(wh.path),
+				},
+				CABundle: caBundle,
+			},
+			Rules:                   wh.rules,
+			ObjectSelector:          wh.objectSelector,
+			MatchConditions:         wh.matchConditions,
+			FailurePolicy:           &failurePolicy,
+			MatchPolicy:             &matchPolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+			TimeoutSeconds:          util.Int32Ptr(10),
+		})
+	}
+
+	return ret
+}
+
+func webhookPath(name controllername.ControllerNamer) string {
+	return path.Join("/validate", name.LoggerName())
+}
+
+// managedByLabel is set on the resources the App Routing Operator creates
+const managedByLabel = "app.kubernetes.io/managed-by"
+
+// notManagedByOperator excludes the resources the App Routing Operator creates itself, they're valid by construction
+func notManagedByOperator() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      managedByLabel,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{manifests.GetTopLevelLabels()[managedByLabel]},
+			},
+		},
+	}
+}
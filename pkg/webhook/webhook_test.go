@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/aks-app-routing-operator/pkg/config"
+	"github.com/Azure/aks-app-routing-operator/pkg/controller/controllername"
+	"github.com/Azure/aks-app-routing-operator/pkg/manifests"
+	"github.com/Azure/aks-app-routing-operator/pkg/util"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		EnableWebhooks:          true,
+		WebhookCertDir:          t.TempDir(),
+		WebhookServiceName:      "app-routing-operator-webhook",
+		WebhookServiceNamespace: "kube-system",
+	}
+}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, apiextensionsv1.AddToScheme(s))
+	return s
+}
+
+func testWebhook(validate func(context.Context, admission.Request) error) *webhook {
+	name := controllername.New("test", "webhook")
+	return &webhook{
+		name:     name,
+		path:     webhookPath(name),
+		rules:    rules("group", "v1", "things", admissionregistrationv1.Create),
+		validate: validate,
+	}
+}
+
+func TestHandle(t *testing.T) {
+	cases := []struct {
+		name        string
+		err         error
+		wantAllowed bool
+		wantCode    int32
+		wantMessage string
+		wantWarning string
+	}{
+		{
+			name:        "allowed",
+			wantAllowed: true,
+			wantCode:    http.StatusOK,
+		},
+		{
+			name:        "user error",
+			err:         util.NewUserError(errors.New("internal detail"), "fix your resource"),
+			wantCode:    http.StatusForbidden,
+			wantMessage: "fix your resource",
+		},
+		{
+			name:        "wrapped user error",
+			err:         errors.Join(errors.New("context"), util.NewUserError(errors.New("internal detail"), "fix your resource")),
+			wantCode:    http.StatusForbidden,
+			wantMessage: "fix your resource",
+		},
+		{
+			name:        "other error",
+			err:         errors.New("api server unavailable"),
+			wantAllowed: true,
+			wantCode:    http.StatusOK,
+			wantWarning: unvalidatedWarning,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wh := testWebhook(func(context.Context, admission.Request) error { return tc.err })
+
+			resp := wh.Handle(context.Background(), admission.Request{})
+			require.Equal(t, tc.wantAllowed, resp.Allowed)
+			require.Equal(t, tc.wantCode, resp.Result.Code)
+			require.Equal(t, tc.wantMessage, resp.Result.Message)
+			if tc.wantWarning == "" {
+				require.Empty(t, resp.Warnings)
+			} else {
+				require.Equal(t, []string{tc.wantWarning}, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestValidatingWebhookConfiguration(t *testing.T) {
+	conf := testConfig(t)
+	wh := testWebhook(nil)
+	wh.objectSelector = notManagedByOperator()
+
+	vwc := validatingWebhookConfiguration(conf, []*webhook{wh}, []byte("ca"))
+	require.Equal(t, conf.WebhookServiceName, vwc.Name)
+	require.Len(t, vwc.Webhooks, 1)
+
+	got := vwc.Webhooks[0]
+	require.Equal(t, "test-webhook.approuting.kubernetes.azure.com", got.Name)
+	require.Equal(t, "/validate/test-webhook", *got.ClientConfig.Service.Path)
+	require.Equal(t, conf.WebhookServiceName, got.ClientConfig.Service.Name)
+	require.Equal(t, conf.WebhookServiceNamespace, got.ClientConfig.Service.Namespace)
+	require.Equal(t, []byte("ca"), got.ClientConfig.CABundle)
+	require.Equal(t, wh.rules, got.Rules)
+	require.Equal(t, admissionregistrationv1.Ignore, *got.FailurePolicy)
+	require.Equal(t, admissionregistrationv1.SideEffectClassNone, *got.SideEffects)
+
+	selector, err := metav1.LabelSelectorAsSelector(got.ObjectSelector)
+	require.NoError(t, err)
+	require.True(t, selector.Matches(labels.Set{}))
+	require.False(t, selector.Matches(labels.Set(manifests.GetTopLevelLabels())))
+}
+
+func TestHasAnyAnnotation(t *testing.T) {
+	require.Equal(t, `has(object.metadata.annotations) && ("a" in object.metadata.annotations)`, hasAnyAnnotation("a"))
+	require.Equal(t,
+		`has(object.metadata.annotations) && ("a" in object.metadata.annotations || "b/c" in object.metadata.annotations)`,
+		hasAnyAnnotation("a", "b/c"),
+	)
+}
+
+func TestSetupDisabled(t *testing.T) {
+	conf := testConfig(t)
+	conf.EnableWebhooks = false
+
+	existing := validatingWebhookConfiguration(conf, nil, nil)
+	cl := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(existing).Build()
+
+	require.NoError(t, Setup(nil, conf, cl, nil, logr.Discard()))
+	err := cl.Get(context.Background(), client.ObjectKeyFromObject(existing), &admissionregistrationv1.ValidatingWebhookConfiguration{})
+	require.True(t, apierrors.IsNotFound(err))
+
+	// nothing left to remove
+	require.NoError(t, Setup(nil, conf, cl, nil, logr.Discard()))
+}
@@ -28,6 +28,11 @@ const (
 	ManagedResourceNs = "app-routing-system"
 )
 
+const (
+	webhookServiceName = "app-routing-operator-webhook"
+	webhookPort        = 9443
+)
+
 const (
 	defaultDomainServerName = "default-domain-server"
 	defaultDomainCertSecret = "default-domain-cert"
@@ -250,6 +255,11 @@ func (o *OperatorConfig) args(publicZones, privateZones []string) []string {
 		// these two don't do anything yet in the e2e test but are needed so the operator can run
 		ret = append(ret, "--default-domain-client-id", "test-default-domain-client-id")
 		ret = append(ret, "--default-domain-zone-id", "/subscriptions/test-subscription/resourceGroups/test-rg/providers/Microsoft.Network/dnszones/test-domain.com")
+
+		ret = append(ret, "--enable-webhooks")
+		ret = append(ret, "--webhook-port", fmt.Sprintf("%d", webhookPort))
+		ret = append(ret, "--webhook-service-name", webhookServiceName)
+		ret = append(ret, "--webhook-service-namespace", operatorNs)
 	}
 
 	if o.Version.SupportsDalec() {
@@ -423,6 +433,7 @@ func Operator(latestImage string, publicZones, privateZones []string, cfg *Opera
 	if cfg.Version == OperatorVersionLatest {
 		defaultDomainRes := DefaultDomainServer(defaultDomainServerName)
 		ret = append(defaultDomainRes, ret...)
+		ret = append(ret, webhookService())
 	}
 
 	// edit and select relevant manifest config by version
@@ -441,3 +452,32 @@ func Operator(latestImage string, publicZones, privateZones []string, cfg *Opera
 
 	return ret
 }
+
+// webhookService routes the API server's admission requests to the operator's webhook server
+func webhookService() *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      webhookServiceName,
+			Namespace: operatorNs,
+			Labels: map[string]string{
+				ManagedByKey: ManagedByVal,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: operatorDeploymentLabels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "webhook",
+					Port:       443,
+					TargetPort: intstr.FromInt(webhookPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}